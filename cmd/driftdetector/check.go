@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"driftdetector/internal/providers/aws"
+	"driftdetector/internal/terraform"
+	"driftdetector/pkg/logging"
+)
+
+// preflightCheck is a single check run by the check subcommand. run returns a short detail shown on success.
+type preflightCheck struct {
+	name string
+	run  func(ctx context.Context) (string, error)
+}
+
+// newCheckCommand creates the check subcommand, which validates that AWS credentials resolve, that the region is
+// valid and that the Terraform configuration exists and parses, so that a scan does not fail halfway.
+func newCheckCommand() *cobra.Command {
+	var region string
+	var configPath string
+	var desiredJSON string
+
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Check AWS credentials, region and Terraform configuration before a scan",
+		// The error is printed once by main
+		SilenceErrors: true,
+		// A failed check is not a usage error
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if configPath != "" && desiredJSON != "" {
+				return fmt.Errorf("--config-path and --desired-json are mutually exclusive")
+			}
+
+			ctx := context.Background()
+			preflight, err := aws.NewPreflight(ctx, region)
+			if err != nil {
+				return fmt.Errorf("failed to initialize AWS clients: %w", err)
+			}
+
+			checks := []preflightCheck{
+				{name: "AWS credentials", run: preflight.CallerIdentity},
+				{name: "AWS region", run: func(ctx context.Context) (string, error) {
+					return preflight.Region(), preflight.CheckRegion(ctx)
+				}},
+				configCheck(configPath, desiredJSON),
+			}
+
+			if failed := runChecks(ctx, os.Stdout, checks); failed > 0 {
+				return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&region, "region", "", "AWS region to check (default: SDK configured region)")
+	cmd.Flags().StringVar(&configPath, "config-path", "", "Path to the Terraform configuration file to check")
+	cmd.Flags().StringVar(&desiredJSON, "desired-json", "", "Path to the desired-state JSON file to check, instead of a Terraform configuration")
+	return cmd
+}
+
+// configCheck returns the check that the desired state parses, from the Terraform configuration or the
+// desired-state JSON file, whichever is given.
+func configCheck(configPath, desiredJSON string) preflightCheck {
+	// Parser warnings would interleave with the check results
+	logger := logging.NewDefaultLogger()
+	logger.SetLevel(logging.ERROR)
+
+	if desiredJSON != "" {
+		return preflightCheck{name: "Desired-state JSON", run: func(ctx context.Context) (string, error) {
+			return parseCheck(ctx, terraform.NewJSONParserWithLogger(logger), desiredJSON)
+		}}
+	}
+	return preflightCheck{name: "Terraform configuration", run: func(ctx context.Context) (string, error) {
+		if configPath == "" {
+			return "", fmt.Errorf("no configuration given: use --config-path or --desired-json")
+		}
+		return parseCheck(ctx, terraform.NewParserWithLogger(logger), configPath)
+	}}
+}
+
+// parseCheck checks that the file at path provides a desired state. The path may be an s3:// or http(s)://
+// URL, so reading it is left to the provider, which reports missing files.
+func parseCheck(ctx context.Context, provider terraform.DesiredStateProvider, path string) (string, error) {
+	if _, err := provider.GetDesiredState(ctx, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// runChecks runs every check, writing a PASS or FAIL line for each, and returns the number of failed checks.
+// All checks run even after a failure, so that every problem is reported at once.
+func runChecks(ctx context.Context, w io.Writer, checks []preflightCheck) int {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	failed := 0
+	for _, check := range checks {
+		detail, err := check.run(ctx)
+		if err != nil {
+			failed++
+			fmt.Fprintf(tw, "FAIL\t%s\t%v\n", check.name, err)
+			continue
+		}
+		fmt.Fprintf(tw, "PASS\t%s\t%s\n", check.name, detail)
+	}
+	tw.Flush()
+	return failed
+}
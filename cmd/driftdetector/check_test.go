@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunChecks(t *testing.T) {
+	var ran []string
+	checks := []preflightCheck{
+		{name: "first", run: func(context.Context) (string, error) {
+			ran = append(ran, "first")
+			return "ok detail", nil
+		}},
+		{name: "second", run: func(context.Context) (string, error) {
+			ran = append(ran, "second")
+			return "", errors.New("access denied")
+		}},
+		{name: "third", run: func(context.Context) (string, error) {
+			ran = append(ran, "third")
+			return "", nil
+		}},
+	}
+
+	var out bytes.Buffer
+	failed := runChecks(context.Background(), &out, checks)
+
+	assert.Equal(t, 1, failed)
+	assert.Equal(t, []string{"first", "second", "third"}, ran, "every check should run after a failure")
+	assert.Contains(t, out.String(), "PASS  first")
+	assert.Contains(t, out.String(), "ok detail")
+	assert.Contains(t, out.String(), "FAIL  second")
+	assert.Contains(t, out.String(), "access denied")
+	assert.Contains(t, out.String(), "PASS  third")
+}
+
+func TestConfigCheck(t *testing.T) {
+	ctx := context.Background()
+	configPath := filepath.Join("..", "..", "internal", "terraform", "testdata", "valid_instance.tf")
+
+	t.Run("Valid configuration", func(t *testing.T) {
+		detail, err := configCheck(configPath, "").run(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, configPath, detail)
+	})
+
+	t.Run("Missing file", func(t *testing.T) {
+		_, err := configCheck(filepath.Join(t.TempDir(), "missing.tf"), "").run(ctx)
+		assert.Error(t, err)
+	})
+
+	t.Run("Remote configuration", func(t *testing.T) {
+		hcl, err := os.ReadFile(configPath)
+		require.NoError(t, err)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(hcl)
+		}))
+		defer server.Close()
+
+		detail, err := configCheck(server.URL+"/main.tf", "").run(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, server.URL+"/main.tf", detail)
+	})
+
+	t.Run("No configuration given", func(t *testing.T) {
+		check := configCheck("", "")
+		_, err := check.run(ctx)
+		assert.ErrorContains(t, err, "no configuration given")
+		assert.Equal(t, "Terraform configuration", check.name)
+	})
+
+	t.Run("Desired-state JSON", func(t *testing.T) {
+		check := configCheck("", filepath.Join(t.TempDir(), "missing.json"))
+		assert.Equal(t, "Desired-state JSON", check.name)
+		_, err := check.run(ctx)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"driftdetector/internal/report"
+)
+
+// newCompareCommand creates the compare subcommand, which diffs the JSON reports of two saved runs to show
+// the drift introduced and resolved in between, without querying AWS.
+func newCompareCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "compare <earlier.json> <later.json>",
+		Short: "Compare the JSON reports of two runs: newly drifted, resolved and still drifting attributes",
+		Args:  cobra.ExactArgs(2),
+		// The error is printed once by main
+		SilenceErrors: true,
+		// An unreadable report is not a usage error
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			before, err := readJSONReport(args[0])
+			if err != nil {
+				return err
+			}
+			after, err := readJSONReport(args[1])
+			if err != nil {
+				return err
+			}
+
+			return report.WriteRunComparison(os.Stdout, report.CompareRuns(before.Reports, after.Reports))
+		},
+	}
+}
+
+// readJSONReport reads the JSON report saved by a run with --output json
+func readJSONReport(path string) (*report.JSONEnvelope, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open report: %w", err)
+	}
+	defer file.Close()
+
+	envelope, err := report.ReadJSONReport(file)
+	if err != nil {
+		return nil, fmt.Errorf("invalid report %s: %w", path, err)
+	}
+	return envelope, nil
+}
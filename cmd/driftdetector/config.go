@@ -0,0 +1,148 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultConfigFile is read from the working directory when --config is not set
+const defaultConfigFile = "driftdetector.yaml"
+
+// configFlag names the flag that points at the config file, which can't itself be set from the file
+const configFlag = "config"
+
+// attributeProfilesKey names the config file option defining attribute profiles, which has no flag
+const attributeProfilesKey = "attribute-profiles"
+
+// envPrefix prefixes the environment variables that set flags, e.g. DRIFTDETECTOR_INSTANCE_IDS
+const envPrefix = "DRIFTDETECTOR_"
+
+// applyEnvironment sets the flags that were not given on the command line from DRIFTDETECTOR_-prefixed
+// environment variables. It runs before applyConfigFile, so environment variables override the config file.
+func applyEnvironment(flags *pflag.FlagSet) error {
+	var err error
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if err != nil || flag.Changed {
+			return
+		}
+		value, ok := os.LookupEnv(envVarName(flag.Name))
+		if !ok {
+			return
+		}
+		if setErr := flags.Set(flag.Name, value); setErr != nil {
+			err = fmt.Errorf("invalid value for %s: %w", envVarName(flag.Name), setErr)
+		}
+	})
+	return err
+}
+
+// envVarName returns the environment variable for a flag, e.g. DRIFTDETECTOR_CONFIG_PATH for --config-path
+func envVarName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// applyConfigFile sets the flags that were not given on the command line or by environment variables
+// from a YAML config file.
+// The file maps flag names to values, e.g.:
+//
+//	instance-ids: [i-123, i-456]
+//	config-path: ./main.tf
+//	concurrency: 4
+//
+// The file can also define named attribute sets for --attribute-profile, which are returned:
+//
+//	attribute-profiles:
+//	  compute: [instance_type, ami]
+//
+// When path is empty the default config file is used if it exists.
+func applyConfigFile(flags *pflag.FlagSet, path string) (map[string][]string, error) {
+	explicit := path != ""
+	if !explicit {
+		path = defaultConfigFile
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	var values map[string]any
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to decode config file %s: %w", path, err)
+	}
+
+	// Apply in a stable order so errors are reported deterministically
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var profiles map[string][]string
+	for _, name := range names {
+		if name == attributeProfilesKey {
+			if profiles, err = configAttributeProfiles(values[name]); err != nil {
+				return nil, fmt.Errorf("config file %s: invalid value for %q: %w", path, name, err)
+			}
+			continue
+		}
+		flag := flags.Lookup(name)
+		if flag == nil || name == configFlag {
+			return nil, fmt.Errorf("config file %s: unknown option %q", path, name)
+		}
+		// Flags given on the command line take precedence
+		if flag.Changed {
+			continue
+		}
+		if err := flags.Set(name, configValueString(values[name])); err != nil {
+			return nil, fmt.Errorf("config file %s: invalid value for %q: %w", path, name, err)
+		}
+	}
+	return profiles, nil
+}
+
+// configValueString converts a config file value to its flag string form. Lists become comma-separated.
+func configValueString(value any) string {
+	list, ok := value.([]any)
+	if !ok {
+		return fmt.Sprint(value)
+	}
+
+	items := make([]string, len(list))
+	for i, item := range list {
+		items[i] = fmt.Sprint(item)
+	}
+	return strings.Join(items, ",")
+}
+
+// configAttributeProfiles converts the attribute-profiles option, a map of profile names to lists of attributes
+func configAttributeProfiles(value any) (map[string][]string, error) {
+	entries, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("expected a map of profile names to lists of attributes")
+	}
+
+	profiles := make(map[string][]string, len(entries))
+	for name, entry := range entries {
+		list, ok := entry.([]any)
+		if !ok {
+			return nil, fmt.Errorf("profile %q: expected a list of attributes", name)
+		}
+		attributes := make([]string, len(list))
+		for i, item := range list {
+			attributes[i] = fmt.Sprint(item)
+		}
+		profiles[name] = attributes
+	}
+	return profiles, nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testFlags creates a flag set with a representative subset of the CLI flags
+func testFlags() (*pflag.FlagSet, *string, *string, *int, *bool, *time.Duration) {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	instanceIDs := flags.String("instance-ids", "", "")
+	output := flags.String("output", "table", "")
+	concurrency := flags.Int("concurrency", 1, "")
+	quiet := flags.Bool("quiet", false, "")
+	interval := flags.Duration("interval", 5*time.Minute, "")
+	flags.String(configFlag, "", "")
+	return flags, instanceIDs, output, concurrency, quiet, interval
+}
+
+// writeConfigFile writes a config file to a temporary directory and returns its path
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "driftdetector.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestApplyConfigFile(t *testing.T) {
+	path := writeConfigFile(t, `
+instance-ids: [i-123, i-456]
+output: json
+concurrency: 4
+quiet: true
+interval: 30s
+`)
+	flags, instanceIDs, output, concurrency, quiet, interval := testFlags()
+
+	// Flags given on the command line override the file
+	require.NoError(t, flags.Parse([]string{"--output", "sarif"}))
+	profiles, err := applyConfigFile(flags, path)
+	require.NoError(t, err)
+	assert.Nil(t, profiles)
+
+	assert.Equal(t, "i-123,i-456", *instanceIDs)
+	assert.Equal(t, "sarif", *output)
+	assert.Equal(t, 4, *concurrency)
+	assert.True(t, *quiet)
+	assert.Equal(t, 30*time.Second, *interval)
+}
+
+func TestApplyConfigFile_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"Unknown option", "instance-id: i-123\n"},
+		{"Config option", "config: other.yaml\n"},
+		{"Invalid value", "concurrency: many\n"},
+		{"Invalid YAML", "instance-ids: [\n"},
+		{"Invalid attribute profiles", "attribute-profiles: [instance_type]\n"},
+		{"Invalid attribute profile", "attribute-profiles:\n  compute: instance_type\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags, _, _, _, _, _ := testFlags()
+			_, err := applyConfigFile(flags, writeConfigFile(t, tt.content))
+			assert.Error(t, err)
+		})
+	}
+
+	// An explicitly requested file must exist
+	flags, _, _, _, _, _ := testFlags()
+	_, err := applyConfigFile(flags, filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestApplyConfigFile_AttributeProfiles(t *testing.T) {
+	path := writeConfigFile(t, `
+output: json
+attribute-profiles:
+  compute: [instance_type, ami]
+  network: [subnet_id]
+`)
+	flags, _, output, _, _, _ := testFlags()
+
+	profiles, err := applyConfigFile(flags, path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"compute": {"instance_type", "ami"}, "network": {"subnet_id"}}, profiles)
+	assert.Equal(t, "json", *output)
+}
+
+func TestApplyConfigFile_DefaultFileOptional(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(t.TempDir()))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	flags, _, output, _, _, _ := testFlags()
+	_, err = applyConfigFile(flags, "")
+	require.NoError(t, err)
+	assert.Equal(t, "table", *output, "Defaults apply without a config file")
+
+	require.NoError(t, os.WriteFile(defaultConfigFile, []byte("output: json\n"), 0o600))
+	_, err = applyConfigFile(flags, "")
+	require.NoError(t, err)
+	assert.Equal(t, "json", *output)
+}
+
+func TestApplyEnvironment(t *testing.T) {
+	t.Setenv("DRIFTDETECTOR_INSTANCE_IDS", "i-env")
+	t.Setenv("DRIFTDETECTOR_OUTPUT", "json")
+	t.Setenv("DRIFTDETECTOR_CONCURRENCY", "8")
+	path := writeConfigFile(t, "output: sarif\nconcurrency: 4\nquiet: true\n")
+
+	flags, instanceIDs, output, concurrency, quiet, _ := testFlags()
+
+	// Precedence: flag > environment variable > config file > default
+	require.NoError(t, flags.Parse([]string{"--concurrency", "2"}))
+	require.NoError(t, applyEnvironment(flags))
+	_, err := applyConfigFile(flags, path)
+	require.NoError(t, err)
+
+	assert.Equal(t, "i-env", *instanceIDs)
+	assert.Equal(t, "json", *output)
+	assert.Equal(t, 2, *concurrency)
+	assert.True(t, *quiet)
+}
+
+func TestApplyEnvironment_InvalidValue(t *testing.T) {
+	t.Setenv("DRIFTDETECTOR_CONCURRENCY", "many")
+
+	flags, _, _, _, _, _ := testFlags()
+	err := applyEnvironment(flags)
+
+	assert.ErrorContains(t, err, "DRIFTDETECTOR_CONCURRENCY")
+}
+
+func TestEnvVarName(t *testing.T) {
+	assert.Equal(t, "DRIFTDETECTOR_CONFIG_PATH", envVarName("config-path"))
+	assert.Equal(t, "DRIFTDETECTOR_CONFIG", envVarName(configFlag))
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"driftdetector/internal/providers/aws"
+	"driftdetector/internal/terraform"
+)
+
+// newExportCommand creates the export subcommand, which renders the current AWS state of instances as
+// Terraform HCL or desired-state JSON, to bootstrap a configuration from existing instances.
+func newExportCommand() *cobra.Command {
+	var instanceIDs string
+	var format string
+	var region string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Render the current AWS state of EC2 instances as Terraform HCL or desired-state JSON",
+		// The error is printed once by main
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if instanceIDs == "" {
+				return fmt.Errorf("the --instance-ids flag is required")
+			}
+
+			// Parse the comma-separated instance IDs
+			instanceIDSlice := strings.Split(instanceIDs, ",")
+			for i, id := range instanceIDSlice {
+				instanceIDSlice[i] = strings.TrimSpace(id)
+			}
+
+			ctx := context.Background()
+			var awsService *aws.InstanceService
+			var err error
+			if region != "" {
+				awsService, err = aws.NewInstanceServiceForRegion(ctx, region)
+			} else {
+				awsService, err = aws.NewInstanceServiceWithDefaultConfig(ctx)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to initialize AWS service: %w", err)
+			}
+
+			instances, err := awsService.GetInstancesDetails(ctx, instanceIDSlice)
+			if err != nil {
+				return fmt.Errorf("error fetching AWS instance details: %w", err)
+			}
+
+			data, err := terraform.Export(instances, strings.ToLower(format))
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(data)
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&instanceIDs, "instance-ids", "", "Comma-separated list of AWS EC2 instance IDs to export")
+	cmd.Flags().StringVar(&format, "format", terraform.ExportFormatHCL, "Export format: hcl (aws_instance resource blocks) or json (the --desired-json format)")
+	cmd.Flags().StringVar(&region, "region", "", "AWS region of the instances (default: SDK configured region)")
+	return cmd
+}
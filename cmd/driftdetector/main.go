@@ -2,40 +2,181 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"runtime"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"driftdetector/internal/driftcheck"
 	"driftdetector/internal/orchestrator"
+	"driftdetector/internal/providers/aws"
+	"driftdetector/internal/report"
 )
 
+// Exit codes reported by the CLI, so CI can tell drift and errors apart
+const (
+	ExitOK            = 0   // No drift and no errors
+	ExitError         = 1   // One or more instances could not be checked
+	ExitDrift         = 2   // Drift detected
+	ExitDriftAndError = 3   // Drift detected and one or more instances could not be checked
+	ExitInterrupted   = 130 // The run was interrupted; the summary covers the instances checked so far
+)
+
+// exitCode computes the process exit code from the run outcome.
+// errorExitCode replaces ExitError for runs with errors; when it is 0 errors are non-fatal
+// and a run with both drift and errors exits with ExitDrift instead of ExitDriftAndError.
+func exitCode(hasDrift, hasError bool, errorExitCode int) int {
+	switch {
+	case hasDrift && hasError && errorExitCode != ExitOK:
+		return ExitDriftAndError
+	case hasDrift:
+		return ExitDrift
+	case hasError:
+		return errorExitCode
+	default:
+		return ExitOK
+	}
+}
+
+// parseOutputFiles parses the comma-separated --output-file entries. A plain path receives the
+// document of the --output format; a path suffixed with a format (e.g. report.json:json) is an
+// additional report written in that format.
+func parseOutputFiles(value string) (string, []orchestrator.ReportSink, error) {
+	var outputFile string
+	var reports []orchestrator.ReportSink
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		// Split on the last colon only when the suffix is a known format, so paths may contain colons
+		if i := strings.LastIndex(entry, ":"); i >= 0 {
+			if format, err := report.ParseOutputFormat(entry[i+1:]); err == nil {
+				reports = append(reports, orchestrator.ReportSink{Format: string(format), File: entry[:i]})
+				continue
+			}
+		}
+
+		if outputFile != "" {
+			return "", nil, fmt.Errorf("only one --output-file entry may omit the format, got %s and %s", outputFile, entry)
+		}
+		outputFile = entry
+	}
+	return outputFile, reports, nil
+}
+
+// joinCategories joins error categories with commas, as accepted by --retry-on
+func joinCategories(categories []aws.ErrorCategory) string {
+	names := make([]string, len(categories))
+	for i, category := range categories {
+		names[i] = string(category)
+	}
+	return strings.Join(names, ",")
+}
+
+// closeService closes the files opened by the service, reporting errors as they may lose report content
+func closeService(service *orchestrator.Service) {
+	if err := service.Close(); err != nil {
+		log.Printf("Failed to close the report files: %v", err)
+	}
+}
+
 func main() {
 	var instanceIDs string
+	var asgName string
 	var configPath string
+	var desiredJSONPath string
+	var planJSONPath string
+	var attributeMappingPath string
+	var launchTemplate string
+	var awsSource string
+	var awsEndpointURL string
+	var useFIPS bool
 	var attributesToCheck string
+	var strictAttributes bool
+	var attributeProfile string
+	var attributeProfiles map[string][]string
+	var equivalentTypes string
 	var outputFormat string
 	var concurrencyLimit int
+	var parallelRegions bool
+	var retryAttempts int
+	var retryOn string
 	var verbose bool
+	var checkAMIDeprecation bool
+	var sgMatchBy string
+	var matchBy string
+	var tagsMode string
+	var tagValueCaseInsensitive bool
+	var failOnMissingTFAttribute bool
+	var includeVolatile bool
+	var onlyManaged bool
+	var colorMode string
+	var maxColumnWidth string
+	var noColor bool
+	var errorExitCode int
+	var maxDrifts int
+	var quiet bool
+	var suppressEmpty bool
+	var showAllAttributes bool
+	var diffContext int
+	var groupBy string
+	var regions string
+	var accountsFile string
+	var watch bool
+	var watchInterval time.Duration
+	var slowThreshold time.Duration
+	var logFile string
+	var logMaxSize int
+	var progress bool
+	var metricsFile string
+	var notifyWebhook string
+	var outputFile string
+	var templateFile string
+	var jsonCompact bool
+	var jsonIndent int
+	var prometheusTextfile string
+	var onlyStates string
+	var allowedAZs string
+	var launchedAfter string
+	var launchedBefore string
+	var configFile string
 
 	rootCmd := &cobra.Command{
 		Use:   "driftdetector",
 		Short: "Detect infrastructure drift between AWS EC2 instances and Terraform configurations",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// Options not given on the command line can come from the environment, then the config file
+			if err := applyEnvironment(cmd.Flags()); err != nil {
+				return err
+			}
+			var err error
+			attributeProfiles, err = applyConfigFile(cmd.Flags(), configFile)
+			return err
+		},
 		Run: func(cmd *cobra.Command, args []string) {
 			// Check required flags
-			if instanceIDs == "" || configPath == "" {
-				fmt.Println("Both --instance-ids and --config-path flags are required")
+			if (instanceIDs == "" && asgName == "" && accountsFile == "") || (configPath == "" && desiredJSONPath == "" && planJSONPath == "") {
+				fmt.Println("Both --instance-ids (or --asg or --accounts-file) and --config-path (or --desired-json or --plan-json) flags are required")
 				_ = cmd.Help()
 				os.Exit(1)
 			}
 
 			// Parse the comma-separated instance IDs
-			instanceIDSlice := strings.Split(instanceIDs, ",")
-			for i, id := range instanceIDSlice {
-				instanceIDSlice[i] = strings.TrimSpace(id)
+			var instanceIDSlice []string
+			if instanceIDs != "" {
+				instanceIDSlice = strings.Split(instanceIDs, ",")
+				for i, id := range instanceIDSlice {
+					instanceIDSlice[i] = strings.TrimSpace(id)
+				}
 			}
 
 			// Parse the optional attributes to check
@@ -47,14 +188,132 @@ func main() {
 				}
 			}
 
+			// Parse the optional groups of equivalent instance types
+			var equivalentTypeSlice []string
+			if equivalentTypes != "" {
+				equivalentTypeSlice = strings.Split(equivalentTypes, ",")
+				for i, group := range equivalentTypeSlice {
+					equivalentTypeSlice[i] = strings.TrimSpace(group)
+				}
+			}
+
+			// Parse the optional regions
+			var regionSlice []string
+			if regions != "" {
+				regionSlice = strings.Split(regions, ",")
+				for i, region := range regionSlice {
+					regionSlice[i] = strings.TrimSpace(region)
+				}
+			}
+
+			// Load the optional accounts to scan
+			var accounts []orchestrator.Account
+			if accountsFile != "" {
+				var err error
+				if accounts, err = orchestrator.LoadAccounts(accountsFile); err != nil {
+					fmt.Println(err)
+					os.Exit(1)
+				}
+			}
+
+			// Parse the optional error categories to retry
+			var retryOnSlice []string
+			if retryOn != "" {
+				retryOnSlice = strings.Split(retryOn, ",")
+				for i, category := range retryOnSlice {
+					retryOnSlice[i] = strings.TrimSpace(category)
+				}
+			}
+
+			// Parse the optional instance states to check
+			var stateSlice []string
+			if onlyStates != "" {
+				stateSlice = strings.Split(onlyStates, ",")
+				for i, state := range stateSlice {
+					stateSlice[i] = strings.TrimSpace(state)
+				}
+			}
+
+			// Parse the optional availability zone policy
+			var allowedAZSlice []string
+			if allowedAZs != "" {
+				allowedAZSlice = strings.Split(allowedAZs, ",")
+				for i, az := range allowedAZSlice {
+					allowedAZSlice[i] = strings.TrimSpace(az)
+				}
+			}
+
+			// Parse the output files, with optional additional reports
+			outputFilePath, reports, err := parseOutputFiles(outputFile)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			// --no-color is shorthand for --color never
+			if noColor {
+				colorMode = string(report.ColorModeNever)
+			}
+
 			// Create orchestrator config
 			config := orchestrator.Config{
-				InstanceIDs:       instanceIDSlice,
-				ConfigPath:        configPath,
-				AttributesToCheck: attrSlice,
-				OutputFormat:      outputFormat,
-				ConcurrencyLimit:  concurrencyLimit,
-				Verbose:           verbose,
+				InstanceIDs:          instanceIDSlice,
+				AutoScalingGroup:     asgName,
+				ConfigPath:           configPath,
+				DesiredJSONPath:      desiredJSONPath,
+				PlanJSONPath:         planJSONPath,
+				AttributeMappingPath: attributeMappingPath,
+				LaunchTemplate:       launchTemplate,
+				AWSSource:            awsSource,
+				AWSEndpointURL:       awsEndpointURL,
+				UseFIPS:              useFIPS,
+				AttributesToCheck:    attrSlice,
+				StrictAttributes:     strictAttributes,
+				AttributeProfile:     attributeProfile,
+				AttributeProfiles:    attributeProfiles,
+				EquivalentTypes:      equivalentTypeSlice,
+				OutputFormat:         outputFormat,
+				ConcurrencyLimit:     concurrencyLimit,
+				ParallelRegions:      parallelRegions,
+				RetryAttempts:        retryAttempts,
+				RetryOn:              retryOnSlice,
+				Verbose:              verbose,
+				CheckAMIDeprecation:  checkAMIDeprecation,
+				AllowedAZs:           allowedAZSlice,
+				SecurityGroupMatchBy: sgMatchBy,
+				MatchBy:              matchBy,
+				TagsMode:             tagsMode,
+				IgnoreTagValueCase:   tagValueCaseInsensitive,
+				SkipUnsetAttributes:  !failOnMissingTFAttribute,
+				IncludeVolatile:      includeVolatile,
+				OnlyManaged:          onlyManaged,
+				ColorMode:            colorMode,
+				MaxColumnWidth:       maxColumnWidth,
+				MaxDrifts:            maxDrifts,
+				Quiet:                quiet,
+				SuppressEmpty:        suppressEmpty,
+				ShowAllAttributes:    showAllAttributes,
+				DiffContext:          diffContext,
+				GroupBy:              groupBy,
+				Regions:              regionSlice,
+				Accounts:             accounts,
+				Watch:                watch,
+				WatchInterval:        watchInterval,
+				SlowThreshold:        slowThreshold,
+				LogFile:              logFile,
+				LogMaxSize:           logMaxSize,
+				Progress:             progress,
+				MetricsFile:          metricsFile,
+				NotifyWebhook:        notifyWebhook,
+				OutputFile:           outputFilePath,
+				TemplateFile:         templateFile,
+				Reports:              reports,
+				JSONCompact:          jsonCompact,
+				JSONIndent:           jsonIndent,
+				PrometheusTextfile:   prometheusTextfile,
+				OnlyStates:           stateSlice,
+				LaunchedAfter:        launchedAfter,
+				LaunchedBefore:       launchedBefore,
 			}
 
 			// Create orchestrator service
@@ -63,30 +322,102 @@ func main() {
 				log.Fatalf("Failed to initialize the service: %v", err)
 			}
 
-			ctx := context.Background()
-			hasDrift, hasError, err := service.Run(ctx)
+			// Interrupting a run cancels it, and the instances checked so far are still summarized
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
 
-			if err != nil {
-				log.Fatalf("Error: %v", err)
+			// In watch mode keep checking until interrupted
+			if watch {
+				err := service.Watch(ctx)
+				closeService(service)
+				if err != nil {
+					log.Fatalf("Error: %v", err)
+				}
+				return
 			}
 
-			// Set exit code based on whether drift was detected
-			if hasDrift {
-				os.Exit(2) // Non-zero exit code indicates drift detected
+			hasDrift, hasError, err := service.Run(ctx)
+			closeService(service)
+
+			if errors.Is(err, context.Canceled) {
+				log.Printf("Error: %v", err)
+				os.Exit(ExitInterrupted)
 			}
-			if hasError {
-				os.Exit(1) // Error occurred during execution
+
+			// The errors of instances that could not be checked are already logged and set the exit code
+			var instanceErr *orchestrator.InstanceError
+			if err != nil && !errors.As(err, &instanceErr) {
+				log.Fatalf("Error: %v", err)
 			}
+
+			// Set exit code based on whether drift was detected and whether errors occurred
+			os.Exit(exitCode(hasDrift, hasError, errorExitCode))
 		},
 	}
 
 	// Define flags
-	rootCmd.Flags().StringVar(&instanceIDs, "instance-ids", "", "Comma-separated list of AWS EC2 instance IDs")
+	rootCmd.Flags().StringVar(&configFile, configFlag, "", "Path to a YAML file setting any of these options by flag name (default: ./"+defaultConfigFile+" if present)")
+	rootCmd.Flags().StringVar(&instanceIDs, "instance-ids", "", "Comma-separated list of AWS EC2 instance IDs, optionally prefixed with a region (e.g., us-east-1/i-123)")
+	rootCmd.Flags().StringVar(&asgName, "asg", "", "Name of an Auto Scaling group whose current member instances are checked, in addition to --instance-ids")
+	rootCmd.Flags().StringVar(&regions, "regions", "", "Comma-separated list of AWS regions; unqualified instance IDs are checked in the first one (default: SDK configured region)")
+	rootCmd.Flags().StringVar(&accountsFile, "accounts-file", "", "YAML file of AWS accounts whose instances are all checked, each with the account_id, the role_arn to assume and its regions")
 	rootCmd.Flags().StringVar(&configPath, "config-path", "", "Path to the Terraform configuration file")
+	rootCmd.Flags().StringVar(&desiredJSONPath, "desired-json", "", "Path to a JSON file of the desired instance details, used instead of --config-path")
+	rootCmd.Flags().StringVar(&planJSONPath, "plan-json", "", "Path to a Terraform plan in JSON (terraform show -json plan.out), used instead of --config-path")
+	rootCmd.Flags().StringVar(&awsSource, "aws-source", "", "file:// URL of a JSON fixture of instances keyed by instance ID, used instead of calling AWS (for demos and tests)")
+	rootCmd.Flags().StringVar(&awsEndpointURL, "aws-endpoint-url", "", "Base URL of the EC2 API to call instead of the public endpoint, e.g. a VPC endpoint; only for instances of a single region")
+	rootCmd.Flags().BoolVar(&useFIPS, "use-fips", false, "Call the FIPS endpoints of the AWS regions, e.g. in GovCloud")
+	rootCmd.Flags().StringVar(&launchTemplate, "launch-template", "", "Name of an aws_launch_template resource in --config-path to use as the desired state instead of the aws_instance")
+	rootCmd.Flags().StringVar(&attributeMappingPath, "attribute-mapping", "", "Path to a YAML file mapping attribute names to the HCL attribute names used by your modules")
 	rootCmd.Flags().StringVar(&attributesToCheck, "attributes", "", "Comma-separated list of attributes to check for drift (e.g., instance_type,tags)")
-	rootCmd.Flags().StringVar(&outputFormat, "output", "table", "Output format: table or json")
-	rootCmd.Flags().IntVar(&concurrencyLimit, "concurrency", runtime.NumCPU(), "Maximum number of instances to check concurrently (default: number of CPU cores)")
+	rootCmd.Flags().StringVar(&attributeProfile, "attribute-profile", "", "Named set of attributes to check, added to --attributes: security, network, cost or one defined under attribute-profiles in the config file")
+	rootCmd.Flags().BoolVar(&strictAttributes, "strict-attributes", false, "Fail when --attributes contains an unsupported attribute instead of warning and skipping it")
+	rootCmd.Flags().StringVar(&equivalentTypes, "equivalent-types", "", "Comma-separated groups of interchangeable instance types not reported as drift (e.g., t3.micro=t3a.micro)")
+	rootCmd.Flags().StringVar(&onlyStates, "only-states", "", "Comma-separated list of instance states to check (e.g., running); instances in other states are skipped (default: all states)")
+	rootCmd.Flags().StringVar(&launchedAfter, "launched-after", "", "Only check instances launched after this: a duration ago (e.g., 24h), an RFC 3339 timestamp or a date (YYYY-MM-DD)")
+	rootCmd.Flags().StringVar(&launchedBefore, "launched-before", "", "Only check instances launched before this, e.g. 1h to leave out instances still being configured; same formats as --launched-after")
+	rootCmd.Flags().StringVar(&outputFormat, "output", "table", "Output format: table, json, sarif, diff, html, junit, jsonl or template")
+	rootCmd.Flags().StringVar(&outputFile, "output-file", "", "Write the json, sarif, html, junit or template report to this file instead of stdout; comma-separated path:format entries (e.g., report.json:json) write additional reports in those formats")
+	rootCmd.Flags().StringVar(&templateFile, "template-file", "", "Go text/template file rendering the template output format, executed once with the reports of the run")
+	rootCmd.Flags().BoolVar(&jsonCompact, "json-compact", false, "Write the json report on a single line instead of pretty-printed, e.g. for log ingestion")
+	rootCmd.Flags().IntVar(&jsonIndent, "json-indent", 2, "Number of spaces per indentation level of the pretty-printed json report")
+	rootCmd.Flags().IntVar(&concurrencyLimit, "concurrency", runtime.NumCPU(), "Maximum number of instances to check, and AWS API calls in flight across all regions, concurrently (default: number of CPU cores)")
+	rootCmd.Flags().BoolVar(&parallelRegions, "parallel-regions", false, "Fetch the instances of all regions concurrently, within the --concurrency limit")
+	rootCmd.Flags().IntVar(&retryAttempts, "retry-attempts", aws.DefaultRetryAttempts, "Attempts of a failing AWS API call, including the first (1 disables retries)")
+	rootCmd.Flags().StringVar(&retryOn, "retry-on", joinCategories(aws.DefaultRetryableCategories), "Comma-separated list of AWS error categories to retry; permission_denied, resource_not_found and invalid_input are never retried")
 	rootCmd.Flags().BoolVar(&verbose, "verbose", false, "Enable verbose/debug output")
+	rootCmd.Flags().BoolVar(&showAllAttributes, "show-all-attributes", false, "List every compared attribute in the table, with an OK or DRIFT status, as evidence of what was verified")
+	rootCmd.Flags().IntVar(&diffContext, "diff-context", 0, "Number of unchanged attributes shown before and after each drifted one in diff output, like diff -U")
+	rootCmd.Flags().StringVar(&groupBy, "group-by", "", "After the reports, list the drifts of this attribute grouping the instances that drifted the same way (e.g., instance_type)")
+	rootCmd.Flags().BoolVar(&quiet, "quiet", false, "Only print reports for instances with drift, plus the summary and any errors")
+	rootCmd.Flags().BoolVar(&suppressEmpty, "suppress-empty", false, "Print nothing for instances without drift, not even an empty table")
+	rootCmd.Flags().BoolVar(&watch, "watch", false, "Keep checking for drift on an interval until interrupted")
+	rootCmd.Flags().DurationVar(&watchInterval, "interval", 5*time.Minute, "Time between checks in watch mode (e.g., 30s, 5m)")
+	rootCmd.Flags().BoolVar(&progress, "progress", false, "Print the number of instances checked so far to stderr, updated in place on a terminal")
+	rootCmd.Flags().StringVar(&logFile, "log-file", "", "Write log lines to this file instead of the console, rotating it by size (e.g. for watch mode)")
+	rootCmd.Flags().IntVar(&logMaxSize, "log-max-size", 100, "Size in megabytes at which --log-file is rotated")
+	rootCmd.Flags().DurationVar(&slowThreshold, "slow-threshold", 30*time.Second, "Warn about instances, and region fetches, taking longer than this (0 disables the warnings)")
+	rootCmd.Flags().StringVar(&metricsFile, "metrics-file", "", "Write run statistics (instances checked, drifted, errored, API calls, duration) as JSON to this file")
+	rootCmd.Flags().StringVar(&notifyWebhook, "notify-webhook", "", "URL to POST a JSON summary of the drifted instances to when drift is detected, e.g. a Slack incoming webhook")
+	rootCmd.Flags().StringVar(&prometheusTextfile, "prometheus-textfile", "", "Write run statistics in the Prometheus text format to this file, for the node_exporter textfile collector")
+	rootCmd.Flags().IntVar(&errorExitCode, "error-exit-code", ExitError, "Exit code used when instances could not be checked (0 makes errors non-fatal)")
+	rootCmd.Flags().IntVar(&maxDrifts, "max-drifts", 0, "Number of instances with drift tolerated before exiting with the drift code, for drift expected during migrations")
+	rootCmd.Flags().StringVar(&matchBy, "match-by", orchestrator.MatchByNone, "Match each instance to the aws_instance resource with the same tag: name (default: compare all instances with the first resource)")
+	rootCmd.Flags().BoolVar(&failOnMissingTFAttribute, "fail-on-missing-tf-attribute", true, "Report drift when the Terraform configuration leaves an attribute empty and AWS has a value; false treats such attributes as not managed")
+	rootCmd.Flags().BoolVar(&onlyManaged, "only-managed", false, "Only compare the attributes the Terraform configuration sets, out of --attributes or all attributes")
+	rootCmd.Flags().BoolVar(&includeVolatile, "include-volatile", false, "Compare volatile attributes, such as public_ip and private_ip, of stopped instances too; they are skipped by default")
+	rootCmd.Flags().StringVar(&tagsMode, "tags-mode", string(driftcheck.TagsModeExact), "Compare tags: exact, or subset to ignore tags only present on AWS")
+	rootCmd.Flags().BoolVar(&tagValueCaseInsensitive, "tag-value-case-insensitive", false, "Compare tag values ignoring case, e.g. Environment=Prod and Environment=prod; tag keys stay case-sensitive")
+	rootCmd.Flags().StringVar(&sgMatchBy, "sg-match-by", orchestrator.SecurityGroupMatchByID, "Compare security groups by: id or name")
+	rootCmd.Flags().StringVar(&colorMode, "color", string(report.ColorModeAuto), "Colorize table output: auto, always or never")
+	rootCmd.Flags().StringVar(&maxColumnWidth, "max-col-width", report.ColumnWidthAuto, "Truncate table values longer than this many characters with an ellipsis: auto (fit the terminal), 0 (never) or a number; JSON output always holds the full values")
+	rootCmd.Flags().BoolVar(&noColor, "no-color", false, "Disable colorized table output (same as --color never)")
+	rootCmd.Flags().BoolVar(&checkAMIDeprecation, "check-ami-deprecation", false, "Report instances running an AMI whose deprecation time has passed")
+	rootCmd.Flags().StringVar(&allowedAZs, "allowed-azs", "", "Comma-separated list of availability zones; instances in other zones are reported as az_policy drift")
+
+	rootCmd.AddCommand(newExportCommand())
+	rootCmd.AddCommand(newCheckCommand())
+	rootCmd.AddCommand(newCompareCommand())
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
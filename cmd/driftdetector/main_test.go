@@ -0,0 +1,68 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"driftdetector/internal/orchestrator"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name          string
+		hasDrift      bool
+		hasError      bool
+		errorExitCode int
+		expected      int
+	}{
+		{"No drift, no errors", false, false, ExitError, ExitOK},
+		{"Errors only", false, true, ExitError, ExitError},
+		{"Drift only", true, false, ExitError, ExitDrift},
+		{"Drift and errors", true, true, ExitError, ExitDriftAndError},
+		{"Errors only with override", false, true, 5, 5},
+		{"Drift and errors with override", true, true, 5, ExitDriftAndError},
+		{"Errors non-fatal", false, true, ExitOK, ExitOK},
+		{"Drift and non-fatal errors", true, true, ExitOK, ExitDrift},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, exitCode(tt.hasDrift, tt.hasError, tt.errorExitCode))
+		})
+	}
+}
+
+func TestParseOutputFiles(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      string
+		outputFile string
+		reports    []orchestrator.ReportSink
+	}{
+		{"Empty", "", "", nil},
+		{"Plain path", "report.sarif", "report.sarif", nil},
+		{"Additional report", "report.json:json", "", []orchestrator.ReportSink{{Format: "JSON", File: "report.json"}}},
+		{
+			"Mixed",
+			"report.html, drift.xml:junit,out.json:JSON",
+			"report.html",
+			[]orchestrator.ReportSink{{Format: "JUNIT", File: "drift.xml"}, {Format: "JSON", File: "out.json"}},
+		},
+		{"Colon in path", "C:/reports/drift.sarif", "C:/reports/drift.sarif", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputFile, reports, err := parseOutputFiles(tt.value)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.outputFile, outputFile)
+			assert.Equal(t, tt.reports, reports)
+		})
+	}
+}
+
+func TestParseOutputFiles_SeveralPlainPaths(t *testing.T) {
+	_, _, err := parseOutputFiles("a.sarif,b.sarif")
+	assert.Error(t, err)
+}
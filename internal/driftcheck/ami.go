@@ -0,0 +1,29 @@
+package driftcheck
+
+import (
+	"fmt"
+	"time"
+
+	"driftdetector/internal/models"
+)
+
+// AMIDeprecationAttribute is the attribute name under which deprecated AMI violations are reported.
+const AMIDeprecationAttribute = "ami_deprecation"
+
+// CheckAMIDeprecation flags the result as drifted when the instance's AMI was deprecated before now.
+// Unlike the attribute comparators this is an independent policy check: the Terraform configuration
+// is not consulted, only the AMI details resolved from AWS.
+// It returns true if a violation was recorded.
+func CheckAMIDeprecation(result *DriftResult, image *models.ImageDetails, now time.Time) bool {
+	if result == nil || image == nil || !image.IsDeprecated(now) {
+		return false
+	}
+
+	result.HasDrift = true
+	result.Drifts[AMIDeprecationAttribute] = models.DriftDetail{
+		Attribute:      AMIDeprecationAttribute,
+		AWSValue:       fmt.Sprintf("%s deprecated since %s", image.ImageID, image.DeprecationTime.Format(time.RFC3339)),
+		TerraformValue: "not deprecated",
+	}
+	return true
+}
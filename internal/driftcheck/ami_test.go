@@ -0,0 +1,54 @@
+package driftcheck
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"driftdetector/internal/models"
+)
+
+func TestCheckAMIDeprecation(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		image       *models.ImageDetails
+		expectDrift bool
+	}{
+		{
+			name:        "Deprecated AMI",
+			image:       &models.ImageDetails{ImageID: "ami-old", DeprecationTime: now.Add(-24 * time.Hour)},
+			expectDrift: true,
+		},
+		{
+			name:        "Deprecation scheduled in the future",
+			image:       &models.ImageDetails{ImageID: "ami-current", DeprecationTime: now.Add(24 * time.Hour)},
+			expectDrift: false,
+		},
+		{
+			name:        "No deprecation time",
+			image:       &models.ImageDetails{ImageID: "ami-current"},
+			expectDrift: false,
+		},
+		{
+			name:        "Unresolved image",
+			image:       nil,
+			expectDrift: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &DriftResult{Drifts: make(map[string]models.DriftDetail)}
+
+			flagged := CheckAMIDeprecation(result, tt.image, now)
+
+			assert.Equal(t, tt.expectDrift, flagged, "Violation flag should match expectations")
+			assert.Equal(t, tt.expectDrift, result.HasDrift, "HasDrift should match expectations")
+			_, exists := result.Drifts[AMIDeprecationAttribute]
+			assert.Equal(t, tt.expectDrift, exists, "Drift detail presence should match expectations")
+		})
+	}
+}
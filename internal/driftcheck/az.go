@@ -0,0 +1,35 @@
+package driftcheck
+
+import (
+	"slices"
+	"strings"
+
+	"driftdetector/internal/models"
+)
+
+// AZPolicyAttribute is the attribute name under which availability zones outside the allowed set are reported.
+const AZPolicyAttribute = "az_policy"
+
+// CheckAllowedAZ flags the result as drifted when the instance's availability zone is not one of allowedAZs,
+// compared case-insensitively. Like CheckAMIDeprecation this is an independent policy check, which holds
+// even when the Terraform configuration does not pin the availability zone.
+// Instances whose availability zone is unknown are not flagged. It returns true if a violation was recorded.
+func CheckAllowedAZ(result *DriftResult, availabilityZone string, allowedAZs []string) bool {
+	if result == nil || availabilityZone == "" || len(allowedAZs) == 0 {
+		return false
+	}
+	allowed := slices.ContainsFunc(allowedAZs, func(az string) bool {
+		return strings.EqualFold(az, availabilityZone)
+	})
+	if allowed {
+		return false
+	}
+
+	result.HasDrift = true
+	result.Drifts[AZPolicyAttribute] = models.DriftDetail{
+		Attribute:      AZPolicyAttribute,
+		AWSValue:       availabilityZone,
+		TerraformValue: "one of " + strings.Join(allowedAZs, ", "),
+	}
+	return true
+}
@@ -0,0 +1,49 @@
+package driftcheck
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"driftdetector/internal/models"
+)
+
+func TestCheckAllowedAZ(t *testing.T) {
+	allowed := []string{"us-east-1a", "us-east-1b"}
+
+	tests := []struct {
+		name             string
+		availabilityZone string
+		allowedAZs       []string
+		expectDrift      bool
+	}{
+		{"Allowed zone", "us-east-1a", allowed, false},
+		{"Allowed zone in another case", "US-EAST-1B", allowed, false},
+		{"Zone outside the allowed set", "us-east-1c", allowed, true},
+		{"Unknown zone", "", allowed, false},
+		{"No policy", "us-east-1c", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &DriftResult{Drifts: make(map[string]models.DriftDetail)}
+
+			flagged := CheckAllowedAZ(result, tt.availabilityZone, tt.allowedAZs)
+
+			assert.Equal(t, tt.expectDrift, flagged)
+			assert.Equal(t, tt.expectDrift, result.HasDrift)
+			if tt.expectDrift {
+				assert.Equal(t, models.DriftDetail{
+					Attribute:      AZPolicyAttribute,
+					AWSValue:       tt.availabilityZone,
+					TerraformValue: "one of us-east-1a, us-east-1b",
+				}, result.Drifts[AZPolicyAttribute])
+			} else {
+				assert.Empty(t, result.Drifts)
+			}
+		})
+	}
+
+	// A nil result is ignored
+	assert.False(t, CheckAllowedAZ(nil, "us-east-1c", allowed))
+}
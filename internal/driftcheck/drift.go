@@ -1,30 +1,119 @@
 package driftcheck
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"maps"
 	"reflect"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
 
 	"driftdetector/internal/models"
 )
 
+// defaultTenancy is the tenancy of instances on shared hardware
+const defaultTenancy = "default"
+
+// onDemandLifecycle is the lifecycle of on-demand instances, for which AWS reports no lifecycle
+const onDemandLifecycle = "on-demand"
+
+// stopShutdownBehavior is the default shutdown behavior, keeping the instance and its volumes on shutdown
+const stopShutdownBehavior = "stop"
+
+// nameTag is the tag holding the display name of an instance
+const nameTag = "Name"
+
+// TagsMode selects how the tags comparator compares the tags of an instance
+type TagsMode string
+
+const (
+	// TagsModeExact reports drift for any difference in tags, including tags only present on AWS
+	TagsModeExact TagsMode = "exact"
+	// TagsModeSubset only reports drift for tags of the configuration that are missing or different on AWS,
+	// for when automation legitimately adds tags to instances
+	TagsModeSubset TagsMode = "subset"
+)
+
+// ParseTagsMode parses a tags mode, case-insensitively. An empty mode is exact.
+func ParseTagsMode(mode string) (TagsMode, error) {
+	switch TagsMode(strings.ToLower(mode)) {
+	case "", TagsModeExact:
+		return TagsModeExact, nil
+	case TagsModeSubset:
+		return TagsModeSubset, nil
+	default:
+		return "", NewDriftError(ErrInvalidInput,
+			fmt.Sprintf("Invalid tags mode %q: must be %q or %q", mode, TagsModeExact, TagsModeSubset), "tags", nil)
+	}
+}
+
 // getSkipAttributes returns a list of attributes that should be skipped during drift detection.
+// name is only checked when requested, as the Name tag is otherwise already compared with the other tags.
 func getSkipAttributes() []string {
-	skipAttributes := []string{"instance_id"}
+	skipAttributes := []string{"instance_id", "name"}
 	return skipAttributes
 }
 
+// volatileAttributes are released or reassigned while an instance is stopped, so they are not compared for
+// stopped instances unless DetectOptions.IncludeVolatile is set
+var volatileAttributes = []string{"public_ip", "private_ip"}
+
+// unmanagedWhenUnsetAttributes are attributes that an instance always has on AWS, so a configuration leaving
+// them unset almost always omitted them rather than expects them empty. With DetectOptions.SkipUnset they
+// are classified as unmanaged instead of being silently skipped.
+var unmanagedWhenUnsetAttributes = []string{"subnet_id", "ami", "key_name"}
+
+// definingAttributes maps attributes that are defined within another attribute of the configuration
+// to that attribute, to locate their source
+var definingAttributes = map[string]string{
+	"name": "tags",
+}
+
 // AttributeComparator is a function type that compares two attributes
 // and returns whether they differ, along with their values.
 type AttributeComparator func(aws, tf *models.InstanceDetails) (hasDrift bool, awsValue any, tfValue any)
 
+// DetectOptions configures DetectDriftWithOptions. The zero value checks all comparable attributes exactly.
+type DetectOptions struct {
+	// Attributes to compare; empty compares all comparable attributes
+	Attributes []string
+	// StrictAttributes aborts the check on an unsupported attribute; otherwise it is returned as an
+	// ErrResourceMissing error alongside the result of the remaining attributes
+	StrictAttributes bool
+	// EquivalentTypes are instance types that are not drift of each other; nil makes every type equivalent only to itself
+	EquivalentTypes InstanceTypeEquivalences
+	// TagsMode selects how tags are compared; an empty mode is exact
+	TagsMode TagsMode
+	// IgnoreTagValueCase compares tag values case-insensitively, e.g. Environment=Prod is no drift of
+	// Environment=prod. Tag keys are always case-sensitive.
+	IgnoreTagValueCase bool
+	// SkipUnset treats attributes the Terraform configuration leaves empty as not managed rather than expected
+	// to be empty, e.g. an unset ami is no drift whatever AWS reports; it is listed in DriftResult.Unmanaged
+	SkipUnset bool
+	// IncludeVolatile compares volatile attributes, such as the public IP, of stopped instances too
+	IncludeVolatile bool
+	// OnlyManaged only compares the attributes that the Terraform configuration sets, out of Attributes or
+	// of all comparable attributes, so fields populated by AWS that the configuration never set are not drift
+	OnlyManaged bool
+}
+
 // DetectDrift compares AWS EC2 instance details with Terraform configuration details.
 // It returns a DriftResult containing information about detected drifts.
 // The attributesToCheck parameter specifies which attributes to compare.
 // If attributesToCheck is empty, it checks all comparable attributes.
+// It is a wrapper of DetectDriftWithOptions with the other options left at their defaults.
 func DetectDrift(awsInstance, tfInstance *models.InstanceDetails, attributesToCheck []string) (*DriftResult, error) {
+	return DetectDriftWithOptions(awsInstance, tfInstance, DetectOptions{Attributes: attributesToCheck})
+}
+
+// DetectDriftWithOptions compares AWS EC2 instance details with Terraform configuration details.
+// It returns a DriftResult containing information about detected drifts, for the attributes and
+// comparison modes of opts.
+func DetectDriftWithOptions(awsInstance, tfInstance *models.InstanceDetails, opts DetectOptions) (*DriftResult, error) {
 	// Validate input parameters
 	if awsInstance == nil {
 		return nil, NewDriftError(ErrInvalidInput, "AWS instance details are nil", "", nil)
@@ -37,17 +126,45 @@ func DetectDrift(awsInstance, tfInstance *models.InstanceDetails, attributesToCh
 	result := &DriftResult{
 		HasDrift:  false,
 		Drifts:    make(map[string]models.DriftDetail),
+		Matches:   make(map[string]models.DriftDetail),
+		Unmanaged: make(map[string]models.DriftDetail),
 		AwsConfig: awsInstance,
 		TfConfig:  tfInstance,
 	}
 
 	// Get the comparators for all supported attributes
-	allAttributes := getAttributeComparators()
+	allAttributes := getAttributeComparators(opts.EquivalentTypes, opts.TagsMode, opts.IgnoreTagValueCase)
+	if opts.SkipUnset {
+		for name, checkFn := range allAttributes {
+			allAttributes[name] = skipUnsetTerraformValue(checkFn)
+		}
+	}
+
+	// A stopped instance has no public IP, which would otherwise be reported as drift
+	attributes := opts.Attributes
+	if !opts.IncludeVolatile && awsInstance.State == "stopped" {
+		for _, attr := range volatileAttributes {
+			delete(allAttributes, attr)
+		}
+		attributes = slices.DeleteFunc(slices.Clone(attributes), func(attr string) bool {
+			return slices.Contains(volatileAttributes, normalizeAttributeName(attr))
+		})
+	}
+
+	// Only the attributes the configuration declares are compared with OnlyManaged, which may be none
+	specific := len(opts.Attributes) > 0
+	if opts.OnlyManaged {
+		if !specific {
+			attributes = comparableAttributes(allAttributes)
+			specific = true
+		}
+		attributes = managedAttributes(tfInstance, attributes)
+	}
 
 	// Determine which attributes to check
-	if len(attributesToCheck) > 0 {
+	if specific {
 		// When a subset is provided, check only those attributes
-		if err := checkSpecificAttributes(result, awsInstance, tfInstance, attributesToCheck, allAttributes); err != nil {
+		if err := checkSpecificAttributes(result, awsInstance, tfInstance, attributes, allAttributes, opts.StrictAttributes); err != nil {
 			return result, err
 		}
 	} else {
@@ -57,19 +174,71 @@ func DetectDrift(awsInstance, tfInstance *models.InstanceDetails, attributesToCh
 		}
 	}
 
+	if opts.SkipUnset {
+		classifyUnmanaged(result)
+	}
 	return result, nil
 }
 
-// getAttributeComparators returns a map of attribute names to comparison functions.
+// classifyUnmanaged moves the unmanagedWhenUnsetAttributes that the configuration leaves unset while AWS has
+// a value from the matches to the unmanaged attributes, so they are neither drift nor reported as verified.
+// An attribute unset on both sides is still a match.
+func classifyUnmanaged(result *DriftResult) {
+	for _, attr := range unmanagedWhenUnsetAttributes {
+		match, ok := result.Matches[attr]
+		if !ok || match.TerraformValue != "" || match.AWSValue == "" {
+			continue
+		}
+		result.Unmanaged[attr] = match
+		delete(result.Matches, attr)
+	}
+}
+
+// Compare compares the actual details of an instance with the desired ones, attribute by attribute, and is
+// the entry point for using the comparison engine outside of drift detection, e.g. to diff two AWS instances.
+// Both sides may come from any source: the drift details report the actual values as AWSValue and the
+// desired ones as TerraformValue. An empty attrs compares all supported attributes; unsupported attributes
+// are returned as an ErrResourceMissing error alongside the result of the others.
+func Compare(actual, desired *models.InstanceDetails, attrs []string) (*DriftResult, error) {
+	return DetectDriftWithOptions(actual, desired, DetectOptions{Attributes: attrs})
+}
+
+// getAttributeComparators returns a map of attribute names to comparison functions,
+// made of the built-in comparators and any registered with RegisterComparator.
+func getAttributeComparators(equivalentTypes InstanceTypeEquivalences, tagsMode TagsMode, ignoreTagValueCase bool) map[string]AttributeComparator {
+	comparators := builtinAttributeComparators(equivalentTypes, tagsMode, ignoreTagValueCase)
+	for name, fn := range registeredComparators() {
+		comparators[name] = fn
+	}
+	return comparators
+}
+
+// builtinAttributeComparators returns the comparators for the attributes of InstanceDetails.
 // This allows for easy extension with new attributes without modifying the main logic.
-func getAttributeComparators() map[string]AttributeComparator {
+func builtinAttributeComparators(equivalentTypes InstanceTypeEquivalences, tagsMode TagsMode, ignoreTagValueCase bool) map[string]AttributeComparator {
 	return map[string]AttributeComparator{
 		//! Skip instance_id since it's not defined in HCL and is assigned by AWS
 		"instance_type": func(aws, tf *models.InstanceDetails) (bool, any, any) {
+			// Interchangeable types, such as an AMD variant swapped in by autoscaling, are not drift
+			if equivalentTypes.Equivalent(aws.InstanceType, tf.InstanceType) {
+				return false, aws.InstanceType, tf.InstanceType
+			}
 			return aws.InstanceType != tf.InstanceType, aws.InstanceType, tf.InstanceType
 		},
 		"tags": func(aws, tf *models.InstanceDetails) (bool, any, any) {
-			return !reflect.DeepEqual(aws.Tags, tf.Tags), aws.Tags, tf.Tags
+			awsTags := aws.Tags
+			if ignoreTagValueCase {
+				awsTags = foldTagValueCase(awsTags, tf.Tags)
+			}
+			if tagsMode == TagsModeSubset {
+				// Tags only present on AWS are left out, so they are neither drift nor reported
+				awsTags = subsetTags(awsTags, tf.Tags)
+			}
+			// Fleets with verbose tagging compare many tags per instance, so this is cheaper than reflect.DeepEqual
+			return !maps.Equal(awsTags, tf.Tags), awsTags, tf.Tags
+		},
+		"name": func(aws, tf *models.InstanceDetails) (bool, any, any) {
+			return aws.Tags[nameTag] != tf.Tags[nameTag], aws.Tags[nameTag], tf.Tags[nameTag]
 		},
 		"ami": func(aws, tf *models.InstanceDetails) (bool, any, any) {
 			return aws.AMI != tf.AMI, aws.AMI, tf.AMI
@@ -90,10 +259,300 @@ func getAttributeComparators() map[string]AttributeComparator {
 		"subnet_id": func(aws, tf *models.InstanceDetails) (bool, any, any) {
 			return aws.SubnetID != tf.SubnetID, aws.SubnetID, tf.SubnetID
 		},
+		"vpc_id": func(aws, tf *models.InstanceDetails) (bool, any, any) {
+			// aws_instance has no vpc_id argument, the VPC follows from the subnet, so it is only compared
+			// when the desired state sets it explicitly
+			if tf.VPCID == "" {
+				return false, aws.VPCID, nil
+			}
+			return aws.VPCID != tf.VPCID, aws.VPCID, tf.VPCID
+		},
+		"key_name": func(aws, tf *models.InstanceDetails) (bool, any, any) {
+			return aws.KeyName != tf.KeyName, aws.KeyName, tf.KeyName
+		},
+		"availability_zone": func(aws, tf *models.InstanceDetails) (bool, any, any) {
+			// Like the VPC, the zone usually follows from the subnet, so it is only compared when the desired
+			// state sets it
+			if tf.AvailabilityZone == "" {
+				return false, aws.AvailabilityZone, nil
+			}
+			return aws.AvailabilityZone != tf.AvailabilityZone, aws.AvailabilityZone, tf.AvailabilityZone
+		},
+		"placement_group": func(aws, tf *models.InstanceDetails) (bool, any, any) {
+			// Most instances are not in a placement group, so empty on both sides is no drift
+			if aws.PlacementGroup == "" && tf.PlacementGroup == "" {
+				return false, nil, nil
+			}
+			return aws.PlacementGroup != tf.PlacementGroup, aws.PlacementGroup, tf.PlacementGroup
+		},
+		"instance_lifecycle": func(aws, tf *models.InstanceDetails) (bool, any, any) {
+			// An instance that should be on-demand running as spot, or vice versa, changes the bill
+			awsLifecycle, tfLifecycle := lifecycleOrOnDemand(aws.InstanceLifecycle), lifecycleOrOnDemand(tf.InstanceLifecycle)
+			return awsLifecycle != tfLifecycle, awsLifecycle, tfLifecycle
+		},
+		"capacity_reservation_id": func(aws, tf *models.InstanceDetails) (bool, any, any) {
+			// Most instances do not target a capacity reservation, so empty on both sides is no drift
+			if aws.CapacityReservationID == "" && tf.CapacityReservationID == "" {
+				return false, nil, nil
+			}
+			return aws.CapacityReservationID != tf.CapacityReservationID, aws.CapacityReservationID, tf.CapacityReservationID
+		},
+		"block_devices": func(aws, tf *models.InstanceDetails) (bool, any, any) {
+			// Without ebs_block_device blocks, volumes are attached outside of the instance resource,
+			// e.g. with aws_volume_attachment
+			if tf.BlockDevices == nil {
+				return false, nil, nil
+			}
+			// Devices are matched by name, so drifts are reported per device like tags
+			awsDevices := blockDeviceValues(aws.BlockDevices, tf.BlockDevices)
+			tfDevices := blockDeviceValues(tf.BlockDevices, aws.BlockDevices)
+			return !reflect.DeepEqual(awsDevices, tfDevices), awsDevices, tfDevices
+		},
+		"volume_tags": func(aws, tf *models.InstanceDetails) (bool, any, any) {
+			// Volume tags are only fetched from AWS when requested, and only managed when the configuration sets volume_tags
+			if tf.VolumeTags == nil {
+				return false, nil, nil
+			}
+			// Every volume, the root volume included, should have the volume_tags, so drifts are reported per device
+			// like block devices
+			awsTags, tfTags := volumeTagValues(aws.Volumes(), tf.VolumeTags)
+			return !maps.Equal(awsTags, tfTags), awsTags, tfTags
+		},
+		RootDeleteOnTerminationAttribute: func(aws, tf *models.InstanceDetails) (bool, any, any) {
+			// Only this setting of the root volume is compared: a root volume kept after termination is orphaned and keeps costing
+			if aws.RootDeleteOnTermination == nil {
+				return false, nil, nil
+			}
+			// Terraform deletes the root volume on termination unless set otherwise
+			tfValue := tf.RootDeleteOnTermination == nil || *tf.RootDeleteOnTermination
+			return *aws.RootDeleteOnTermination != tfValue, *aws.RootDeleteOnTermination, tfValue
+		},
+		"tenancy": func(aws, tf *models.InstanceDetails) (bool, any, any) {
+			// Terraform leaves tenancy unset for shared hardware, which AWS reports as default
+			awsTenancy, tfTenancy := tenancyOrDefault(aws.Tenancy), tenancyOrDefault(tf.Tenancy)
+			return awsTenancy != tfTenancy, awsTenancy, tfTenancy
+		},
+		"metadata_options": func(aws, tf *models.InstanceDetails) (bool, any, any) {
+			// Only the settings Terraform manages are compared, AWS defaults the others
+			if tf.MetadataOptions == nil {
+				return false, nil, nil
+			}
+			tfOptions := metadataOptionsValues(tf.MetadataOptions)
+			awsOptions := metadataOptionsValues(aws.MetadataOptions)
+			for key := range awsOptions {
+				if _, managed := tfOptions[key]; !managed {
+					delete(awsOptions, key)
+				}
+			}
+			return !reflect.DeepEqual(awsOptions, tfOptions), awsOptions, tfOptions
+		},
+		"disable_api_termination": func(aws, tf *models.InstanceDetails) (bool, any, any) {
+			// Termination protection is only fetched from AWS when requested
+			if aws.DisableApiTermination == nil {
+				return false, nil, nil
+			}
+			// Terraform leaves termination protection disabled unless set
+			tfValue := tf.DisableApiTermination != nil && *tf.DisableApiTermination
+			return *aws.DisableApiTermination != tfValue, *aws.DisableApiTermination, tfValue
+		},
+		ShutdownBehaviorAttribute: func(aws, tf *models.InstanceDetails) (bool, any, any) {
+			// The shutdown behavior is only fetched from AWS when requested
+			if aws.InstanceInitiatedShutdownBehavior == "" {
+				return false, nil, nil
+			}
+			// Terraform leaves the instance to stop on shutdown unless set
+			tfBehavior := shutdownBehaviorOrStop(tf.InstanceInitiatedShutdownBehavior)
+			return aws.InstanceInitiatedShutdownBehavior != tfBehavior, aws.InstanceInitiatedShutdownBehavior, tfBehavior
+		},
+		"cpu_credits": func(aws, tf *models.InstanceDetails) (bool, any, any) {
+			// CPU credits are only fetched from AWS when requested, and only burstable instances have them
+			if aws.CPUCredits == "" {
+				return false, nil, nil
+			}
+			// Without a credit_specification block the default of the instance family applies, e.g. unlimited for T3
+			if tf.CPUCredits == "" {
+				return false, aws.CPUCredits, nil
+			}
+			return aws.CPUCredits != tf.CPUCredits, aws.CPUCredits, tf.CPUCredits
+		},
+		"private_ip": func(aws, tf *models.InstanceDetails) (bool, any, any) {
+			// IP addresses are usually assigned by AWS, so they are only compared when the configuration pins them
+			if tf.PrivateIP == "" {
+				return false, aws.PrivateIP, nil
+			}
+			return aws.PrivateIP != tf.PrivateIP, aws.PrivateIP, tf.PrivateIP
+		},
+		"public_ip": func(aws, tf *models.InstanceDetails) (bool, any, any) {
+			if tf.PublicIP == "" {
+				return false, aws.PublicIP, nil
+			}
+			return aws.PublicIP != tf.PublicIP, aws.PublicIP, tf.PublicIP
+		},
+		"hibernation": func(aws, tf *models.InstanceDetails) (bool, any, any) {
+			// Hibernation can only be set at launch, so a change means the instance was relaunched with other settings
+			return aws.HibernationEnabled != tf.HibernationEnabled, aws.HibernationEnabled, tf.HibernationEnabled
+		},
+		"user_data": func(aws, tf *models.InstanceDetails) (bool, any, any) {
+			// User data is only fetched from AWS when requested. Scripts can be large, so only their hashes are compared and reported
+			if aws.UserData == nil {
+				return false, nil, nil
+			}
+			awsHash, tfHash := userDataHash(aws.UserData), userDataHash(tf.UserData)
+			return awsHash != tfHash, awsHash, tfHash
+		},
 		// Additional attributes can be added here as the model evolves
 	}
 }
 
+// skipUnsetTerraformValue wraps a comparator so that an attribute whose Terraform value is an empty string
+// is not drift. Comparators that default an unset value, such as tenancy, are unaffected as their Terraform
+// value is never empty.
+func skipUnsetTerraformValue(checkFn AttributeComparator) AttributeComparator {
+	return func(aws, tf *models.InstanceDetails) (bool, any, any) {
+		hasDrift, awsValue, tfValue := checkFn(aws, tf)
+		if tfValue == "" {
+			return false, awsValue, tfValue
+		}
+		return hasDrift, awsValue, tfValue
+	}
+}
+
+// userDataHash returns the SHA-256 hash of the user data as "sha256:<hex>", or an empty string if there is none
+func userDataHash(userData *string) string {
+	if userData == nil || *userData == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(*userData))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// tenancyOrDefault returns the tenancy, or the default tenancy when it is not set
+func tenancyOrDefault(tenancy string) string {
+	if tenancy == "" {
+		return defaultTenancy
+	}
+	return tenancy
+}
+
+// lifecycleOrOnDemand returns the instance lifecycle, or on-demand when it is not set
+func lifecycleOrOnDemand(lifecycle string) string {
+	if lifecycle == "" {
+		return onDemandLifecycle
+	}
+	return lifecycle
+}
+
+// shutdownBehaviorOrStop returns the shutdown behavior, or stop when it is not set
+func shutdownBehaviorOrStop(behavior string) string {
+	if behavior == "" {
+		return stopShutdownBehavior
+	}
+	return behavior
+}
+
+// metadataOptionsValues returns the metadata options that are set, keyed by their Terraform argument name,
+// so drifts are reported per setting like tags
+func metadataOptionsValues(options *models.MetadataOptions) map[string]string {
+	values := make(map[string]string)
+	if options == nil {
+		return values
+	}
+	if options.HttpTokens != "" {
+		values["http_tokens"] = options.HttpTokens
+	}
+	if options.HttpEndpoint != "" {
+		values["http_endpoint"] = options.HttpEndpoint
+	}
+	if options.HttpPutResponseHopLimit != 0 {
+		values["http_put_response_hop_limit"] = strconv.Itoa(options.HttpPutResponseHopLimit)
+	}
+	return values
+}
+
+// blockDeviceValues describes the given block devices keyed by device name. A device that is also in other is
+// described by the size and type known on both sides, as AWS may not have fetched them and Terraform may leave
+// them to their defaults; any other device is described in full, including its volume ID.
+func blockDeviceValues(devices, other []models.BlockDevice) map[string]string {
+	otherByName := make(map[string]models.BlockDevice, len(other))
+	for _, device := range other {
+		otherByName[device.DeviceName] = device
+	}
+
+	values := make(map[string]string, len(devices))
+	for _, device := range devices {
+		match, matched := otherByName[device.DeviceName]
+		var parts []string
+		if !matched && device.VolumeID != "" {
+			parts = append(parts, device.VolumeID)
+		}
+		if device.VolumeType != "" && (!matched || match.VolumeType != "") {
+			parts = append(parts, device.VolumeType)
+		}
+		if device.VolumeSize != 0 && (!matched || match.VolumeSize != 0) {
+			parts = append(parts, fmt.Sprintf("%dGiB", device.VolumeSize))
+		}
+		if len(parts) == 0 {
+			parts = append(parts, "attached")
+		}
+		values[device.DeviceName] = strings.Join(parts, " ")
+	}
+	return values
+}
+
+// volumeTagValues returns the tags of each device whose tags were fetched, and the tags it should have, keyed
+// by device name. Tags are formatted as sorted key=value pairs, so they can be reported per device.
+func volumeTagValues(devices []*models.BlockDevice, tfTags map[string]string) (map[string]string, map[string]string) {
+	awsValues := make(map[string]string, len(devices))
+	tfValues := make(map[string]string, len(devices))
+	for _, device := range devices {
+		if device.VolumeTags == nil {
+			continue
+		}
+		awsValues[device.DeviceName] = formatVolumeTags(device.VolumeTags)
+		tfValues[device.DeviceName] = formatVolumeTags(tfTags)
+	}
+	return awsValues, tfValues
+}
+
+// formatVolumeTags formats tags as key=value pairs sorted by key
+func formatVolumeTags(tags map[string]string) string {
+	pairs := make([]string, 0, len(tags))
+	for _, key := range slices.Sorted(maps.Keys(tags)) {
+		pairs = append(pairs, key+"="+tags[key])
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// subsetTags returns the tags of awsTags whose keys are in tfTags, or nil if tfTags is nil
+func subsetTags(awsTags, tfTags map[string]string) map[string]string {
+	if tfTags == nil {
+		return nil
+	}
+	subset := make(map[string]string, len(tfTags))
+	for key := range tfTags {
+		if value, ok := awsTags[key]; ok {
+			subset[key] = value
+		}
+	}
+	return subset
+}
+
+// foldTagValueCase returns a copy of awsTags in which the values equal to those of tfTags ignoring case are
+// replaced with the Terraform ones, so only the tags whose values differ by more than case are drift
+func foldTagValueCase(awsTags, tfTags map[string]string) map[string]string {
+	if awsTags == nil {
+		return nil
+	}
+	folded := make(map[string]string, len(awsTags))
+	for key, value := range awsTags {
+		if tfValue, ok := tfTags[key]; ok && strings.EqualFold(value, tfValue) {
+			value = tfValue
+		}
+		folded[key] = value
+	}
+	return folded
+}
+
 // sortedCopy creates a sorted copy of a string slice
 func sortedCopy(original []string) []string {
 	if original == nil {
@@ -114,18 +573,39 @@ func checkSpecificAttributes(
 	tfInstance *models.InstanceDetails,
 	attributesToCheck []string,
 	allAttributes map[string]AttributeComparator,
+	strictAttributes bool,
 ) error {
+	var errs []error
 	for _, attr := range attributesToCheck {
 		normalizedAttr := normalizeAttributeName(attr)
-		if checkFn, exists := allAttributes[normalizedAttr]; exists {
-			if err := checkAttributeAndUpdateResult(result, normalizedAttr, checkFn, awsInstance, tfInstance); err != nil {
-				return err
+		checkFn, exists := allAttributes[normalizedAttr]
+		if !exists {
+			unsupportedErr := NewDriftError(ErrResourceMissing, "Requested attribute is not supported", attr, nil)
+			if strictAttributes {
+				return unsupportedErr
 			}
-		} else {
-			return NewDriftError(ErrResourceMissing, "Requested attribute is not supported", attr, nil)
+			// An unsupported attribute, typically a typo, should not prevent checking the others
+			errs = append(errs, unsupportedErr)
+			continue
+		}
+		// A failed comparison is reported without stopping the remaining attributes
+		if err := checkAttributeAndUpdateResult(result, normalizedAttr, checkFn, awsInstance, tfInstance); err != nil {
+			errs = append(errs, err)
 		}
 	}
-	return nil
+	return errors.Join(errs...)
+}
+
+// comparableAttributes returns the sorted names of the attributes compared when no subset is requested
+func comparableAttributes(allAttributes map[string]AttributeComparator) []string {
+	attributes := make([]string, 0, len(allAttributes))
+	for attr := range allAttributes {
+		if !slices.Contains(getSkipAttributes(), attr) {
+			attributes = append(attributes, attr)
+		}
+	}
+	sort.Strings(attributes)
+	return attributes
 }
 
 // checkAllAttributes checks for drift in all available attributes except instance_id
@@ -135,16 +615,18 @@ func checkAllAttributes(
 	tfInstance *models.InstanceDetails,
 	allAttributes map[string]AttributeComparator,
 ) error {
+	var errs []error
 	for attr, checkFn := range allAttributes {
 		// Skip attributes that should be skipped
 		if slices.Contains(getSkipAttributes(), attr) {
 			continue
 		}
+		// A failed comparison is reported without stopping the remaining attributes
 		if err := checkAttributeAndUpdateResult(result, attr, checkFn, awsInstance, tfInstance); err != nil {
-			return err
+			errs = append(errs, err)
 		}
 	}
-	return nil
+	return errors.Join(errs...)
 }
 
 // checkAttributeAndUpdateResult checks a single attribute for drift and updates the result
@@ -154,67 +636,220 @@ func checkAttributeAndUpdateResult(
 	checkFn AttributeComparator,
 	awsInstance,
 	tfInstance *models.InstanceDetails,
-) error {
+) (err error) {
 	// Add basic validation
 	if attrName == "" {
 		return NewDriftError(ErrInvalidInput, "Attribute name cannot be empty", "", nil)
 	}
 
-	// Use recover to handle any panics during comparison
+	// Use recover to turn any panic during comparison into an error for this attribute
 	defer func() {
 		if r := recover(); r != nil {
-			err, ok := r.(error)
+			cause, ok := r.(error)
 			if !ok {
-				err = fmt.Errorf("%v", r)
+				cause = fmt.Errorf("%v", r)
 			}
-			// We can't return from defer, so we panic with our wrapped error
-			// This will be caught by the calling function
-			panic(NewDriftError(ErrComparisonFailed, fmt.Sprintf("Panic during comparison: %v", r), attrName, err))
+			err = NewDriftError(ErrComparisonFailed, fmt.Sprintf("Panic during comparison: %v", r), attrName, cause)
 		}
 	}()
 
 	hasDrift, awsValue, tfValue := checkFn(awsInstance, tfInstance)
-	if hasDrift {
-		// Mark the overall result as having drift
-		result.HasDrift = true
-
-		// Record the specific drift details
-		result.Drifts[attrName] = models.DriftDetail{
+	if !hasDrift {
+		// Matching attributes are kept for reports listing everything that was verified
+		result.Matches[attrName] = models.DriftDetail{
 			Attribute:      attrName,
 			AWSValue:       awsValue,
 			TerraformValue: tfValue,
+			Source:         sourceLocation(tfInstance, attrName),
 		}
+		return nil
 	}
 
+	// Mark the overall result as having drift
+	result.HasDrift = true
+
+	// Keyed attributes are recorded per key so the report shows exactly which entries differ
+	if keyDrifts, ok := diffKeyedValues(attrName, awsValue, tfValue); ok {
+		for _, detail := range keyDrifts {
+			detail.Source = sourceLocation(tfInstance, attrName)
+			result.Drifts[detail.Attribute] = detail
+		}
+		return nil
+	}
+
+	// Record the specific drift details
+	result.Drifts[attrName] = models.DriftDetail{
+		Attribute:      attrName,
+		AWSValue:       awsValue,
+		TerraformValue: tfValue,
+		SetChanges:     diffSetValues(awsValue, tfValue),
+		Source:         sourceLocation(tfInstance, attrName),
+	}
 	return nil
 }
 
+// sourceLocation returns where the attribute is defined in the Terraform configuration, or nil if unknown
+func sourceLocation(tfInstance *models.InstanceDetails, attrName string) *models.SourceLocation {
+	location, ok := tfInstance.SourceLocations[attrName]
+	if !ok {
+		if definingAttr, defined := definingAttributes[attrName]; defined {
+			return sourceLocation(tfInstance, definingAttr)
+		}
+		return nil
+	}
+	return &location
+}
+
+// diffSetValues returns the entries added and removed between two string slices compared as sets, such as
+// security groups, in sorted order. It returns nil if the values are not string slices.
+func diffSetValues(awsValue, tfValue any) *models.SetChanges {
+	awsSet, awsOk := awsValue.([]string)
+	tfSet, tfOk := tfValue.([]string)
+	if !awsOk || !tfOk {
+		return nil
+	}
+
+	changes := &models.SetChanges{}
+	for _, entry := range sortedCopy(awsSet) {
+		if !slices.Contains(tfSet, entry) {
+			changes.Added = append(changes.Added, entry)
+		}
+	}
+	for _, entry := range sortedCopy(tfSet) {
+		if !slices.Contains(awsSet, entry) {
+			changes.Removed = append(changes.Removed, entry)
+		}
+	}
+	return changes
+}
+
+// diffKeyedValues breaks a drift between two string maps down into one DriftDetail per differing key,
+// named "<attribute>.<key>". It returns false if the values are not string maps.
+func diffKeyedValues(attrName string, awsValue, tfValue any) ([]models.DriftDetail, bool) {
+	awsMap, awsOk := awsValue.(map[string]string)
+	tfMap, tfOk := tfValue.(map[string]string)
+	if !awsOk || !tfOk {
+		return nil, false
+	}
+
+	// Collect the union of keys in a stable order
+	keys := make([]string, 0, len(awsMap)+len(tfMap))
+	for key := range awsMap {
+		keys = append(keys, key)
+	}
+	for key := range tfMap {
+		if _, exists := awsMap[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	drifts := make([]models.DriftDetail, 0, len(keys))
+	for _, key := range keys {
+		awsVal, inAWS := awsMap[key]
+		tfVal, inTF := tfMap[key]
+
+		detail := models.DriftDetail{Attribute: attrName + "." + key}
+		switch {
+		case inAWS && !inTF:
+			detail.AWSValue, detail.Change = awsVal, models.ChangeAdded
+		case !inAWS && inTF:
+			detail.TerraformValue, detail.Change = tfVal, models.ChangeRemoved
+		case awsVal != tfVal:
+			detail.AWSValue, detail.TerraformValue, detail.Change = awsVal, tfVal, models.ChangeChanged
+		default:
+			continue
+		}
+		drifts = append(drifts, detail)
+	}
+
+	return drifts, true
+}
+
+// SupportedAttributes validates attributesToCheck without comparing any instance, so configuration
+// mistakes can be reported once rather than for every instance. It returns the supported attributes and
+// an error joining an ErrInvalidInput DriftError per empty name and an ErrResourceMissing one per
+// unsupported attribute.
+func SupportedAttributes(attributesToCheck []string) ([]string, error) {
+	allAttributes := getAttributeComparators(nil, TagsModeExact, false)
+
+	var supported []string
+	var errs []error
+	for _, attr := range attributesToCheck {
+		if strings.TrimSpace(attr) == "" {
+			errs = append(errs, NewDriftError(ErrInvalidInput, "Attribute name cannot be empty", "", nil))
+			continue
+		}
+		if _, exists := allAttributes[normalizeAttributeName(attr)]; !exists {
+			errs = append(errs, NewDriftError(ErrResourceMissing, "Requested attribute is not supported", attr, nil))
+			continue
+		}
+		supported = append(supported, attr)
+	}
+	return supported, errors.Join(errs...)
+}
+
+// NormalizeAttribute returns the name that the drifts of attr are reported under, resolving aliases such as
+// "type" for instance_type, so callers can match drifts against an attribute given by users.
+func NormalizeAttribute(attr string) string {
+	return normalizeAttributeName(attr)
+}
+
+// AttributeRequested returns true if attr is explicitly listed in attributesToCheck, under any of its aliases.
+// It lets callers skip fetching data that is costly to retrieve when it is not going to be compared.
+func AttributeRequested(attributesToCheck []string, attr string) bool {
+	for _, requested := range attributesToCheck {
+		if normalizeAttributeName(requested) == attr {
+			return true
+		}
+	}
+	return false
+}
+
 // normalizeAttributeName standardizes attribute names for comparison.
 // This allows users to specify attributes with different formats (e.g., "instance-type" or "instanceType")
 // and still have them correctly matched to the appropriate comparator.
 func normalizeAttributeName(attr string) string {
-	// Convert to lowercase
-	normalized := strings.ToLower(attr)
-
-	// Replace common separators with underscore
-	normalized = strings.ReplaceAll(normalized, "-", "_")
-	normalized = strings.ReplaceAll(normalized, " ", "_")
+	// Convert to lowercase and replace common separators with underscore
+	normalized := normalizeSeparators(attr)
 
 	specialCases := map[string]string{
-		"type":           "instance_type",
-		"instancetype":   "instance_type",
-		"sg":             "security_groups",
-		"securitygroup":  "security_groups",
-		"security_group": "security_groups",
-		"securitygroups": "security_groups",
-		"subnet":         "subnet_id",
-		"vpc":            "vpc_id",
-		"id":             "instance_id",
+		"type":                 "instance_type",
+		"instancetype":         "instance_type",
+		"sg":                   "security_groups",
+		"securitygroup":        "security_groups",
+		"security_group":       "security_groups",
+		"securitygroups":       "security_groups",
+		"subnet":               "subnet_id",
+		"vpc":                  "vpc_id",
+		"vpcid":                "vpc_id",
+		"key":                  "key_name",
+		"keyname":              "key_name",
+		"key_pair":             "key_name",
+		"keypair":              "key_name",
+		"az":                   "availability_zone",
+		"availabilityzone":     "availability_zone",
+		"placement":            "placement_group",
+		"placementgroup":       "placement_group",
+		"lifecycle":            "instance_lifecycle",
+		"market_type":          "instance_lifecycle",
+		"spot":                 "instance_lifecycle",
+		"capacity_reservation": "capacity_reservation_id",
+		"volumes":              "block_devices",
+		"ebs_volumes":          "block_devices",
+		"ebs_block_device":     "block_devices",
+		"name_tag":             "name",
+		"id":                   "instance_id",
 	}
 
 	if replacement, exists := specialCases[normalized]; exists {
 		return replacement
 	}
 
+	// Fall back to aliases registered by importers
+	if replacement, exists := registeredAlias(normalized); exists {
+		return replacement
+	}
+
 	return normalized
 }
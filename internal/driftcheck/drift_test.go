@@ -2,9 +2,12 @@ package driftcheck
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"driftdetector/internal/models"
 )
@@ -64,7 +67,7 @@ func TestDetectDrift_WithDrift(t *testing.T) {
 	// Check results
 	assert.True(t, result.HasDrift, "Expected drift, but none found")
 
-	// Should find 2 drifts: instance_type and tags
+	// Should find 2 drifts: instance_type and tags.Name
 	assert.Equal(t, 2, len(result.Drifts), "Expected 2 drift details")
 
 	// Check instance_type drift
@@ -73,16 +76,287 @@ func TestDetectDrift_WithDrift(t *testing.T) {
 	assert.Equal(t, "t2.medium", drift.AWSValue, "Incorrect AWS value for instance_type")
 	assert.Equal(t, "t2.micro", drift.TerraformValue, "Incorrect Terraform value for instance_type")
 
-	// Check tags drift
-	tagsDrift, tagsExist := result.Drifts["tags"]
-	assert.True(t, tagsExist, "Expected drift in 'tags'")
+	// Check tags drift is reported for the differing key only
+	tagsDrift, tagsExist := result.Drifts["tags.Name"]
+	assert.True(t, tagsExist, "Expected drift in 'tags.Name'")
+	assert.Equal(t, "test-instance-aws", tagsDrift.AWSValue, "Incorrect AWS value for tags.Name")
+	assert.Equal(t, "test-instance", tagsDrift.TerraformValue, "Incorrect Terraform value for tags.Name")
+	assert.Equal(t, models.ChangeChanged, tagsDrift.Change, "Expected tags.Name to be reported as changed")
+}
+
+func TestDetectDrift_TagsPerKey(t *testing.T) {
+	awsInstance := &models.InstanceDetails{
+		Tags: map[string]string{
+			"Name":  "web",
+			"Env":   "prod",
+			"Owner": "ops", // Added outside Terraform
+		},
+	}
+	tfInstance := &models.InstanceDetails{
+		Tags: map[string]string{
+			"Name":       "web",
+			"Env":        "staging",
+			"CostCenter": "1234", // Removed from AWS
+		},
+	}
+
+	result, err := DetectDrift(awsInstance, tfInstance, []string{"tags"})
+	assert.NoError(t, err, "Unexpected error")
+	assert.True(t, result.HasDrift, "Expected tag drift")
+	assert.Equal(t, 3, len(result.Drifts), "Expected one drift per differing key")
+	assert.NotContains(t, result.Drifts, "tags.Name", "Matching keys should not be reported")
+
+	assert.Equal(t, models.DriftDetail{
+		Attribute: "tags.Owner", AWSValue: "ops", Change: models.ChangeAdded,
+	}, result.Drifts["tags.Owner"])
+	assert.Equal(t, models.DriftDetail{
+		Attribute: "tags.CostCenter", TerraformValue: "1234", Change: models.ChangeRemoved,
+	}, result.Drifts["tags.CostCenter"])
+	assert.Equal(t, models.DriftDetail{
+		Attribute: "tags.Env", AWSValue: "prod", TerraformValue: "staging", Change: models.ChangeChanged,
+	}, result.Drifts["tags.Env"])
+
+	// Tags only present on one side are all reported as added
+	result, _ = DetectDrift(awsInstance, &models.InstanceDetails{}, []string{"tags"})
+	assert.Equal(t, 3, len(result.Drifts), "Expected every AWS tag to be reported")
+	for _, d := range result.Drifts {
+		assert.Equal(t, models.ChangeAdded, d.Change)
+	}
+}
+
+func TestDetectDrift_TagsSubset(t *testing.T) {
+	tfInstance := &models.InstanceDetails{
+		Tags: map[string]string{"Name": "web", "Env": "prod"},
+	}
+
+	// Tags added on AWS are not drift
+	awsInstance := &models.InstanceDetails{
+		Tags: map[string]string{"Name": "web", "Env": "prod", "aws:autoscaling:groupName": "web-asg"},
+	}
+	result, err := DetectDriftWithOptions(awsInstance, tfInstance,
+		DetectOptions{Attributes: []string{"tags"}, TagsMode: TagsModeSubset})
+	assert.NoError(t, err)
+	assert.False(t, result.HasDrift, "Tags only present on AWS should be ignored in subset mode")
+
+	// They are still drift in exact mode
+	result, err = DetectDrift(awsInstance, tfInstance, []string{"tags"})
+	assert.NoError(t, err)
+	assert.True(t, result.HasDrift)
+
+	// Missing and different tags of the configuration are drift, and the AWS-only tags are not reported
+	awsInstance = &models.InstanceDetails{
+		Tags: map[string]string{"Env": "staging", "Owner": "ops"},
+	}
+	result, err = DetectDriftWithOptions(awsInstance, tfInstance,
+		DetectOptions{Attributes: []string{"tags"}, TagsMode: TagsModeSubset})
+	assert.NoError(t, err)
+	assert.True(t, result.HasDrift)
+	assert.Len(t, result.Drifts, 2)
+	assert.Equal(t, models.ChangeRemoved, result.Drifts["tags.Name"].Change)
+	assert.Equal(t, models.ChangeChanged, result.Drifts["tags.Env"].Change)
+	assert.NotContains(t, result.Drifts, "tags.Owner")
+}
+
+func TestDetectDrift_LargeTagMaps(t *testing.T) {
+	awsTags, tfTags := largeTagMaps(150)
+	awsInstance := &models.InstanceDetails{Tags: awsTags}
+	tfInstance := &models.InstanceDetails{Tags: tfTags}
+
+	result, err := DetectDrift(awsInstance, tfInstance, []string{"tags"})
+	assert.NoError(t, err)
+	assert.False(t, result.HasDrift)
+
+	// A single differing value among many is drift, and the only one reported
+	awsTags["Key149"] = "changed"
+	result, err = DetectDrift(awsInstance, tfInstance, []string{"tags"})
+	assert.NoError(t, err)
+	assert.True(t, result.HasDrift)
+	assert.Len(t, result.Drifts, 1)
+	assert.Equal(t, "changed", result.Drifts["tags.Key149"].AWSValue)
+
+	// No tags on either side is no drift, whether the configuration has an empty tags map or none
+	result, err = DetectDrift(&models.InstanceDetails{}, &models.InstanceDetails{Tags: map[string]string{}}, []string{"tags"})
+	assert.NoError(t, err)
+	assert.False(t, result.HasDrift)
+}
+
+// largeTagMaps returns two equal tag maps of n tags, as on instances of fleets with verbose tagging
+func largeTagMaps(n int) (map[string]string, map[string]string) {
+	awsTags := make(map[string]string, n)
+	for i := range n {
+		awsTags[fmt.Sprintf("Key%d", i)] = fmt.Sprintf("value-%d", i)
+	}
+	return awsTags, maps.Clone(awsTags)
+}
+
+// BenchmarkTagsComparator measures comparing large tag maps, which is done for every instance
+func BenchmarkTagsComparator(b *testing.B) {
+	compare := builtinAttributeComparators(nil, TagsModeExact, false)["tags"]
+	for _, bm := range []struct {
+		name    string
+		differs bool
+	}{
+		{"equal", false},
+		{"different", true},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			awsTags, tfTags := largeTagMaps(200)
+			if bm.differs {
+				awsTags["Key0"] = "changed"
+			}
+			awsInstance := &models.InstanceDetails{Tags: awsTags}
+			tfInstance := &models.InstanceDetails{Tags: tfTags}
+			b.ResetTimer()
+			for range b.N {
+				if hasDrift, _, _ := compare(awsInstance, tfInstance); hasDrift != bm.differs {
+					b.Fatalf("expected drift %t", bm.differs)
+				}
+			}
+		})
+	}
+}
+
+func TestDetectDrift_IgnoreTagValueCase(t *testing.T) {
+	tfInstance := &models.InstanceDetails{Tags: map[string]string{"Environment": "prod", "Team": "web"}}
+	awsInstance := &models.InstanceDetails{Tags: map[string]string{"Environment": "Prod", "Team": "Web"}}
+
+	// Values differing only in case are drift by default
+	result, err := DetectDrift(awsInstance, tfInstance, []string{"tags"})
+	assert.NoError(t, err)
+	assert.True(t, result.HasDrift)
+
+	opts := DetectOptions{Attributes: []string{"tags"}, IgnoreTagValueCase: true}
+	result, err = DetectDriftWithOptions(awsInstance, tfInstance, opts)
+	assert.NoError(t, err)
+	assert.False(t, result.HasDrift)
+
+	// Other differences are still drift, reported with their original values, and keys stay case-sensitive
+	awsInstance = &models.InstanceDetails{Tags: map[string]string{"environment": "prod", "Environment": "Staging", "Team": "WEB"}}
+	result, err = DetectDriftWithOptions(awsInstance, tfInstance, opts)
+	assert.NoError(t, err)
+	assert.True(t, result.HasDrift)
+	assert.Len(t, result.Drifts, 2)
+	assert.Equal(t, "Staging", result.Drifts["tags.Environment"].AWSValue)
+	assert.Equal(t, models.ChangeAdded, result.Drifts["tags.environment"].Change)
+	assert.NotContains(t, result.Drifts, "tags.Team")
+
+	// Combined with the subset mode
+	opts.TagsMode = TagsModeSubset
+	awsInstance = &models.InstanceDetails{Tags: map[string]string{"Environment": "PROD", "Team": "web", "Owner": "ops"}}
+	result, err = DetectDriftWithOptions(awsInstance, tfInstance, opts)
+	assert.NoError(t, err)
+	assert.False(t, result.HasDrift)
+}
+
+func TestParseTagsMode(t *testing.T) {
+	mode, err := ParseTagsMode("")
+	assert.NoError(t, err)
+	assert.Equal(t, TagsModeExact, mode)
+
+	mode, err = ParseTagsMode("SUBSET")
+	assert.NoError(t, err)
+	assert.Equal(t, TagsModeSubset, mode)
+
+	_, err = ParseTagsMode("superset")
+	assert.True(t, IsErrorCategory(err, ErrInvalidInput))
+}
+
+func TestDetectDrift_SkipUnset(t *testing.T) {
+	awsInstance := &models.InstanceDetails{
+		AMI:          "ami-123",
+		InstanceType: "t2.micro",
+		KeyName:      "deployer",
+		SubnetID:     "subnet-123",
+		Tenancy:      "dedicated",
+	}
+	tfInstance := &models.InstanceDetails{InstanceType: "t2.small"}
+	attrs := []string{"ami", "instance_type", "key_name", "subnet_id", "tenancy"}
+
+	// By default, an empty Terraform value is expected to be empty on AWS
+	result, err := DetectDrift(awsInstance, tfInstance, attrs)
+	assert.NoError(t, err)
+	assert.Len(t, result.Drifts, 5)
+
+	// With skipUnset it is not managed, while set values and defaulted attributes are still compared
+	result, err = DetectDriftWithOptions(awsInstance, tfInstance, DetectOptions{Attributes: attrs, SkipUnset: true})
+	assert.NoError(t, err)
+	assert.True(t, result.HasDrift)
+	assert.Len(t, result.Drifts, 2)
+	assert.Contains(t, result.Drifts, "instance_type")
+	assert.Contains(t, result.Drifts, "tenancy", "An unset tenancy means default")
+
+	// The unset ami, key_name and subnet_id are classified as unmanaged rather than verified
+	assert.Equal(t, []models.DriftDetail{
+		{Attribute: "ami", AWSValue: "ami-123", TerraformValue: ""},
+		{Attribute: "key_name", AWSValue: "deployer", TerraformValue: ""},
+		{Attribute: "subnet_id", AWSValue: "subnet-123", TerraformValue: ""},
+	}, ConvertToUnmanaged(result))
+	assert.NotContains(t, result.Matches, "ami")
+	assert.NotContains(t, result.Matches, "subnet_id")
 
-	// Verify tags type
-	_, awsOk := tagsDrift.AWSValue.(map[string]string)
-	assert.True(t, awsOk, "Expected AWS tags to be map[string]string")
+	// Unset on both sides is a match, not unmanaged
+	result, err = DetectDriftWithOptions(&models.InstanceDetails{AMI: "ami-123"}, &models.InstanceDetails{},
+		DetectOptions{Attributes: []string{"ami", "subnet_id"}, SkipUnset: true})
+	assert.NoError(t, err)
+	assert.Contains(t, result.Matches, "subnet_id")
+	assert.Equal(t, []string{"ami"}, slices.Sorted(maps.Keys(result.Unmanaged)))
 
-	_, tfOk := tagsDrift.TerraformValue.(map[string]string)
-	assert.True(t, tfOk, "Expected Terraform tags to be map[string]string")
+	// Without skipUnset nothing is unmanaged, the unset values are drift
+	result, err = DetectDrift(awsInstance, tfInstance, attrs)
+	assert.NoError(t, err)
+	assert.Empty(t, result.Unmanaged)
+}
+
+func TestDetectDriftWithOptions(t *testing.T) {
+	awsInstance := &models.InstanceDetails{
+		InstanceType: "t3a.micro",
+		AMI:          "ami-123",
+		Tags:         map[string]string{"Name": "web", "Owner": "ops"},
+	}
+	tfInstance := &models.InstanceDetails{
+		InstanceType: "t3.micro",
+		Tags:         map[string]string{"Name": "web"},
+	}
+
+	// The zero value checks every attribute exactly
+	result, err := DetectDriftWithOptions(awsInstance, tfInstance, DetectOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, result.Drifts, "instance_type")
+	assert.Contains(t, result.Drifts, "ami")
+	assert.Contains(t, result.Drifts, "tags.Owner")
+
+	equivalences, err := ParseInstanceTypeEquivalences([]string{"t3.micro=t3a.micro"})
+	require.NoError(t, err)
+	opts := DetectOptions{
+		Attributes:      []string{"instance_type", "ami", "tags"},
+		EquivalentTypes: equivalences,
+		TagsMode:        TagsModeSubset,
+		SkipUnset:       true,
+	}
+	result, err = DetectDriftWithOptions(awsInstance, tfInstance, opts)
+	require.NoError(t, err)
+	assert.False(t, result.HasDrift, "Every difference should be covered by the options")
+
+	// DetectDrift is a wrapper comparing the attributes exactly
+	result, err = DetectDriftWithOptions(awsInstance, tfInstance, DetectOptions{Attributes: opts.Attributes})
+	require.NoError(t, err)
+	wrapped, err := DetectDrift(awsInstance, tfInstance, opts.Attributes)
+	require.NoError(t, err)
+	assert.Equal(t, result, wrapped)
+}
+
+func TestDetectDrift_Matches(t *testing.T) {
+	awsInstance := &models.InstanceDetails{InstanceType: "t2.micro", AMI: "ami-123"}
+	tfInstance := &models.InstanceDetails{InstanceType: "t2.small", AMI: "ami-123"}
+
+	result, err := DetectDrift(awsInstance, tfInstance, []string{"instance_type", "ami"})
+	require.NoError(t, err)
+
+	// Attributes compared without drift are kept with their values
+	assert.Equal(t, []models.DriftDetail{
+		{Attribute: "ami", AWSValue: "ami-123", TerraformValue: "ami-123"},
+	}, ConvertToMatches(result))
+	assert.NotContains(t, result.Matches, "instance_type")
 }
 
 func TestDetectDrift_SpecificAttributes(t *testing.T) {
@@ -139,6 +413,10 @@ func TestNormalizeAttributeName(t *testing.T) {
 		{"securitygroup", "security_groups"},
 		{"subnet", "subnet_id"},
 		{"vpc", "vpc_id"},
+		{"VpcId", "vpc_id"},
+		{"az", "availability_zone"},
+		{"AvailabilityZone", "availability_zone"},
+		{"placement-group", "placement_group"},
 		{"id", "instance_id"},
 		{"custom_attribute", "custom_attribute"},
 	}
@@ -188,6 +466,421 @@ func TestDetectDrift_SecurityGroups(t *testing.T) {
 	assert.False(t, result3.HasDrift, "Expected no drift for security groups in different order")
 }
 
+func TestDetectDrift_SourceLocations(t *testing.T) {
+	awsInstance := &models.InstanceDetails{
+		InstanceType: "t2.large",
+		Tags:         map[string]string{"Env": "prod"},
+	}
+	tfInstance := &models.InstanceDetails{
+		InstanceType: "t2.micro",
+		Tags:         map[string]string{"Env": "staging"},
+		SourceLocations: map[string]models.SourceLocation{
+			"instance_type": {Filename: "main.tf", Line: 3},
+			"tags":          {Filename: "main.tf", Line: 8},
+		},
+	}
+
+	result, err := DetectDrift(awsInstance, tfInstance, []string{"instance_type", "tags", "ami"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "main.tf:3", result.Drifts["instance_type"].Source.String())
+	assert.Equal(t, "main.tf:8", result.Drifts["tags.Env"].Source.String(), "Per-key drifts use the location of their attribute")
+
+	// Attributes not defined in the configuration have no location
+	result, _ = DetectDrift(&models.InstanceDetails{AMI: "ami-1"}, tfInstance, []string{"ami"})
+	assert.Nil(t, result.Drifts["ami"].Source)
+}
+
+func TestDetectDrift_VPCAlias(t *testing.T) {
+	awsInstance := &models.InstanceDetails{VPCID: "vpc-aws"}
+	tfInstance := &models.InstanceDetails{VPCID: "vpc-tf"}
+
+	// The "vpc" alias should resolve to the vpc_id comparator rather than failing as unsupported
+	result, err := DetectDrift(awsInstance, tfInstance, []string{"vpc"})
+	assert.NoError(t, err, "vpc alias should be supported")
+	assert.True(t, result.HasDrift, "Expected drift for different VPC IDs")
+	assert.Equal(t, "vpc-aws", result.Drifts["vpc_id"].AWSValue)
+	assert.Equal(t, "vpc-tf", result.Drifts["vpc_id"].TerraformValue)
+
+	// The configuration cannot set the VPC of an aws_instance, so an unset VPC is no drift
+	result, err = DetectDrift(awsInstance, &models.InstanceDetails{}, []string{"vpc_id"})
+	assert.NoError(t, err)
+	assert.False(t, result.HasDrift, "Expected no drift when the configuration sets no VPC")
+}
+
+func TestDetectDrift_Placement(t *testing.T) {
+	awsInstance := &models.InstanceDetails{
+		AvailabilityZone: "us-east-1b",
+	}
+
+	// Instance relaunched into a different AZ should drift
+	tfInstance := &models.InstanceDetails{
+		AvailabilityZone: "us-east-1a",
+	}
+	result, err := DetectDrift(awsInstance, tfInstance, []string{"availability_zone", "placement_group"})
+	assert.NoError(t, err)
+	assert.True(t, result.HasDrift, "Expected drift for different availability zones")
+	assert.Contains(t, result.Drifts, "availability_zone")
+	assert.NotContains(t, result.Drifts, "placement_group", "Empty placement group on both sides should not drift")
+
+	// Placement group set in Terraform but missing in AWS should drift
+	tfInstance = &models.InstanceDetails{
+		AvailabilityZone: "us-east-1b",
+		PlacementGroup:   "cluster-pg",
+	}
+	result, err = DetectDrift(awsInstance, tfInstance, []string{"az", "placement_group"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(result.Drifts), "Expected only the placement group to drift")
+	assert.Equal(t, "cluster-pg", result.Drifts["placement_group"].TerraformValue)
+
+	// A zone left to the subnet is no drift
+	result, err = DetectDrift(awsInstance, &models.InstanceDetails{SubnetID: "subnet-123"}, []string{"availability_zone"})
+	assert.NoError(t, err)
+	assert.False(t, result.HasDrift, "Expected no drift when the configuration sets no availability zone")
+}
+
+func TestDetectDrift_Tenancy(t *testing.T) {
+	awsInstance := &models.InstanceDetails{Tenancy: "default"}
+
+	// Tenancy left unset in Terraform means shared hardware
+	result, err := DetectDrift(awsInstance, &models.InstanceDetails{}, []string{"tenancy"})
+	assert.NoError(t, err)
+	assert.False(t, result.HasDrift, "Empty Terraform tenancy should match default")
+
+	// An instance moved to dedicated hardware should drift
+	awsInstance.Tenancy = "dedicated"
+	result, err = DetectDrift(awsInstance, &models.InstanceDetails{}, []string{"tenancy"})
+	assert.NoError(t, err)
+	assert.True(t, result.HasDrift, "Expected drift for dedicated tenancy")
+	assert.Equal(t, "dedicated", result.Drifts["tenancy"].AWSValue)
+	assert.Equal(t, "default", result.Drifts["tenancy"].TerraformValue)
+
+	result, err = DetectDrift(awsInstance, &models.InstanceDetails{Tenancy: "dedicated"}, []string{"tenancy"})
+	assert.NoError(t, err)
+	assert.False(t, result.HasDrift)
+}
+
+func TestDetectDrift_InstanceLifecycle(t *testing.T) {
+	// On-demand instances have no lifecycle on either side
+	result, err := DetectDrift(&models.InstanceDetails{}, &models.InstanceDetails{}, []string{"instance_lifecycle"})
+	assert.NoError(t, err)
+	assert.False(t, result.HasDrift)
+
+	// An on-demand instance that became spot during recovery drifts
+	result, err = DetectDrift(&models.InstanceDetails{InstanceLifecycle: "spot"}, &models.InstanceDetails{}, []string{"lifecycle"})
+	assert.NoError(t, err)
+	assert.True(t, result.HasDrift)
+	assert.Equal(t, "spot", result.Drifts["instance_lifecycle"].AWSValue)
+	assert.Equal(t, "on-demand", result.Drifts["instance_lifecycle"].TerraformValue)
+
+	result, err = DetectDrift(&models.InstanceDetails{}, &models.InstanceDetails{InstanceLifecycle: "spot"}, []string{"instance_lifecycle"})
+	assert.NoError(t, err)
+	assert.True(t, result.HasDrift, "Expected drift for a spot instance replaced on-demand")
+}
+
+func TestDetectDrift_SecurityGroupSetChanges(t *testing.T) {
+	awsInstance := &models.InstanceDetails{SecurityGroups: []string{"sg-3", "sg-1", "sg-4"}}
+	tfInstance := &models.InstanceDetails{SecurityGroups: []string{"sg-1", "sg-2", "sg-3"}}
+
+	result, err := DetectDrift(awsInstance, tfInstance, []string{"security_groups"})
+	assert.NoError(t, err)
+	drift := result.Drifts["security_groups"]
+	assert.Equal(t, []string{"sg-3", "sg-1", "sg-4"}, drift.AWSValue, "The full lists are still reported")
+	assert.Equal(t, &models.SetChanges{Added: []string{"sg-4"}, Removed: []string{"sg-2"}}, drift.SetChanges)
+
+	// Scalar attributes have no set changes
+	result, err = DetectDrift(&models.InstanceDetails{AMI: "ami-1"}, &models.InstanceDetails{AMI: "ami-2"}, []string{"ami"})
+	assert.NoError(t, err)
+	assert.Nil(t, result.Drifts["ami"].SetChanges)
+}
+
+func TestDetectDrift_Name(t *testing.T) {
+	awsInstance := &models.InstanceDetails{Tags: map[string]string{"Name": "web-2", "Team": "platform"}}
+	tfInstance := &models.InstanceDetails{
+		Tags:            map[string]string{"Name": "web-1", "Team": "platform"},
+		SourceLocations: map[string]models.SourceLocation{"tags": {Filename: "main.tf", Line: 4}},
+	}
+
+	result, err := DetectDrift(awsInstance, tfInstance, []string{"name"})
+	assert.NoError(t, err)
+	assert.True(t, result.HasDrift)
+	assert.Len(t, result.Drifts, 1)
+	assert.Equal(t, "web-2", result.Drifts["name"].AWSValue)
+	assert.Equal(t, "web-1", result.Drifts["name"].TerraformValue)
+	assert.Equal(t, 4, result.Drifts["name"].Source.Line, "The Name tag is located with the tags")
+
+	// Without requested attributes the Name tag is only reported with the other tags
+	result, err = DetectDrift(awsInstance, tfInstance, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, result.Drifts, "tags.Name")
+	assert.NotContains(t, result.Drifts, "name")
+
+	// Other tags do not affect the name
+	tfInstance.Tags = map[string]string{"Name": "web-2"}
+	result, err = DetectDrift(awsInstance, tfInstance, []string{"Name"})
+	assert.NoError(t, err)
+	assert.False(t, result.HasDrift)
+}
+
+func TestDetectDrift_CapacityReservationID(t *testing.T) {
+	result, err := DetectDrift(&models.InstanceDetails{}, &models.InstanceDetails{}, []string{"capacity_reservation_id"})
+	assert.NoError(t, err)
+	assert.False(t, result.HasDrift, "No reservation on either side is no drift")
+
+	result, err = DetectDrift(&models.InstanceDetails{}, &models.InstanceDetails{CapacityReservationID: "cr-12345"}, []string{"capacity_reservation_id"})
+	assert.NoError(t, err)
+	assert.True(t, result.HasDrift)
+	assert.Equal(t, "", result.Drifts["capacity_reservation_id"].AWSValue)
+	assert.Equal(t, "cr-12345", result.Drifts["capacity_reservation_id"].TerraformValue)
+}
+
+func TestDetectDrift_BlockDevices(t *testing.T) {
+	awsInstance := &models.InstanceDetails{
+		BlockDevices: []models.BlockDevice{
+			{DeviceName: "/dev/sdg", VolumeID: "vol-logs"},
+			{DeviceName: "/dev/sdf", VolumeID: "vol-data", VolumeSize: 100, VolumeType: "gp2"},
+			{DeviceName: "/dev/sdh", VolumeID: "vol-manual", VolumeSize: 50, VolumeType: "gp3"},
+		},
+	}
+
+	// Without ebs_block_device blocks Terraform does not manage the volumes
+	result, err := DetectDrift(awsInstance, &models.InstanceDetails{}, []string{"block_devices"})
+	assert.NoError(t, err)
+	assert.False(t, result.HasDrift)
+
+	tfInstance := &models.InstanceDetails{
+		BlockDevices: []models.BlockDevice{
+			{DeviceName: "/dev/sdf", VolumeSize: 100, VolumeType: "gp3"},
+			{DeviceName: "/dev/sdg", VolumeSize: 20},
+			{DeviceName: "/dev/sdi", VolumeSize: 10},
+		},
+	}
+	result, err = DetectDrift(awsInstance, tfInstance, []string{"volumes"})
+	assert.NoError(t, err)
+	assert.True(t, result.HasDrift)
+	assert.Len(t, result.Drifts, 3, "The size of /dev/sdg is unknown in AWS, so it should not drift")
+
+	drift := result.Drifts["block_devices./dev/sdf"]
+	assert.Equal(t, "gp2 100GiB", drift.AWSValue)
+	assert.Equal(t, "gp3 100GiB", drift.TerraformValue)
+	assert.Equal(t, models.ChangeChanged, drift.Change)
+	drift = result.Drifts["block_devices./dev/sdh"]
+	assert.Equal(t, "vol-manual gp3 50GiB", drift.AWSValue)
+	assert.Equal(t, models.ChangeAdded, drift.Change)
+	assert.Equal(t, models.ChangeRemoved, result.Drifts["block_devices./dev/sdi"].Change)
+
+	// Devices are matched by name regardless of order
+	tfInstance.BlockDevices = []models.BlockDevice{{DeviceName: "/dev/sdh"}, {DeviceName: "/dev/sdg"}, {DeviceName: "/dev/sdf", VolumeType: "gp2"}}
+	result, err = DetectDrift(awsInstance, tfInstance, []string{"block_devices"})
+	assert.NoError(t, err)
+	assert.False(t, result.HasDrift)
+}
+
+func TestDetectDrift_VolumeTags(t *testing.T) {
+	awsInstance := &models.InstanceDetails{
+		BlockDevices: []models.BlockDevice{
+			{DeviceName: "/dev/sdf", VolumeID: "vol-data", VolumeTags: map[string]string{"CostCenter": "42", "Team": "data"}},
+			{DeviceName: "/dev/sdg", VolumeID: "vol-logs", VolumeTags: map[string]string{"Team": "data", "CostCenter": "7"}},
+			// Tags that were not fetched are not compared
+			{DeviceName: "/dev/sdh", VolumeID: "vol-manual"},
+		},
+	}
+
+	// Without volume_tags Terraform does not manage the tags of the volumes
+	result, err := DetectDrift(awsInstance, &models.InstanceDetails{}, []string{"volume_tags"})
+	assert.NoError(t, err)
+	assert.False(t, result.HasDrift)
+
+	tfInstance := &models.InstanceDetails{VolumeTags: map[string]string{"Team": "data", "CostCenter": "42"}}
+	result, err = DetectDrift(awsInstance, tfInstance, []string{"volume_tags"})
+	assert.NoError(t, err)
+	assert.True(t, result.HasDrift)
+	require.Len(t, result.Drifts, 1)
+	drift := result.Drifts["volume_tags./dev/sdg"]
+	assert.Equal(t, "CostCenter=7, Team=data", drift.AWSValue)
+	assert.Equal(t, "CostCenter=42, Team=data", drift.TerraformValue)
+	assert.Equal(t, models.ChangeChanged, drift.Change)
+
+	// volume_tags apply to the root volume too, the only volume of most instances
+	rootOnly := &models.InstanceDetails{
+		RootVolume: &models.BlockDevice{DeviceName: "/dev/xvda", VolumeID: "vol-root", VolumeTags: map[string]string{"Team": "data"}},
+	}
+	result, err = DetectDrift(rootOnly, tfInstance, []string{"volume_tags"})
+	assert.NoError(t, err)
+	require.Len(t, result.Drifts, 1)
+	drift = result.Drifts["volume_tags./dev/xvda"]
+	assert.Equal(t, "Team=data", drift.AWSValue)
+	assert.Equal(t, "CostCenter=42, Team=data", drift.TerraformValue)
+}
+
+func TestDetectDrift_MetadataOptions(t *testing.T) {
+	awsInstance := &models.InstanceDetails{
+		MetadataOptions: &models.MetadataOptions{HttpTokens: "optional", HttpEndpoint: "enabled", HttpPutResponseHopLimit: 1},
+	}
+
+	// Without a metadata_options block Terraform does not manage the settings
+	result, err := DetectDrift(awsInstance, &models.InstanceDetails{}, []string{"metadata_options"})
+	assert.NoError(t, err)
+	assert.False(t, result.HasDrift)
+
+	// IMDSv2 required in Terraform but optional in AWS should drift, settings Terraform leaves unset should not
+	tfInstance := &models.InstanceDetails{
+		MetadataOptions: &models.MetadataOptions{HttpTokens: "required", HttpEndpoint: "enabled"},
+		SourceLocations: map[string]models.SourceLocation{"metadata_options": {Filename: "main.tf", Line: 5}},
+	}
+	result, err = DetectDrift(awsInstance, tfInstance, []string{"metadata_options"})
+	assert.NoError(t, err)
+	assert.True(t, result.HasDrift, "Expected drift for IMDSv2 not being enforced")
+	assert.Len(t, result.Drifts, 1, "Only the differing setting should be reported")
+	drift := result.Drifts["metadata_options.http_tokens"]
+	assert.Equal(t, "optional", drift.AWSValue)
+	assert.Equal(t, "required", drift.TerraformValue)
+	assert.Equal(t, models.ChangeChanged, drift.Change)
+	assert.Equal(t, 5, drift.Source.Line)
+
+	tfInstance.MetadataOptions = &models.MetadataOptions{HttpTokens: "optional", HttpPutResponseHopLimit: 1}
+	result, err = DetectDrift(awsInstance, tfInstance, []string{"metadata_options"})
+	assert.NoError(t, err)
+	assert.False(t, result.HasDrift)
+}
+
+func TestDetectDrift_DisableApiTermination(t *testing.T) {
+	enabled, disabled := true, false
+
+	// Not fetched from AWS, so there is nothing to compare
+	result, err := DetectDrift(&models.InstanceDetails{}, &models.InstanceDetails{DisableApiTermination: &enabled}, nil)
+	assert.NoError(t, err)
+	assert.NotContains(t, result.Drifts, "disable_api_termination")
+
+	// Protection toggled in the console while Terraform leaves it unset
+	result, err = DetectDrift(&models.InstanceDetails{DisableApiTermination: &enabled}, &models.InstanceDetails{}, []string{"disable_api_termination"})
+	assert.NoError(t, err)
+	assert.True(t, result.HasDrift)
+	assert.Equal(t, true, result.Drifts["disable_api_termination"].AWSValue)
+	assert.Equal(t, false, result.Drifts["disable_api_termination"].TerraformValue)
+
+	result, err = DetectDrift(&models.InstanceDetails{DisableApiTermination: &disabled}, &models.InstanceDetails{DisableApiTermination: &disabled}, []string{"disable_api_termination"})
+	assert.NoError(t, err)
+	assert.False(t, result.HasDrift)
+}
+
+func TestDetectDrift_RootDeleteOnTermination(t *testing.T) {
+	deleted, kept := true, false
+
+	// Unknown on AWS, e.g. an instance store root volume, so there is nothing to compare
+	result, err := DetectDrift(&models.InstanceDetails{}, &models.InstanceDetails{RootDeleteOnTermination: &kept}, nil)
+	assert.NoError(t, err)
+	assert.NotContains(t, result.Drifts, RootDeleteOnTerminationAttribute)
+
+	// Terraform deletes the root volume unless set otherwise, so a kept volume is drift
+	result, err = DetectDrift(&models.InstanceDetails{RootDeleteOnTermination: &kept}, &models.InstanceDetails{}, nil)
+	assert.NoError(t, err)
+	assert.True(t, result.HasDrift)
+	assert.Equal(t, false, result.Drifts[RootDeleteOnTerminationAttribute].AWSValue)
+	assert.Equal(t, true, result.Drifts[RootDeleteOnTerminationAttribute].TerraformValue)
+
+	result, err = DetectDrift(&models.InstanceDetails{RootDeleteOnTermination: &deleted}, &models.InstanceDetails{}, []string{RootDeleteOnTerminationAttribute})
+	assert.NoError(t, err)
+	assert.False(t, result.HasDrift)
+}
+
+func TestDetectDrift_ShutdownBehavior(t *testing.T) {
+	// Not fetched from AWS, so there is nothing to compare
+	result, err := DetectDrift(&models.InstanceDetails{}, &models.InstanceDetails{InstanceInitiatedShutdownBehavior: "terminate"}, nil)
+	assert.NoError(t, err)
+	assert.NotContains(t, result.Drifts, ShutdownBehaviorAttribute)
+
+	// Switched to terminate while Terraform leaves it to stop
+	result, err = DetectDrift(&models.InstanceDetails{InstanceInitiatedShutdownBehavior: "terminate"}, &models.InstanceDetails{}, []string{ShutdownBehaviorAttribute})
+	assert.NoError(t, err)
+	assert.True(t, result.HasDrift)
+	assert.Equal(t, "terminate", result.Drifts[ShutdownBehaviorAttribute].AWSValue)
+	assert.Equal(t, "stop", result.Drifts[ShutdownBehaviorAttribute].TerraformValue)
+
+	result, err = DetectDrift(&models.InstanceDetails{InstanceInitiatedShutdownBehavior: "stop"}, &models.InstanceDetails{}, []string{ShutdownBehaviorAttribute})
+	assert.NoError(t, err)
+	assert.False(t, result.HasDrift)
+}
+
+func TestDetectDrift_CPUCredits(t *testing.T) {
+	// Not fetched from AWS, so there is nothing to compare
+	result, err := DetectDrift(&models.InstanceDetails{}, &models.InstanceDetails{CPUCredits: "standard"}, nil)
+	assert.NoError(t, err)
+	assert.NotContains(t, result.Drifts, "cpu_credits")
+
+	// Switched to unlimited outside of Terraform
+	result, err = DetectDrift(&models.InstanceDetails{CPUCredits: "unlimited"}, &models.InstanceDetails{CPUCredits: "standard"}, []string{"cpu_credits"})
+	assert.NoError(t, err)
+	assert.True(t, result.HasDrift)
+	assert.Equal(t, "unlimited", result.Drifts["cpu_credits"].AWSValue)
+	assert.Equal(t, "standard", result.Drifts["cpu_credits"].TerraformValue)
+
+	// Without a credit_specification block the family default is not managed
+	result, err = DetectDrift(&models.InstanceDetails{CPUCredits: "unlimited"}, &models.InstanceDetails{}, []string{"cpu_credits"})
+	assert.NoError(t, err)
+	assert.False(t, result.HasDrift)
+}
+
+func TestDetectDrift_Hibernation(t *testing.T) {
+	// Relaunched with hibernation while Terraform leaves it disabled
+	result, err := DetectDrift(&models.InstanceDetails{HibernationEnabled: true}, &models.InstanceDetails{}, nil)
+	assert.NoError(t, err)
+	assert.True(t, result.HasDrift)
+	assert.Equal(t, true, result.Drifts["hibernation"].AWSValue)
+	assert.Equal(t, false, result.Drifts["hibernation"].TerraformValue)
+
+	result, err = DetectDrift(&models.InstanceDetails{HibernationEnabled: true}, &models.InstanceDetails{HibernationEnabled: true}, []string{"hibernation"})
+	assert.NoError(t, err)
+	assert.False(t, result.HasDrift)
+}
+
+func TestDetectDrift_UserData(t *testing.T) {
+	script, edited := "#!/bin/bash\nyum install -y nginx\n", "#!/bin/bash\nyum install -y httpd\n"
+
+	// Not fetched from AWS, so there is nothing to compare
+	result, err := DetectDrift(&models.InstanceDetails{}, &models.InstanceDetails{UserData: &script}, nil)
+	assert.NoError(t, err)
+	assert.NotContains(t, result.Drifts, "user_data")
+
+	// Only the hashes are reported, never the scripts
+	result, err = DetectDrift(&models.InstanceDetails{UserData: &edited}, &models.InstanceDetails{UserData: &script}, []string{"user_data"})
+	assert.NoError(t, err)
+	assert.True(t, result.HasDrift)
+	assert.Equal(t, userDataHash(&edited), result.Drifts["user_data"].AWSValue)
+	assert.Equal(t, userDataHash(&script), result.Drifts["user_data"].TerraformValue)
+	assert.Regexp(t, "^sha256:[0-9a-f]{64}$", result.Drifts["user_data"].AWSValue)
+
+	result, err = DetectDrift(&models.InstanceDetails{UserData: &script}, &models.InstanceDetails{UserData: &script}, []string{"user_data"})
+	assert.NoError(t, err)
+	assert.False(t, result.HasDrift)
+
+	// No user data in AWS matches none in Terraform
+	empty := ""
+	result, err = DetectDrift(&models.InstanceDetails{UserData: &empty}, &models.InstanceDetails{}, []string{"user_data"})
+	assert.NoError(t, err)
+	assert.False(t, result.HasDrift)
+}
+
+func TestSupportedAttributes(t *testing.T) {
+	supported, err := SupportedAttributes([]string{"instance_type", "SG", "bogus", ""})
+	assert.Equal(t, []string{"instance_type", "SG"}, supported, "Aliases are supported under the name given")
+
+	unsupported, rest := SplitErrorCategory(err, ErrResourceMissing)
+	assert.Len(t, unsupported, 1)
+	assert.Equal(t, "bogus", unsupported[0].Attribute)
+	assert.True(t, IsErrorCategory(rest, ErrInvalidInput), "Empty names are invalid input")
+
+	supported, err = SupportedAttributes([]string{"tags"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"tags"}, supported)
+}
+
+func TestAttributeRequested(t *testing.T) {
+	assert.True(t, AttributeRequested([]string{"instance_type", "Disable-Api-Termination"}, "disable_api_termination"))
+	assert.False(t, AttributeRequested([]string{"instance_type"}, "disable_api_termination"))
+	assert.False(t, AttributeRequested(nil, "disable_api_termination"), "All attributes does not request costly ones")
+}
+
 func TestDetectDrift_InstanceIDExplicit(t *testing.T) {
 	awsInstance := &models.InstanceDetails{
 		InstanceID: "i-12345",
@@ -335,3 +1028,130 @@ func TestIsErrorCategory(t *testing.T) {
 	regularErr := fmt.Errorf("regular error")
 	assert.False(t, IsErrorCategory(regularErr, ErrInvalidInput), "Should return false for regular error")
 }
+
+func TestDetectDrift_UnsupportedAttributeAmongValid(t *testing.T) {
+	awsInstance := &models.InstanceDetails{
+		InstanceID:   "i-12345",
+		InstanceType: "t2.medium",
+		Tags:         map[string]string{"Name": "aws"},
+	}
+	tfInstance := &models.InstanceDetails{
+		InstanceType: "t2.micro",
+		Tags:         map[string]string{"Name": "terraform"},
+	}
+
+	result, err := DetectDrift(awsInstance, tfInstance, []string{"instance_type", "bogus", "tags", "also_bogus"})
+
+	// Both unsupported attributes are reported
+	assert.True(t, IsErrorCategory(err, ErrResourceMissing))
+	unsupported, rest := SplitErrorCategory(err, ErrResourceMissing)
+	assert.NoError(t, rest)
+	if assert.Len(t, unsupported, 2) {
+		assert.Equal(t, "bogus", unsupported[0].Attribute)
+		assert.Equal(t, "also_bogus", unsupported[1].Attribute)
+	}
+
+	// The valid attributes are still compared
+	assert.True(t, result.HasDrift)
+	assert.Contains(t, result.Drifts, "instance_type")
+	assert.Contains(t, result.Drifts, "tags.Name")
+}
+
+func TestDetectDrift_StrictAttributes(t *testing.T) {
+	awsInstance := &models.InstanceDetails{
+		InstanceID:   "i-12345",
+		InstanceType: "t2.medium",
+	}
+	tfInstance := &models.InstanceDetails{
+		InstanceType: "t2.micro",
+	}
+
+	// In strict mode the first unsupported attribute aborts the check
+	result, err := DetectDriftWithOptions(awsInstance, tfInstance,
+		DetectOptions{Attributes: []string{"bogus", "instance_type"}, StrictAttributes: true})
+	assert.True(t, IsErrorCategory(err, ErrResourceMissing))
+	assert.False(t, result.HasDrift)
+	assert.Empty(t, result.Drifts)
+}
+
+func TestConvertToDrifts_SortedByAttribute(t *testing.T) {
+	result := &DriftResult{
+		HasDrift: true,
+		Drifts: map[string]models.DriftDetail{
+			"vpc_id":        {Attribute: "vpc_id"},
+			"tags.Name":     {Attribute: "tags.Name"},
+			"ami":           {Attribute: "ami"},
+			"instance_type": {Attribute: "instance_type"},
+			"tags.Env":      {Attribute: "tags.Env"},
+			"subnet_id":     {Attribute: "subnet_id"},
+		},
+	}
+	expected := []string{"ami", "instance_type", "subnet_id", "tags.Env", "tags.Name", "vpc_id"}
+
+	// Map iteration order is randomized, so repeated calls would expose any instability
+	for i := 0; i < 20; i++ {
+		drifts := ConvertToDrifts(result)
+		attributes := make([]string, len(drifts))
+		for j, d := range drifts {
+			attributes[j] = d.Attribute
+		}
+		assert.Equal(t, expected, attributes)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	// Two AWS instances can be compared with each other
+	actual := &models.InstanceDetails{InstanceID: "i-1", InstanceType: "t3.large", Tags: map[string]string{"Name": "web"}}
+	desired := &models.InstanceDetails{InstanceID: "i-2", InstanceType: "t3.micro", Tags: map[string]string{"Name": "web"}}
+
+	result, err := Compare(actual, desired, nil)
+	assert.NoError(t, err)
+	assert.True(t, result.HasDrift)
+	assert.Len(t, result.Drifts, 1, "instance_id is never compared")
+	assert.Equal(t, "t3.large", result.Drifts["instance_type"].AWSValue)
+	assert.Equal(t, "t3.micro", result.Drifts["instance_type"].TerraformValue)
+
+	result, err = Compare(actual, desired, []string{"tags"})
+	assert.NoError(t, err)
+	assert.False(t, result.HasDrift)
+
+	_, err = Compare(nil, desired, nil)
+	assert.True(t, IsErrorCategory(err, ErrInvalidInput))
+}
+
+func TestDetectDrift_VolatileAttributes(t *testing.T) {
+	tfInstance := &models.InstanceDetails{PrivateIP: "10.0.1.10", PublicIP: "203.0.113.10"}
+
+	// Addresses assigned by AWS are not managed by a configuration that leaves them unset
+	result, err := DetectDrift(&models.InstanceDetails{PrivateIP: "10.0.1.99", PublicIP: "203.0.113.99"}, &models.InstanceDetails{}, []string{"private_ip", "public_ip"})
+	assert.NoError(t, err)
+	assert.False(t, result.HasDrift)
+
+	// A running instance that lost its pinned addresses drifted
+	running := &models.InstanceDetails{State: "running", PrivateIP: "10.0.1.99"}
+	result, err = DetectDrift(running, tfInstance, []string{"private_ip", "public_ip"})
+	assert.NoError(t, err)
+	assert.Contains(t, result.Drifts, "private_ip")
+	assert.Contains(t, result.Drifts, "public_ip")
+
+	// A stopped instance has released its public IP, so its volatile attributes are skipped
+	stopped := &models.InstanceDetails{State: "stopped", PrivateIP: "10.0.1.99", InstanceType: "t3.small"}
+	result, err = DetectDrift(stopped, tfInstance, []string{"private_ip", "public_ip"})
+	assert.NoError(t, err)
+	assert.False(t, result.HasDrift)
+	assert.Empty(t, result.Matches, "Skipped attributes are not reported as verified either")
+
+	result, err = DetectDrift(stopped, tfInstance, nil)
+	assert.NoError(t, err)
+	assert.NotContains(t, result.Drifts, "public_ip")
+	assert.NotContains(t, result.Matches, "public_ip")
+	assert.Contains(t, result.Drifts, "instance_type", "Other attributes are still compared")
+
+	// Unless they are explicitly included
+	result, err = DetectDriftWithOptions(stopped, tfInstance, DetectOptions{
+		Attributes:      []string{"public_ip"},
+		IncludeVolatile: true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "203.0.113.10", result.Drifts["public_ip"].TerraformValue)
+}
@@ -0,0 +1,65 @@
+package driftcheck
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InstanceTypeEquivalences maps instance types to the representative of their group of interchangeable
+// types, e.g. t3.micro and t3a.micro. The instance_type comparator does not report drift between types
+// of the same group. A nil map makes every type equivalent only to itself.
+type InstanceTypeEquivalences map[string]string
+
+// ParseInstanceTypeEquivalences parses groups of interchangeable instance types written as
+// "t3.micro=t3a.micro". A group may list more than two types, and groups sharing a type are merged.
+// Types are compared case-insensitively.
+func ParseInstanceTypeEquivalences(groups []string) (InstanceTypeEquivalences, error) {
+	equivalences := make(InstanceTypeEquivalences)
+	for _, group := range groups {
+		types := strings.Split(group, "=")
+		if len(types) < 2 {
+			return nil, NewDriftError(ErrInvalidInput,
+				fmt.Sprintf("Equivalent instance types %q must be of the form type=type", group), "instance_type", nil)
+		}
+		for i, instanceType := range types {
+			types[i] = normalizeInstanceType(instanceType)
+			if types[i] == "" {
+				return nil, NewDriftError(ErrInvalidInput,
+					fmt.Sprintf("Equivalent instance types %q contain an empty type", group), "instance_type", nil)
+			}
+		}
+
+		// The types join the group of the first one, together with the groups they already belong to
+		representative := equivalences.representative(types[0])
+		equivalences[types[0]] = representative
+		for _, instanceType := range types[1:] {
+			previous := equivalences.representative(instanceType)
+			for member, memberRepresentative := range equivalences {
+				if memberRepresentative == previous {
+					equivalences[member] = representative
+				}
+			}
+			equivalences[instanceType] = representative
+		}
+	}
+	return equivalences, nil
+}
+
+// Equivalent returns true if the instance types are the same or interchangeable
+func (e InstanceTypeEquivalences) Equivalent(a, b string) bool {
+	a, b = normalizeInstanceType(a), normalizeInstanceType(b)
+	return e.representative(a) == e.representative(b)
+}
+
+// representative returns the representative of the group of a normalized instance type, the type itself if it has none
+func (e InstanceTypeEquivalences) representative(instanceType string) string {
+	if representative, ok := e[instanceType]; ok {
+		return representative
+	}
+	return instanceType
+}
+
+// normalizeInstanceType standardizes an instance type for comparison
+func normalizeInstanceType(instanceType string) string {
+	return strings.ToLower(strings.TrimSpace(instanceType))
+}
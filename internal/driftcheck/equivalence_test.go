@@ -0,0 +1,47 @@
+package driftcheck
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"driftdetector/internal/models"
+)
+
+func TestParseInstanceTypeEquivalences(t *testing.T) {
+	equivalences, err := ParseInstanceTypeEquivalences([]string{"t3.micro=t3a.micro", " M5.Large = m5a.large ", "m5a.large=m6a.large"})
+	assert.NoError(t, err)
+
+	assert.True(t, equivalences.Equivalent("t3.micro", "t3a.micro"))
+	assert.True(t, equivalences.Equivalent("t3a.micro", "T3.MICRO"), "Types are compared case-insensitively")
+	assert.True(t, equivalences.Equivalent("m5.large", "m6a.large"), "Groups sharing a type are merged")
+	assert.True(t, equivalences.Equivalent("c5.xlarge", "c5.xlarge"))
+	assert.False(t, equivalences.Equivalent("t3.micro", "t3.small"))
+	assert.False(t, equivalences.Equivalent("t3.micro", "m5.large"))
+
+	// A nil map only treats identical types as equivalent
+	assert.True(t, InstanceTypeEquivalences(nil).Equivalent("t3.micro", "t3.micro"))
+	assert.False(t, InstanceTypeEquivalences(nil).Equivalent("t3.micro", "t3a.micro"))
+}
+
+func TestParseInstanceTypeEquivalences_Invalid(t *testing.T) {
+	for _, group := range []string{"t3.micro", "t3.micro=", "=t3a.micro"} {
+		_, err := ParseInstanceTypeEquivalences([]string{group})
+		assert.True(t, IsErrorCategory(err, ErrInvalidInput), group)
+	}
+}
+
+func TestDetectDrift_EquivalentInstanceTypes(t *testing.T) {
+	equivalences, err := ParseInstanceTypeEquivalences([]string{"t3.micro=t3a.micro"})
+	assert.NoError(t, err)
+
+	result, err := DetectDriftWithOptions(&models.InstanceDetails{InstanceType: "t3a.micro"}, &models.InstanceDetails{InstanceType: "t3.micro"},
+		DetectOptions{Attributes: []string{"instance_type"}, EquivalentTypes: equivalences})
+	assert.NoError(t, err)
+	assert.False(t, result.HasDrift)
+
+	result, err = DetectDriftWithOptions(&models.InstanceDetails{InstanceType: "t3.small"}, &models.InstanceDetails{InstanceType: "t3.micro"},
+		DetectOptions{Attributes: []string{"instance_type"}, EquivalentTypes: equivalences})
+	assert.NoError(t, err)
+	assert.True(t, result.HasDrift)
+}
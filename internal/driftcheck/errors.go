@@ -70,3 +70,28 @@ func IsErrorCategory(err error, category string) bool {
 
 	return false
 }
+
+// SplitErrorCategory separates the DriftErrors of a category from an error returned by DetectDrift,
+// which may join several errors. It returns the matching errors and the joined remainder, if any.
+func SplitErrorCategory(err error, category string) ([]*DriftError, error) {
+	if err == nil {
+		return nil, nil
+	}
+
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+
+	var matching []*DriftError
+	var rest []error
+	for _, e := range errs {
+		var driftErr *DriftError
+		if errors.As(e, &driftErr) && driftErr.Category == category {
+			matching = append(matching, driftErr)
+			continue
+		}
+		rest = append(rest, e)
+	}
+	return matching, errors.Join(rest...)
+}
@@ -0,0 +1,50 @@
+package driftcheck
+
+import (
+	"slices"
+
+	"driftdetector/internal/models"
+)
+
+// managedChecks report whether the Terraform configuration sets an attribute, i.e. whether it is under
+// Terraform management. Attributes without a check, such as registered ones, are considered managed.
+var managedChecks = map[string]func(tf *models.InstanceDetails) bool{
+	"instance_type":                  func(tf *models.InstanceDetails) bool { return tf.InstanceType != "" },
+	"tags":                           func(tf *models.InstanceDetails) bool { return len(tf.Tags) > 0 },
+	"name":                           func(tf *models.InstanceDetails) bool { return tf.Tags[nameTag] != "" },
+	"ami":                            func(tf *models.InstanceDetails) bool { return tf.AMI != "" },
+	"security_groups":                func(tf *models.InstanceDetails) bool { return len(tf.SecurityGroups) > 0 },
+	"subnet_id":                      func(tf *models.InstanceDetails) bool { return tf.SubnetID != "" },
+	"vpc_id":                         func(tf *models.InstanceDetails) bool { return tf.VPCID != "" },
+	"key_name":                       func(tf *models.InstanceDetails) bool { return tf.KeyName != "" },
+	"availability_zone":              func(tf *models.InstanceDetails) bool { return tf.AvailabilityZone != "" },
+	"placement_group":                func(tf *models.InstanceDetails) bool { return tf.PlacementGroup != "" },
+	"instance_lifecycle":             func(tf *models.InstanceDetails) bool { return tf.InstanceLifecycle != "" },
+	"capacity_reservation_id":        func(tf *models.InstanceDetails) bool { return tf.CapacityReservationID != "" },
+	"block_devices":                  func(tf *models.InstanceDetails) bool { return tf.BlockDevices != nil },
+	"volume_tags":                    func(tf *models.InstanceDetails) bool { return tf.VolumeTags != nil },
+	RootDeleteOnTerminationAttribute: func(tf *models.InstanceDetails) bool { return tf.RootDeleteOnTermination != nil },
+	"tenancy":                        func(tf *models.InstanceDetails) bool { return tf.Tenancy != "" },
+	"metadata_options":               func(tf *models.InstanceDetails) bool { return tf.MetadataOptions != nil },
+	"disable_api_termination":        func(tf *models.InstanceDetails) bool { return tf.DisableApiTermination != nil },
+	ShutdownBehaviorAttribute:        func(tf *models.InstanceDetails) bool { return tf.InstanceInitiatedShutdownBehavior != "" },
+	"cpu_credits":                    func(tf *models.InstanceDetails) bool { return tf.CPUCredits != "" },
+	"private_ip":                     func(tf *models.InstanceDetails) bool { return tf.PrivateIP != "" },
+	"public_ip":                      func(tf *models.InstanceDetails) bool { return tf.PublicIP != "" },
+	"hibernation":                    func(tf *models.InstanceDetails) bool { return tf.HibernationEnabled },
+	"user_data":                      func(tf *models.InstanceDetails) bool { return tf.UserData != nil },
+}
+
+// attributeManaged returns true if the Terraform configuration sets the normalized attribute
+func attributeManaged(tf *models.InstanceDetails, attr string) bool {
+	isSet, ok := managedChecks[attr]
+	return !ok || isSet(tf)
+}
+
+// managedAttributes returns the attributes that the Terraform configuration sets, out of attributes.
+// Unsupported attributes are kept, so they are still reported as such.
+func managedAttributes(tf *models.InstanceDetails, attributes []string) []string {
+	return slices.DeleteFunc(slices.Clone(attributes), func(attr string) bool {
+		return !attributeManaged(tf, normalizeAttributeName(attr))
+	})
+}
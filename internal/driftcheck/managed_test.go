@@ -0,0 +1,54 @@
+package driftcheck
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"driftdetector/internal/models"
+)
+
+func TestManagedChecksCoverBuiltinAttributes(t *testing.T) {
+	for attr := range builtinAttributeComparators(nil, TagsModeExact, false) {
+		assert.Contains(t, managedChecks, attr, "Attributes the configuration leaves unset would be compared with OnlyManaged")
+	}
+}
+
+func TestDetectDrift_OnlyManaged(t *testing.T) {
+	awsInstance := &models.InstanceDetails{
+		InstanceType: "t3.large",
+		AMI:          "ami-aws",
+		SubnetID:     "subnet-aws",
+		Tenancy:      "dedicated",
+		Tags:         map[string]string{"Name": "web"},
+	}
+	tfInstance := &models.InstanceDetails{InstanceType: "t3.micro", Tags: map[string]string{"Name": "web"}}
+
+	// Only the instance type and tags are declared, so the AMI, subnet and tenancy set by AWS are not drift
+	result, err := DetectDriftWithOptions(awsInstance, tfInstance, DetectOptions{OnlyManaged: true})
+	assert.NoError(t, err)
+	assert.Len(t, result.Drifts, 1)
+	assert.Contains(t, result.Drifts, "instance_type")
+	assert.Contains(t, result.Matches, "tags")
+	assert.NotContains(t, result.Matches, "name", "name is only compared when requested")
+
+	// Requested attributes are narrowed to the declared ones
+	result, err = DetectDriftWithOptions(awsInstance, tfInstance, DetectOptions{
+		Attributes:  []string{"ami", "tags"},
+		OnlyManaged: true,
+	})
+	assert.NoError(t, err)
+	assert.False(t, result.HasDrift)
+	assert.Len(t, result.Matches, 1)
+	assert.Contains(t, result.Matches, "tags")
+
+	// Unsupported attributes are still reported
+	_, err = DetectDriftWithOptions(awsInstance, tfInstance, DetectOptions{Attributes: []string{"bogus"}, OnlyManaged: true})
+	assert.True(t, IsErrorCategory(err, ErrResourceMissing))
+
+	// Nothing is compared against a configuration that declares nothing
+	result, err = DetectDriftWithOptions(awsInstance, &models.InstanceDetails{}, DetectOptions{OnlyManaged: true})
+	assert.NoError(t, err)
+	assert.False(t, result.HasDrift)
+	assert.Empty(t, result.Matches)
+}
@@ -1,6 +1,8 @@
 package driftcheck
 
 import (
+	"sort"
+
 	"driftdetector/internal/models"
 )
 
@@ -8,19 +10,45 @@ import (
 type DriftResult struct {
 	HasDrift  bool                          // True if any drift is detected
 	Drifts    map[string]models.DriftDetail // Map of attribute names to drift details
+	Matches   map[string]models.DriftDetail // Map of the attributes compared without drift to their values
+	Unmanaged map[string]models.DriftDetail // Attributes the configuration leaves unset while AWS has a value, with SkipUnset
 	AwsConfig *models.InstanceDetails       // The AWS configuration used for comparison
 	TfConfig  *models.InstanceDetails       // The Terraform configuration used for comparison
 }
 
 // ConvertToDrifts converts a DriftResult to a slice of Drift for backward compatibility.
+// The drifts are sorted by attribute so reports are identical between runs.
 func ConvertToDrifts(result *DriftResult) []models.DriftDetail {
-	drifts := make([]models.DriftDetail, 0, len(result.Drifts))
-	for _, detail := range result.Drifts {
+	return sortedDetails(result.Drifts)
+}
+
+// ConvertToMatches returns the attributes compared without drift, sorted by attribute, as evidence of what
+// was verified. The AWS and Terraform values of each are equal, or equivalent for the comparator.
+func ConvertToMatches(result *DriftResult) []models.DriftDetail {
+	return sortedDetails(result.Matches)
+}
+
+// ConvertToUnmanaged returns the attributes the configuration leaves unset while AWS has a value, sorted by
+// attribute. They are only classified with DetectOptions.SkipUnset and are informational, not drift.
+func ConvertToUnmanaged(result *DriftResult) []models.DriftDetail {
+	return sortedDetails(result.Unmanaged)
+}
+
+// sortedDetails copies the details of a map into a slice sorted by attribute
+func sortedDetails(details map[string]models.DriftDetail) []models.DriftDetail {
+	drifts := make([]models.DriftDetail, 0, len(details))
+	for _, detail := range details {
 		drifts = append(drifts, models.DriftDetail{
 			Attribute:      detail.Attribute,
 			AWSValue:       detail.AWSValue,
 			TerraformValue: detail.TerraformValue,
+			Change:         detail.Change,
+			SetChanges:     detail.SetChanges,
+			Source:         detail.Source,
 		})
 	}
+	sort.Slice(drifts, func(i, j int) bool {
+		return drifts[i].Attribute < drifts[j].Attribute
+	})
 	return drifts
 }
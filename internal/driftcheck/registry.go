@@ -0,0 +1,71 @@
+package driftcheck
+
+import (
+	"maps"
+	"strings"
+	"sync"
+)
+
+// registry holds the comparators and attribute aliases registered by importers of this package,
+// so fields added to InstanceDetails can be compared without forking the built-in comparators.
+var registry = struct {
+	sync.RWMutex
+	comparators map[string]AttributeComparator
+	aliases     map[string]string
+}{
+	comparators: make(map[string]AttributeComparator),
+	aliases:     make(map[string]string),
+}
+
+// RegisterComparator registers a comparator for the named attribute, which DetectDrift then checks
+// like the built-in attributes. The name should be in the lower snake_case form used by --attributes.
+// Registering a built-in attribute name replaces the built-in comparator.
+func RegisterComparator(name string, fn AttributeComparator) error {
+	if name == "" {
+		return NewDriftError(ErrInvalidInput, "Attribute name cannot be empty", "", nil)
+	}
+	if fn == nil {
+		return NewDriftError(ErrInvalidInput, "Comparator cannot be nil", name, nil)
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+	registry.comparators[name] = fn
+	return nil
+}
+
+// RegisterAttributeAlias registers an alternative name that normalizes to the given attribute,
+// e.g. "kp" for "key_name". Aliases are matched case-insensitively, with "-" and " " treated as "_".
+func RegisterAttributeAlias(alias, name string) error {
+	if alias == "" || name == "" {
+		return NewDriftError(ErrInvalidInput, "Alias and attribute name cannot be empty", name, nil)
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+	registry.aliases[normalizeSeparators(alias)] = name
+	return nil
+}
+
+// registeredComparators returns a copy of the registered comparators
+func registeredComparators() map[string]AttributeComparator {
+	registry.RLock()
+	defer registry.RUnlock()
+	return maps.Clone(registry.comparators)
+}
+
+// registeredAlias returns the attribute a normalized alias was registered for
+func registeredAlias(normalized string) (string, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	name, ok := registry.aliases[normalized]
+	return name, ok
+}
+
+// normalizeSeparators lowercases an attribute name and replaces common separators with underscores
+func normalizeSeparators(attr string) string {
+	normalized := strings.ToLower(attr)
+	normalized = strings.ReplaceAll(normalized, "-", "_")
+	normalized = strings.ReplaceAll(normalized, " ", "_")
+	return normalized
+}
@@ -0,0 +1,79 @@
+package driftcheck
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"driftdetector/internal/models"
+)
+
+// registerForTest registers a comparator and alias and removes them when the test finishes
+func registerForTest(t *testing.T, name, alias string, fn AttributeComparator) {
+	t.Helper()
+	require.NoError(t, RegisterComparator(name, fn))
+	require.NoError(t, RegisterAttributeAlias(alias, name))
+	t.Cleanup(func() {
+		registry.Lock()
+		defer registry.Unlock()
+		delete(registry.comparators, name)
+		delete(registry.aliases, normalizeSeparators(alias))
+	})
+}
+
+func TestRegisterComparator_CustomAttribute(t *testing.T) {
+	registerForTest(t, "region", "Instance-Region", func(aws, tf *models.InstanceDetails) (bool, any, any) {
+		return aws.Region != tf.Region, aws.Region, tf.Region
+	})
+
+	awsInstance := &models.InstanceDetails{InstanceID: "i-12345", Region: "us-east-1"}
+	tfInstance := &models.InstanceDetails{Region: "eu-west-1"}
+
+	// The alias resolves to the registered attribute
+	result, err := DetectDrift(awsInstance, tfInstance, []string{"Instance-Region"})
+	require.NoError(t, err)
+	assert.True(t, result.HasDrift)
+	require.Len(t, result.Drifts, 1)
+	drift, exists := result.Drifts["region"]
+	require.True(t, exists)
+	assert.Equal(t, "us-east-1", drift.AWSValue)
+	assert.Equal(t, "eu-west-1", drift.TerraformValue)
+
+	// Registered comparators are also part of a full check
+	result, err = DetectDrift(awsInstance, tfInstance, nil)
+	require.NoError(t, err)
+	assert.Contains(t, result.Drifts, "region")
+}
+
+func TestRegisterComparator_InvalidInput(t *testing.T) {
+	err := RegisterComparator("", func(aws, tf *models.InstanceDetails) (bool, any, any) { return false, nil, nil })
+	assert.True(t, IsErrorCategory(err, ErrInvalidInput))
+
+	err = RegisterComparator("region", nil)
+	assert.True(t, IsErrorCategory(err, ErrInvalidInput))
+
+	err = RegisterAttributeAlias("", "region")
+	assert.True(t, IsErrorCategory(err, ErrInvalidInput))
+}
+
+func TestDetectDrift_PanickingComparator(t *testing.T) {
+	registerForTest(t, "exploding", "boom", func(aws, tf *models.InstanceDetails) (bool, any, any) {
+		var tags map[string]string
+		tags["oops"] = "assignment to nil map"
+		return false, nil, nil
+	})
+
+	awsInstance := &models.InstanceDetails{InstanceID: "i-12345", InstanceType: "t2.medium"}
+	tfInstance := &models.InstanceDetails{InstanceType: "t2.micro"}
+
+	result, err := DetectDrift(awsInstance, tfInstance, []string{"boom", "instance_type"})
+	require.Error(t, err)
+	assert.True(t, IsErrorCategory(err, ErrComparisonFailed))
+	assert.Contains(t, err.Error(), "exploding")
+
+	// The remaining attributes are still compared
+	require.NotNil(t, result)
+	assert.True(t, result.HasDrift)
+	assert.Contains(t, result.Drifts, "instance_type")
+}
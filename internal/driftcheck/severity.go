@@ -0,0 +1,38 @@
+package driftcheck
+
+import "strings"
+
+// RootDeleteOnTerminationAttribute is the attribute comparing whether the root volume is deleted on termination
+const RootDeleteOnTerminationAttribute = "root_delete_on_termination"
+
+// ShutdownBehaviorAttribute is the attribute comparing whether the instance stops or terminates when shut down from within
+const ShutdownBehaviorAttribute = "instance_initiated_shutdown_behavior"
+
+// Severity ranks how serious the drift of an attribute is
+type Severity string
+
+const (
+	// SeverityMedium is the severity of attributes without one of their own
+	SeverityMedium Severity = "medium"
+	// SeverityHigh is the severity of drift with a direct cost or security impact
+	SeverityHigh Severity = "high"
+)
+
+// attributeSeverities holds the attributes whose severity is not SeverityMedium
+var attributeSeverities = map[string]Severity{
+	RootDeleteOnTerminationAttribute: SeverityHigh,
+	ShutdownBehaviorAttribute:        SeverityHigh,
+	// An instance recovered as spot or off its capacity reservation changes the bill
+	"instance_lifecycle":      SeverityHigh,
+	"capacity_reservation_id": SeverityHigh,
+}
+
+// AttributeSeverity returns the default severity of the drift of an attribute. Per-key drifts such as
+// tags.Environment have the severity of their attribute.
+func AttributeSeverity(attribute string) Severity {
+	name, _, _ := strings.Cut(attribute, ".")
+	if severity, ok := attributeSeverities[name]; ok {
+		return severity
+	}
+	return SeverityMedium
+}
@@ -0,0 +1,16 @@
+package driftcheck
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttributeSeverity(t *testing.T) {
+	assert.Equal(t, SeverityHigh, AttributeSeverity(RootDeleteOnTerminationAttribute))
+	assert.Equal(t, SeverityHigh, AttributeSeverity(ShutdownBehaviorAttribute))
+	assert.Equal(t, SeverityHigh, AttributeSeverity("instance_lifecycle"))
+	assert.Equal(t, SeverityHigh, AttributeSeverity("capacity_reservation_id"))
+	assert.Equal(t, SeverityMedium, AttributeSeverity("instance_type"))
+	assert.Equal(t, SeverityMedium, AttributeSeverity("tags.Environment"), "Per-key drifts have the severity of their attribute")
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// ImageDetails holds the details of an AMI that are relevant to policy checks.
+type ImageDetails struct {
+	ImageID         string    `json:"image_id"`
+	Name            string    `json:"name,omitempty"`
+	DeprecationTime time.Time `json:"deprecation_time,omitempty"` // Zero if the AMI has no deprecation time set
+}
+
+// IsDeprecated reports whether the AMI's deprecation time is set and lies before now.
+func (i *ImageDetails) IsDeprecated(now time.Time) bool {
+	return !i.DeprecationTime.IsZero() && i.DeprecationTime.Before(now)
+}
@@ -1,18 +1,152 @@
 package models
 
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
 // InstanceDetails holds configuration details for an EC2 instance from a source (AWS or Terraform).
 type InstanceDetails struct {
-	InstanceID     string            `json:"instance_id,omitempty"`
-	InstanceType   string            `json:"instance_type,omitempty"`
-	AMI            string            `json:"ami,omitempty"`
-	Tags           map[string]string `json:"tags,omitempty"`
-	SecurityGroups []string          `json:"security_groups,omitempty"`
-	SubnetID       string            `json:"subnet_id,omitempty"`
+	InstanceID         string            `json:"instance_id,omitempty"`
+	InstanceType       string            `json:"instance_type,omitempty"`
+	AMI                string            `json:"ami,omitempty"`
+	Tags               map[string]string `json:"tags,omitempty"`
+	SecurityGroups     []string          `json:"security_groups,omitempty"`
+	SecurityGroupNames []string          `json:"security_group_names,omitempty"` // Names matching SecurityGroups, only reported by AWS
+	SubnetID           string            `json:"subnet_id,omitempty"`
+	VPCID              string            `json:"vpc_id,omitempty"`
+	AvailabilityZone   string            `json:"availability_zone,omitempty"`
+	PlacementGroup     string            `json:"placement_group,omitempty"`
+	KeyName            string            `json:"key_name,omitempty"`
+	Tenancy            string            `json:"tenancy,omitempty"` // default, dedicated or host
+	MetadataOptions    *MetadataOptions  `json:"metadata_options,omitempty"`
+	// InstanceLifecycle is the purchasing option of the instance: spot, scheduled or capacity-block, empty for on-demand
+	InstanceLifecycle string `json:"instance_lifecycle,omitempty"`
+	// CapacityReservationID is the capacity reservation the instance explicitly targets, if any
+	CapacityReservationID string `json:"capacity_reservation_id,omitempty"`
+	// DisableApiTermination is whether termination protection is enabled. AWS only reports it when it was
+	// fetched, as it costs an extra API call per instance.
+	DisableApiTermination *bool `json:"disable_api_termination,omitempty"`
+	// HibernationEnabled is whether the instance was launched with hibernation enabled, which can only be set at launch
+	HibernationEnabled bool `json:"hibernation_enabled,omitempty"`
+	// UserData is the decoded user data script. Like termination protection, AWS only reports it when it was fetched.
+	UserData *string `json:"user_data,omitempty"`
+	// InstanceInitiatedShutdownBehavior is stop or terminate. Like termination protection, AWS only reports it when it
+	// was fetched, and Terraform leaves it empty for the default of stop.
+	InstanceInitiatedShutdownBehavior string `json:"instance_initiated_shutdown_behavior,omitempty"`
+	// CPUCredits is the CPU credit option of burstable performance instances, standard or unlimited. AWS only
+	// reports it when it was fetched, and Terraform leaves it empty without a credit_specification block.
+	CPUCredits string `json:"cpu_credits,omitempty"`
+	// PrivateIP and PublicIP are the primary IPv4 addresses of the instance. They are typically assigned by AWS,
+	// so they are only compared when the configuration sets them, and a stopped instance has no public IP.
+	PrivateIP string `json:"private_ip,omitempty"`
+	PublicIP  string `json:"public_ip,omitempty"`
+	// VolumeTags are the tags every volume of the instance should have, only set for Terraform configurations
+	// that declare volume_tags. AWS reports the tags of each volume in BlockDevices instead.
+	VolumeTags map[string]string `json:"volume_tags,omitempty"`
+	// BlockDevices are the EBS volumes attached besides the root volume. Terraform only sets them when the
+	// configuration declares ebs_block_device blocks.
+	BlockDevices []BlockDevice `json:"block_devices,omitempty"`
+	// RootVolume is the root EBS volume, only reported by AWS. Its tags are compared with VolumeTags, which
+	// Terraform applies to the root volume too.
+	RootVolume *BlockDevice `json:"root_volume,omitempty"`
+	// RootDeleteOnTermination is whether the root EBS volume is deleted when the instance terminates, nil when
+	// unknown, e.g. for an instance store root or when Terraform leaves it to its default of true
+	RootDeleteOnTermination *bool  `json:"root_delete_on_termination,omitempty"`
+	Region                  string `json:"region,omitempty"` // Region the instance was fetched from, empty for the default region
+	State                   string `json:"state,omitempty"`  // Lifecycle state (e.g. running, stopped), only reported by AWS
+	// LaunchTime is when the instance was last launched, only reported by AWS
+	LaunchTime *time.Time `json:"launch_time,omitempty"`
+
+	// SourceLocations maps attribute names to where they are defined, only set for Terraform configurations
+	SourceLocations map[string]SourceLocation `json:"-"`
 }
 
+// IsEmpty reports whether no attribute is set, ignoring their source locations, e.g. for a resource whose
+// arguments could not be resolved.
+func (d InstanceDetails) IsEmpty() bool {
+	d.SourceLocations = nil
+	// Empty collections, e.g. tags = {}, set nothing either
+	if len(d.Tags) == 0 {
+		d.Tags = nil
+	}
+	if len(d.SecurityGroups) == 0 {
+		d.SecurityGroups = nil
+	}
+	if len(d.SecurityGroupNames) == 0 {
+		d.SecurityGroupNames = nil
+	}
+	if len(d.VolumeTags) == 0 {
+		d.VolumeTags = nil
+	}
+	if len(d.BlockDevices) == 0 {
+		d.BlockDevices = nil
+	}
+	return reflect.DeepEqual(d, InstanceDetails{})
+}
+
+// Volumes returns the EBS volumes of the instance: the root volume, if known, followed by BlockDevices.
+func (d *InstanceDetails) Volumes() []*BlockDevice {
+	volumes := make([]*BlockDevice, 0, len(d.BlockDevices)+1)
+	if d.RootVolume != nil {
+		volumes = append(volumes, d.RootVolume)
+	}
+	for i := range d.BlockDevices {
+		volumes = append(volumes, &d.BlockDevices[i])
+	}
+	return volumes
+}
+
+// MetadataOptions holds the instance metadata service (IMDS) settings of an instance.
+type MetadataOptions struct {
+	HttpTokens              string `json:"http_tokens,omitempty"`   // optional, or required to enforce IMDSv2
+	HttpEndpoint            string `json:"http_endpoint,omitempty"` // enabled or disabled
+	HttpPutResponseHopLimit int    `json:"http_put_response_hop_limit,omitempty"`
+}
+
+// BlockDevice is an EBS volume attached to an instance. Size and type are zero when unknown, e.g. when
+// Terraform leaves them to their defaults or AWS volume details were not fetched.
+type BlockDevice struct {
+	DeviceName string `json:"device_name"`
+	VolumeID   string `json:"volume_id,omitempty"`   // Only reported by AWS
+	VolumeSize int    `json:"volume_size,omitempty"` // In GiB
+	VolumeType string `json:"volume_type,omitempty"` // e.g. gp3, io2
+	// VolumeTags are the tags of the volume, only reported by AWS when they were fetched, non-nil then
+	VolumeTags map[string]string `json:"volume_tags,omitempty"`
+}
+
+// SourceLocation identifies where an attribute is defined in a configuration file.
+type SourceLocation struct {
+	Filename string `json:"filename"`
+	Line     int    `json:"line"`
+}
+
+// String formats the location as filename:line
+func (l SourceLocation) String() string {
+	return fmt.Sprintf("%s:%d", l.Filename, l.Line)
+}
+
+// Change types describe how a single key of a keyed attribute (e.g. tags) differs.
+const (
+	ChangeAdded   = "added"   // Key exists in AWS but not in Terraform
+	ChangeRemoved = "removed" // Key exists in Terraform but not in AWS
+	ChangeChanged = "changed" // Key exists in both with different values
+)
+
 // DriftDetail represents the difference found for a specific attribute.
 type DriftDetail struct {
 	Attribute      string
 	AWSValue       any
 	TerraformValue any
+	Change         string          `json:",omitempty"` // Set for per-key drifts, e.g. tags.Environment
+	SetChanges     *SetChanges     `json:",omitempty"` // Set for drifts of unordered lists, e.g. security_groups
+	Source         *SourceLocation `json:",omitempty"` // Where the attribute is defined in the Terraform configuration, if known
+}
+
+// SetChanges holds the entries of an unordered list attribute that differ between AWS and Terraform,
+// so that large lists do not have to be compared by eye.
+type SetChanges struct {
+	Added   []string `json:"added,omitempty"`   // Entries in AWS but not in Terraform
+	Removed []string `json:"removed,omitempty"` // Entries in Terraform but not in AWS
 }
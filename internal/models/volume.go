@@ -0,0 +1,10 @@
+package models
+
+// VolumeDetails holds the details of an EBS volume that are not reported with the instance it is attached to.
+type VolumeDetails struct {
+	VolumeID   string `json:"volume_id"`
+	VolumeSize int    `json:"volume_size,omitempty"` // In GiB
+	VolumeType string `json:"volume_type,omitempty"`
+	// Tags are the tags of the volume, empty rather than nil for a volume without tags
+	Tags map[string]string `json:"tags,omitempty"`
+}
@@ -0,0 +1,175 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+	"gopkg.in/yaml.v3"
+
+	"driftdetector/internal/providers/aws"
+)
+
+// accountSeparator separates the account from the region of the instances of an account of Accounts,
+// which are qualified with both, e.g. 123456789012:us-east-1/i-123.
+const accountSeparator = ":"
+
+// accountIDPattern matches AWS account IDs
+var accountIDPattern = regexp.MustCompile(`^[0-9]{12}$`)
+
+// Account is an AWS account whose instances are all scanned, in each of its regions, with the credentials of
+// a role assumed in the account.
+type Account struct {
+	AccountID string   `yaml:"account_id"`
+	RoleARN   string   `yaml:"role_arn"`
+	Regions   []string `yaml:"regions"`
+}
+
+// accountsFile is the format of the accounts file, e.g.:
+//
+//	accounts:
+//	  - account_id: "123456789012"
+//	    role_arn: arn:aws:iam::123456789012:role/DriftDetector
+//	    regions: [us-east-1, eu-west-1]
+type accountsFile struct {
+	Accounts []Account `yaml:"accounts"`
+}
+
+// LoadAccounts reads the accounts to scan from a YAML accounts file
+func LoadAccounts(path string) ([]Account, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read accounts file %s: %w", path, err)
+	}
+
+	var file accountsFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode accounts file %s: %w", path, err)
+	}
+	if len(file.Accounts) == 0 {
+		return nil, fmt.Errorf("accounts file %s lists no accounts", path)
+	}
+	return file.Accounts, nil
+}
+
+// validateAccounts checks that each account has a valid ID, a role to assume and regions, and is listed once
+func validateAccounts(accounts []Account) error {
+	seen := make(map[string]bool, len(accounts))
+	for _, account := range accounts {
+		if !accountIDPattern.MatchString(account.AccountID) {
+			return fmt.Errorf("invalid account ID %q: expected 12 digits", account.AccountID)
+		}
+		if seen[account.AccountID] {
+			return fmt.Errorf("account %s is listed more than once", account.AccountID)
+		}
+		seen[account.AccountID] = true
+		if account.RoleARN == "" {
+			return fmt.Errorf("a role to assume is required for account %s", account.AccountID)
+		}
+		if len(account.Regions) == 0 {
+			return fmt.Errorf("at least one region is required for account %s", account.AccountID)
+		}
+		for _, region := range account.Regions {
+			if region == "" || strings.Contains(region, regionSeparator) {
+				return fmt.Errorf("invalid region %q of account %s", region, account.AccountID)
+			}
+		}
+	}
+	return nil
+}
+
+// accountScope is the key of the AWS service of a region of an account, which qualifies the IDs of its
+// instances like a region does
+func accountScope(accountID, region string) string {
+	return accountID + accountSeparator + region
+}
+
+// isAccountScope reports whether the region of a qualified instance ID is the scope of an account
+func isAccountScope(region string) bool {
+	return strings.Contains(region, accountSeparator)
+}
+
+// accountScopes returns the scope of every region of every account, in the order they are listed
+func accountScopes(accounts []Account) []string {
+	var scopes []string
+	for _, account := range accounts {
+		for _, region := range account.Regions {
+			scopes = append(scopes, accountScope(account.AccountID, region))
+		}
+	}
+	return scopes
+}
+
+// newAccountServices creates the AWS service of each region of each account, assuming the role of the
+// account. The services share the options, including the limiter, so ConcurrencyLimit bounds the API calls
+// in flight across all accounts.
+func newAccountServices(accounts []Account, opts []aws.InstanceServiceOption) (map[string]aws.InstanceServiceAPI, error) {
+	services := make(map[string]aws.InstanceServiceAPI)
+	for _, account := range accounts {
+		for _, region := range account.Regions {
+			service, err := aws.NewInstanceServiceForAccount(context.Background(), region, account.RoleARN, opts...)
+			if err != nil {
+				return nil, fmt.Errorf("failed to initialize AWS service for account %s in region %s: %w", account.AccountID, region, err)
+			}
+			services[accountScope(account.AccountID, region)] = service
+		}
+	}
+	return services, nil
+}
+
+// listAccountInstanceIDs returns the IDs of every instance of every region of the accounts, qualified with
+// their scope. The regions of all accounts are listed concurrently, within the ConcurrencyLimit of the
+// AWS services; results keep the order of the accounts file so runs are deterministic. A region that cannot
+// be listed, e.g. because the role cannot be assumed, does not stop the scan of the others: it is returned
+// as a result carrying the error, keyed by its scope.
+func (s *Service) listAccountInstanceIDs(ctx context.Context) ([]string, []DriftDetectionResult, error) {
+	scopes := accountScopes(s.config.Accounts)
+	scopeIDs := make([][]string, len(scopes))
+	scopeErrs := make([]error, len(scopes))
+
+	var g errgroup.Group
+	for i, scope := range scopes {
+		awsSrv, err := s.serviceForRegion(scope)
+		if err != nil {
+			return nil, nil, err
+		}
+		lister, ok := awsSrv.(aws.InstanceLister)
+		if !ok {
+			return nil, nil, fmt.Errorf("the AWS service of %s does not support listing instances", accountScopeName(scope))
+		}
+
+		g.Go(func() error {
+			instanceIDs, err := lister.ListInstanceIDs(ctx)
+			if err != nil {
+				s.logger.Warn("Could not list the instances of %s, continuing with the other accounts: %v", accountScopeName(scope), err)
+				scopeErrs[i] = fmt.Errorf("error listing the instances of %s: %w", accountScopeName(scope), err)
+				return nil
+			}
+			s.logger.Info("Found %d instances in %s", len(instanceIDs), accountScopeName(scope))
+			for _, id := range instanceIDs {
+				scopeIDs[i] = append(scopeIDs[i], qualifyInstanceID(scope, id))
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	var instanceIDs []string
+	var failed []DriftDetectionResult
+	for i, ids := range scopeIDs {
+		instanceIDs = append(instanceIDs, ids...)
+		if scopeErrs[i] != nil {
+			failed = append(failed, DriftDetectionResult{InstanceID: scopes[i], Error: scopeErrs[i]})
+		}
+	}
+	return instanceIDs, failed, nil
+}
+
+// accountScopeName describes the scope of an account for messages, e.g. account 123456789012 in us-east-1
+func accountScopeName(scope string) string {
+	accountID, region, _ := strings.Cut(scope, accountSeparator)
+	return fmt.Sprintf("account %s in %s", accountID, region)
+}
@@ -0,0 +1,163 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"driftdetector/internal/models"
+	awsMocks "driftdetector/internal/providers/aws/mocks"
+)
+
+// listingInstanceService is an instance service that also lists the instances of its account and region
+type listingInstanceService struct {
+	*awsMocks.InstanceServiceAPI
+	instanceIDs []string
+	err         error
+}
+
+func (s *listingInstanceService) ListInstanceIDs(context.Context) ([]string, error) {
+	return s.instanceIDs, s.err
+}
+
+func TestLoadAccounts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "accounts.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`accounts:
+  - account_id: "123456789012"
+    role_arn: arn:aws:iam::123456789012:role/DriftDetector
+    regions: [us-east-1, eu-west-1]
+`), 0o600))
+
+	accounts, err := LoadAccounts(path)
+	require.NoError(t, err)
+	assert.Equal(t, []Account{{
+		AccountID: "123456789012",
+		RoleARN:   "arn:aws:iam::123456789012:role/DriftDetector",
+		Regions:   []string{"us-east-1", "eu-west-1"},
+	}}, accounts)
+
+	_, err = LoadAccounts(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read accounts file")
+
+	require.NoError(t, os.WriteFile(path, []byte("accounts: {"), 0o600))
+	_, err = LoadAccounts(path)
+	assert.ErrorContains(t, err, "failed to decode accounts file")
+
+	require.NoError(t, os.WriteFile(path, []byte("accounts: []"), 0o600))
+	_, err = LoadAccounts(path)
+	assert.ErrorContains(t, err, "lists no accounts")
+}
+
+func TestValidateAccounts(t *testing.T) {
+	valid := Account{AccountID: "123456789012", RoleARN: "arn:aws:iam::123456789012:role/Drift", Regions: []string{"us-east-1"}}
+	assert.NoError(t, validateAccounts(nil))
+	assert.NoError(t, validateAccounts([]Account{valid}))
+
+	tests := []struct {
+		name     string
+		accounts []Account
+		wantErr  string
+	}{
+		{"Malformed account ID", []Account{{AccountID: "1234", RoleARN: valid.RoleARN, Regions: valid.Regions}}, "expected 12 digits"},
+		{"Duplicate account", []Account{valid, valid}, "listed more than once"},
+		{"Missing role", []Account{{AccountID: valid.AccountID, Regions: valid.Regions}}, "a role to assume is required"},
+		{"Missing regions", []Account{{AccountID: valid.AccountID, RoleARN: valid.RoleARN}}, "at least one region is required"},
+		{"Invalid region", []Account{{AccountID: valid.AccountID, RoleARN: valid.RoleARN, Regions: []string{"us/east"}}}, "invalid region"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, validateAccounts(tt.accounts), tt.wantErr)
+		})
+	}
+}
+
+// TestRun_Accounts tests that every instance of every region of the accounts is checked through the service of
+// its account and region, next to the listed instances
+func TestRun_Accounts(t *testing.T) {
+	config := Config{
+		InstanceIDs: []string{"i-000000a1"},
+		ConfigPath:  "test.tf",
+		Accounts: []Account{
+			{AccountID: "111111111111", RoleARN: "arn:aws:iam::111111111111:role/Drift", Regions: []string{"us-east-1", "eu-west-1"}},
+			{AccountID: "222222222222", RoleARN: "arn:aws:iam::222222222222:role/Drift", Regions: []string{"us-east-1"}},
+		},
+	}
+	service, defaultMock, parserMock, reportMock := setupServiceWithMocks(t, config)
+	scopes := map[string]*listingInstanceService{
+		"111111111111:us-east-1": {InstanceServiceAPI: awsMocks.NewInstanceServiceAPI(t), instanceIDs: []string{"i-000000b1", "i-000000b2"}},
+		"111111111111:eu-west-1": {InstanceServiceAPI: awsMocks.NewInstanceServiceAPI(t)},
+		"222222222222:us-east-1": {InstanceServiceAPI: awsMocks.NewInstanceServiceAPI(t), instanceIDs: []string{"i-000000c1"}},
+	}
+	for scope, awsSrv := range scopes {
+		service.SetRegionalService(scope, awsSrv)
+	}
+
+	parserMock.On("GetDesiredState", mock.Anything, config.ConfigPath).Return(&models.InstanceDetails{InstanceType: "t2.micro"}, nil)
+	defaultMock.On("GetInstancesDetails", mock.Anything, []string{"i-000000a1"}).
+		Return([]*models.InstanceDetails{{InstanceID: "i-000000a1", InstanceType: "t2.micro"}}, nil).Once()
+	scopes["111111111111:us-east-1"].On("GetInstancesDetails", mock.Anything, []string{"i-000000b1", "i-000000b2"}).
+		Return([]*models.InstanceDetails{
+			{InstanceID: "i-000000b1", InstanceType: "t2.micro"},
+			{InstanceID: "i-000000b2", InstanceType: "t2.large"},
+		}, nil).Once()
+	scopes["222222222222:us-east-1"].On("GetInstancesDetails", mock.Anything, []string{"i-000000c1"}).
+		Return([]*models.InstanceDetails{{InstanceID: "i-000000c1", InstanceType: "t2.micro"}}, nil).Once()
+	reportMock.On("PrintReport", mock.Anything, mock.Anything, mock.Anything).Return(nil).Times(4)
+
+	anyDrift, anyError, err := service.Run(context.Background())
+
+	require.NoError(t, err)
+	assert.True(t, anyDrift)
+	assert.False(t, anyError)
+	assert.Equal(t, []string{"i-000000a1", "111111111111:us-east-1/i-000000b1", "111111111111:us-east-1/i-000000b2",
+		"222222222222:us-east-1/i-000000c1"}, service.instanceIDs)
+	assert.Equal(t, 4, service.Stats().InstancesChecked)
+}
+
+// TestRun_AccountListingFails tests that an account whose instances cannot be listed is reported as errored,
+// while the instances of the other accounts are still checked
+func TestRun_AccountListingFails(t *testing.T) {
+	config := Config{
+		ConfigPath: "test.tf",
+		Accounts: []Account{
+			{AccountID: "111111111111", RoleARN: "arn:aws:iam::111111111111:role/Drift", Regions: []string{"us-east-1"}},
+			{AccountID: "222222222222", RoleARN: "arn:aws:iam::222222222222:role/Drift", Regions: []string{"us-east-1", "eu-west-1"}},
+		},
+	}
+	service, _, parserMock, reportMock := setupServiceWithMocks(t, config)
+	service.SetRegionalService("111111111111:us-east-1", &listingInstanceService{
+		InstanceServiceAPI: awsMocks.NewInstanceServiceAPI(t),
+		err:                errors.New("AccessDenied: not authorized to perform sts:AssumeRole"),
+	})
+	listed := &listingInstanceService{InstanceServiceAPI: awsMocks.NewInstanceServiceAPI(t), instanceIDs: []string{"i-000000c1"}}
+	service.SetRegionalService("222222222222:us-east-1", listed)
+	service.SetRegionalService("222222222222:eu-west-1", &listingInstanceService{
+		InstanceServiceAPI: awsMocks.NewInstanceServiceAPI(t),
+		err:                errors.New("AccessDenied: not authorized to perform ec2:DescribeInstances"),
+	})
+
+	parserMock.On("GetDesiredState", mock.Anything, config.ConfigPath).Return(&models.InstanceDetails{InstanceType: "t2.micro"}, nil)
+	listed.On("GetInstancesDetails", mock.Anything, []string{"i-000000c1"}).
+		Return([]*models.InstanceDetails{{InstanceID: "i-000000c1", InstanceType: "t2.large"}}, nil).Once()
+	reportMock.On("PrintReport", "i-000000c1", mock.Anything, mock.Anything).Return(nil).Once()
+
+	anyDrift, anyError, err := service.Run(context.Background())
+
+	assert.True(t, anyDrift, "The instances of the listed account are still checked")
+	assert.True(t, anyError)
+	assert.ErrorContains(t, err, "error listing the instances of account 111111111111 in us-east-1")
+	assert.ErrorContains(t, err, "error listing the instances of account 222222222222 in eu-west-1")
+
+	var instanceErr *InstanceError
+	if assert.ErrorAs(t, err, &instanceErr) {
+		assert.Equal(t, "111111111111:us-east-1", instanceErr.InstanceID)
+	}
+	assert.Equal(t, 3, service.Stats().InstancesChecked)
+	assert.Equal(t, 2, service.Stats().InstancesErrored)
+}
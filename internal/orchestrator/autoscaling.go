@@ -0,0 +1,70 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"driftdetector/internal/providers/aws"
+)
+
+// resolveInstanceIDs returns the instance IDs to check: InstanceIDs followed by the current members of
+// AutoScalingGroup and the instances of Accounts that are not already listed. Members are resolved in the
+// region of unqualified instance IDs. The regions of accounts whose instances could not be listed are
+// returned as results carrying the listing error.
+func (s *Service) resolveInstanceIDs(ctx context.Context) ([]string, []DriftDetectionResult, error) {
+	if s.config.AutoScalingGroup == "" && len(s.config.Accounts) == 0 {
+		return s.config.InstanceIDs, nil, nil
+	}
+
+	instanceIDs := slices.Clone(s.config.InstanceIDs)
+	add := func(ids []string) {
+		for _, id := range ids {
+			if !slices.Contains(instanceIDs, id) {
+				instanceIDs = append(instanceIDs, id)
+			}
+		}
+	}
+
+	if s.config.AutoScalingGroup != "" {
+		members, err := s.autoScalingGroupInstanceIDs(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		add(members)
+	}
+	var failed []DriftDetectionResult
+	if len(s.config.Accounts) > 0 {
+		accountIDs, accountFailed, err := s.listAccountInstanceIDs(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		add(accountIDs)
+		failed = accountFailed
+	}
+
+	switch {
+	case len(instanceIDs) > 0 || len(failed) > 0:
+		return instanceIDs, failed, nil
+	case len(s.config.Accounts) == 0:
+		return nil, nil, fmt.Errorf("no instances to check: Auto Scaling group %s has no instances", s.config.AutoScalingGroup)
+	case s.config.AutoScalingGroup == "":
+		return nil, nil, fmt.Errorf("no instances to check: the accounts have no instances")
+	default:
+		return nil, nil, fmt.Errorf("no instances to check: Auto Scaling group %s and the accounts have no instances", s.config.AutoScalingGroup)
+	}
+}
+
+// autoScalingGroupInstanceIDs returns the current members of AutoScalingGroup
+func (s *Service) autoScalingGroupInstanceIDs(ctx context.Context) ([]string, error) {
+	resolver, ok := s.awsSrv.(aws.AutoScalingGroupAPI)
+	if !ok {
+		return nil, fmt.Errorf("the AWS service does not support resolving Auto Scaling group %s", s.config.AutoScalingGroup)
+	}
+	members, err := resolver.GetAutoScalingGroupInstanceIDs(ctx, s.config.AutoScalingGroup)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving the instances of Auto Scaling group %s: %w", s.config.AutoScalingGroup, err)
+	}
+	s.logger.Info("Found %d instances in Auto Scaling group %s", len(members), s.config.AutoScalingGroup)
+	return members, nil
+}
@@ -0,0 +1,80 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"driftdetector/internal/models"
+	awsMocks "driftdetector/internal/providers/aws/mocks"
+)
+
+// asgInstanceService is an instance service that also resolves Auto Scaling groups, from members
+type asgInstanceService struct {
+	*awsMocks.InstanceServiceAPI
+	members map[string][]string
+}
+
+func (s *asgInstanceService) GetAutoScalingGroupInstanceIDs(_ context.Context, groupName string) ([]string, error) {
+	return s.members[groupName], nil
+}
+
+// TestRun_AutoScalingGroup tests that the members of the group are checked next to the listed instances, once each
+func TestRun_AutoScalingGroup(t *testing.T) {
+	config := Config{
+		InstanceIDs:       []string{"i-000000a1"},
+		AutoScalingGroup:  "web-asg",
+		ConfigPath:        "test.tf",
+		AttributesToCheck: []string{"instance_type"},
+	}
+	instanceMock, parserMock, reportMock, loggerMock := createMocks(t)
+	awsService := &asgInstanceService{
+		InstanceServiceAPI: instanceMock,
+		members:            map[string][]string{"web-asg": {"i-000000a1", "i-000000a2"}},
+	}
+	service := NewService(config, awsService, parserMock, reportMock, loggerMock)
+
+	parserMock.On("GetDesiredState", mock.Anything, config.ConfigPath).Return(&models.InstanceDetails{InstanceType: "t2.micro"}, nil)
+	instanceMock.On("GetInstancesDetails", mock.Anything, []string{"i-000000a1", "i-000000a2"}).Return([]*models.InstanceDetails{
+		{InstanceID: "i-000000a1", InstanceType: "t2.micro"},
+		{InstanceID: "i-000000a2", InstanceType: "t2.large"},
+	}, nil).Once()
+	reportMock.On("PrintReport", "i-000000a1", mock.Anything, mock.Anything).Return(nil)
+	reportMock.On("PrintReport", "i-000000a2", mock.Anything, mock.Anything).Return(nil)
+
+	anyDrift, anyError, err := service.Run(context.Background())
+
+	assert.NoError(t, err)
+	assert.True(t, anyDrift)
+	assert.False(t, anyError)
+	assert.Equal(t, 2, service.Stats().InstancesChecked)
+}
+
+// TestRun_AutoScalingGroupEmpty tests that a run without any instance to check fails
+func TestRun_AutoScalingGroupEmpty(t *testing.T) {
+	config := Config{AutoScalingGroup: "web-asg", ConfigPath: "test.tf"}
+	instanceMock, parserMock, reportMock, loggerMock := createMocks(t)
+	service := NewService(config, &asgInstanceService{InstanceServiceAPI: instanceMock}, parserMock, reportMock, loggerMock)
+	parserMock.On("GetDesiredState", mock.Anything, config.ConfigPath).Return(&models.InstanceDetails{InstanceType: "t2.micro"}, nil)
+
+	_, anyError, err := service.Run(context.Background())
+
+	require.Error(t, err)
+	assert.True(t, anyError)
+	assert.Contains(t, err.Error(), "has no instances")
+}
+
+// TestRun_AutoScalingGroupUnsupported tests that a service unable to resolve groups fails the run
+func TestRun_AutoScalingGroupUnsupported(t *testing.T) {
+	config := Config{AutoScalingGroup: "web-asg", ConfigPath: "test.tf"}
+	service, _, parserMock, _ := setupServiceWithMocks(t, config)
+	parserMock.On("GetDesiredState", mock.Anything, config.ConfigPath).Return(&models.InstanceDetails{InstanceType: "t2.micro"}, nil)
+
+	_, _, err := service.Run(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not support resolving Auto Scaling group web-asg")
+}
@@ -0,0 +1,160 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"driftdetector/internal/models"
+)
+
+// fakeClock is a Clock whose time only moves when advanced, firing the tickers whose period elapsed
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+func (c *fakeClock) NewTicker(d time.Duration) Ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ticker := &fakeTicker{c: make(chan time.Time, 1), period: d, next: c.now.Add(d)}
+	c.tickers = append(c.tickers, ticker)
+	return ticker
+}
+
+// Advance moves the time forward by d. Like time.Ticker, a ticker whose receiver is behind drops ticks.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	for _, ticker := range c.tickers {
+		if ticker.stopped || ticker.next.After(c.now) {
+			continue
+		}
+		for !ticker.next.After(c.now) {
+			ticker.next = ticker.next.Add(ticker.period)
+		}
+		select {
+		case ticker.c <- c.now:
+		default:
+		}
+	}
+}
+
+// fakeTicker is a Ticker fired by fakeClock.Advance
+type fakeTicker struct {
+	c       chan time.Time
+	period  time.Duration
+	next    time.Time
+	stopped bool
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.c }
+func (t *fakeTicker) Stop()               { t.stopped = true }
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := newFakeClock(start)
+	ticker := clock.NewTicker(time.Minute)
+
+	clock.Advance(30 * time.Second)
+	assert.Equal(t, 30*time.Second, clock.Since(start))
+	assert.Empty(t, ticker.C(), "no tick before the period elapsed")
+
+	clock.Advance(30 * time.Second)
+	assert.Equal(t, start.Add(time.Minute), <-ticker.C())
+
+	// Ticks are dropped while the receiver is behind
+	clock.Advance(3 * time.Minute)
+	assert.Len(t, ticker.C(), 1)
+	<-ticker.C()
+
+	ticker.Stop()
+	clock.Advance(time.Hour)
+	assert.Empty(t, ticker.C())
+}
+
+// TestRun_DurationFromClock tests that the duration of a run is measured with the clock of the service
+func TestRun_DurationFromClock(t *testing.T) {
+	config := Config{
+		InstanceIDs: []string{"i-00000001"},
+		ConfigPath:  "test.tf",
+	}
+	clock := newFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	service, instanceMock, parserMock, reportMock := setupServiceWithMocks(t, config)
+	service.clock = clock
+
+	parserMock.On("GetDesiredState", mock.Anything, config.ConfigPath).Return(&models.InstanceDetails{InstanceType: "t2.micro"}, nil)
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).Return(
+		func(context.Context, []string) ([]*models.InstanceDetails, error) {
+			clock.Advance(3 * time.Second)
+			return []*models.InstanceDetails{{InstanceID: "i-00000001", InstanceType: "t2.micro"}}, nil
+		})
+	reportMock.On("PrintReport", "i-00000001", mock.Anything, mock.Anything).Return(nil)
+
+	_, _, err := service.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3*time.Second, service.Stats().Duration)
+}
+
+// TestWatch_TicksFromClock tests that watch cycles run on the ticks of the clock of the service
+func TestWatch_TicksFromClock(t *testing.T) {
+	config := Config{
+		InstanceIDs:   []string{"i-00000001"},
+		ConfigPath:    "test.tf",
+		Watch:         true,
+		WatchInterval: time.Hour,
+	}
+	clock := newFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	service, instanceMock, parserMock, _ := setupServiceWithMocks(t, config)
+	service.clock = clock
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	parserMock.On("GetDesiredState", mock.Anything, config.ConfigPath).Return(&models.InstanceDetails{InstanceType: "t2.micro"}, nil)
+	cycles := make(chan struct{})
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).Return(
+		func(context.Context, []string) ([]*models.InstanceDetails, error) {
+			cycles <- struct{}{}
+			return nil, errors.New("AWS error")
+		})
+
+	done := make(chan error)
+	go func() { done <- service.Watch(ctx) }()
+
+	<-cycles // The first cycle runs immediately
+	select {
+	case <-cycles:
+		t.Fatal("a cycle ran before the interval elapsed")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// An hour later the next cycle runs
+	clock.Advance(time.Hour)
+	<-cycles
+
+	cancel()
+	assert.NoError(t, <-done)
+}
@@ -0,0 +1,49 @@
+package orchestrator
+
+import (
+	"fmt"
+	"io"
+
+	"driftdetector/internal/driftcheck"
+	"driftdetector/internal/report"
+)
+
+// validateGroupBy checks that GroupBy is a checked attribute and that the output format leaves room for the
+// groups, which are written as a table after the reports.
+func (s *Service) validateGroupBy() error {
+	if s.config.GroupBy == "" {
+		return nil
+	}
+	if format := s.getOutputFormat(); format != report.OutputFormatTypeTABLE && format != report.OutputFormatTypeDIFF {
+		return fmt.Errorf("grouping drifts by attribute is only supported for the table and diff output formats")
+	}
+	if _, err := driftcheck.SupportedAttributes([]string{s.config.GroupBy}); err != nil {
+		return fmt.Errorf("invalid attribute to group by: %w", err)
+	}
+	attribute := driftcheck.NormalizeAttribute(s.config.GroupBy)
+	if len(s.config.AttributesToCheck) > 0 && !driftcheck.AttributeRequested(s.config.AttributesToCheck, attribute) {
+		return fmt.Errorf("cannot group by %s, which is not in the attributes to check", attribute)
+	}
+	return nil
+}
+
+// writeDriftGroups writes the drifts of the GroupBy attribute across all drifted instances, grouping
+// the instances that drifted from and to the same values.
+func (s *Service) writeDriftGroups(w io.Writer, results []DriftDetectionResult) error {
+	var reports []report.DriftReport
+	for _, result := range results {
+		if !result.HasDrift || result.Result == nil {
+			continue
+		}
+		reports = append(reports, report.DriftReport{
+			InstanceID: result.InstanceID,
+			Drifts:     driftcheck.ConvertToDrifts(result.Result),
+		})
+	}
+
+	attribute := driftcheck.NormalizeAttribute(s.config.GroupBy)
+	if err := report.WriteDriftGroups(w, attribute, report.GroupDrifts(reports, attribute)); err != nil {
+		return fmt.Errorf("error writing report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package orchestrator
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"driftdetector/internal/driftcheck"
+	"driftdetector/internal/models"
+)
+
+// TestWriteDriftGroups tests that the instances are grouped by the drift of the aliased attribute
+func TestWriteDriftGroups(t *testing.T) {
+	service, _, _, _ := setupServiceWithMocks(t, Config{GroupBy: "type"})
+	typeDrift := func(aws string) *driftcheck.DriftResult {
+		return &driftcheck.DriftResult{HasDrift: true, Drifts: map[string]models.DriftDetail{
+			"instance_type": {Attribute: "instance_type", AWSValue: aws, TerraformValue: "t3.micro"},
+		}}
+	}
+	results := []DriftDetectionResult{
+		{InstanceID: "i-000000a1", HasDrift: true, Result: typeDrift("t2.micro")},
+		{InstanceID: "i-000000a2", HasDrift: true, Result: typeDrift("t2.micro")},
+		{InstanceID: "i-000000a3", Result: &driftcheck.DriftResult{}},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, service.writeDriftGroups(&out, results))
+
+	assert.Contains(t, out.String(), "DRIFT OF instance_type BY VALUE:")
+	assert.Regexp(t, `instance_type\s+t2\.micro\s+t3\.micro\s+2: i-000000a1, i-000000a2`, out.String())
+}
+
+func TestValidateGroupBy(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{name: "Alias of a supported attribute", config: Config{GroupBy: "type"}},
+		{name: "Checked attribute", config: Config{GroupBy: "instance_type", AttributesToCheck: []string{"instance_type", "ami"}}},
+		{name: "Diff output", config: Config{GroupBy: "ami", OutputFormat: "diff"}},
+		{name: "Unsupported attribute", config: Config{GroupBy: "colour"}, wantErr: true},
+		{name: "Attribute not checked", config: Config{GroupBy: "tags", AttributesToCheck: []string{"ami"}}, wantErr: true},
+		{name: "JSON output", config: Config{GroupBy: "ami", OutputFormat: "json"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _, _, _ := setupServiceWithMocks(t, tt.config)
+			err := service.validateGroupBy()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
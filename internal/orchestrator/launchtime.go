@@ -0,0 +1,65 @@
+package orchestrator
+
+import (
+	"fmt"
+	"time"
+)
+
+// launchWindow bounds the launch times of the instances to check. A zero bound leaves that side open.
+type launchWindow struct {
+	after  time.Time // Instances launched before this are skipped
+	before time.Time // Instances launched at or after this are skipped
+}
+
+// includes returns true if an instance launched at launchTime falls within the window.
+// Instances with an unknown launch time are always included.
+func (w launchWindow) includes(launchTime *time.Time) bool {
+	if launchTime == nil {
+		return true
+	}
+	if !w.after.IsZero() && launchTime.Before(w.after) {
+		return false
+	}
+	if !w.before.IsZero() && !launchTime.Before(w.before) {
+		return false
+	}
+	return true
+}
+
+// resolveLaunchWindow resolves LaunchedAfter and LaunchedBefore relative to now, so durations select
+// a moving window, e.g. the instances launched in the last 24 hours of every watch cycle.
+func (s *Service) resolveLaunchWindow(now time.Time) (launchWindow, error) {
+	after, err := parseLaunchTimeBound(s.config.LaunchedAfter, now)
+	if err != nil {
+		return launchWindow{}, fmt.Errorf("invalid launched-after: %w", err)
+	}
+	before, err := parseLaunchTimeBound(s.config.LaunchedBefore, now)
+	if err != nil {
+		return launchWindow{}, fmt.Errorf("invalid launched-before: %w", err)
+	}
+	if !after.IsZero() && !before.IsZero() && !after.Before(before) {
+		return launchWindow{}, fmt.Errorf("launched-after (%s) must be earlier than launched-before (%s)",
+			after.Format(time.RFC3339), before.Format(time.RFC3339))
+	}
+	return launchWindow{after: after, before: before}, nil
+}
+
+// parseLaunchTimeBound parses a launch time bound: a duration ago (e.g. 24h), an RFC 3339 timestamp or
+// a date. An empty value is the zero time, an open bound.
+func parseLaunchTimeBound(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if ago, err := time.ParseDuration(value); err == nil {
+		if ago < 0 {
+			return time.Time{}, fmt.Errorf("duration %s must not be negative", value)
+		}
+		return now.Add(-ago), nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a duration ago (e.g. 24h), an RFC 3339 timestamp or a date (YYYY-MM-DD)", value)
+}
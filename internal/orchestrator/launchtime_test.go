@@ -0,0 +1,53 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLaunchTimeBound(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	bound, err := parseLaunchTimeBound("", now)
+	assert.NoError(t, err)
+	assert.True(t, bound.IsZero(), "An empty bound is open")
+
+	bound, err = parseLaunchTimeBound("24h", now)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(-24*time.Hour), bound)
+
+	bound, err = parseLaunchTimeBound("2024-05-01T08:30:00Z", now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC), bound)
+
+	bound, err = parseLaunchTimeBound("2024-05-01", now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), bound)
+
+	_, err = parseLaunchTimeBound("-1h", now)
+	assert.Error(t, err)
+	_, err = parseLaunchTimeBound("last week", now)
+	assert.Error(t, err)
+}
+
+func TestResolveLaunchWindow(t *testing.T) {
+	now := time.Now()
+	service := &Service{config: Config{LaunchedAfter: "1h", LaunchedBefore: "24h"}}
+	_, err := service.resolveLaunchWindow(now)
+	assert.ErrorContains(t, err, "must be earlier", "An empty window is a configuration mistake")
+
+	service.config = Config{LaunchedAfter: "24h", LaunchedBefore: "1h"}
+	window, err := service.resolveLaunchWindow(now)
+	assert.NoError(t, err)
+
+	launchedAgo := func(ago time.Duration) *time.Time {
+		launchTime := now.Add(-ago)
+		return &launchTime
+	}
+	assert.True(t, window.includes(launchedAgo(2*time.Hour)))
+	assert.False(t, window.includes(launchedAgo(48*time.Hour)))
+	assert.False(t, window.includes(launchedAgo(time.Minute)))
+	assert.True(t, window.includes(nil), "Instances with an unknown launch time are included")
+}
@@ -1,15 +1,153 @@
 package orchestrator
 
-import "driftdetector/internal/driftcheck"
+import (
+	"fmt"
+	"time"
+
+	"driftdetector/internal/driftcheck"
+)
+
+// Security group match modes control how AWS security groups are compared with Terraform.
+const (
+	// SecurityGroupMatchByID compares the sg-... IDs reported by AWS (default)
+	SecurityGroupMatchByID = "id"
+	// SecurityGroupMatchByName compares the group names reported by AWS
+	SecurityGroupMatchByName = "name"
+)
+
+// Resource match modes control which Terraform resource each AWS instance is compared with.
+const (
+	// MatchByNone compares every instance with the first aws_instance resource (default)
+	MatchByNone = ""
+	// MatchByName compares each instance with the aws_instance resource whose Name tag equals the instance's
+	MatchByName = "name"
+)
+
+// nameTag is the tag instances and resources are matched by with MatchByName
+const nameTag = "Name"
+
+// terminationProtectionAttribute is only fetched from AWS when it is explicitly requested, see AttributesToCheck.
+const terminationProtectionAttribute = "disable_api_termination"
+
+// userDataAttribute is only fetched from AWS when it is explicitly requested, like terminationProtectionAttribute.
+const userDataAttribute = "user_data"
+
+// cpuCreditsAttribute is only fetched from AWS when it is explicitly requested, and only for burstable instances.
+const cpuCreditsAttribute = "cpu_credits"
+
+// blockDevicesAttribute is always compared by device name, but volume sizes and types are only fetched from AWS
+// when it is explicitly requested.
+const blockDevicesAttribute = "block_devices"
+
+// volumeTagsAttribute is compared from the tags of the volumes, which are only fetched from AWS when it is explicitly
+// requested, along with their sizes and types.
+const volumeTagsAttribute = "volume_tags"
+
+// logMaxBackups is the number of rotated log files kept next to LogFile, so long-running watch mode does not fill the disk
+const logMaxBackups = 5
+
+// Reasons for skipping an instance, as reported in the summary
+const (
+	skipReasonState      = "state"
+	skipReasonLaunchTime = "launch time"
+)
+
+// instanceStates are the EC2 instance lifecycle states accepted by OnlyStates.
+var instanceStates = []string{"pending", "running", "shutting-down", "terminated", "stopping", "stopped"}
 
 // Config contains all the parameters needed for the drift detection process.
 type Config struct {
-	InstanceIDs       []string // AWS EC2 instance IDs
-	ConfigPath        string   // Path to Terraform configuration file
-	AttributesToCheck []string // List of attributes to check for drift
-	OutputFormat      string   // Output format (json or table)
-	ConcurrencyLimit  int      // Maximum number of concurrent instance checks (0 = unlimited)
-	Verbose           bool     // Enable verbose output
+	InstanceIDs          []string            // AWS EC2 instance IDs, optionally qualified with a region (us-east-1/i-123)
+	AutoScalingGroup     string              // Name of an Auto Scaling group whose current members are checked, next to InstanceIDs
+	ConfigPath           string              // Path to Terraform configuration file
+	DesiredJSONPath      string              // Path to a JSON file of the desired instance details, used instead of ConfigPath
+	PlanJSONPath         string              // Path to a Terraform plan in JSON (terraform show -json), used instead of ConfigPath
+	AttributeMappingPath string              // Path to a YAML file mapping attribute names to custom HCL attribute names
+	LaunchTemplate       string              // Name of the aws_launch_template resource in ConfigPath used as desired state instead of an aws_instance
+	AttributesToCheck    []string            // List of attributes to check for drift
+	StrictAttributes     bool                // Fail on unsupported attributes instead of warning and skipping them
+	AttributeProfile     string              // Named attribute set added to AttributesToCheck, e.g. security
+	AttributeProfiles    map[string][]string // Named attribute sets, overriding the built-in ones of the same name
+	EquivalentTypes      []string            // Groups of interchangeable instance types, e.g. t3.micro=t3a.micro, that are not drift
+	OnlyManaged          bool                // Only compare the attributes the Terraform configuration sets
+	IncludeVolatile      bool                // Compare volatile attributes, such as the public IP, of stopped instances too
+	SkipUnsetAttributes  bool                // Treat attributes the Terraform configuration leaves empty as not managed instead of expected empty
+	OutputFormat         string              // Output format (json or table)
+	OutputFile           string              // File to write single-document formats (json, sarif, html, junit, template) to instead of stdout
+	TemplateFile         string              // text/template file rendering the template output format
+	Reports              []ReportSink        // Additional reports written to files in their own format, next to OutputFormat
+	JSONCompact          bool                // Write the JSON document on a single line instead of pretty-printed, e.g. for log ingestion
+	JSONIndent           int                 // Spaces per indentation level of the pretty-printed JSON document (0 = 2)
+	ConcurrencyLimit     int                 // Maximum number of concurrent instance checks and AWS API calls across all regions (0 = unlimited)
+	ParallelRegions      bool                // Fetch the instances of all regions concurrently, within ConcurrencyLimit
+	RetryAttempts        int                 // Attempts of a failing AWS API call, including the first (0 = aws.DefaultRetryAttempts)
+	RetryOn              []string            // AWS error categories retried (empty = aws.DefaultRetryableCategories)
+	Verbose              bool                // Enable verbose output
+	CheckAMIDeprecation  bool                // Flag instances whose AMI has been deprecated
+	AllowedAZs           []string            // Flag instances outside these availability zones, whatever the Terraform configuration (empty = any zone)
+	SecurityGroupMatchBy string              // Compare security groups by "id" (default) or "name"
+	TagsMode             string              // Compare tags "exact" (default) or as a "subset", ignoring tags only present on AWS
+	IgnoreTagValueCase   bool                // Compare tag values ignoring case, e.g. Prod and prod; keys stay case-sensitive
+	MatchBy              string              // Match instances to aws_instance resources: MatchByNone or MatchByName
+	ColorMode            string              // Table colorization: auto (default), always or never
+	MaxColumnWidth       string              // Truncation of long table values: auto (default, fits the terminal), 0 (none) or a number of characters
+	MaxDrifts            int                 // Number of drifted instances tolerated before Run reports drift (0 = any drift)
+	Quiet                bool                // Only report instances with drift (the summary and errors are always shown)
+	SuppressEmpty        bool                // Print nothing for instances without drift, leaving the summary as without Quiet
+	ShowAllAttributes    bool                // List every compared attribute in the table with an OK or DRIFT status, not only drifts
+	DiffContext          int                 // Matching attributes shown before and after each drifted one in diff output
+	GroupBy              string              // Attribute whose drifts are listed after the reports, grouping the instances that drifted the same way
+	Regions              []string            // AWS regions to check, the first one is used for unqualified instance IDs
+	Accounts             []Account           // AWS accounts whose instances are all checked, in each of their regions with an assumed role
+	AWSSource            string              // file:// URL of a JSON fixture of instances to use instead of the AWS API, for demos and tests
+	AWSEndpointURL       string              // Base URL of the EC2 API, e.g. a VPC endpoint, instead of the public endpoint of the region
+	UseFIPS              bool                // Use the FIPS endpoint of each region, e.g. for GovCloud
+	Watch                bool                // Keep re-checking the instances until interrupted
+	WatchInterval        time.Duration       // Time between watch cycles
+	MetricsFile          string              // Path to write the run statistics to as JSON, if set
+	PrometheusTextfile   string              // Path to write the run statistics to in the Prometheus text format, if set
+	NotifyWebhook        string              // URL to POST a JSON summary to when drift is detected, if set
+	OnlyStates           []string            // Only check instances in these lifecycle states (empty = all states)
+	LaunchedAfter        string              // Only check instances launched after this: a duration ago (e.g. 24h), an RFC 3339 timestamp or a date
+	LaunchedBefore       string              // Only check instances launched before this, in the same formats as LaunchedAfter
+	Progress             bool                // Print the number of instances checked so far to stderr while a run is in progress
+	LogFile              string              // Write log lines to this file instead of the console, rotating it by size
+	LogMaxSize           int                 // Size in megabytes at which LogFile is rotated
+	SlowThreshold        time.Duration       // Log a warning for instances and region fetches taking longer than this (0 = disabled)
+}
+
+// InstanceError is the error of an instance that could not be checked.
+type InstanceError struct {
+	InstanceID string
+	Err        error
+}
+
+// Error implements the error interface
+func (e *InstanceError) Error() string {
+	return fmt.Sprintf("instance %s: %v", e.InstanceID, e.Err)
+}
+
+// Unwrap returns the underlying error, so errors.As can match aws.Error or driftcheck.DriftError
+func (e *InstanceError) Unwrap() error {
+	return e.Err
+}
+
+// DesiredStateUnavailableError is returned by a run whose desired state was parsed but sets no attribute, e.g.
+// an aws_instance whose arguments could not be resolved. Comparing against it would report every attribute
+// of every instance as drift, so the run fails instead.
+type DesiredStateUnavailableError struct {
+	Path string
+}
+
+// Error implements the error interface
+func (e *DesiredStateUnavailableError) Error() string {
+	return fmt.Sprintf("desired state unavailable: %s defines no attribute to compare", e.Path)
+}
+
+// ReportSink is an additional report, written to File in Format (e.g. json), next to the main output.
+type ReportSink struct {
+	Format string
+	File   string
 }
 
 // DriftDetectionResult contains the result of a drift detection for a single instance.
@@ -18,4 +156,7 @@ type DriftDetectionResult struct {
 	HasDrift   bool
 	Error      error
 	Result     *driftcheck.DriftResult
+	Skipped    bool          // The instance was not checked because it is excluded by OnlyStates or its launch time
+	SkipReason string        // Why the instance was skipped: skipReasonState or skipReasonLaunchTime
+	Duration   time.Duration // Time spent processing the instance, zero if it was not processed
 }
@@ -0,0 +1,114 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// notifyTimeout bounds a notification, so an unresponsive endpoint does not hold up the run
+const notifyTimeout = 10 * time.Second
+
+// driftDetectedEvent is the event of the notifications sent when drift is detected
+const driftDetectedEvent = "drift_detected"
+
+// DriftNotification is the JSON summary POSTed to the notification webhook when a run detects drift.
+type DriftNotification struct {
+	Event string `json:"event"`
+	// Text is a one-line summary, which chat webhooks such as Slack incoming webhooks display as the message
+	Text             string             `json:"text"`
+	Stats            RunStats           `json:"stats"`
+	DriftedInstances []NotifiedInstance `json:"drifted_instances"`
+}
+
+// NotifiedInstance is a drifted instance of a DriftNotification, with the names of its drifted attributes.
+type NotifiedInstance struct {
+	InstanceID string   `json:"instance_id"`
+	Attributes []string `json:"attributes"`
+}
+
+// buildDriftNotification builds the notification of a run from its results and statistics
+func buildDriftNotification(results []DriftDetectionResult, stats RunStats) DriftNotification {
+	notification := DriftNotification{
+		Event: driftDetectedEvent,
+		Text: fmt.Sprintf("driftdetector: %d of %d instances drifted from the Terraform configuration",
+			stats.InstancesDrifted, stats.InstancesChecked),
+		Stats:            stats,
+		DriftedInstances: make([]NotifiedInstance, 0, stats.InstancesDrifted),
+	}
+	for _, result := range results {
+		if !result.HasDrift || result.Result == nil {
+			continue
+		}
+		// Keyed attributes such as tags.Name are listed once under their attribute, like in the run statistics
+		drifted := make(map[string]bool, len(result.Result.Drifts))
+		for attribute := range result.Result.Drifts {
+			name, _, _ := strings.Cut(attribute, ".")
+			drifted[name] = true
+		}
+		attributes := make([]string, 0, len(drifted))
+		for name := range drifted {
+			attributes = append(attributes, name)
+		}
+		sort.Strings(attributes)
+		notification.DriftedInstances = append(notification.DriftedInstances, NotifiedInstance{
+			InstanceID: result.InstanceID,
+			Attributes: attributes,
+		})
+	}
+	sort.Slice(notification.DriftedInstances, func(i, j int) bool {
+		return notification.DriftedInstances[i].InstanceID < notification.DriftedInstances[j].InstanceID
+	})
+	return notification
+}
+
+// notifyDrift POSTs the notification of a run to the configured webhook if any instance drifted.
+// Notifications are best effort: a failure is logged and does not fail the run, as the reports were already written.
+func (s *Service) notifyDrift(ctx context.Context, results []DriftDetectionResult, stats RunStats) {
+	if s.config.NotifyWebhook == "" || stats.InstancesDrifted == 0 {
+		return
+	}
+	if err := postNotification(ctx, s.config.NotifyWebhook, buildDriftNotification(results, stats)); err != nil {
+		s.logger.Warn("Failed to send the drift notification: %s", err)
+		return
+	}
+	s.logger.Info("Sent the drift notification for %d instances", stats.InstancesDrifted)
+}
+
+// postNotification POSTs the notification as JSON to the webhook URL
+func postNotification(ctx context.Context, webhook string, notification DriftNotification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("error encoding notification: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook, bytes.NewReader(body))
+	if err != nil {
+		return errors.New("invalid notification webhook URL")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		// The URL of a webhook is often its secret, so it is left out of the error
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return urlErr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("notification webhook responded %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"driftdetector/internal/driftcheck"
+	"driftdetector/internal/models"
+	awsMocks "driftdetector/internal/providers/aws/mocks"
+	reportMocks "driftdetector/internal/report/mocks"
+	terraformMocks "driftdetector/internal/terraform/mocks"
+	loggerMocks "driftdetector/pkg/logging/mocks"
+)
+
+// webhookServer starts a server recording the notifications it receives, responding with status
+func webhookServer(t *testing.T, status int) (*httptest.Server, *[]DriftNotification) {
+	var received []DriftNotification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		var notification DriftNotification
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&notification))
+		received = append(received, notification)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, &received
+}
+
+// TestRun_NotifyWebhook tests that a run with drift posts a summary of the drifted instances once reported
+func TestRun_NotifyWebhook(t *testing.T) {
+	server, received := webhookServer(t, http.StatusOK)
+	config := Config{
+		InstanceIDs:       []string{"i-000000a1", "i-000000a2"},
+		ConfigPath:        "test.tf",
+		AttributesToCheck: []string{"instance_type", "tags"},
+		NotifyWebhook:     server.URL,
+	}
+	service, instanceMock, parserMock, reportMock := setupServiceWithMocks(t, config)
+
+	parserMock.On("GetDesiredState", mock.Anything, config.ConfigPath).Return(&models.InstanceDetails{
+		InstanceType: "t2.micro",
+		Tags:         map[string]string{"Env": "prod"},
+	}, nil)
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).Return([]*models.InstanceDetails{
+		{InstanceID: "i-000000a1", InstanceType: "t2.large", Tags: map[string]string{"Env": "dev"}},
+		{InstanceID: "i-000000a2", InstanceType: "t2.micro", Tags: map[string]string{"Env": "prod"}},
+	}, nil)
+	reportMock.On("PrintReport", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	anyDrift, _, err := service.Run(context.Background())
+
+	require.NoError(t, err)
+	assert.True(t, anyDrift)
+	require.Len(t, *received, 1)
+	notification := (*received)[0]
+	assert.Equal(t, "drift_detected", notification.Event)
+	assert.Equal(t, "driftdetector: 1 of 2 instances drifted from the Terraform configuration", notification.Text)
+	assert.Equal(t, 1, notification.Stats.InstancesDrifted)
+	assert.Equal(t, []NotifiedInstance{{InstanceID: "i-000000a1", Attributes: []string{"instance_type", "tags"}}},
+		notification.DriftedInstances)
+}
+
+// TestRun_NotifyWebhookNoDrift tests that a clean run sends no notification
+func TestRun_NotifyWebhookNoDrift(t *testing.T) {
+	server, received := webhookServer(t, http.StatusOK)
+	config := Config{InstanceIDs: []string{"i-000000a1"}, ConfigPath: "test.tf", NotifyWebhook: server.URL}
+	service, instanceMock, parserMock, reportMock := setupServiceWithMocks(t, config)
+
+	parserMock.On("GetDesiredState", mock.Anything, config.ConfigPath).Return(&models.InstanceDetails{InstanceType: "t2.micro"}, nil)
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).Return([]*models.InstanceDetails{
+		{InstanceID: "i-000000a1", InstanceType: "t2.micro"},
+	}, nil)
+	reportMock.On("PrintReport", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	_, _, err := service.Run(context.Background())
+
+	require.NoError(t, err)
+	assert.Empty(t, *received)
+}
+
+// TestNotifyDrift_Failure tests that a failed notification is only logged, without the webhook URL
+func TestNotifyDrift_Failure(t *testing.T) {
+	server, received := webhookServer(t, http.StatusInternalServerError)
+	loggerMock := loggerMocks.NewLogger(t)
+	service := NewService(Config{NotifyWebhook: server.URL}, awsMocks.NewInstanceServiceAPI(t),
+		terraformMocks.NewDesiredStateProvider(t), reportMocks.NewIPrinter(t), loggerMock)
+
+	loggerMock.On("Warn", "Failed to send the drift notification: %s", mock.MatchedBy(func(err error) bool {
+		return assert.NotContains(t, err.Error(), server.URL) && assert.Contains(t, err.Error(), "500")
+	})).Return().Once()
+
+	results := []DriftDetectionResult{{
+		InstanceID: "i-000000a1",
+		HasDrift:   true,
+		Result:     &driftcheck.DriftResult{HasDrift: true, Drifts: map[string]models.DriftDetail{"tags.Env": {}}},
+	}}
+	service.notifyDrift(context.Background(), results, RunStats{InstancesChecked: 1, InstancesDrifted: 1})
+
+	assert.Len(t, *received, 1)
+}
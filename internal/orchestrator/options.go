@@ -0,0 +1,55 @@
+package orchestrator
+
+import (
+	"driftdetector/internal/providers/aws"
+	"driftdetector/internal/report"
+	"driftdetector/internal/terraform"
+	"driftdetector/pkg/logging"
+)
+
+// Option overrides a dependency of the service created by NewServiceWithOptions.
+type Option func(*serviceOptions)
+
+// serviceOptions holds the dependencies overridden by options, nil ones use the default implementation
+type serviceOptions struct {
+	awsSrv  aws.InstanceServiceAPI
+	parser  terraform.DesiredStateProvider
+	printer report.IPrinter
+	logger  logging.Logger
+	clock   Clock
+}
+
+// WithAWSService sets the AWS service used for unqualified instance IDs
+func WithAWSService(awsSrv aws.InstanceServiceAPI) Option {
+	return func(o *serviceOptions) {
+		o.awsSrv = awsSrv
+	}
+}
+
+// WithParser sets the parser of the desired state
+func WithParser(parser terraform.DesiredStateProvider) Option {
+	return func(o *serviceOptions) {
+		o.parser = parser
+	}
+}
+
+// WithPrinter sets the report printer
+func WithPrinter(printer report.IPrinter) Option {
+	return func(o *serviceOptions) {
+		o.printer = printer
+	}
+}
+
+// WithLogger sets the logger
+func WithLogger(logger logging.Logger) Option {
+	return func(o *serviceOptions) {
+		o.logger = logger
+	}
+}
+
+// WithClock sets the source of time, e.g. a fake clock for deterministic timing
+func WithClock(clock Clock) Option {
+	return func(o *serviceOptions) {
+		o.clock = clock
+	}
+}
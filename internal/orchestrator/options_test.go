@@ -0,0 +1,166 @@
+package orchestrator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"driftdetector/internal/models"
+	"driftdetector/internal/providers/aws"
+	"driftdetector/internal/report"
+	"driftdetector/internal/terraform"
+)
+
+// TestNewServiceWithOptions tests that injected dependencies are used instead of the defaults
+func TestNewServiceWithOptions(t *testing.T) {
+	config := Config{InstanceIDs: []string{"i-00000001"}, ConfigPath: "test.tf"}
+	instanceMock, parserMock, reportMock, logger := createMocks(t)
+	clock := newFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	service, err := NewServiceWithOptions(config,
+		WithAWSService(instanceMock),
+		WithParser(parserMock),
+		WithPrinter(reportMock),
+		WithLogger(logger),
+		WithClock(clock),
+	)
+	require.NoError(t, err)
+	assert.Same(t, clock, service.clock)
+
+	parserMock.On("GetDesiredState", mock.Anything, config.ConfigPath).Return(&models.InstanceDetails{InstanceType: "t2.micro"}, nil)
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).
+		Return([]*models.InstanceDetails{{InstanceID: "i-00000001", InstanceType: "t2.large"}}, nil)
+	reportMock.On("PrintReport", "i-00000001", mock.Anything, report.OutputFormatTypeTABLE).Return(nil)
+
+	anyDrift, anyError, err := service.Run(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, anyDrift)
+	assert.False(t, anyError)
+}
+
+// TestNewServiceWithOptions_Defaults tests that dependencies which are not overridden get the default implementation
+func TestNewServiceWithOptions_Defaults(t *testing.T) {
+	instanceMock, _, reportMock, _ := createMocks(t)
+
+	service, err := NewServiceWithOptions(Config{ConfigPath: "test.tf"}, WithAWSService(instanceMock))
+	require.NoError(t, err)
+	assert.Same(t, instanceMock, service.awsSrv, "An injected AWS service should not be replaced")
+	assert.IsType(t, report.FormatPrinter{}, service.reportPrinter)
+	assert.NotNil(t, service.desiredState)
+	assert.NotNil(t, service.logger)
+	assert.Equal(t, realClock{}, service.clock)
+
+	// Invalid settings of a default dependency are reported, unless the dependency is injected
+	_, err = NewServiceWithOptions(Config{ColorMode: "sometimes"}, WithAWSService(instanceMock))
+	assert.Error(t, err)
+	_, err = NewServiceWithOptions(Config{ColorMode: "sometimes"}, WithAWSService(instanceMock), WithPrinter(reportMock))
+	assert.NoError(t, err)
+	_, err = NewServiceWithOptions(Config{OutputFormat: "template", TemplateFile: filepath.Join(t.TempDir(), "missing.tmpl")},
+		WithAWSService(instanceMock))
+	assert.ErrorContains(t, err, "error reading template file")
+}
+
+// TestNewServiceWithOptions_ReportFiles tests that report files are only created, truncating existing ones, once
+// they are written, so a run rejected by validation leaves them in place
+func TestNewServiceWithOptions_ReportFiles(t *testing.T) {
+	reportFile := filepath.Join(t.TempDir(), "report.json")
+	require.NoError(t, os.WriteFile(reportFile, []byte("previous report"), 0o600))
+	instanceMock, parserMock, _, logger := createMocks(t)
+
+	// Without instances to check the configuration is invalid
+	config := Config{ConfigPath: "test.tf", Reports: []ReportSink{{Format: "jsonl", File: reportFile}}}
+	service, err := NewServiceWithOptions(config, WithAWSService(instanceMock), WithParser(parserMock), WithLogger(logger))
+	require.NoError(t, err)
+	_, _, err = service.Run(context.Background())
+	assert.Error(t, err)
+	require.NoError(t, service.Close())
+
+	content, err := os.ReadFile(reportFile)
+	require.NoError(t, err)
+	assert.Equal(t, "previous report", string(content))
+
+	// A run that reports overwrites the file
+	config.InstanceIDs = []string{"i-00000001"}
+	service, err = NewServiceWithOptions(config, WithAWSService(instanceMock), WithParser(parserMock), WithLogger(logger))
+	require.NoError(t, err)
+	parserMock.On("GetDesiredState", mock.Anything, config.ConfigPath).Return(&models.InstanceDetails{InstanceType: "t2.micro"}, nil)
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).
+		Return([]*models.InstanceDetails{{InstanceID: "i-00000001", InstanceType: "t2.micro"}}, nil)
+	_, _, err = service.Run(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, service.Close())
+
+	content, err = os.ReadFile(reportFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"instance_id":"i-00000001"`)
+}
+
+// TestNewServiceWithOptions_FileAWSSource tests that instances are read from the AWS source fixture instead of AWS
+func TestNewServiceWithOptions_FileAWSSource(t *testing.T) {
+	fixture := filepath.Join(t.TempDir(), "instances.json")
+	require.NoError(t, os.WriteFile(fixture, []byte(`{"i-00000001": {"instance_type": "t2.large"}}`), 0o600))
+	_, parserMock, reportMock, logger := createMocks(t)
+
+	config := Config{InstanceIDs: []string{"i-00000001"}, ConfigPath: "test.tf", AWSSource: "file://" + fixture, Regions: []string{"eu-west-1"}}
+	service, err := NewServiceWithOptions(config, WithParser(parserMock), WithPrinter(reportMock), WithLogger(logger))
+	require.NoError(t, err)
+	assert.IsType(t, &aws.FileInstanceService{}, service.awsSrv)
+
+	parserMock.On("GetDesiredState", mock.Anything, config.ConfigPath).Return(&models.InstanceDetails{InstanceType: "t2.micro"}, nil)
+	reportMock.On("PrintReport", "i-00000001", mock.Anything, report.OutputFormatTypeTABLE).Return(nil)
+
+	anyDrift, anyError, err := service.Run(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, anyDrift)
+	assert.False(t, anyError)
+
+	_, err = NewServiceWithOptions(Config{AWSSource: "s3://bucket/instances.json"}, WithParser(parserMock), WithPrinter(reportMock))
+	assert.ErrorContains(t, err, "unsupported AWS source")
+}
+
+// TestNewServiceWithOptions_PlanJSON tests that the desired state is read from the after state of a Terraform plan
+func TestNewServiceWithOptions_PlanJSON(t *testing.T) {
+	plan := filepath.Join(t.TempDir(), "plan.json")
+	require.NoError(t, os.WriteFile(plan, []byte(`{"format_version": "1.2", "resource_changes": [
+		{"address": "aws_instance.web", "mode": "managed", "type": "aws_instance",
+		 "change": {"actions": ["update"], "after": {"instance_type": "t2.micro"}}}
+	]}`), 0o600))
+	instanceMock, _, reportMock, logger := createMocks(t)
+
+	config := Config{InstanceIDs: []string{"i-00000001"}, PlanJSONPath: plan}
+	service, err := NewServiceWithOptions(config, WithAWSService(instanceMock), WithPrinter(reportMock), WithLogger(logger))
+	require.NoError(t, err)
+	assert.IsType(t, &terraform.PlanParser{}, service.desiredState)
+
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).
+		Return([]*models.InstanceDetails{{InstanceID: "i-00000001", InstanceType: "t2.large"}}, nil)
+	reportMock.On("PrintReport", "i-00000001", mock.MatchedBy(func(drifts []models.DriftDetail) bool {
+		return len(drifts) == 1 && drifts[0].TerraformValue == "t2.micro"
+	}), report.OutputFormatTypeTABLE).Return(nil)
+
+	anyDrift, _, err := service.Run(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, anyDrift)
+}
+
+// TestNewServiceWithOptions_LogFile tests that the default logger writes to the log file, which Close closes
+func TestNewServiceWithOptions_LogFile(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "driftdetector.log")
+	instanceMock, parserMock, reportMock, _ := createMocks(t)
+
+	config := Config{LogFile: logFile, LogMaxSize: 1}
+	service, err := NewServiceWithOptions(config, WithAWSService(instanceMock), WithParser(parserMock), WithPrinter(reportMock))
+	require.NoError(t, err)
+	service.logger.Info("Watch cycle %d completed", 1)
+	require.NoError(t, service.Close())
+
+	content, err := os.ReadFile(logFile)
+	require.NoError(t, err)
+	assert.Equal(t, "[INFO]: Watch cycle 1 completed\n", string(content))
+}
@@ -2,10 +2,20 @@ package orchestrator
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
+	"text/template"
+	"time"
 
 	"golang.org/x/sync/errgroup"
+	"gopkg.in/natefinch/lumberjack.v2"
 
 	"driftdetector/internal/driftcheck"
 	"driftdetector/internal/models"
@@ -21,16 +31,26 @@ import (
 type Service struct {
 	config          Config
 	awsSrv          aws.InstanceServiceAPI
-	terraformParser terraform.IProvider
+	regionalAWSSrvs map[string]aws.InstanceServiceAPI
+	desiredState    terraform.DesiredStateProvider
 	reportPrinter   report.IPrinter
+	outputFormat    report.OutputFormatType // Parsed from OutputFormat by NewService, so it is not parsed for every report
 	logger          logging.Logger
+	stats           RunStats
+	equivalentTypes driftcheck.InstanceTypeEquivalences // Parsed from EquivalentTypes by parseEquivalentTypes
+	closers         []io.Closer                         // Files opened by NewServiceWithOptions, closed by Close
+	launchWindow    launchWindow                        // Resolved from LaunchedAfter and LaunchedBefore at the start of each run
+	desiredByName   map[string]*models.InstanceDetails  // Resources keyed by Name tag with MatchByName, parsed at the start of each run
+	instanceIDs     []string                            // InstanceIDs and the members of AutoScalingGroup, resolved at the start of each run
+	progressOut     io.Writer                           // Where the progress of runs is written with Progress, stderr so reports on stdout are unaffected
+	clock           Clock                               // Source of time, replaced by a fake clock in tests
 }
 
 // NewService creates a new orchestrator service with the given configuration.
 func NewService(
 	config Config,
 	awsSrv aws.InstanceServiceAPI,
-	terraformParser terraform.IProvider,
+	desiredState terraform.DesiredStateProvider,
 	reportPrinter report.IPrinter,
 	logger logging.Logger,
 ) *Service {
@@ -42,83 +62,541 @@ func NewService(
 	return &Service{
 		config:          config,
 		awsSrv:          awsSrv,
-		terraformParser: terraformParser,
+		regionalAWSSrvs: make(map[string]aws.InstanceServiceAPI),
+		desiredState:    desiredState,
 		reportPrinter:   reportPrinter,
+		outputFormat:    parseOutputFormat(config.OutputFormat),
 		logger:          logger,
+		progressOut:     os.Stderr,
+		clock:           realClock{},
 	}
 }
 
 // NewDefaultService creates a new service with default implementations of dependencies
 func NewDefaultService(config Config) (*Service, error) {
-	// Create AWS instance service with default configuration
-	awsService, err := aws.NewInstanceServiceWithDefaultConfig(context.Background())
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize AWS service: %w", err)
+	return NewServiceWithOptions(config)
+}
+
+// NewServiceWithOptions creates a new service, using the default implementation for every dependency
+// that is not overridden by an option. Defaults are only created when needed, so e.g. a service with
+// an injected AWS service does not require AWS credentials.
+// Services for qualified instance IDs of other regions can be added with SetRegionalService.
+func NewServiceWithOptions(config Config, opts ...Option) (*Service, error) {
+	var options serviceOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var files []io.Closer
+	logger := options.logger
+	if logger == nil {
+		var logFile io.Closer
+		logger, logFile = newDefaultLogger(config)
+		if logFile != nil {
+			files = append(files, logFile)
+		}
+	}
+
+	awsSrv := options.awsSrv
+	var regionalServices map[string]aws.InstanceServiceAPI
+	if awsSrv == nil {
+		var err error
+		awsSrv, regionalServices, err = newDefaultAWSServices(config)
+		if err != nil {
+			closeAll(files)
+			return nil, err
+		}
+	}
+
+	desiredState := options.parser
+	if desiredState == nil {
+		var err error
+		desiredState, err = newDefaultParser(config, logger)
+		if err != nil {
+			closeAll(files)
+			return nil, err
+		}
 	}
 
+	reportPrinter := options.printer
+	if reportPrinter == nil {
+		var err error
+		var reportFiles []io.Closer
+		reportPrinter, reportFiles, err = newDefaultPrinter(config)
+		files = append(files, reportFiles...)
+		if err != nil {
+			closeAll(files)
+			return nil, err
+		}
+	}
+
+	service := NewService(config, awsSrv, desiredState, reportPrinter, logger)
+	service.closers = files
+	if options.clock != nil {
+		service.clock = options.clock
+	}
+	for region, regionalService := range regionalServices {
+		service.SetRegionalService(region, regionalService)
+	}
+	return service, nil
+}
+
+// newDefaultLogger creates the logger, keeping stdout free of log lines for machine-readable output formats.
+// With LogFile it writes to that file instead, rotated once it reaches LogMaxSize, and also returns the file
+// to close.
+func newDefaultLogger(config Config) (logging.Logger, io.Closer) {
 	logger := logging.NewDefaultLogger()
 	// Set the logger level based on the verbose flag
 	if config.Verbose {
 		logger.SetLevel(logging.DEBUG)
 	}
+	// Keep machine-readable reports on stdout free of log lines so they can be redirected as is
+	switch strings.ToUpper(config.OutputFormat) {
+	case string(report.OutputFormatTypeJSON), string(report.OutputFormatTypeSARIF), string(report.OutputFormatTypeHTML),
+		string(report.OutputFormatTypeJUnit), string(report.OutputFormatTypeJSONL), string(report.OutputFormatTypeTemplate):
+		logger.SetOutput(os.Stderr)
+	}
+	if config.LogFile != "" {
+		logFile := &lumberjack.Logger{
+			Filename:   config.LogFile,
+			MaxSize:    config.LogMaxSize,
+			MaxBackups: logMaxBackups,
+		}
+		logger.SetOutput(logFile)
+		return logger, logFile
+	}
+	return logger, nil
+}
+
+// newDefaultAWSServices creates the AWS instance service for unqualified instance IDs and one service per region.
+// The services share a limiter, so ConcurrencyLimit caps the AWS API calls in flight across all regions together.
+func newDefaultAWSServices(config Config) (aws.InstanceServiceAPI, map[string]aws.InstanceServiceAPI, error) {
+	if config.AWSSource != "" {
+		return newFileAWSServices(config)
+	}
 
-	return NewService(
-		config,
-		awsService,
-		terraform.NewParserWithLogger(logger),
-		report.NewDefaultPrinter(),
-		logger,
-	), nil
+	opts := []aws.InstanceServiceOption{
+		aws.WithLimiter(aws.NewLimiter(config.ConcurrencyLimit)),
+		aws.WithRetryPolicy(retryPolicy(config)),
+		aws.WithEndpoint(aws.Endpoint{URL: config.AWSEndpointURL, UseFIPS: config.UseFIPS}),
+	}
+
+	// Create AWS instance service with default configuration
+	awsService, err := aws.NewInstanceServiceWithDefaultConfig(context.Background(), opts...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize AWS service: %w", err)
+	}
+
+	// Create one AWS instance service per region
+	regionalServices := make(map[string]aws.InstanceServiceAPI)
+	for _, region := range regionsToConfigure(config) {
+		regionalService, err := aws.NewInstanceServiceForRegion(context.Background(), region, opts...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize AWS service for region %s: %w", region, err)
+		}
+		regionalServices[region] = regionalService
+	}
+
+	// Every region of every account has its own service, with the credentials of the role of the account
+	accountServices, err := newAccountServices(config.Accounts, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	maps.Copy(regionalServices, accountServices)
+
+	// Unqualified instance IDs are checked in the first configured region, if any
+	var defaultService aws.InstanceServiceAPI = awsService
+	if len(config.Regions) > 0 {
+		defaultService = regionalServices[config.Regions[0]]
+	}
+	return defaultService, regionalServices, nil
+}
+
+// newFileAWSServices creates the services reading instances from the AWS source fixture instead of the AWS API.
+// The fixture is keyed by instance ID, so every region is served from it.
+func newFileAWSServices(config Config) (aws.InstanceServiceAPI, map[string]aws.InstanceServiceAPI, error) {
+	if !strings.HasPrefix(config.AWSSource, aws.FileSourcePrefix) {
+		return nil, nil, fmt.Errorf("unsupported AWS source %q: must be a %s URL", config.AWSSource, aws.FileSourcePrefix)
+	}
+	fileService, err := aws.NewFileInstanceService(config.AWSSource)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	regionalServices := make(map[string]aws.InstanceServiceAPI)
+	for _, region := range append(regionsToConfigure(config), accountScopes(config.Accounts)...) {
+		regionalServices[region] = fileService
+	}
+	return fileService, regionalServices, nil
+}
+
+// retryPolicy returns the policy for retrying failed AWS API calls, the default one unless configured otherwise
+func retryPolicy(config Config) aws.RetryPolicy {
+	policy := aws.DefaultRetryPolicy()
+	if config.RetryAttempts > 0 {
+		policy.MaxAttempts = config.RetryAttempts
+	}
+	if len(config.RetryOn) > 0 {
+		policy.Categories = make([]aws.ErrorCategory, len(config.RetryOn))
+		for i, category := range config.RetryOn {
+			policy.Categories[i] = aws.ErrorCategory(category)
+		}
+	}
+	return policy
+}
+
+// newDefaultParser creates the parser of the desired state: HCL, optionally with an attribute mapping, JSON or a plan
+func newDefaultParser(config Config, logger logging.Logger) (terraform.DesiredStateProvider, error) {
+	// The parsers provide the desired state and can be wrapped in a CachingParser
+	var terraformParser interface {
+		terraform.IProvider
+		terraform.DesiredStateProvider
+	}
+	// The files of a configuration directory are parsed concurrently, within ConcurrencyLimit
+	terraformParser = terraform.NewParserWithLogger(logger).WithConcurrency(config.ConcurrencyLimit)
+	if config.AttributeMappingPath != "" {
+		mapping, err := terraform.LoadAttributeMapping(config.AttributeMappingPath)
+		if err != nil {
+			return nil, err
+		}
+		terraformParser = terraform.NewParserWithAttributeMapping(logger, mapping).WithConcurrency(config.ConcurrencyLimit)
+	}
+	if config.LaunchTemplate != "" {
+		terraformParser = terraform.NewLaunchTemplateParserWithLogger(logger, config.LaunchTemplate)
+	}
+	if config.DesiredJSONPath != "" {
+		terraformParser = terraform.NewJSONParserWithLogger(logger)
+	}
+	if config.PlanJSONPath != "" {
+		terraformParser = terraform.NewPlanParserWithLogger(logger)
+	}
+	// Watch mode re-parses the configuration every cycle, so only do so when the file changed
+	if config.Watch {
+		terraformParser = terraform.NewCachingParser(terraformParser)
+	}
+	return terraformParser, nil
+}
+
+// newDefaultPrinter creates the report printer bound to the output format, writing to stdout, or to the output
+// file for document formats, and to the files of the additional reports. The report files are only created on
+// their first write, so a run rejected by validateConfig leaves existing reports in place. It returns the
+// files, which the caller must close.
+func newDefaultPrinter(config Config) (report.IPrinter, []io.Closer, error) {
+	colorMode, err := report.ParseColorMode(config.ColorMode)
+	if err != nil {
+		return nil, nil, err
+	}
+	maxColumnWidth, err := report.ResolveMaxColumnWidth(config.MaxColumnWidth, os.Stdout)
+	if err != nil {
+		return nil, nil, err
+	}
+	var tmpl *template.Template
+	if config.TemplateFile != "" {
+		if tmpl, err = report.ParseTemplateFile(config.TemplateFile); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var sinks []report.Sink
+	var files []io.Closer
+	for _, sink := range config.Reports {
+		format, err := report.ParseOutputFormat(sink.Format)
+		if err != nil {
+			return nil, files, fmt.Errorf("invalid report %s: %w", sink.File, err)
+		}
+		file := &lazyFile{path: sink.File}
+		files = append(files, file)
+		sinks = append(sinks, report.Sink{Format: format, Writer: file})
+	}
+
+	return report.NewFormatPrinter(parseOutputFormat(config.OutputFormat), os.Stdout, report.PrinterOptions{
+		Color:          report.ShouldColorize(colorMode, os.Stdout),
+		MaxColumnWidth: maxColumnWidth,
+		ConfigPath:     desiredStatePath(config),
+		OutputFile:     config.OutputFile,
+		JSONCompact:    config.JSONCompact,
+		JSONIndent:     config.JSONIndent,
+		Template:       tmpl,
+		DiffContext:    config.DiffContext,
+		// Without ShowAllAttributes, matches are only passed for the context of the diff
+		MatchesAsContext: !config.ShowAllAttributes,
+		Sinks:            sinks,
+	}), files, nil
+}
+
+// Close releases the resources opened by NewServiceWithOptions, such as the files of additional reports.
+// It must be called once the service is no longer used.
+func (s *Service) Close() error {
+	err := closeAll(s.closers)
+	s.closers = nil
+	return err
+}
+
+// lazyFile is a file created, truncating any existing one, on its first write
+type lazyFile struct {
+	path string
+	file *os.File
+	err  error
+}
+
+// Write implements io.Writer, creating the file first
+func (f *lazyFile) Write(p []byte) (int, error) {
+	if f.file == nil && f.err == nil {
+		f.file, f.err = os.Create(f.path)
+	}
+	if f.err != nil {
+		return 0, fmt.Errorf("failed to create report file: %w", f.err)
+	}
+	return f.file.Write(p)
+}
+
+// Close implements io.Closer, closing the file if it was created
+func (f *lazyFile) Close() error {
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Close()
+}
+
+// closeAll closes all closers and returns their errors joined
+func closeAll(closers []io.Closer) error {
+	var errs []error
+	for _, closer := range closers {
+		errs = append(errs, closer.Close())
+	}
+	return errors.Join(errs...)
 }
 
 // Run executes the drift detection workflow for all instances
+// It reports whether more instances drifted than MaxDrifts tolerates, and whether any instance could not be checked.
+// The errors of the instances that could not be checked are returned joined, as *InstanceError values wrapping
+// the underlying aws.Error or driftcheck.DriftError; any other error means the run itself failed.
+// The statistics of the run are available from Stats once it completes.
+// When the context is cancelled mid-run, the instances checked so far are still summarized and reported,
+// and the returned error wraps the context's error.
 func (s *Service) Run(ctx context.Context) (bool, bool, error) {
+	start := s.clock.Now()
 	s.logger.Info("Starting drift detection workflow")
 	s.logger.Debug("Configuration: %+v", s.config)
 	// Validate configuration
 	if err := s.validateConfig(); err != nil {
 		return false, true, err
 	}
+	if err := s.expandAttributeProfile(); err != nil {
+		return false, true, err
+	}
+	if err := s.checkAttributes(); err != nil {
+		return false, true, err
+	}
+	if err := s.parseEquivalentTypes(); err != nil {
+		return false, true, err
+	}
 
 	// Parse Terraform configuration (only once, shared across all instances)
-	tfConfig, err := s.parseTerrformConfig()
+	tfConfig, err := s.parseTerrformConfig(ctx)
 	if err != nil {
 		return false, true, err
 	}
 
 	// Process all instances concurrently and collect results
-	results, err := s.processAllInstances(ctx, tfConfig)
-	if err != nil {
-		return s.anyDriftDetected(results), true, err
+	results, err := s.processAllInstances(ctx, tfConfig, true)
+	interrupted := ctx.Err() != nil
+	if interrupted {
+		results = withoutCancelled(results)
+		s.logger.Warn("Drift detection interrupted, reporting the %d of %d instances checked so far",
+			len(results), len(s.instanceIDs))
+	} else if err != nil {
+		return s.driftExceedsMax(results), true, err
 	}
 
 	// Generate summary report
 	s.generateSummaryReport(results)
+	if s.config.GroupBy != "" {
+		if err := s.writeDriftGroups(os.Stdout, results); err != nil {
+			return s.driftExceedsMax(results), true, err
+		}
+	}
 
-	return s.anyDriftDetected(results), s.anyErrorsOccurred(results), nil
+	if err := s.flushReports(results); err != nil {
+		return s.driftExceedsMax(results), true, err
+	}
+
+	s.stats = s.collectRunStats(results, s.clock.Since(start))
+	s.logRunStats(s.stats)
+	// An interrupted run is incomplete, and its context no longer allows the request
+	if !interrupted {
+		s.notifyDrift(ctx, results, s.stats)
+	}
+	if s.config.MetricsFile != "" {
+		if err := s.writeMetricsFile(s.stats); err != nil {
+			return s.driftExceedsMax(results), true, err
+		}
+	}
+	if s.config.PrometheusTextfile != "" {
+		if err := s.writePrometheusTextfile(s.stats); err != nil {
+			return s.driftExceedsMax(results), true, err
+		}
+	}
+
+	if interrupted {
+		return s.driftExceedsMax(results), s.anyErrorsOccurred(results), fmt.Errorf("drift detection interrupted: %w", ctx.Err())
+	}
+	return s.driftExceedsMax(results), s.anyErrorsOccurred(results), instanceErrors(results)
 }
 
-// parseTerrformConfig parses the HCL configuration file at the specified path.
-// This is done once for all instances to avoid repeated parsing.
-func (s *Service) parseTerrformConfig() (*models.InstanceDetails, error) {
-	tfConfig, err := s.terraformParser.ParseHCLConfig(s.config.ConfigPath)
+// withoutCancelled drops the results of instances whose checks were cut short by cancellation,
+// as they were not checked rather than failed.
+func withoutCancelled(results []DriftDetectionResult) []DriftDetectionResult {
+	return slices.DeleteFunc(results, func(result DriftDetectionResult) bool {
+		return errors.Is(result.Error, context.Canceled) || errors.Is(result.Error, context.DeadlineExceeded)
+	})
+}
+
+// parseTerrformConfig parses the HCL configuration file, or desired state JSON file, at the specified path.
+// This is done once for all instances to avoid repeated parsing. With MatchByName every aws_instance resource
+// is parsed and keyed by its Name tag instead, and the returned configuration is nil.
+func (s *Service) parseTerrformConfig(ctx context.Context) (*models.InstanceDetails, error) {
+	if s.config.MatchBy == MatchByName {
+		return nil, s.parseResourcesByName()
+	}
+
+	tfConfig, err := s.desiredState.GetDesiredState(ctx, desiredStatePath(s.config))
 	if err != nil {
 		return nil, fmt.Errorf("error parsing Terraform configuration: %w", err)
 	}
+	if tfConfig == nil || tfConfig.IsEmpty() {
+		return nil, &DesiredStateUnavailableError{Path: desiredStatePath(s.config)}
+	}
 	return tfConfig, nil
 }
 
+// parseResourcesByName parses every aws_instance resource of the configuration and keys them by Name tag,
+// for desiredStateFor. Resources without a Name tag cannot be matched and are left out.
+func (s *Service) parseResourcesByName() error {
+	multiParser, ok := s.desiredState.(terraform.IMultiProvider)
+	if !ok {
+		return fmt.Errorf("matching instances by name is not supported by the configured parser")
+	}
+	resources, err := multiParser.ParseHCLConfigs(desiredStatePath(s.config))
+	if err != nil {
+		return fmt.Errorf("error parsing Terraform configuration: %w", err)
+	}
+
+	desiredByName := make(map[string]*models.InstanceDetails, len(resources))
+	for _, resource := range resources {
+		name := resource.Tags[nameTag]
+		if name == "" {
+			s.logger.Warn("Ignoring an aws_instance resource without a %s tag, which cannot be matched", nameTag)
+			continue
+		}
+		if _, exists := desiredByName[name]; exists {
+			return fmt.Errorf("error parsing Terraform configuration: multiple aws_instance resources are tagged %s=%q", nameTag, name)
+		}
+		desiredByName[name] = resource
+	}
+	s.desiredByName = desiredByName
+	return nil
+}
+
+// desiredStateFor returns the desired state to compare an instance with: tfConfig, or with MatchByName the
+// resource whose Name tag equals the instance's.
+func (s *Service) desiredStateFor(awsInstance, tfConfig *models.InstanceDetails) (*models.InstanceDetails, error) {
+	if s.config.MatchBy != MatchByName {
+		return tfConfig, nil
+	}
+	name := awsInstance.Tags[nameTag]
+	if name == "" {
+		return nil, fmt.Errorf("instance %s has no %s tag to match an aws_instance resource by", awsInstance.InstanceID, nameTag)
+	}
+	desired, ok := s.desiredByName[name]
+	if !ok {
+		return nil, fmt.Errorf("no aws_instance resource tagged %s=%q matches instance %s", nameTag, name, awsInstance.InstanceID)
+	}
+	return desired, nil
+}
+
 // processAllInstances handles the concurrent processing of all instances and result collection.
-// It returns the results and any error that occurred during processing.
-func (s *Service) processAllInstances(ctx context.Context, tfConfig *models.InstanceDetails) ([]DriftDetectionResult, error) {
-	s.logger.Debug("Fetching AWS instance details for %d instances", len(s.config.InstanceIDs))
+// When emitReports is false the per-instance reports are left to the caller.
+// It returns the results and any error that occurred during processing. Once the context is cancelled
+// no further instances are queued, and the results of the instances already processed are returned.
+func (s *Service) processAllInstances(ctx context.Context, tfConfig *models.InstanceDetails, emitReports bool) ([]DriftDetectionResult, error) {
+	// Durations are relative to the start of the run
+	launchWindow, err := s.resolveLaunchWindow(s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	s.launchWindow = launchWindow
+
+	// The members of an Auto Scaling group change over time, so they are resolved every run
+	var unlistedResults []DriftDetectionResult
+	s.instanceIDs, unlistedResults, err = s.resolveInstanceIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Debug("Fetching AWS instance details for %d instances", len(s.instanceIDs))
 	// Fetch AWS instance details
-	awsInstance, err := s.fetchAWSInstanceDetails(ctx, s.config.InstanceIDs)
+	awsInstance, failedResults, err := s.fetchAWSInstanceDetails(ctx, s.instanceIDs)
 	if err != nil {
 		return nil, err
 	}
+	// Regions of accounts that could not be listed are reported like instances that could not be fetched
+	failedResults = append(unlistedResults, failedResults...)
 
 	s.logger.Info("Fetched %d AWS instances", len(awsInstance))
+	s.recordProvenance(ctx, awsInstance, failedResults)
+
+	// Resolve the AMIs up front so each image is only described once
+	var amiImages map[string]*models.ImageDetails
+	if s.config.CheckAMIDeprecation {
+		amiImages, err = s.fetchAMIDetails(ctx, awsInstance)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Termination protection, user data and the shutdown behavior cost an API call per instance, so they are only
+	// fetched when explicitly requested. Instances whose attributes could not all be fetched are reported as
+	// errored rather than checked.
+	fetchFailed := make(map[string]error)
+	if driftcheck.AttributeRequested(s.config.AttributesToCheck, terminationProtectionAttribute) {
+		failed, err := s.fetchTerminationProtection(ctx, awsInstance)
+		if err != nil {
+			return nil, err
+		}
+		addFetchErrors(fetchFailed, failed)
+	}
+	if driftcheck.AttributeRequested(s.config.AttributesToCheck, userDataAttribute) {
+		failed, err := s.fetchUserData(ctx, awsInstance)
+		if err != nil {
+			return nil, err
+		}
+		addFetchErrors(fetchFailed, failed)
+	}
+	if driftcheck.AttributeRequested(s.config.AttributesToCheck, driftcheck.ShutdownBehaviorAttribute) {
+		failed, err := s.fetchShutdownBehavior(ctx, awsInstance)
+		if err != nil {
+			return nil, err
+		}
+		addFetchErrors(fetchFailed, failed)
+	}
+	if driftcheck.AttributeRequested(s.config.AttributesToCheck, cpuCreditsAttribute) {
+		failed, err := s.fetchCreditSpecifications(ctx, awsInstance)
+		if err != nil {
+			return nil, err
+		}
+		addFetchErrors(fetchFailed, failed)
+	}
+	if driftcheck.AttributeRequested(s.config.AttributesToCheck, blockDevicesAttribute) ||
+		driftcheck.AttributeRequested(s.config.AttributesToCheck, volumeTagsAttribute) {
+		failed, err := s.fetchVolumeDetails(ctx, awsInstance)
+		if err != nil {
+			return nil, err
+		}
+		addFetchErrors(fetchFailed, failed)
+	}
+
+	awsInstance, failedResults = withoutFetchFailures(awsInstance, fetchFailed, failedResults)
 
 	// Create a new error group for concurrent processing
 	g, _ := errgroup.WithContext(ctx)
@@ -133,22 +611,49 @@ func (s *Service) processAllInstances(ctx context.Context, tfConfig *models.Inst
 	// Channel to submit final aggregated results
 	resultChan := make(chan []DriftDetectionResult)
 
+	// Instances in excluded states, e.g. terminated, would only report misleading drift
+	var queued []*models.InstanceDetails
+	var skippedResults []DriftDetectionResult
+	for _, instance := range awsInstance {
+		if reason := s.skipReason(instance); reason != "" {
+			s.logger.Debug("Skipping instance %s excluded by its %s", instance.InstanceID, reason)
+			skippedResults = append(skippedResults, DriftDetectionResult{InstanceID: instance.InstanceID, Skipped: true, SkipReason: reason})
+			continue
+		}
+		queued = append(queued, instance)
+	}
+
 	// Consumer worker ready to aggregate results from driftReportChan
+	progress := newProgressCounter(s.config.Progress, s.progressOut, len(queued))
 	go func() {
 		// Submit final result to the result channel
-		resultChan <- s.collectResults(driftReportChan)
+		resultChan <- s.collectResults(driftReportChan, progress)
 	}()
 
+	process := s.processInstance
+	if !emitReports {
+		process = s.checkInstance
+	}
+
 	// Start a goroutine for each instance using the error group
-	for _, instance := range awsInstance {
+	for _, instance := range queued {
+		if ctx.Err() != nil {
+			s.logger.Debug("Context cancelled, not queuing the remaining instances")
+			break
+		}
+
 		// Add the task to the error group
 		// Since the error Group "Go" method is blocking depending on the ConcurrencyLimit set
 		// it's important that the consumer worker is started before the producer
 		s.logger.Debug("Queuing drift detection for instance %s", instance.InstanceID)
 		g.Go(func() error {
 			s.logger.Debug("Processing instance %s", instance.InstanceID)
-			// Process this instance
-			driftReportChan <- s.processInstance(instance, tfConfig)
+			// Process this instance, timing it to pinpoint slow ones
+			start := s.clock.Now()
+			result := process(instance, tfConfig, amiImages)
+			result.Duration = s.clock.Since(start)
+			s.warnIfSlow(result.Duration, "Processing instance %s", instance.InstanceID)
+			driftReportChan <- result
 			return nil
 		})
 	}
@@ -159,16 +664,55 @@ func (s *Service) processAllInstances(ctx context.Context, tfConfig *models.Inst
 	close(driftReportChan) // Close the channel to signal completion to the consumer
 	s.logger.Debug("All instance processing completed")
 
-	return <-resultChan, nil
+	// Instances that could not be fetched or were skipped are reported alongside the checked ones
+	results := append(<-resultChan, failedResults...)
+	return append(results, skippedResults...), nil
 }
 
-// collectResults gathers results from the result channel.
-func (s *Service) collectResults(resultChan <-chan DriftDetectionResult) []DriftDetectionResult {
-	results := make([]DriftDetectionResult, 0, len(s.config.InstanceIDs))
+// warnIfSlow logs a warning when an operation, described by the format and args, took longer than the
+// configured SlowThreshold.
+func (s *Service) warnIfSlow(elapsed time.Duration, format string, args ...interface{}) {
+	if s.config.SlowThreshold <= 0 || elapsed <= s.config.SlowThreshold {
+		return
+	}
+	s.logger.Warn("%s took %s, longer than the slow threshold of %s",
+		fmt.Sprintf(format, args...), elapsed.Round(time.Millisecond), s.config.SlowThreshold)
+}
+
+// skipReason returns why an instance is not checked, skipReasonState or skipReasonLaunchTime, or an empty
+// string if it is checked.
+func (s *Service) skipReason(instance *models.InstanceDetails) string {
+	if !s.stateIncluded(instance.State) {
+		return skipReasonState
+	}
+	if !s.launchWindow.includes(instance.LaunchTime) {
+		return skipReasonLaunchTime
+	}
+	return ""
+}
+
+// stateIncluded returns true if instances in the given lifecycle state should be checked.
+func (s *Service) stateIncluded(state string) bool {
+	if len(s.config.OnlyStates) == 0 {
+		return true
+	}
+	for _, included := range s.config.OnlyStates {
+		if strings.EqualFold(included, state) {
+			return true
+		}
+	}
+	return false
+}
+
+// collectResults gathers results from the result channel, counting them on progress if it is not nil.
+func (s *Service) collectResults(resultChan <-chan DriftDetectionResult, progress *progressCounter) []DriftDetectionResult {
+	results := make([]DriftDetectionResult, 0, len(s.instanceIDs))
 
 	for result := range resultChan {
 		results = append(results, result)
+		progress.increment()
 	}
+	progress.finish()
 
 	return results
 }
@@ -185,17 +729,52 @@ func (s *Service) anyDriftDetected(results []DriftDetectionResult) bool {
 	return false
 }
 
+// driftExceedsMax returns true if more instances have drift than the MaxDrifts tolerance allows.
+func (s *Service) driftExceedsMax(results []DriftDetectionResult) bool {
+	drifted := countDrifts(results)
+	if drifted > 0 && drifted <= s.config.MaxDrifts {
+		s.logger.Info("%d instances with drift are within the tolerance of %d", drifted, s.config.MaxDrifts)
+		return false
+	}
+	return drifted > s.config.MaxDrifts
+}
+
 // anyErrorsOccurred returns true if any instance processing resulted in an error.
 func (s *Service) anyErrorsOccurred(results []DriftDetectionResult) bool {
 	return countErrors(results) > 0
 }
 
-// processInstance handles drift detection for a single instance.
-func (s *Service) processInstance(awsInstance *models.InstanceDetails, tfConfig *models.InstanceDetails) DriftDetectionResult {
+// processInstance handles drift detection and reporting for a single instance.
+// amiImages holds the resolved AMIs keyed by image ID and is nil unless the AMI deprecation check is enabled.
+func (s *Service) processInstance(
+	awsInstance *models.InstanceDetails,
+	tfConfig *models.InstanceDetails,
+	amiImages map[string]*models.ImageDetails,
+) DriftDetectionResult {
+	result := s.checkInstance(awsInstance, tfConfig, amiImages)
+	if result.Error != nil {
+		return result
+	}
+
+	return s.reportInstance(result)
+}
+
+// checkInstance detects drift for a single instance without generating a report.
+func (s *Service) checkInstance(
+	awsInstance *models.InstanceDetails,
+	tfConfig *models.InstanceDetails,
+	amiImages map[string]*models.ImageDetails,
+) DriftDetectionResult {
 	result := DriftDetectionResult{
 		InstanceID: awsInstance.InstanceID,
 	}
 
+	tfConfig, err := s.desiredStateFor(awsInstance, tfConfig)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
 	// Detect drift between AWS and Terraform configurations
 	s.logger.Debug("Comparing AWS state with Terraform configuration for instance %s", awsInstance.InstanceID)
 	driftResult, err := s.detectInstanceDrift(awsInstance, tfConfig)
@@ -204,11 +783,35 @@ func (s *Service) processInstance(awsInstance *models.InstanceDetails, tfConfig
 		return result
 	}
 
+	if s.config.CheckAMIDeprecation {
+		s.checkAMIDeprecation(awsInstance, driftResult, amiImages)
+	}
+	if driftcheck.CheckAllowedAZ(driftResult, awsInstance.AvailabilityZone, s.config.AllowedAZs) {
+		s.logger.Warn("Instance %s is in availability zone %s, outside the allowed zones", awsInstance.InstanceID, awsInstance.AvailabilityZone)
+	}
+
+	for _, unmanaged := range driftcheck.ConvertToUnmanaged(driftResult) {
+		s.logger.Info("Instance %s: %s is not set in the Terraform configuration, unmanaged with %v on AWS",
+			awsInstance.InstanceID, unmanaged.Attribute, unmanaged.AWSValue)
+	}
+
 	result.HasDrift = driftResult.HasDrift
 	result.Result = driftResult
 
+	return result
+}
+
+// reportInstance generates the individual report for a successfully checked instance.
+// A report failure is recorded as the result's error.
+func (s *Service) reportInstance(result DriftDetectionResult) DriftDetectionResult {
+	// In quiet mode, or with SuppressEmpty, instances without drift are only counted in the summary
+	if (s.config.Quiet || s.config.SuppressEmpty) && !result.HasDrift {
+		s.logger.Debug("No drift for instance %s, skipping its empty report", result.InstanceID)
+		return result
+	}
+
 	// Generate individual report for this instance
-	if err := s.generateInstanceReport(awsInstance.InstanceID, driftResult); err != nil {
+	if err := s.generateInstanceReport(result.InstanceID, result.Result); err != nil {
 		result.Error = fmt.Errorf("error generating report: %w", err)
 	}
 
@@ -216,43 +819,524 @@ func (s *Service) processInstance(awsInstance *models.InstanceDetails, tfConfig
 }
 
 // fetchAWSInstanceDetails retrieves the current state of instances from AWS.
-func (s *Service) fetchAWSInstanceDetails(ctx context.Context, instanceIDs []string) ([]*models.InstanceDetails, error) {
-	awsInstances, err := s.awsSrv.GetInstancesDetails(ctx, instanceIDs)
+// Instances that could not be fetched are returned as results carrying the fetch error.
+func (s *Service) fetchAWSInstanceDetails(
+	ctx context.Context,
+	instanceIDs []string,
+) ([]*models.InstanceDetails, []DriftDetectionResult, error) {
+	awsInstances, failed, err := s.fetchRegionalInstanceDetails(ctx, instanceIDs)
 	if err != nil {
-		return nil, fmt.Errorf("error fetching AWS instance details: %w", err)
+		return nil, nil, fmt.Errorf("error fetching AWS instance details: %w", err)
+	}
+
+	failedResults := make([]DriftDetectionResult, 0, len(failed))
+	for _, id := range instanceIDs {
+		if fetchErr, ok := failed[id]; ok {
+			failedResults = append(failedResults, DriftDetectionResult{
+				InstanceID: id,
+				Error:      fmt.Errorf("error fetching AWS instance details: %w", fetchErr),
+			})
+		}
+	}
+	return awsInstances, failedResults, nil
+}
+
+// fetchAMIDetails resolves the distinct AMIs used by the given instances, keyed by image ID.
+// AMIs are regional, so each one is resolved through the service of the region its instance was fetched from.
+// Images that cannot be described, e.g. deregistered AMIs, are left out, so their instances are still checked
+// without the deprecation policy.
+func (s *Service) fetchAMIDetails(ctx context.Context, instances []*models.InstanceDetails) (map[string]*models.ImageDetails, error) {
+	var regions []string
+	imageIDsByRegion := make(map[string][]string)
+	seen := make(map[string]bool, len(instances))
+	for _, instance := range instances {
+		if instance.AMI == "" || seen[instance.AMI] {
+			continue
+		}
+		seen[instance.AMI] = true
+		if _, exists := imageIDsByRegion[instance.Region]; !exists {
+			regions = append(regions, instance.Region)
+		}
+		imageIDsByRegion[instance.Region] = append(imageIDsByRegion[instance.Region], instance.AMI)
+	}
+
+	images := make(map[string]*models.ImageDetails, len(seen))
+	for _, region := range regions {
+		awsSrv, err := s.serviceForRegion(region)
+		if err != nil {
+			return nil, err
+		}
+
+		s.logger.Debug("Fetching AMI details for %d images", len(imageIDsByRegion[region]))
+		details, err := awsSrv.GetImagesDetails(ctx, imageIDsByRegion[region])
+		if err != nil {
+			s.logger.Warn("Error fetching AMI details of %s, skipping their deprecation check: %v",
+				strings.Join(imageIDsByRegion[region], ", "), err)
+			continue
+		}
+		for _, image := range details {
+			images[image.ImageID] = image
+		}
+	}
+	return images, nil
+}
+
+// fetchVolumeDetails fills in the size, type and tags of the volumes attached to the given instances, the root volume
+// included. Like AMIs,
+// volumes are regional and are described in batches through the service of their instance's region. When the
+// volumes of a region cannot be described, e.g. because one was detached during the run, the error is returned
+// for each instance of that region with volumes, keyed by instance ID, and the other regions are still filled in.
+func (s *Service) fetchVolumeDetails(ctx context.Context, instances []*models.InstanceDetails) (map[string]error, error) {
+	var regions []string
+	volumeIDsByRegion := make(map[string][]string)
+	instanceIDsByRegion := make(map[string][]string)
+	for _, instance := range instances {
+		if s.skipReason(instance) != "" {
+			continue
+		}
+		for _, device := range instance.Volumes() {
+			if device.VolumeID == "" {
+				continue
+			}
+			if _, exists := volumeIDsByRegion[instance.Region]; !exists {
+				regions = append(regions, instance.Region)
+			}
+			volumeIDsByRegion[instance.Region] = append(volumeIDsByRegion[instance.Region], device.VolumeID)
+			if !slices.Contains(instanceIDsByRegion[instance.Region], instance.InstanceID) {
+				instanceIDsByRegion[instance.Region] = append(instanceIDsByRegion[instance.Region], instance.InstanceID)
+			}
+		}
+	}
+
+	volumes := make(map[string]*models.VolumeDetails)
+	failed := make(map[string]error)
+	for _, region := range regions {
+		awsSrv, err := s.serviceForRegion(region)
+		if err != nil {
+			return nil, err
+		}
+
+		s.logger.Debug("Fetching volume details for %d volumes", len(volumeIDsByRegion[region]))
+		details, err := awsSrv.GetVolumesDetails(ctx, volumeIDsByRegion[region])
+		if err != nil {
+			for _, id := range instanceIDsByRegion[region] {
+				failed[id] = fmt.Errorf("error fetching volume details: %w", err)
+			}
+			continue
+		}
+		for _, volume := range details {
+			volumes[volume.VolumeID] = volume
+		}
+	}
+
+	for _, instance := range instances {
+		for _, device := range instance.Volumes() {
+			if volume, ok := volumes[device.VolumeID]; ok {
+				device.VolumeSize = volume.VolumeSize
+				device.VolumeType = volume.VolumeType
+				device.VolumeTags = volume.Tags
+			}
+		}
+	}
+	return failed, nil
+}
+
+// fetchCreditSpecifications fills in the CPU credits of the given burstable instances. Like volumes, credit
+// specifications are described in batches through the service of each instance's region, and the error of a
+// region that cannot be described is returned for each of its burstable instances, keyed by instance ID.
+func (s *Service) fetchCreditSpecifications(ctx context.Context, instances []*models.InstanceDetails) (map[string]error, error) {
+	var regions []string
+	instanceIDsByRegion := make(map[string][]string)
+	for _, instance := range instances {
+		// Other instance types have no credit specification, which AWS reports as an error
+		if s.skipReason(instance) != "" || !isBurstableInstanceType(instance.InstanceType) {
+			continue
+		}
+		if _, exists := instanceIDsByRegion[instance.Region]; !exists {
+			regions = append(regions, instance.Region)
+		}
+		instanceIDsByRegion[instance.Region] = append(instanceIDsByRegion[instance.Region], instance.InstanceID)
+	}
+
+	cpuCredits := make(map[string]string)
+	failed := make(map[string]error)
+	for _, region := range regions {
+		awsSrv, err := s.serviceForRegion(region)
+		if err != nil {
+			return nil, err
+		}
+
+		s.logger.Debug("Fetching credit specifications for %d instances", len(instanceIDsByRegion[region]))
+		regionCredits, err := awsSrv.GetCreditSpecifications(ctx, instanceIDsByRegion[region])
+		if err != nil {
+			for _, id := range instanceIDsByRegion[region] {
+				failed[id] = fmt.Errorf("error fetching credit specifications: %w", err)
+			}
+			continue
+		}
+		maps.Copy(cpuCredits, regionCredits)
+	}
+
+	for _, instance := range instances {
+		instance.CPUCredits = cpuCredits[instance.InstanceID]
+	}
+	return failed, nil
+}
+
+// burstableInstanceTypePattern matches the burstable performance instance families t1, t2, t3, t3a and t4g,
+// and not other families starting with t, such as the Trainium trn1
+var burstableInstanceTypePattern = regexp.MustCompile(`^t[0-9]`)
+
+// isBurstableInstanceType returns true for burstable performance instance types, such as t3.micro, which
+// accrue CPU credits
+func isBurstableInstanceType(instanceType string) bool {
+	return burstableInstanceTypePattern.MatchString(instanceType)
+}
+
+// fetchTerminationProtection fills in whether termination protection is enabled for the given instances,
+// through the service of the region each instance was fetched from. Skipped instances are left out.
+func (s *Service) fetchTerminationProtection(ctx context.Context, instances []*models.InstanceDetails) (map[string]error, error) {
+	return s.fetchPerInstance(ctx, instances, func(ctx context.Context, awsSrv aws.InstanceServiceAPI, instance *models.InstanceDetails) error {
+		disabled, err := awsSrv.GetDisableApiTermination(ctx, instance.InstanceID)
+		if err != nil {
+			return fmt.Errorf("error fetching termination protection of instance %s: %w", instance.InstanceID, err)
+		}
+		instance.DisableApiTermination = &disabled
+		return nil
+	})
+}
+
+// fetchUserData fills in the user data of the given instances, leaving out skipped instances.
+func (s *Service) fetchUserData(ctx context.Context, instances []*models.InstanceDetails) (map[string]error, error) {
+	return s.fetchPerInstance(ctx, instances, func(ctx context.Context, awsSrv aws.InstanceServiceAPI, instance *models.InstanceDetails) error {
+		userData, err := awsSrv.GetUserData(ctx, instance.InstanceID)
+		if err != nil {
+			return fmt.Errorf("error fetching user data of instance %s: %w", instance.InstanceID, err)
+		}
+		instance.UserData = &userData
+		return nil
+	})
+}
+
+// fetchShutdownBehavior fills in whether the given instances stop or terminate on shutdown, leaving out skipped instances.
+func (s *Service) fetchShutdownBehavior(ctx context.Context, instances []*models.InstanceDetails) (map[string]error, error) {
+	return s.fetchPerInstance(ctx, instances, func(ctx context.Context, awsSrv aws.InstanceServiceAPI, instance *models.InstanceDetails) error {
+		behavior, err := awsSrv.GetInstanceInitiatedShutdownBehavior(ctx, instance.InstanceID)
+		if err != nil {
+			return fmt.Errorf("error fetching shutdown behavior of instance %s: %w", instance.InstanceID, err)
+		}
+		instance.InstanceInitiatedShutdownBehavior = behavior
+		return nil
+	})
+}
+
+// fetchPerInstance calls fetch concurrently for each instance that is not skipped, with the AWS service
+// of the instance's region, within the concurrency limit. A failed fetch does not stop the others: the
+// errors are returned keyed by instance ID.
+func (s *Service) fetchPerInstance(
+	ctx context.Context,
+	instances []*models.InstanceDetails,
+	fetch func(ctx context.Context, awsSrv aws.InstanceServiceAPI, instance *models.InstanceDetails) error,
+) (map[string]error, error) {
+	var g errgroup.Group
+	if s.config.ConcurrencyLimit > 0 {
+		g.SetLimit(s.config.ConcurrencyLimit)
+	}
+
+	var mu sync.Mutex
+	failed := make(map[string]error)
+	for _, instance := range instances {
+		if s.skipReason(instance) != "" {
+			continue
+		}
+		awsSrv, err := s.serviceForRegion(instance.Region)
+		if err != nil {
+			_ = g.Wait()
+			return nil, err
+		}
+
+		g.Go(func() error {
+			if err := fetch(ctx, awsSrv, instance); err != nil {
+				mu.Lock()
+				failed[instance.InstanceID] = err
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
+	return failed, nil
+}
+
+// addFetchErrors adds the fetch errors of instances to failed, keeping the first error of an instance
+func addFetchErrors(failed, errs map[string]error) {
+	for id, err := range errs {
+		if _, exists := failed[id]; !exists {
+			failed[id] = err
+		}
+	}
+}
+
+// withoutFetchFailures drops the instances whose attributes could not all be fetched, as comparing them would
+// report misleading drift, and returns them as results carrying the fetch error next to failedResults.
+func withoutFetchFailures(
+	instances []*models.InstanceDetails,
+	fetchFailed map[string]error,
+	failedResults []DriftDetectionResult,
+) ([]*models.InstanceDetails, []DriftDetectionResult) {
+	if len(fetchFailed) == 0 {
+		return instances, failedResults
+	}
+	checked := make([]*models.InstanceDetails, 0, len(instances))
+	for _, instance := range instances {
+		if err, ok := fetchFailed[instance.InstanceID]; ok {
+			failedResults = append(failedResults, DriftDetectionResult{InstanceID: instance.InstanceID, Error: err})
+			continue
+		}
+		checked = append(checked, instance)
+	}
+	return checked, failedResults
+}
+
+// checkAMIDeprecation applies the AMI deprecation policy to the drift result of a single instance.
+func (s *Service) checkAMIDeprecation(awsInstance *models.InstanceDetails, driftResult *driftcheck.DriftResult, amiImages map[string]*models.ImageDetails) {
+	image, ok := amiImages[awsInstance.AMI]
+	if !ok {
+		s.logger.Debug("AMI %s of instance %s could not be resolved, skipping deprecation check", awsInstance.AMI, awsInstance.InstanceID)
+		return
+	}
+	if driftcheck.CheckAMIDeprecation(driftResult, image, s.clock.Now()) {
+		s.logger.Warn("Instance %s is running deprecated AMI %s", awsInstance.InstanceID, image.ImageID)
 	}
-	return awsInstances, nil
 }
 
 // detectInstanceDrift checks for differences between the actual AWS instance state
 // and the desired state defined in Terraform.
 func (s *Service) detectInstanceDrift(awsInstance, tfConfig *models.InstanceDetails) (*driftcheck.DriftResult, error) {
-	driftResult, err := driftcheck.DetectDrift(awsInstance, tfConfig, s.config.AttributesToCheck)
+	// The attributes were validated up front by checkAttributes, so errors here are specific to the instance
+	driftResult, err := driftcheck.DetectDriftWithOptions(s.securityGroupView(awsInstance), tfConfig, driftcheck.DetectOptions{
+		Attributes:         s.config.AttributesToCheck,
+		StrictAttributes:   s.config.StrictAttributes,
+		EquivalentTypes:    s.equivalentTypes,
+		TagsMode:           driftcheck.TagsMode(strings.ToLower(s.config.TagsMode)),
+		IgnoreTagValueCase: s.config.IgnoreTagValueCase,
+		SkipUnset:          s.config.SkipUnsetAttributes,
+		IncludeVolatile:    s.config.IncludeVolatile,
+		OnlyManaged:        s.config.OnlyManaged,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("error detecting drift: %w", err)
 	}
 	return driftResult, nil
 }
 
-// getOutputFormat converts the string format to report.OutputFormatType.
+// securityGroupView returns the AWS instance as it should be compared against Terraform.
+// When matching by name, the security group IDs are replaced with their names on a copy of the instance,
+// so configurations that reference groups by name do not report false drift.
+func (s *Service) securityGroupView(awsInstance *models.InstanceDetails) *models.InstanceDetails {
+	if strings.ToLower(s.config.SecurityGroupMatchBy) != SecurityGroupMatchByName {
+		return awsInstance
+	}
+
+	view := *awsInstance
+	view.SecurityGroups = awsInstance.SecurityGroupNames
+	return &view
+}
+
+// getOutputFormat returns the output format the reports are printed in.
 func (s *Service) getOutputFormat() report.OutputFormatType {
-	switch strings.ToUpper(s.config.OutputFormat) {
-	case "JSON":
-		return report.OutputFormatTypeJSON
-	default:
+	return s.outputFormat
+}
+
+// parseOutputFormat converts the string format to report.OutputFormatType.
+func parseOutputFormat(name string) report.OutputFormatType {
+	format, err := report.ParseOutputFormat(name)
+	if err != nil {
 		// Default to table format for better human readability
 		return report.OutputFormatTypeTABLE
 	}
+	return format
 }
 
 // validateConfig checks if the required configuration is provided.
 func (s *Service) validateConfig() error {
-	if len(s.config.InstanceIDs) == 0 {
-		return fmt.Errorf("at least one instance ID is required")
+	if len(s.config.InstanceIDs) == 0 && s.config.AutoScalingGroup == "" && len(s.config.Accounts) == 0 {
+		return fmt.Errorf("at least one instance ID, an Auto Scaling group or an account is required")
+	}
+	if err := validateAccounts(s.config.Accounts); err != nil {
+		return err
+	}
+	for _, id := range s.config.InstanceIDs {
+		if err := validateRegionalInstanceID(id); err != nil {
+			return err
+		}
 	}
-	if s.config.ConfigPath == "" {
+	if err := validateInstanceIDFormat(s.config.InstanceIDs); err != nil {
+		return err
+	}
+	// Each instance is checked and counted once, however many times it is listed
+	instanceIDs, duplicates := uniqueInstanceIDs(s.config.InstanceIDs)
+	if len(duplicates) > 0 {
+		s.logger.Warn("Ignoring duplicate instance IDs: %s", strings.Join(duplicates, ", "))
+		s.config.InstanceIDs = instanceIDs
+	}
+	desiredStateSources := 0
+	for _, path := range []string{s.config.ConfigPath, s.config.DesiredJSONPath, s.config.PlanJSONPath} {
+		if path != "" {
+			desiredStateSources++
+		}
+	}
+	if desiredStateSources == 0 {
 		return fmt.Errorf("terraform configuration path is required")
 	}
+	if desiredStateSources > 1 {
+		return fmt.Errorf("terraform configuration path, desired state JSON path and plan JSON path are mutually exclusive")
+	}
+	if s.config.LaunchTemplate != "" && s.config.ConfigPath == "" {
+		return fmt.Errorf("a launch template is read from the terraform configuration, which is required")
+	}
+	if s.config.LaunchTemplate != "" && s.config.AttributeMappingPath != "" {
+		return fmt.Errorf("an attribute mapping is not supported for launch templates")
+	}
+	if s.config.RetryAttempts < 0 {
+		return fmt.Errorf("retry attempts must not be negative")
+	}
+	for _, category := range s.config.RetryOn {
+		if err := aws.ValidateRetryCategory(aws.ErrorCategory(category)); err != nil {
+			return fmt.Errorf("invalid retry category: %w", err)
+		}
+	}
+	if _, err := s.resolveLaunchWindow(s.clock.Now()); err != nil {
+		return err
+	}
+	if s.config.SlowThreshold < 0 {
+		return fmt.Errorf("slow threshold must not be negative, got %s", s.config.SlowThreshold)
+	}
+	if s.config.MaxDrifts < 0 {
+		return fmt.Errorf("max drifts must not be negative")
+	}
+	if s.config.DiffContext < 0 {
+		return fmt.Errorf("diff context must not be negative, got %d", s.config.DiffContext)
+	}
+	if s.config.LogMaxSize < 0 {
+		return fmt.Errorf("log max size must not be negative, got %d", s.config.LogMaxSize)
+	}
+	if s.config.NotifyWebhook != "" && !strings.HasPrefix(s.config.NotifyWebhook, "https://") &&
+		!strings.HasPrefix(s.config.NotifyWebhook, "http://") {
+		// The URL is left out, as it is often the secret of the webhook
+		return fmt.Errorf("notification webhook must be an http:// or https:// URL")
+	}
+	if s.config.AWSEndpointURL != "" && !strings.HasPrefix(s.config.AWSEndpointURL, "https://") &&
+		!strings.HasPrefix(s.config.AWSEndpointURL, "http://") {
+		return fmt.Errorf("AWS endpoint URL must be an http:// or https:// URL, got %q", s.config.AWSEndpointURL)
+	}
+	// An endpoint URL serves a single region, so it cannot be shared by the services of several regions
+	if s.config.AWSEndpointURL != "" && len(regionsToConfigure(s.config))+len(accountScopes(s.config.Accounts)) > 1 {
+		return fmt.Errorf("an AWS endpoint URL can only be used to check instances of a single region")
+	}
+	if err := s.validateGroupBy(); err != nil {
+		return err
+	}
+	if s.config.JSONIndent < 0 {
+		return fmt.Errorf("JSON indent must not be negative, got %d", s.config.JSONIndent)
+	}
+	if s.config.OutputFile != "" && !report.IsDocumentFormat(s.getOutputFormat()) {
+		return fmt.Errorf("an output file is only supported for the json, sarif, html, junit and template output formats")
+	}
+	if err := s.validateTemplateFile(); err != nil {
+		return err
+	}
+	for _, sink := range s.config.Reports {
+		if _, err := report.ParseOutputFormat(sink.Format); err != nil {
+			return fmt.Errorf("invalid report %s: %w", sink.File, err)
+		}
+		if sink.File == "" {
+			return fmt.Errorf("a file is required for the additional %s report", sink.Format)
+		}
+	}
+	if len(s.config.Reports) > 0 && s.config.Watch {
+		return fmt.Errorf("additional reports are not supported in watch mode")
+	}
+	for _, state := range s.config.OnlyStates {
+		if !slices.Contains(instanceStates, strings.ToLower(state)) {
+			return fmt.Errorf("invalid instance state %q: must be one of %s", state, strings.Join(instanceStates, ", "))
+		}
+	}
+	switch s.config.MatchBy {
+	case MatchByNone:
+	case MatchByName:
+		if (s.config.ConfigPath == "" && s.config.PlanJSONPath == "") || s.config.LaunchTemplate != "" {
+			return fmt.Errorf("matching instances by name requires the aws_instance resources of a terraform configuration or plan")
+		}
+	default:
+		return fmt.Errorf("invalid match mode %q: must be %q", s.config.MatchBy, MatchByName)
+	}
+	switch strings.ToLower(s.config.SecurityGroupMatchBy) {
+	case "", SecurityGroupMatchByID, SecurityGroupMatchByName:
+	default:
+		return fmt.Errorf("invalid security group match mode %q: must be %q or %q",
+			s.config.SecurityGroupMatchBy, SecurityGroupMatchByID, SecurityGroupMatchByName)
+	}
+	if slices.Contains(s.config.AllowedAZs, "") {
+		return fmt.Errorf("allowed availability zones cannot be empty")
+	}
+	if _, err := driftcheck.ParseTagsMode(s.config.TagsMode); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validateTemplateFile checks that a template file is given when the output format or an additional report
+// is rendered with a template, and that one is not given needlessly.
+func (s *Service) validateTemplateFile() error {
+	usesTemplate := s.getOutputFormat() == report.OutputFormatTypeTemplate
+	for _, sink := range s.config.Reports {
+		if format, err := report.ParseOutputFormat(sink.Format); err == nil && format == report.OutputFormatTypeTemplate {
+			usesTemplate = true
+		}
+	}
+	if usesTemplate && s.config.TemplateFile == "" {
+		return fmt.Errorf("a template file is required for the template output format")
+	}
+	if !usesTemplate && s.config.TemplateFile != "" {
+		return fmt.Errorf("a template file is only used by the template output format")
+	}
+	return nil
+}
+
+// checkAttributes validates the attributes to check once, before any instance is processed, since an
+// unsupported attribute is a configuration mistake rather than a problem of a single instance.
+// In lenient mode unsupported attributes are logged and dropped, as long as a supported one remains;
+// otherwise, and for empty attribute names, the run fails fast.
+func (s *Service) checkAttributes() error {
+	if len(s.config.AttributesToCheck) == 0 {
+		return nil
+	}
+
+	supported, err := driftcheck.SupportedAttributes(s.config.AttributesToCheck)
+	if err == nil {
+		return nil
+	}
+
+	unsupported, rest := driftcheck.SplitErrorCategory(err, driftcheck.ErrResourceMissing)
+	if s.config.StrictAttributes || rest != nil || len(supported) == 0 {
+		return fmt.Errorf("invalid attributes to check: %w", err)
+	}
+
+	for _, warning := range unsupported {
+		s.logger.Warn("Skipping unsupported attribute %q", warning.Attribute)
+	}
+	s.config.AttributesToCheck = supported
+	return nil
+}
+
+// parseEquivalentTypes parses the groups of interchangeable instance types once, before any instance is processed.
+func (s *Service) parseEquivalentTypes() error {
+	equivalentTypes, err := driftcheck.ParseInstanceTypeEquivalences(s.config.EquivalentTypes)
+	if err != nil {
+		return fmt.Errorf("invalid equivalent instance types: %w", err)
+	}
+	s.equivalentTypes = equivalentTypes
 	return nil
 }
 
@@ -264,10 +1348,58 @@ func (s *Service) generateInstanceReport(instanceID string, driftResult *driftch
 	// Determine the output format from the configuration
 	format := s.getOutputFormat()
 
+	// Attributes left to AWS are listed too, so the report shows what the configuration does not manage.
+	// They are recorded even when there are none, clearing those of a previous watch cycle.
+	if recorder, ok := s.reportPrinter.(report.IUnmanagedRecorder); ok {
+		recorder.SetUnmanaged(instanceID, driftcheck.ConvertToUnmanaged(driftResult))
+	}
+
+	// List the attributes that passed too, for printers that can show them or print them as context of the diff
+	if matchPrinter, ok := s.reportPrinter.(report.IMatchPrinter); ok && (s.config.ShowAllAttributes || s.config.DiffContext > 0) {
+		return matchPrinter.PrintReportWithMatches(instanceID, drifts, driftcheck.ConvertToMatches(driftResult), format)
+	}
+
 	// Generate and print the report using the configured printer
 	return s.reportPrinter.PrintReport(instanceID, drifts, format)
 }
 
+// flushReports reports the errored results, for printers that include them, then writes the reports
+// buffered by printers of single-document formats such as SARIF and HTML.
+func (s *Service) flushReports(results []DriftDetectionResult) error {
+	format := s.getOutputFormat()
+	if errorReporter, ok := s.reportPrinter.(report.IErrorReporter); ok {
+		for _, result := range results {
+			if result.Error == nil {
+				continue
+			}
+			if err := errorReporter.ReportError(result.InstanceID, result.Error, format); err != nil {
+				return fmt.Errorf("error writing report: %w", err)
+			}
+		}
+	}
+	if !report.IsDocumentFormat(format) && !s.hasDocumentReports() {
+		return nil
+	}
+	flusher, ok := s.reportPrinter.(report.IFlusher)
+	if !ok {
+		return nil
+	}
+	if err := flusher.Flush(format); err != nil {
+		return fmt.Errorf("error writing report: %w", err)
+	}
+	return nil
+}
+
+// hasDocumentReports returns true if one of the additional reports has a document format
+func (s *Service) hasDocumentReports() bool {
+	for _, sink := range s.config.Reports {
+		if format, err := report.ParseOutputFormat(sink.Format); err == nil && report.IsDocumentFormat(format) {
+			return true
+		}
+	}
+	return false
+}
+
 // generateSummaryReport generates a summary report for all instances.
 // This gives an overview of the drift detection results across all instances,
 // which is particularly useful when checking multiple instances at once.
@@ -276,22 +1408,116 @@ func (s *Service) generateSummaryReport(results []DriftDetectionResult) {
 	errCount := countErrors(results)
 	if errCount > 0 {
 		for _, r := range results {
-			if r.Error != nil {
-				// Log each error with the associated instance ID for easier troubleshooting
+			if r.Error == nil {
+				continue
+			}
+			// Log each error with the associated instance ID, and its category so failures can be grouped
+			var awsErr *aws.Error
+			if errors.As(r.Error, &awsErr) {
+				s.logger.Error("Instance %s: Error [%s] - %s", r.InstanceID, awsErr.Category, r.Error)
+			} else {
 				s.logger.Error("Instance %s: Error - %s", r.InstanceID, r.Error)
 			}
 		}
 	}
 
 	// Only generate a summary if more than one instance was checked
-	// For a single instance, the detailed report is sufficient
-	if len(results) > 1 {
-		s.logger.Info("Summary: Checked %d instances, %d with drift, %d with errors",
-			len(results),
-			countDrifts(results),
-			errCount,
-		)
+	// For a single instance, the detailed report is sufficient unless quiet or watch mode suppressed it
+	if len(results) > 1 || s.config.Quiet || s.config.Watch {
+		skipped := countSkipped(results)
+		checked := len(results) - skipped
+		drifted := countDrifts(results)
+		format := "Summary: Checked %d instances, %d with drift (%.1f%%), %d with errors"
+		args := []interface{}{checked, drifted, driftRate(drifted, checked), errCount}
+		// Instances skipped for each reason are counted separately
+		for _, reason := range []string{skipReasonState, skipReasonLaunchTime} {
+			if count := countSkippedBy(results, reason); count > 0 {
+				format += ", %d skipped by " + reason
+				args = append(args, count)
+			}
+		}
+		s.logger.Info(format, args...)
+
+		// Break the drift down by attribute, to show which attributes drift most often across the fleet
+		if drifted > 0 {
+			s.logger.Info("Drift by attribute: %s", formatAttributeDriftCounts(countAttributeDrifts(results)))
+		}
+	}
+}
+
+// driftRate returns the percentage of checked instances with drift.
+func driftRate(drifted, checked int) float64 {
+	if checked == 0 {
+		return 0
+	}
+	return float64(drifted) * 100 / float64(checked)
+}
+
+// countAttributeDrifts counts, for each attribute, the instances with drift in that attribute.
+// Keyed attributes such as tags count once per instance, whichever of their keys drifted.
+func countAttributeDrifts(results []DriftDetectionResult) map[string]int {
+	counts := make(map[string]int)
+	for _, r := range results {
+		if !r.HasDrift || r.Result == nil {
+			continue
+		}
+		attributes := make(map[string]bool)
+		for _, drift := range r.Result.Drifts {
+			attribute, _, _ := strings.Cut(drift.Attribute, ".")
+			attributes[attribute] = true
+		}
+		for attribute := range attributes {
+			counts[attribute]++
+		}
+	}
+	return counts
+}
+
+// formatAttributeDriftCounts formats the counts as "instance_type: 12 instances, tags: 5 instances",
+// most drifted attributes first.
+func formatAttributeDriftCounts(counts map[string]int) string {
+	attributes := make([]string, 0, len(counts))
+	for attribute := range counts {
+		attributes = append(attributes, attribute)
+	}
+	slices.SortFunc(attributes, func(a, b string) int {
+		if counts[a] != counts[b] {
+			return counts[b] - counts[a]
+		}
+		return strings.Compare(a, b)
+	})
+
+	parts := make([]string, len(attributes))
+	for i, attribute := range attributes {
+		noun := "instances"
+		if counts[attribute] == 1 {
+			noun = "instance"
+		}
+		parts[i] = fmt.Sprintf("%s: %d %s", attribute, counts[attribute], noun)
 	}
+	return strings.Join(parts, ", ")
+}
+
+// countSkipped counts the number of instances skipped for any reason.
+func countSkipped(results []DriftDetectionResult) int {
+	count := 0
+	for _, r := range results {
+		if r.Skipped {
+			count++
+		}
+	}
+	return count
+}
+
+// countSkippedBy counts the number of instances skipped for the given reason.
+func countSkippedBy(results []DriftDetectionResult, reason string) int {
+	count := 0
+	for _, r := range results {
+		if r.Skipped && r.SkipReason == reason {
+			count++
+		}
+	}
+	return count
 }
 
 // countDrifts counts the number of instances with drift.
@@ -305,6 +1531,17 @@ func countDrifts(results []DriftDetectionResult) int {
 	return count
 }
 
+// instanceErrors joins the errors of the instances that could not be checked, nil if there are none.
+func instanceErrors(results []DriftDetectionResult) error {
+	var errs []error
+	for _, r := range results {
+		if r.Error != nil {
+			errs = append(errs, &InstanceError{InstanceID: r.InstanceID, Err: r.Error})
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // countErrors counts the number of instances with errors.
 func countErrors(results []DriftDetectionResult) int {
 	count := 0
@@ -315,3 +1552,14 @@ func countErrors(results []DriftDetectionResult) int {
 	}
 	return count
 }
+
+// desiredStatePath returns the file the desired instance details are read from.
+func desiredStatePath(config Config) string {
+	if config.DesiredJSONPath != "" {
+		return config.DesiredJSONPath
+	}
+	if config.PlanJSONPath != "" {
+		return config.PlanJSONPath
+	}
+	return config.ConfigPath
+}
@@ -1,18 +1,27 @@
 package orchestrator
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"driftdetector/internal/driftcheck"
 	"driftdetector/internal/models"
+	awsProvider "driftdetector/internal/providers/aws"
 	awsMocks "driftdetector/internal/providers/aws/mocks"
 	"driftdetector/internal/report"
 	reportMocks "driftdetector/internal/report/mocks"
+	"driftdetector/internal/terraform"
 	terraformMocks "driftdetector/internal/terraform/mocks"
 	"driftdetector/pkg/logging"
 	loggerMocks "driftdetector/pkg/logging/mocks"
@@ -21,8 +30,8 @@ import (
 // createMocks is a helper function to create mock instances for testing
 // It initializes all the required dependencies with mocks that can be configured
 // with expectations for each test case.
-func createMocks(t *testing.T) (*awsMocks.InstanceServiceAPI, *terraformMocks.IProvider, *reportMocks.IPrinter, logging.Logger) {
-	parserMock := terraformMocks.NewIProvider(t)
+func createMocks(t *testing.T) (*awsMocks.InstanceServiceAPI, *terraformMocks.DesiredStateProvider, *reportMocks.IPrinter, logging.Logger) {
+	parserMock := terraformMocks.NewDesiredStateProvider(t)
 	instanceMock := awsMocks.NewInstanceServiceAPI(t)
 	reportMock := reportMocks.NewIPrinter(t)
 	loggerMock := logging.NewMockLogger()
@@ -31,7 +40,7 @@ func createMocks(t *testing.T) (*awsMocks.InstanceServiceAPI, *terraformMocks.IP
 }
 
 // setupServiceWithMocks creates a new Service instance with the provided configuration and mocks
-func setupServiceWithMocks(t *testing.T, config Config) (*Service, *awsMocks.InstanceServiceAPI, *terraformMocks.IProvider, *reportMocks.IPrinter) {
+func setupServiceWithMocks(t *testing.T, config Config) (*Service, *awsMocks.InstanceServiceAPI, *terraformMocks.DesiredStateProvider, *reportMocks.IPrinter) {
 	instanceMock, parserMock, reportMock, loggerMock := createMocks(t)
 	service := NewService(config, instanceMock, parserMock, reportMock, loggerMock)
 	return service, instanceMock, parserMock, reportMock
@@ -49,7 +58,7 @@ func TestValidateConfig(t *testing.T) {
 		{
 			name: "Valid config with single instance",
 			config: Config{
-				InstanceIDs: []string{"i-12345"},
+				InstanceIDs: []string{"i-00012345"},
 				ConfigPath:  "/path/to/config.tf",
 			},
 			wantErr: false,
@@ -57,11 +66,91 @@ func TestValidateConfig(t *testing.T) {
 		{
 			name: "Valid config with multiple instances",
 			config: Config{
-				InstanceIDs: []string{"i-12345", "i-67890"},
+				InstanceIDs: []string{"i-00012345", "i-00067890"},
 				ConfigPath:  "/path/to/config.tf",
 			},
 			wantErr: false,
 		},
+		{
+			name: "Match by name",
+			config: Config{
+				InstanceIDs: []string{"i-00012345"},
+				ConfigPath:  "/path/to/config.tf",
+				MatchBy:     MatchByName,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Auto Scaling group without instance IDs",
+			config: Config{
+				AutoScalingGroup: "web-asg",
+				ConfigPath:       "/path/to/config.tf",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Notification webhook without a scheme",
+			config: Config{
+				InstanceIDs:   []string{"i-00012345"},
+				ConfigPath:    "/path/to/config.tf",
+				NotifyWebhook: "hooks.slack.com/services/T000",
+			},
+			wantErr: true,
+		},
+		{
+			name: "AWS endpoint URL without a scheme",
+			config: Config{
+				InstanceIDs:    []string{"i-00012345"},
+				ConfigPath:     "/path/to/config.tf",
+				AWSEndpointURL: "vpce-123.ec2.us-east-1.vpce.amazonaws.com",
+			},
+			wantErr: true,
+		},
+		{
+			name: "AWS endpoint URL with several regions",
+			config: Config{
+				InstanceIDs:    []string{"i-00012345", "eu-west-1/i-00067890"},
+				ConfigPath:     "/path/to/config.tf",
+				Regions:        []string{"us-east-1"},
+				AWSEndpointURL: "https://vpce-123.ec2.us-east-1.vpce.amazonaws.com",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Negative JSON indent",
+			config: Config{
+				InstanceIDs: []string{"i-00012345"},
+				ConfigPath:  "/path/to/config.tf",
+				JSONIndent:  -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Match by name without aws_instance resources",
+			config: Config{
+				InstanceIDs:     []string{"i-00012345"},
+				DesiredJSONPath: "/path/to/desired.json",
+				MatchBy:         MatchByName,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid match mode",
+			config: Config{
+				InstanceIDs: []string{"i-00012345"},
+				ConfigPath:  "/path/to/config.tf",
+				MatchBy:     "position",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Malformed instance IDs",
+			config: Config{
+				InstanceIDs: []string{"foo", "bar"},
+				ConfigPath:  "/path/to/config.tf",
+			},
+			wantErr: true,
+		},
 		{
 			name: "Missing instance IDs",
 			config: Config{
@@ -72,7 +161,262 @@ func TestValidateConfig(t *testing.T) {
 		{
 			name: "Missing config path",
 			config: Config{
-				InstanceIDs: []string{"i-12345"},
+				InstanceIDs: []string{"i-00012345"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Valid region-qualified instance IDs",
+			config: Config{
+				InstanceIDs: []string{"us-east-1/i-00012345", "i-00067890"},
+				ConfigPath:  "/path/to/config.tf",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Desired state JSON instead of config path",
+			config: Config{
+				InstanceIDs:     []string{"i-00012345"},
+				DesiredJSONPath: "/path/to/desired.json",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Both config path and desired state JSON",
+			config: Config{
+				InstanceIDs:     []string{"i-00012345"},
+				ConfigPath:      "/path/to/config.tf",
+				DesiredJSONPath: "/path/to/desired.json",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Plan JSON instead of config path",
+			config: Config{
+				InstanceIDs:  []string{"i-00012345"},
+				PlanJSONPath: "/path/to/plan.json",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Both plan JSON and desired state JSON",
+			config: Config{
+				InstanceIDs:     []string{"i-00012345"},
+				DesiredJSONPath: "/path/to/desired.json",
+				PlanJSONPath:    "/path/to/plan.json",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Match by name with plan JSON",
+			config: Config{
+				InstanceIDs:  []string{"i-00012345"},
+				PlanJSONPath: "/path/to/plan.json",
+				MatchBy:      MatchByName,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Launch template from config path",
+			config: Config{
+				InstanceIDs:    []string{"i-00012345"},
+				ConfigPath:     "/path/to/config.tf",
+				LaunchTemplate: "web",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Launch template with desired state JSON",
+			config: Config{
+				InstanceIDs:     []string{"i-00012345"},
+				DesiredJSONPath: "/path/to/desired.json",
+				LaunchTemplate:  "web",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Launch template with attribute mapping",
+			config: Config{
+				InstanceIDs:          []string{"i-00012345"},
+				ConfigPath:           "/path/to/config.tf",
+				AttributeMappingPath: "/path/to/mapping.yaml",
+				LaunchTemplate:       "web",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid instance state",
+			config: Config{
+				InstanceIDs: []string{"i-00012345"},
+				ConfigPath:  "/path/to/config.tf",
+				OnlyStates:  []string{"running", "sleeping"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Malformed region-qualified instance ID",
+			config: Config{
+				InstanceIDs: []string{"us-east-1/"},
+				ConfigPath:  "/path/to/config.tf",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Valid security group match mode",
+			config: Config{
+				InstanceIDs:          []string{"i-00012345"},
+				ConfigPath:           "/path/to/config.tf",
+				SecurityGroupMatchBy: "name",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Invalid security group match mode",
+			config: Config{
+				InstanceIDs:          []string{"i-00012345"},
+				ConfigPath:           "/path/to/config.tf",
+				SecurityGroupMatchBy: "arn",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Valid tags mode",
+			config: Config{
+				InstanceIDs: []string{"i-00012345"},
+				ConfigPath:  "/path/to/config.tf",
+				TagsMode:    "Subset",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Invalid tags mode",
+			config: Config{
+				InstanceIDs: []string{"i-00012345"},
+				ConfigPath:  "/path/to/config.tf",
+				TagsMode:    "superset",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Output file with html format",
+			config: Config{
+				InstanceIDs:  []string{"i-00012345"},
+				ConfigPath:   "/path/to/config.tf",
+				OutputFormat: "html",
+				OutputFile:   "report.html",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Output file with table format",
+			config: Config{
+				InstanceIDs:  []string{"i-00012345"},
+				ConfigPath:   "/path/to/config.tf",
+				OutputFormat: "table",
+				OutputFile:   "report.txt",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Template format with a template file",
+			config: Config{
+				InstanceIDs:  []string{"i-00012345"},
+				ConfigPath:   "/path/to/config.tf",
+				OutputFormat: "template",
+				TemplateFile: "report.tmpl",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Template format without a template file",
+			config: Config{
+				InstanceIDs:  []string{"i-00012345"},
+				ConfigPath:   "/path/to/config.tf",
+				OutputFormat: "template",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Template file with table format",
+			config: Config{
+				InstanceIDs:  []string{"i-00012345"},
+				ConfigPath:   "/path/to/config.tf",
+				OutputFormat: "table",
+				TemplateFile: "report.tmpl",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Template file for an additional report",
+			config: Config{
+				InstanceIDs:  []string{"i-00012345"},
+				ConfigPath:   "/path/to/config.tf",
+				Reports:      []ReportSink{{Format: "template", File: "report.txt"}},
+				TemplateFile: "report.tmpl",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Additional report",
+			config: Config{
+				InstanceIDs: []string{"i-00012345"},
+				ConfigPath:  "/path/to/config.tf",
+				Reports:     []ReportSink{{Format: "json", File: "report.json"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Additional report with invalid format",
+			config: Config{
+				InstanceIDs: []string{"i-00012345"},
+				ConfigPath:  "/path/to/config.tf",
+				Reports:     []ReportSink{{Format: "xml", File: "report.xml"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Additional report in watch mode",
+			config: Config{
+				InstanceIDs: []string{"i-00012345"},
+				ConfigPath:  "/path/to/config.tf",
+				Watch:       true,
+				Reports:     []ReportSink{{Format: "json", File: "report.json"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Retry on network errors",
+			config: Config{
+				InstanceIDs: []string{"i-00012345"},
+				ConfigPath:  "/path/to/config.tf",
+				RetryOn:     []string{"network_error"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Retry on permanent errors",
+			config: Config{
+				InstanceIDs: []string{"i-00012345"},
+				ConfigPath:  "/path/to/config.tf",
+				RetryOn:     []string{"permission_denied"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Negative max drifts",
+			config: Config{
+				InstanceIDs: []string{"i-00012345"},
+				ConfigPath:  "/path/to/config.tf",
+				MaxDrifts:   -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Negative diff context",
+			config: Config{
+				InstanceIDs: []string{"i-00012345"},
+				ConfigPath:  "/path/to/config.tf",
+				DiffContext: -1,
 			},
 			wantErr: true,
 		},
@@ -101,6 +445,17 @@ func TestValidateConfig(t *testing.T) {
 	}
 }
 
+// TestValidateConfig_WebhookNotInError tests that an invalid webhook URL, often a secret, is not printed in the error
+func TestValidateConfig_WebhookNotInError(t *testing.T) {
+	config := Config{InstanceIDs: []string{"i-00012345"}, ConfigPath: "/path/to/config.tf", NotifyWebhook: "hooks.slack.com/services/T000/B000/secret"}
+	service, _, _, _ := setupServiceWithMocks(t, config)
+
+	err := service.validateConfig()
+
+	assert.ErrorContains(t, err, "notification webhook must be an http:// or https:// URL")
+	assert.NotContains(t, err.Error(), "secret")
+}
+
 // TestCountDrifts tests the countDrifts function to ensure it correctly
 // counts instances with drift.
 func TestCountDrifts(t *testing.T) {
@@ -149,6 +504,16 @@ func TestGetOutputFormat(t *testing.T) {
 			formatString: "JSON",
 			expected:     report.OutputFormatTypeJSON,
 		},
+		{
+			name:         "SARIF format",
+			formatString: "sarif",
+			expected:     report.OutputFormatTypeSARIF,
+		},
+		{
+			name:         "Diff format",
+			formatString: "diff",
+			expected:     report.OutputFormatTypeDIFF,
+		},
 		{
 			name:         "Default to table when unrecognized",
 			formatString: "unknown",
@@ -258,7 +623,7 @@ func TestProcessInstance(t *testing.T) {
 			}
 
 			// Process the instance
-			result := service.processInstance(tc.awsInstance, tfConfig)
+			result := service.processInstance(tc.awsInstance, tfConfig, nil)
 
 			// Verify results
 			if tc.expectErr {
@@ -272,6 +637,285 @@ func TestProcessInstance(t *testing.T) {
 	}
 }
 
+// flushingPrinter is a printer mock that also buffers reports like the SARIF printer
+type flushingPrinter struct {
+	*reportMocks.IPrinter
+	flushes int
+	errored []string
+}
+
+// ReportError records the errored instance
+func (p *flushingPrinter) ReportError(instanceID string, err error, format report.OutputFormatType) error {
+	p.errored = append(p.errored, instanceID)
+	return nil
+}
+
+// Flush records the call
+func (p *flushingPrinter) Flush(format report.OutputFormatType) error {
+	p.flushes++
+	return nil
+}
+
+// TestRun_FlushesSARIF tests that buffered reports are flushed once at the end of a SARIF run only
+func TestRun_FlushesSARIF(t *testing.T) {
+	for _, format := range []string{"sarif", "table"} {
+		t.Run(format, func(t *testing.T) {
+			config := Config{InstanceIDs: []string{"i-00000001"}, ConfigPath: "test.tf", OutputFormat: format}
+			instanceMock, parserMock, reportMock, logger := createMocks(t)
+			printer := &flushingPrinter{IPrinter: reportMock}
+			service := NewService(config, instanceMock, parserMock, printer, logger)
+
+			parserMock.On("GetDesiredState", mock.Anything, config.ConfigPath).Return(&models.InstanceDetails{InstanceType: "t2.micro"}, nil)
+			instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).
+				Return([]*models.InstanceDetails{{InstanceID: "i-00000001", InstanceType: "t2.micro"}}, nil)
+			reportMock.On("PrintReport", "i-00000001", mock.Anything, mock.Anything).Return(nil)
+
+			_, _, err := service.Run(context.Background())
+			assert.NoError(t, err)
+
+			expectedFlushes := 0
+			if format == "sarif" {
+				expectedFlushes = 1
+			}
+			assert.Equal(t, expectedFlushes, printer.flushes)
+		})
+	}
+}
+
+// TestRun_ReportsErrorsToDocumentFormats tests that errored instances are added to document formats such as JUnit
+func TestRun_ReportsErrorsToDocumentFormats(t *testing.T) {
+	config := Config{InstanceIDs: []string{"i-000000a0", "i-000000a1"}, ConfigPath: "test.tf", OutputFormat: "junit"}
+	instanceMock, parserMock, reportMock, logger := createMocks(t)
+	printer := &flushingPrinter{IPrinter: reportMock}
+	service := NewService(config, instanceMock, parserMock, printer, logger)
+
+	parserMock.On("GetDesiredState", mock.Anything, config.ConfigPath).Return(&models.InstanceDetails{InstanceType: "t2.micro"}, nil)
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).Return(
+		[]*models.InstanceDetails{{InstanceID: "i-000000a0", InstanceType: "t2.micro"}},
+		&awsProvider.PartialFetchError{Failed: map[string]error{"i-000000a1": errors.New("not found")}},
+	)
+	reportMock.On("PrintReport", "i-000000a0", mock.Anything, report.OutputFormatTypeJUnit).Return(nil)
+
+	_, anyError, err := service.Run(context.Background())
+	var instanceErr *InstanceError
+	assert.ErrorAs(t, err, &instanceErr)
+	assert.True(t, anyError)
+	assert.Equal(t, []string{"i-000000a1"}, printer.errored)
+	assert.Equal(t, 1, printer.flushes)
+}
+
+// matchPrinter is a printer mock that also lists the attributes compared without drift
+type matchPrinter struct {
+	*reportMocks.IPrinter
+	matches map[string][]models.DriftDetail
+}
+
+// PrintReportWithMatches records the matches of the instance
+func (p *matchPrinter) PrintReportWithMatches(instanceID string, drifts, matches []models.DriftDetail, format report.OutputFormatType) error {
+	p.matches[instanceID] = matches
+	return nil
+}
+
+// TestRun_ShowAllAttributes tests that the matching attributes are only passed to the printer when requested
+func TestRun_ShowAllAttributes(t *testing.T) {
+	for _, showAll := range []bool{true, false} {
+		t.Run(fmt.Sprintf("show all %t", showAll), func(t *testing.T) {
+			config := Config{
+				InstanceIDs:       []string{"i-00000001"},
+				ConfigPath:        "test.tf",
+				AttributesToCheck: []string{"instance_type", "ami"},
+				ShowAllAttributes: showAll,
+			}
+			instanceMock, parserMock, reportMock, logger := createMocks(t)
+			printer := &matchPrinter{IPrinter: reportMock, matches: make(map[string][]models.DriftDetail)}
+			service := NewService(config, instanceMock, parserMock, printer, logger)
+
+			parserMock.On("GetDesiredState", mock.Anything, config.ConfigPath).Return(&models.InstanceDetails{InstanceType: "t2.micro", AMI: "ami-123"}, nil)
+			instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).
+				Return([]*models.InstanceDetails{{InstanceID: "i-00000001", InstanceType: "t2.small", AMI: "ami-123"}}, nil)
+			if !showAll {
+				reportMock.On("PrintReport", "i-00000001", mock.Anything, mock.Anything).Return(nil)
+			}
+
+			_, _, err := service.Run(context.Background())
+			assert.NoError(t, err)
+
+			if showAll {
+				require.Len(t, printer.matches["i-00000001"], 1)
+				assert.Equal(t, "ami", printer.matches["i-00000001"][0].Attribute)
+			} else {
+				assert.Empty(t, printer.matches)
+			}
+		})
+	}
+}
+
+// TestRun_DiffContext tests that the matching attributes are passed to the printer as context of the diff
+func TestRun_DiffContext(t *testing.T) {
+	config := Config{
+		InstanceIDs:       []string{"i-00000001"},
+		ConfigPath:        "test.tf",
+		AttributesToCheck: []string{"instance_type", "ami"},
+		OutputFormat:      "diff",
+		DiffContext:       1,
+	}
+	instanceMock, parserMock, reportMock, logger := createMocks(t)
+	printer := &matchPrinter{IPrinter: reportMock, matches: make(map[string][]models.DriftDetail)}
+	service := NewService(config, instanceMock, parserMock, printer, logger)
+
+	parserMock.On("GetDesiredState", mock.Anything, config.ConfigPath).Return(&models.InstanceDetails{InstanceType: "t2.micro", AMI: "ami-123"}, nil)
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).
+		Return([]*models.InstanceDetails{{InstanceID: "i-00000001", InstanceType: "t2.small", AMI: "ami-123"}}, nil)
+
+	_, _, err := service.Run(context.Background())
+	assert.NoError(t, err)
+	require.Len(t, printer.matches["i-00000001"], 1)
+	assert.Equal(t, "ami", printer.matches["i-00000001"][0].Attribute)
+}
+
+// TestProcessInstance_Quiet tests that quiet mode only reports instances with drift.
+func TestProcessInstance_Quiet(t *testing.T) {
+	tfConfig := &models.InstanceDetails{
+		InstanceType: "t2.micro",
+		Tags: map[string]string{
+			"Environment": "test",
+		},
+	}
+
+	// No PrintReport expectation: the mock fails the test if a report is generated
+	service, _, _, _ := setupServiceWithMocks(t, Config{Quiet: true})
+	result := service.processInstance(createTestDriftInstance("i-clean", "t2.micro"), tfConfig, nil)
+	assert.Nil(t, result.Error, "Should not have an error")
+	assert.False(t, result.HasDrift, "Should not have drift")
+
+	// Instances with drift are still reported
+	service, _, _, reportMock := setupServiceWithMocks(t, Config{Quiet: true})
+	reportMock.On("PrintReport", "i-drift", mock.Anything, mock.Anything).Return(nil)
+	result = service.processInstance(createTestDriftInstance("i-drift", "t2.large"), tfConfig, nil)
+	assert.Nil(t, result.Error, "Should not have an error")
+	assert.True(t, result.HasDrift, "Should have drift")
+}
+
+// TestProcessInstance_SuppressEmpty tests that instances without drift print nothing with SuppressEmpty.
+func TestProcessInstance_SuppressEmpty(t *testing.T) {
+	tfConfig := &models.InstanceDetails{
+		InstanceType: "t2.micro",
+		Tags:         map[string]string{"Environment": "test"},
+	}
+
+	// No PrintReport expectation: the mock fails the test if a report is generated
+	service, _, _, _ := setupServiceWithMocks(t, Config{SuppressEmpty: true})
+	result := service.processInstance(createTestDriftInstance("i-clean", "t2.micro"), tfConfig, nil)
+	assert.NoError(t, result.Error)
+	assert.False(t, result.HasDrift)
+
+	service, _, _, reportMock := setupServiceWithMocks(t, Config{SuppressEmpty: true})
+	reportMock.On("PrintReport", "i-drift", mock.Anything, mock.Anything).Return(nil)
+	result = service.processInstance(createTestDriftInstance("i-drift", "t2.large"), tfConfig, nil)
+	assert.NoError(t, result.Error)
+	assert.True(t, result.HasDrift)
+}
+
+// TestDetectInstanceDrift_SecurityGroupMatchBy tests that security groups are compared
+// by ID by default and by name when configured to.
+func TestDetectInstanceDrift_SecurityGroupMatchBy(t *testing.T) {
+	awsInstance := &models.InstanceDetails{
+		InstanceID:         "i-12345",
+		SecurityGroups:     []string{"sg-0123", "sg-4567"},
+		SecurityGroupNames: []string{"web", "ssh"},
+	}
+
+	tests := []struct {
+		name        string
+		matchBy     string
+		tfGroups    []string
+		expectDrift bool
+	}{
+		{"Default matches IDs", "", []string{"sg-4567", "sg-0123"}, false},
+		{"Default reports names as drift", "", []string{"web", "ssh"}, true},
+		{"Name mode matches names", SecurityGroupMatchByName, []string{"ssh", "web"}, false},
+		{"Name mode reports IDs as drift", SecurityGroupMatchByName, []string{"sg-0123", "sg-4567"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _, _, _ := setupServiceWithMocks(t, Config{
+				AttributesToCheck:    []string{"security_groups"},
+				SecurityGroupMatchBy: tt.matchBy,
+			})
+
+			result, err := service.detectInstanceDrift(awsInstance, &models.InstanceDetails{SecurityGroups: tt.tfGroups})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectDrift, result.HasDrift, "Drift detection result should match expectations")
+		})
+	}
+
+	// The original instance must not be modified by the name view
+	assert.Equal(t, []string{"sg-0123", "sg-4567"}, awsInstance.SecurityGroups)
+}
+
+func TestCheckAttributes(t *testing.T) {
+	awsInstance := &models.InstanceDetails{InstanceID: "i-12345", InstanceType: "t2.medium"}
+	tfConfig := &models.InstanceDetails{InstanceType: "t2.micro"}
+	attributes := []string{"instance_type", "bogus"}
+
+	// Lenient mode drops the unsupported attribute once, so instances still report drift without errors
+	service, _, _, _ := setupServiceWithMocks(t, Config{AttributesToCheck: attributes})
+	assert.NoError(t, service.checkAttributes())
+	assert.Equal(t, []string{"instance_type"}, service.config.AttributesToCheck)
+	result, err := service.detectInstanceDrift(awsInstance, tfConfig)
+	assert.NoError(t, err)
+	assert.True(t, result.HasDrift)
+
+	// Strict mode fails fast
+	service, _, _, _ = setupServiceWithMocks(t, Config{AttributesToCheck: attributes, StrictAttributes: true})
+	err = service.checkAttributes()
+	assert.True(t, driftcheck.IsErrorCategory(err, driftcheck.ErrResourceMissing))
+	assert.ErrorContains(t, err, "bogus")
+
+	// Without any supported attribute there is nothing left to check
+	service, _, _, _ = setupServiceWithMocks(t, Config{AttributesToCheck: []string{"bogus"}})
+	assert.Error(t, service.checkAttributes())
+
+	// Empty attribute names are invalid input even in lenient mode
+	service, _, _, _ = setupServiceWithMocks(t, Config{AttributesToCheck: []string{"instance_type", " "}})
+	err = service.checkAttributes()
+	assert.True(t, driftcheck.IsErrorCategory(err, driftcheck.ErrInvalidInput))
+}
+
+// TestRun_UnsupportedAttributeFailsFast tests that an unsupported attribute in strict mode fails the run
+// before any instance is fetched, instead of erroring every instance.
+func TestRun_UnsupportedAttributeFailsFast(t *testing.T) {
+	config := Config{
+		InstanceIDs:       []string{"i-00000001", "i-00000002"},
+		ConfigPath:        "test.tf",
+		AttributesToCheck: []string{"instance_type", "bogus"},
+		StrictAttributes:  true,
+	}
+	// The mocks fail the test on any call, nothing should be parsed, fetched or reported
+	service, _, _, _ := setupServiceWithMocks(t, config)
+
+	_, anyError, err := service.Run(context.Background())
+	assert.ErrorContains(t, err, "invalid attributes to check")
+	assert.True(t, anyError)
+}
+
+// TestRun_InvalidEquivalentTypesFailsFast tests that malformed equivalent instance types fail the run before any instance is fetched
+func TestRun_InvalidEquivalentTypesFailsFast(t *testing.T) {
+	config := Config{
+		InstanceIDs:     []string{"i-00000001"},
+		ConfigPath:      "test.tf",
+		EquivalentTypes: []string{"t3.micro"},
+	}
+	// The mocks fail the test on any call
+	service, _, _, _ := setupServiceWithMocks(t, config)
+
+	_, anyError, err := service.Run(context.Background())
+	assert.ErrorContains(t, err, "invalid equivalent instance types")
+	assert.True(t, anyError)
+}
+
 // TestGenerateSummaryReport tests the summary report generation
 // to ensure it correctly logs the overview of drift detection results.
 func TestGenerateSummaryReport(t *testing.T) {
@@ -283,10 +927,11 @@ func TestGenerateSummaryReport(t *testing.T) {
 		{InstanceID: "i-1", HasDrift: true},     // Instance with drift
 		{InstanceID: "i-2", Error: expectedErr}, // Instance with error
 		{InstanceID: "i-3", HasDrift: false},    // Instance without drift
+		{InstanceID: "i-4", HasDrift: false},    // Instance without drift
 	}
 
 	// Create service and configure mocks
-	parserMock := terraformMocks.NewIProvider(t)
+	parserMock := terraformMocks.NewDesiredStateProvider(t)
 	instanceMock := awsMocks.NewInstanceServiceAPI(t)
 	reportMock := reportMocks.NewIPrinter(t)
 	loggerMock := loggerMocks.NewLogger(t)
@@ -296,13 +941,156 @@ func TestGenerateSummaryReport(t *testing.T) {
 	// First, expect an error log for the instance with an error
 	loggerMock.On("Error", "Instance %s: Error - %s", "i-2", expectedErr).Return()
 	// Then, expect a summary info log with the drift and error statistics
-	loggerMock.On("Info", "Summary: Checked %d instances, %d with drift, %d with errors",
-		3, 1, 1).Return()
+	loggerMock.On("Info", "Summary: Checked %d instances, %d with drift (%.1f%%), %d with errors",
+		4, 1, 25.0, 1).Return()
+	// And the breakdown by attribute, empty as the drifted instance has no drift details
+	loggerMock.On("Info", "Drift by attribute: %s", "").Return()
 
 	// Run the function being tested
 	service.generateSummaryReport(results)
 }
 
+// TestGenerateSummaryReport_ErrorCategory tests that AWS errors are logged with their category
+func TestGenerateSummaryReport_ErrorCategory(t *testing.T) {
+	loggerMock := loggerMocks.NewLogger(t)
+	service := NewService(Config{}, awsMocks.NewInstanceServiceAPI(t), terraformMocks.NewDesiredStateProvider(t), reportMocks.NewIPrinter(t), loggerMock)
+
+	awsErr := awsProvider.NewAWSError(awsProvider.ErrPermissionDenied, awsProvider.EC2ResourceType, "i-1", "Access denied", nil)
+	err := fmt.Errorf("error fetching AWS instance details: %w", awsErr)
+	loggerMock.On("Error", "Instance %s: Error [%s] - %s", "i-1", awsProvider.ErrPermissionDenied, err).Return()
+
+	service.generateSummaryReport([]DriftDetectionResult{{InstanceID: "i-1", Error: err}})
+}
+
+// TestGenerateSummaryReport_QuietSingleInstance tests that quiet mode always prints the summary,
+// since the per-instance report of a clean instance is suppressed.
+func TestGenerateSummaryReport_QuietSingleInstance(t *testing.T) {
+	loggerMock := loggerMocks.NewLogger(t)
+	service := NewService(Config{Quiet: true}, awsMocks.NewInstanceServiceAPI(t), terraformMocks.NewDesiredStateProvider(t), reportMocks.NewIPrinter(t), loggerMock)
+
+	loggerMock.On("Info", "Summary: Checked %d instances, %d with drift (%.1f%%), %d with errors", 1, 0, 0.0, 0).Return()
+
+	service.generateSummaryReport([]DriftDetectionResult{{InstanceID: "i-1"}})
+}
+
+// TestWarnIfSlow tests that only operations exceeding the slow threshold are logged, and never when it is disabled
+func TestWarnIfSlow(t *testing.T) {
+	loggerMock := loggerMocks.NewLogger(t)
+	service := NewService(Config{SlowThreshold: time.Second}, awsMocks.NewInstanceServiceAPI(t), terraformMocks.NewDesiredStateProvider(t), reportMocks.NewIPrinter(t), loggerMock)
+
+	loggerMock.On("Warn", "%s took %s, longer than the slow threshold of %s",
+		"Processing instance i-slow", 1500*time.Millisecond, time.Second).Return().Once()
+
+	service.warnIfSlow(1500*time.Millisecond, "Processing instance %s", "i-slow")
+	service.warnIfSlow(time.Second, "Processing instance %s", "i-fast")
+
+	service.config.SlowThreshold = 0
+	service.warnIfSlow(time.Hour, "Processing instance %s", "i-unbounded")
+}
+
+// TestCheckInstance_LogsUnmanagedAttributes tests that unset attributes AWS has a value for are logged as
+// unmanaged rather than reported as drift when they are not managed
+func TestCheckInstance_LogsUnmanagedAttributes(t *testing.T) {
+	loggerMock := loggerMocks.NewLogger(t)
+	config := Config{AttributesToCheck: []string{"instance_type", "subnet_id"}, SkipUnsetAttributes: true}
+	service := NewService(config, awsMocks.NewInstanceServiceAPI(t), terraformMocks.NewDesiredStateProvider(t), reportMocks.NewIPrinter(t), loggerMock)
+
+	loggerMock.On("Debug", mock.Anything, mock.Anything).Return()
+	loggerMock.On("Info", "Instance %s: %s is not set in the Terraform configuration, unmanaged with %v on AWS",
+		"i-1", "subnet_id", "subnet-123").Return().Once()
+
+	result := service.checkInstance(&models.InstanceDetails{InstanceID: "i-1", InstanceType: "t2.micro", SubnetID: "subnet-123"},
+		&models.InstanceDetails{InstanceType: "t2.micro"}, nil)
+	require.NoError(t, result.Error)
+	assert.False(t, result.HasDrift)
+}
+
+// TestGenerateInstanceReport_Unmanaged tests that unmanaged attributes are recorded in the instance's report
+func TestGenerateInstanceReport_Unmanaged(t *testing.T) {
+	var out bytes.Buffer
+	printer := report.NewFormatPrinter(report.OutputFormatTypeJSON, &out, report.PrinterOptions{})
+	service := NewService(Config{AttributesToCheck: []string{"instance_type", "key_name"}, SkipUnsetAttributes: true},
+		awsMocks.NewInstanceServiceAPI(t), terraformMocks.NewDesiredStateProvider(t), printer, logging.NewDefaultLogger())
+
+	result := service.checkInstance(&models.InstanceDetails{InstanceID: "i-1", InstanceType: "t2.micro", KeyName: "deployer"},
+		&models.InstanceDetails{InstanceType: "t2.micro"}, nil)
+	require.NoError(t, result.Error)
+	require.NoError(t, service.reportInstance(result).Error)
+	require.NoError(t, printer.(report.IFlusher).Flush(""))
+
+	var envelope report.JSONEnvelope
+	require.NoError(t, json.Unmarshal(out.Bytes(), &envelope))
+	require.Len(t, envelope.Reports, 1)
+	assert.Empty(t, envelope.Reports[0].Drifts)
+	assert.Equal(t, []models.DriftDetail{{Attribute: "key_name", AWSValue: "deployer", TerraformValue: ""}},
+		envelope.Reports[0].Unmanaged)
+}
+
+// TestProcessAllInstances_RecordsDuration tests that the processing time of each instance is recorded
+func TestProcessAllInstances_RecordsDuration(t *testing.T) {
+	config := Config{InstanceIDs: []string{"i-1"}, ConfigPath: "test.tf"}
+	service, instanceMock, _, reportMock := setupServiceWithMocks(t, config)
+
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).
+		Return([]*models.InstanceDetails{{InstanceID: "i-1", InstanceType: "t2.micro"}}, nil)
+	reportMock.On("PrintReport", "i-1", mock.Anything, mock.Anything).
+		Run(func(mock.Arguments) { time.Sleep(10 * time.Millisecond) }).Return(nil)
+
+	results, err := service.processAllInstances(context.Background(), &models.InstanceDetails{InstanceType: "t2.micro"}, true)
+
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.GreaterOrEqual(t, results[0].Duration, 10*time.Millisecond)
+	}
+}
+
+// TestCountAttributeDrifts tests the per-attribute drift breakdown of the summary
+func TestCountAttributeDrifts(t *testing.T) {
+	drifted := func(attributes ...string) DriftDetectionResult {
+		drifts := make(map[string]models.DriftDetail, len(attributes))
+		for _, attribute := range attributes {
+			drifts[attribute] = models.DriftDetail{Attribute: attribute}
+		}
+		return DriftDetectionResult{HasDrift: true, Result: &driftcheck.DriftResult{HasDrift: true, Drifts: drifts}}
+	}
+	results := []DriftDetectionResult{
+		drifted("instance_type", "tags.Name", "tags.Owner"),
+		drifted("instance_type"),
+		drifted("ami"),
+		{InstanceID: "i-clean"},
+	}
+
+	counts := countAttributeDrifts(results)
+
+	// Keyed attributes count once per instance
+	assert.Equal(t, map[string]int{"instance_type": 2, "tags": 1, "ami": 1}, counts)
+	assert.Equal(t, "instance_type: 2 instances, ami: 1 instance, tags: 1 instance", formatAttributeDriftCounts(counts))
+}
+
+// TestDriftExceedsMax tests that drift within the MaxDrifts tolerance does not fail the run
+func TestDriftExceedsMax(t *testing.T) {
+	results := []DriftDetectionResult{{InstanceID: "i-1", HasDrift: true}, {InstanceID: "i-2", HasDrift: true}, {InstanceID: "i-3"}}
+
+	tests := []struct {
+		name      string
+		maxDrifts int
+		results   []DriftDetectionResult
+		expected  bool
+	}{
+		{"No tolerance", 0, results, true},
+		{"Within tolerance", 2, results, false},
+		{"Above tolerance", 1, results, true},
+		{"No drift", 0, results[2:], false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _, _, _ := setupServiceWithMocks(t, Config{MaxDrifts: tt.maxDrifts})
+			assert.Equal(t, tt.expected, service.driftExceedsMax(tt.results))
+		})
+	}
+}
+
 // ================
 // Run function tests
 // ================
@@ -354,19 +1142,19 @@ func TestRun(t *testing.T) {
 		func() testCase {
 			// Start with a standard test case
 			tc := createTestRunCase("Successful run - with drift",
-				[]string{"i-123", "i-456"}, true, false, false)
+				[]string{"i-00000123", "i-00000456"}, true, false, false)
 
 			// Customize AWS instances to create drift in one instance
 			tc.mockAWSInstances = []*models.InstanceDetails{
 				{
-					InstanceID:   "i-123",
+					InstanceID:   "i-00000123",
 					InstanceType: "t2.large", // Drift in instance type
 					Tags: map[string]string{
 						"Environment": "test",
 					},
 				},
 				{
-					InstanceID:   "i-456",
+					InstanceID:   "i-00000456",
 					InstanceType: "t2.micro", // No drift
 					Tags: map[string]string{
 						"Environment": "test",
@@ -380,12 +1168,12 @@ func TestRun(t *testing.T) {
 		func() testCase {
 			// Start with a standard test case
 			tc := createTestRunCase("Successful run - no drift",
-				[]string{"i-123"}, false, false, false)
+				[]string{"i-00000123"}, false, false, false)
 
 			// Add one AWS instance without drift
 			tc.mockAWSInstances = []*models.InstanceDetails{
 				{
-					InstanceID:   "i-123",
+					InstanceID:   "i-00000123",
 					InstanceType: "t2.micro", // No drift
 					Tags: map[string]string{
 						"Environment": "test",
@@ -400,11 +1188,11 @@ func TestRun(t *testing.T) {
 		func() testCase {
 			// Start with a standard test case
 			tc := createTestRunCase("AWS error",
-				[]string{"i-123", "i-error"}, false, true, true)
+				[]string{"i-00000123", "i-000000a2"}, false, true, true)
 
 			// Add AWS error
 			tc.awsErrors = map[string]error{
-				"i-error": errors.New("AWS error"),
+				"i-000000a2": errors.New("AWS error"),
 			}
 
 			return tc
@@ -413,7 +1201,7 @@ func TestRun(t *testing.T) {
 		// Create a test case for Terraform configuration error
 		func() testCase {
 			tc := createTestRunCase("Terraform config error",
-				[]string{"i-123"}, false, false, true)
+				[]string{"i-00000123"}, false, false, true)
 
 			// Set Terraform config error
 			tc.mockTFConfig = nil
@@ -434,7 +1222,7 @@ func TestRun(t *testing.T) {
 		func() testCase {
 			// Start with a standard test case
 			tc := createTestRunCase("With concurrency limit",
-				[]string{"i-123", "i-456"}, false, false, false)
+				[]string{"i-00000123", "i-00000456"}, false, false, false)
 
 			// Set concurrency limit
 			tc.config.ConcurrencyLimit = 1
@@ -442,14 +1230,14 @@ func TestRun(t *testing.T) {
 			// Add AWS instances that match Terraform config (no drift)
 			tc.mockAWSInstances = []*models.InstanceDetails{
 				{
-					InstanceID:   "i-123",
+					InstanceID:   "i-00000123",
 					InstanceType: "t2.micro",
 					Tags: map[string]string{
 						"Environment": "test",
 					},
 				},
 				{
-					InstanceID:   "i-456",
+					InstanceID:   "i-00000456",
 					InstanceType: "t2.micro",
 					Tags: map[string]string{
 						"Environment": "test",
@@ -468,7 +1256,7 @@ func TestRun(t *testing.T) {
 
 			// Configure Terraform parser mock if instance IDs are provided
 			if len(tt.config.InstanceIDs) != 0 {
-				parserMock.On("ParseHCLConfig", tt.config.ConfigPath).Return(tt.mockTFConfig, tt.tfConfigError)
+				parserMock.On("GetDesiredState", mock.Anything, tt.config.ConfigPath).Return(tt.mockTFConfig, tt.tfConfigError)
 			}
 
 			// Configure AWS mock for each instance
@@ -480,7 +1268,7 @@ func TestRun(t *testing.T) {
 			if tt.awsErrors != nil {
 				// Return error for the error case
 				errorInstances := make([]*models.InstanceDetails, 0)
-				instanceMock.On("GetInstancesDetails", mock.Anything, tt.config.InstanceIDs).Return(errorInstances, tt.awsErrors["i-error"])
+				instanceMock.On("GetInstancesDetails", mock.Anything, tt.config.InstanceIDs).Return(errorInstances, tt.awsErrors["i-000000a2"])
 			}
 
 			// Configure report mock if not expecting a configuration error
@@ -504,3 +1292,639 @@ func TestRun(t *testing.T) {
 		})
 	}
 }
+
+// TestRun_AMIDeprecation tests that the opt-in AMI deprecation check reports
+// instances running a deprecated AMI as drifted while leaving current AMIs alone.
+func TestRun_AMIDeprecation(t *testing.T) {
+	config := Config{
+		InstanceIDs:         []string{"i-000000a3", "i-000000a4"},
+		ConfigPath:          "test.tf",
+		AttributesToCheck:   []string{"instance_type"},
+		CheckAMIDeprecation: true,
+	}
+	service, instanceMock, parserMock, reportMock := setupServiceWithMocks(t, config)
+
+	parserMock.On("GetDesiredState", mock.Anything, config.ConfigPath).Return(&models.InstanceDetails{InstanceType: "t2.micro"}, nil)
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).Return([]*models.InstanceDetails{
+		{InstanceID: "i-000000a3", InstanceType: "t2.micro", AMI: "ami-deprecated"},
+		{InstanceID: "i-000000a4", InstanceType: "t2.micro", AMI: "ami-current"},
+	}, nil)
+	instanceMock.On("GetImagesDetails", mock.Anything, mock.MatchedBy(func(ids []string) bool {
+		return len(ids) == 2
+	})).Return([]*models.ImageDetails{
+		{ImageID: "ami-deprecated", DeprecationTime: time.Now().Add(-24 * time.Hour)},
+		{ImageID: "ami-current", DeprecationTime: time.Now().Add(24 * time.Hour)},
+	}, nil)
+
+	// Only the instance on the deprecated AMI should report the violation
+	reportMock.On("PrintReport", "i-000000a3", mock.MatchedBy(func(drifts []models.DriftDetail) bool {
+		return len(drifts) == 1 && drifts[0].Attribute == driftcheck.AMIDeprecationAttribute
+	}), mock.Anything).Return(nil)
+	reportMock.On("PrintReport", "i-000000a4", mock.MatchedBy(func(drifts []models.DriftDetail) bool {
+		return len(drifts) == 0
+	}), mock.Anything).Return(nil)
+
+	anyDrift, anyError, err := service.Run(context.Background())
+
+	assert.NoError(t, err)
+	assert.True(t, anyDrift, "Deprecated AMI should be reported as drift")
+	assert.False(t, anyError)
+}
+
+// TestRun_AllowedAZs tests that instances outside the allowed availability zones are reported
+// as drifted even though the Terraform configuration does not pin the zone.
+func TestRun_AllowedAZs(t *testing.T) {
+	config := Config{
+		InstanceIDs:       []string{"i-000000a3", "i-000000a4"},
+		ConfigPath:        "test.tf",
+		AttributesToCheck: []string{"instance_type"},
+		AllowedAZs:        []string{"us-east-1a", "us-east-1b"},
+	}
+	service, instanceMock, parserMock, reportMock := setupServiceWithMocks(t, config)
+
+	parserMock.On("GetDesiredState", mock.Anything, config.ConfigPath).Return(&models.InstanceDetails{InstanceType: "t2.micro"}, nil)
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).Return([]*models.InstanceDetails{
+		{InstanceID: "i-000000a3", InstanceType: "t2.micro", AvailabilityZone: "us-east-1c"},
+		{InstanceID: "i-000000a4", InstanceType: "t2.micro", AvailabilityZone: "us-east-1b"},
+	}, nil)
+
+	reportMock.On("PrintReport", "i-000000a3", mock.MatchedBy(func(drifts []models.DriftDetail) bool {
+		return len(drifts) == 1 && drifts[0].Attribute == driftcheck.AZPolicyAttribute
+	}), mock.Anything).Return(nil)
+	reportMock.On("PrintReport", "i-000000a4", mock.MatchedBy(func(drifts []models.DriftDetail) bool {
+		return len(drifts) == 0
+	}), mock.Anything).Return(nil)
+
+	anyDrift, anyError, err := service.Run(context.Background())
+
+	assert.NoError(t, err)
+	assert.True(t, anyDrift, "An instance outside the allowed zones should be reported as drift")
+	assert.False(t, anyError)
+}
+
+// TestRun_DuplicateInstanceIDs tests that an instance listed twice is fetched, reported and counted once
+func TestRun_DuplicateInstanceIDs(t *testing.T) {
+	config := Config{
+		InstanceIDs:       []string{"i-000000a1", "i-000000a2", "i-000000a1"},
+		ConfigPath:        "test.tf",
+		AttributesToCheck: []string{"instance_type"},
+	}
+	service, instanceMock, parserMock, reportMock := setupServiceWithMocks(t, config)
+
+	parserMock.On("GetDesiredState", mock.Anything, config.ConfigPath).Return(&models.InstanceDetails{InstanceType: "t2.micro"}, nil)
+	instanceMock.On("GetInstancesDetails", mock.Anything, []string{"i-000000a1", "i-000000a2"}).Return([]*models.InstanceDetails{
+		{InstanceID: "i-000000a1", InstanceType: "t2.micro"},
+		{InstanceID: "i-000000a2", InstanceType: "t2.micro"},
+	}, nil).Once()
+	reportMock.On("PrintReport", "i-000000a1", mock.Anything, mock.Anything).Return(nil).Once()
+	reportMock.On("PrintReport", "i-000000a2", mock.Anything, mock.Anything).Return(nil).Once()
+
+	_, _, err := service.Run(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, service.Stats().InstancesChecked)
+}
+
+// TestRun_AMIDeprecationLookupFails tests that a failed AMI lookup, e.g. of a deregistered AMI, only skips the
+// deprecation check, and that the instances are still checked and reported.
+func TestRun_AMIDeprecationLookupFails(t *testing.T) {
+	config := Config{
+		InstanceIDs:         []string{"i-000000a3", "i-000000a4"},
+		ConfigPath:          "test.tf",
+		AttributesToCheck:   []string{"instance_type"},
+		CheckAMIDeprecation: true,
+	}
+	service, instanceMock, parserMock, reportMock := setupServiceWithMocks(t, config)
+
+	parserMock.On("GetDesiredState", mock.Anything, config.ConfigPath).Return(&models.InstanceDetails{InstanceType: "t2.micro"}, nil)
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).Return([]*models.InstanceDetails{
+		{InstanceID: "i-000000a3", InstanceType: "t2.micro", AMI: "ami-deregistered"},
+		{InstanceID: "i-000000a4", InstanceType: "t2.large", AMI: "ami-current"},
+	}, nil)
+	instanceMock.On("GetImagesDetails", mock.Anything, []string{"ami-deregistered", "ami-current"}).
+		Return(nil, errors.New("InvalidAMIID.NotFound: The image id '[ami-deregistered]' does not exist"))
+	reportMock.On("PrintReport", "i-000000a3", mock.MatchedBy(func(drifts []models.DriftDetail) bool {
+		return len(drifts) == 0
+	}), mock.Anything).Return(nil).Once()
+	reportMock.On("PrintReport", "i-000000a4", mock.MatchedBy(func(drifts []models.DriftDetail) bool {
+		return len(drifts) == 1 && drifts[0].Attribute == "instance_type"
+	}), mock.Anything).Return(nil).Once()
+
+	anyDrift, anyError, err := service.Run(context.Background())
+
+	assert.NoError(t, err)
+	assert.True(t, anyDrift)
+	assert.False(t, anyError)
+	assert.Equal(t, 2, service.Stats().InstancesChecked)
+}
+
+// TestRun_PartialFetch tests that instances which could be fetched are still checked and reported
+// when fetching others failed, and that only the failed ones are marked as errored.
+func TestRun_PartialFetch(t *testing.T) {
+	config := Config{
+		InstanceIDs: []string{"i-000000a0", "i-000000a1"},
+		ConfigPath:  "test.tf",
+	}
+	service, instanceMock, parserMock, reportMock := setupServiceWithMocks(t, config)
+
+	parserMock.On("GetDesiredState", mock.Anything, config.ConfigPath).Return(&models.InstanceDetails{InstanceType: "t2.micro"}, nil)
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).Return(
+		[]*models.InstanceDetails{{InstanceID: "i-000000a0", InstanceType: "t2.large"}},
+		&awsProvider.PartialFetchError{Failed: map[string]error{
+			"i-000000a1": awsProvider.NewAWSError(awsProvider.ErrResourceNotFound, awsProvider.EC2ResourceType, "i-000000a1", "not found", nil),
+		}},
+	)
+	reportMock.On("PrintReport", "i-000000a0", mock.Anything, mock.Anything).Return(nil).Twice()
+
+	results, err := service.processAllInstances(context.Background(), &models.InstanceDetails{InstanceType: "t2.micro"}, true)
+
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	for _, result := range results {
+		switch result.InstanceID {
+		case "i-000000a0":
+			assert.NoError(t, result.Error)
+			assert.True(t, result.HasDrift)
+		case "i-000000a1":
+			assert.ErrorContains(t, result.Error, "not found")
+		default:
+			t.Errorf("unexpected result for %s", result.InstanceID)
+		}
+	}
+
+	// The per-instance errors are returned joined, and can be inspected by callers
+	anyDrift, anyError, err := service.Run(context.Background())
+	assert.True(t, anyDrift)
+	assert.True(t, anyError)
+
+	var instanceErr *InstanceError
+	if assert.ErrorAs(t, err, &instanceErr) {
+		assert.Equal(t, "i-000000a1", instanceErr.InstanceID)
+	}
+	var awsErr *awsProvider.Error
+	if assert.ErrorAs(t, err, &awsErr) {
+		assert.Equal(t, awsProvider.ErrResourceNotFound, awsErr.Category)
+	}
+}
+
+// TestRun_Interrupted tests that cancelling a run stops queuing instances, and that the instances checked
+// before the cancellation are still summarized while the ones cut short are not counted as errors.
+func TestRun_Interrupted(t *testing.T) {
+	config := Config{
+		InstanceIDs:      []string{"i-00000001", "i-00000002", "i-00000003", "i-000000a5"},
+		ConfigPath:       "test.tf",
+		ConcurrencyLimit: 1,
+	}
+	service, instanceMock, parserMock, reportMock := setupServiceWithMocks(t, config)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	parserMock.On("GetDesiredState", mock.Anything, config.ConfigPath).Return(&models.InstanceDetails{InstanceType: "t2.micro"}, nil)
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).Return(
+		[]*models.InstanceDetails{
+			{InstanceID: "i-00000001", InstanceType: "t2.large"},
+			{InstanceID: "i-00000002", InstanceType: "t2.micro"},
+			{InstanceID: "i-00000003", InstanceType: "t2.micro"},
+		},
+		&awsProvider.PartialFetchError{Failed: map[string]error{"i-000000a5": context.Canceled}},
+	)
+	// The first instance is interrupted while reporting; with a concurrency of 1 the second one is
+	// already queued, and the third is never started
+	reportMock.On("PrintReport", "i-00000001", mock.Anything, mock.Anything).Run(func(mock.Arguments) { cancel() }).Return(nil).Once()
+	reportMock.On("PrintReport", "i-00000002", mock.Anything, mock.Anything).Return(nil).Once()
+
+	anyDrift, anyError, err := service.Run(ctx)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.True(t, anyDrift)
+	assert.False(t, anyError, "Instances cut short by the interruption are not errors")
+	assert.Equal(t, 2, service.Stats().InstancesChecked)
+	assert.Equal(t, 1, service.Stats().InstancesDrifted)
+}
+
+// TestProcessAllInstances_TerminationProtection tests that termination protection is only fetched when requested
+func TestProcessAllInstances_TerminationProtection(t *testing.T) {
+	tfConfig := &models.InstanceDetails{InstanceType: "t2.micro"}
+
+	// Not requested: GetDisableApiTermination must not be called, the mock fails on unexpected calls
+	config := Config{InstanceIDs: []string{"i-1"}, ConfigPath: "test.tf", AttributesToCheck: []string{"instance_type"}}
+	service, instanceMock, _, reportMock := setupServiceWithMocks(t, config)
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).
+		Return([]*models.InstanceDetails{{InstanceID: "i-1", InstanceType: "t2.micro"}}, nil)
+	reportMock.On("PrintReport", "i-1", mock.Anything, mock.Anything).Return(nil)
+
+	results, err := service.processAllInstances(context.Background(), tfConfig, true)
+	assert.NoError(t, err)
+	assert.False(t, results[0].HasDrift)
+
+	// Requested: protection enabled in AWS but not in Terraform is drift
+	config.AttributesToCheck = []string{"instance_type", "disable_api_termination"}
+	service, instanceMock, _, reportMock = setupServiceWithMocks(t, config)
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).
+		Return([]*models.InstanceDetails{{InstanceID: "i-1", InstanceType: "t2.micro"}}, nil)
+	instanceMock.On("GetDisableApiTermination", mock.Anything, "i-1").Return(true, nil).Once()
+	reportMock.On("PrintReport", "i-1", mock.Anything, mock.Anything).Return(nil)
+
+	results, err = service.processAllInstances(context.Background(), tfConfig, true)
+	assert.NoError(t, err)
+	assert.True(t, results[0].HasDrift)
+	assert.Contains(t, results[0].Result.Drifts, "disable_api_termination")
+}
+
+// TestRun_TerminationProtectionFetchFails tests that an instance whose termination protection cannot be fetched
+// is reported as errored, while the other instances are still checked
+func TestRun_TerminationProtectionFetchFails(t *testing.T) {
+	config := Config{
+		InstanceIDs:       []string{"i-000000a1", "i-000000a2"},
+		ConfigPath:        "test.tf",
+		AttributesToCheck: []string{"instance_type", "disable_api_termination"},
+	}
+	service, instanceMock, parserMock, reportMock := setupServiceWithMocks(t, config)
+
+	parserMock.On("GetDesiredState", mock.Anything, config.ConfigPath).Return(&models.InstanceDetails{InstanceType: "t2.micro"}, nil)
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).Return([]*models.InstanceDetails{
+		{InstanceID: "i-000000a1", InstanceType: "t2.micro"},
+		{InstanceID: "i-000000a2", InstanceType: "t2.micro"},
+	}, nil)
+	instanceMock.On("GetDisableApiTermination", mock.Anything, "i-000000a1").Return(false, errors.New("AWS error")).Once()
+	instanceMock.On("GetDisableApiTermination", mock.Anything, "i-000000a2").Return(true, nil).Once()
+	reportMock.On("PrintReport", "i-000000a2", mock.MatchedBy(func(drifts []models.DriftDetail) bool {
+		return len(drifts) == 1 && drifts[0].Attribute == "disable_api_termination"
+	}), mock.Anything).Return(nil).Once()
+
+	anyDrift, anyError, err := service.Run(context.Background())
+
+	assert.True(t, anyDrift)
+	assert.True(t, anyError)
+	var instanceErr *InstanceError
+	if assert.ErrorAs(t, err, &instanceErr) {
+		assert.Equal(t, "i-000000a1", instanceErr.InstanceID)
+	}
+	assert.ErrorContains(t, err, "error fetching termination protection of instance i-000000a1")
+	assert.Equal(t, 2, service.Stats().InstancesChecked)
+	assert.Equal(t, 1, service.Stats().InstancesErrored)
+}
+
+// TestProcessAllInstances_UserData tests that user data is fetched when requested
+func TestProcessAllInstances_UserData(t *testing.T) {
+	script := "#!/bin/bash\n"
+	tfConfig := &models.InstanceDetails{InstanceType: "t2.micro", UserData: &script}
+
+	config := Config{InstanceIDs: []string{"i-1"}, ConfigPath: "test.tf", AttributesToCheck: []string{"user_data"}}
+	service, instanceMock, _, reportMock := setupServiceWithMocks(t, config)
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).
+		Return([]*models.InstanceDetails{{InstanceID: "i-1", InstanceType: "t2.micro"}}, nil)
+	instanceMock.On("GetUserData", mock.Anything, "i-1").Return("#!/bin/sh\n", nil).Once()
+	reportMock.On("PrintReport", "i-1", mock.Anything, mock.Anything).Return(nil)
+
+	results, err := service.processAllInstances(context.Background(), tfConfig, true)
+	assert.NoError(t, err)
+	assert.True(t, results[0].HasDrift)
+	assert.Contains(t, results[0].Result.Drifts, "user_data")
+}
+
+// TestProcessAllInstances_UserDataFetchFails tests that an instance whose user data cannot be fetched gets an
+// errored result, while the other instances are still checked
+func TestProcessAllInstances_UserDataFetchFails(t *testing.T) {
+	script := "#!/bin/bash\n"
+	tfConfig := &models.InstanceDetails{InstanceType: "t2.micro", UserData: &script}
+
+	config := Config{InstanceIDs: []string{"i-1", "i-2"}, ConfigPath: "test.tf", AttributesToCheck: []string{"user_data"}}
+	service, instanceMock, _, reportMock := setupServiceWithMocks(t, config)
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).Return([]*models.InstanceDetails{
+		{InstanceID: "i-1", InstanceType: "t2.micro"},
+		{InstanceID: "i-2", InstanceType: "t2.micro"},
+	}, nil)
+	instanceMock.On("GetUserData", mock.Anything, "i-1").Return("", errors.New("AWS error")).Once()
+	instanceMock.On("GetUserData", mock.Anything, "i-2").Return(script, nil).Once()
+	reportMock.On("PrintReport", "i-2", mock.Anything, mock.Anything).Return(nil).Once()
+
+	results, err := service.processAllInstances(context.Background(), tfConfig, true)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "i-2", results[0].InstanceID)
+	assert.NoError(t, results[0].Error)
+	assert.False(t, results[0].HasDrift)
+	assert.Equal(t, "i-1", results[1].InstanceID)
+	assert.ErrorContains(t, results[1].Error, "error fetching user data of instance i-1")
+}
+
+// TestProcessAllInstances_ShutdownBehavior tests that the shutdown behavior is fetched when requested
+func TestProcessAllInstances_ShutdownBehavior(t *testing.T) {
+	tfConfig := &models.InstanceDetails{InstanceType: "t2.micro"}
+
+	config := Config{InstanceIDs: []string{"i-1"}, ConfigPath: "test.tf", AttributesToCheck: []string{"instance_initiated_shutdown_behavior"}}
+	service, instanceMock, _, reportMock := setupServiceWithMocks(t, config)
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).
+		Return([]*models.InstanceDetails{{InstanceID: "i-1", InstanceType: "t2.micro"}}, nil)
+	instanceMock.On("GetInstanceInitiatedShutdownBehavior", mock.Anything, "i-1").Return("terminate", nil).Once()
+	reportMock.On("PrintReport", "i-1", mock.Anything, mock.Anything).Return(nil)
+
+	results, err := service.processAllInstances(context.Background(), tfConfig, true)
+	assert.NoError(t, err)
+	assert.True(t, results[0].HasDrift)
+	assert.Contains(t, results[0].Result.Drifts, "instance_initiated_shutdown_behavior")
+}
+
+// TestProcessAllInstances_ShutdownBehaviorFetchFails tests that an instance whose shutdown behavior cannot be
+// fetched gets an errored result, while the other instances are still checked
+func TestProcessAllInstances_ShutdownBehaviorFetchFails(t *testing.T) {
+	tfConfig := &models.InstanceDetails{InstanceType: "t2.micro"}
+
+	config := Config{InstanceIDs: []string{"i-1", "i-2"}, ConfigPath: "test.tf", AttributesToCheck: []string{"instance_initiated_shutdown_behavior"}}
+	service, instanceMock, _, reportMock := setupServiceWithMocks(t, config)
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).Return([]*models.InstanceDetails{
+		{InstanceID: "i-1", InstanceType: "t2.micro"},
+		{InstanceID: "i-2", InstanceType: "t2.micro"},
+	}, nil)
+	instanceMock.On("GetInstanceInitiatedShutdownBehavior", mock.Anything, "i-1").Return("", errors.New("AWS error")).Once()
+	instanceMock.On("GetInstanceInitiatedShutdownBehavior", mock.Anything, "i-2").Return("terminate", nil).Once()
+	reportMock.On("PrintReport", "i-2", mock.Anything, mock.Anything).Return(nil).Once()
+
+	results, err := service.processAllInstances(context.Background(), tfConfig, true)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "i-2", results[0].InstanceID)
+	assert.True(t, results[0].HasDrift)
+	assert.Equal(t, "i-1", results[1].InstanceID)
+	assert.ErrorContains(t, results[1].Error, "error fetching shutdown behavior of instance i-1")
+}
+
+// TestProcessAllInstances_CPUCredits tests that CPU credits are fetched, for burstable instances only, when requested
+func TestProcessAllInstances_CPUCredits(t *testing.T) {
+	tfConfig := &models.InstanceDetails{CPUCredits: "standard"}
+
+	config := Config{InstanceIDs: []string{"i-1", "i-2", "i-3", "i-4"}, ConfigPath: "test.tf", AttributesToCheck: []string{"cpu_credits"}}
+	service, instanceMock, _, reportMock := setupServiceWithMocks(t, config)
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).Return([]*models.InstanceDetails{
+		{InstanceID: "i-1", InstanceType: "t3.micro"},
+		{InstanceID: "i-2", InstanceType: "m5.large"},
+		{InstanceID: "i-3", InstanceType: "trn1.2xlarge"}, // Trainium, not burstable despite the t
+		{InstanceID: "i-4", InstanceType: "t4g.small"},
+	}, nil)
+	instanceMock.On("GetCreditSpecifications", mock.Anything, []string{"i-1", "i-4"}).
+		Return(map[string]string{"i-1": "unlimited", "i-4": "standard"}, nil).Once()
+	reportMock.On("PrintReport", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	results, err := service.processAllInstances(context.Background(), tfConfig, true)
+	assert.NoError(t, err)
+	for _, result := range results {
+		assert.Equal(t, result.InstanceID == "i-1", result.HasDrift, result.InstanceID)
+	}
+}
+
+// TestProcessAllInstances_CPUCreditsFetchFails tests that the burstable instances of a region whose credit
+// specifications cannot be described get errored results, while the instances of other regions are still checked
+func TestProcessAllInstances_CPUCreditsFetchFails(t *testing.T) {
+	config := Config{InstanceIDs: []string{"i-1", "i-2"}, ConfigPath: "test.tf", AttributesToCheck: []string{"cpu_credits"}}
+	service, instanceMock, _, _ := setupServiceWithMocks(t, config)
+	regionalMock := awsMocks.NewInstanceServiceAPI(t)
+	service.SetRegionalService("eu-west-1", regionalMock)
+	instances := []*models.InstanceDetails{
+		{InstanceID: "i-1", InstanceType: "t3.micro"},
+		{InstanceID: "i-2", InstanceType: "t3.micro", Region: "eu-west-1"},
+	}
+	instanceMock.On("GetCreditSpecifications", mock.Anything, []string{"i-1"}).
+		Return(map[string]string{"i-1": "unlimited"}, nil).Once()
+	regionalMock.On("GetCreditSpecifications", mock.Anything, []string{"i-2"}).
+		Return(nil, errors.New("AWS error")).Once()
+
+	failed, err := service.fetchCreditSpecifications(context.Background(), instances)
+	require.NoError(t, err)
+	assert.Equal(t, "unlimited", instances[0].CPUCredits)
+	require.Len(t, failed, 1)
+	assert.ErrorContains(t, failed["i-2"], "error fetching credit specifications")
+
+	checked, results := withoutFetchFailures(instances, failed, nil)
+	assert.Equal(t, instances[:1], checked)
+	require.Len(t, results, 1)
+	assert.Equal(t, "i-2", results[0].InstanceID)
+}
+
+// TestRun_MatchByName tests that each instance is compared with the aws_instance resource of the same name,
+// and that instances without a matching resource are errors.
+func TestRun_MatchByName(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "main.tf")
+	hcl := `
+resource "aws_instance" "web" {
+  instance_type = "t3.small"
+  tags = { Name = "web" }
+}
+
+resource "aws_instance" "worker" {
+  instance_type = "c5.large"
+  tags = { Name = "worker" }
+}
+`
+	assert.NoError(t, os.WriteFile(configPath, []byte(hcl), 0o600))
+
+	config := Config{
+		InstanceIDs: []string{"i-0000000a", "i-0000000b", "i-0000000c"},
+		ConfigPath:  configPath,
+		MatchBy:     MatchByName,
+	}
+	instanceMock, _, reportMock, logger := createMocks(t)
+	service := NewService(config, instanceMock, terraform.NewParserWithLogger(logger), reportMock, logger)
+
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).Return([]*models.InstanceDetails{
+		{InstanceID: "i-0000000a", InstanceType: "t3.small", Tags: map[string]string{"Name": "web"}},
+		{InstanceID: "i-0000000b", InstanceType: "t3.small", Tags: map[string]string{"Name": "worker"}},
+		{InstanceID: "i-0000000c", InstanceType: "t3.small", Tags: map[string]string{"Name": "bastion"}},
+	}, nil)
+	reportMock.On("PrintReport", "i-0000000a", mock.Anything, mock.Anything).Return(nil).Once()
+	reportMock.On("PrintReport", "i-0000000b", mock.MatchedBy(func(drifts []models.DriftDetail) bool {
+		return len(drifts) == 1 && drifts[0].Attribute == "instance_type" && drifts[0].TerraformValue == "c5.large"
+	}), mock.Anything).Return(nil).Once()
+
+	anyDrift, anyError, err := service.Run(context.Background())
+
+	assert.True(t, anyDrift)
+	assert.True(t, anyError)
+	assert.ErrorContains(t, err, `no aws_instance resource tagged Name="bastion" matches instance i-0000000c`)
+}
+
+// TestProcessAllInstances_BlockDevices tests that volume sizes and types are fetched when block devices are requested
+func TestProcessAllInstances_BlockDevices(t *testing.T) {
+	tfConfig := &models.InstanceDetails{
+		InstanceType: "t2.micro",
+		BlockDevices: []models.BlockDevice{{DeviceName: "/dev/sdf", VolumeSize: 100, VolumeType: "gp3"}},
+	}
+
+	config := Config{InstanceIDs: []string{"i-1"}, ConfigPath: "test.tf", AttributesToCheck: []string{"block_devices"}}
+	service, instanceMock, _, reportMock := setupServiceWithMocks(t, config)
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).
+		Return([]*models.InstanceDetails{{
+			InstanceID:   "i-1",
+			InstanceType: "t2.micro",
+			BlockDevices: []models.BlockDevice{{DeviceName: "/dev/sdf", VolumeID: "vol-1"}},
+		}}, nil)
+	instanceMock.On("GetVolumesDetails", mock.Anything, []string{"vol-1"}).
+		Return([]*models.VolumeDetails{{VolumeID: "vol-1", VolumeSize: 50, VolumeType: "gp3"}}, nil).Once()
+	reportMock.On("PrintReport", "i-1", mock.Anything, mock.Anything).Return(nil)
+
+	results, err := service.processAllInstances(context.Background(), tfConfig, true)
+	assert.NoError(t, err)
+	assert.True(t, results[0].HasDrift)
+	assert.Equal(t, "gp3 50GiB", results[0].Result.Drifts["block_devices./dev/sdf"].AWSValue)
+}
+
+// TestProcessAllInstances_VolumeTags tests that the tags of the volumes are fetched when volume tags are requested
+func TestProcessAllInstances_VolumeTags(t *testing.T) {
+	tfConfig := &models.InstanceDetails{InstanceType: "t2.micro", VolumeTags: map[string]string{"CostCenter": "42"}}
+
+	config := Config{InstanceIDs: []string{"i-1"}, ConfigPath: "test.tf", AttributesToCheck: []string{"volume_tags"}}
+	service, instanceMock, _, reportMock := setupServiceWithMocks(t, config)
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).
+		Return([]*models.InstanceDetails{{
+			InstanceID:   "i-1",
+			InstanceType: "t2.micro",
+			RootVolume:   &models.BlockDevice{DeviceName: "/dev/xvda", VolumeID: "vol-root"},
+			BlockDevices: []models.BlockDevice{{DeviceName: "/dev/sdf", VolumeID: "vol-1"}},
+		}}, nil)
+	instanceMock.On("GetVolumesDetails", mock.Anything, []string{"vol-root", "vol-1"}).
+		Return([]*models.VolumeDetails{
+			{VolumeID: "vol-root", Tags: map[string]string{"CostCenter": "42"}},
+			{VolumeID: "vol-1", Tags: map[string]string{"CostCenter": "7"}},
+		}, nil).Once()
+	reportMock.On("PrintReport", "i-1", mock.Anything, mock.Anything).Return(nil)
+
+	results, err := service.processAllInstances(context.Background(), tfConfig, true)
+	assert.NoError(t, err)
+	assert.True(t, results[0].HasDrift)
+	assert.Equal(t, "CostCenter=7", results[0].Result.Drifts["volume_tags./dev/sdf"].AWSValue)
+	assert.NotContains(t, results[0].Result.Drifts, "volume_tags./dev/xvda", "The root volume has the volume tags")
+}
+
+// TestFetchVolumeDetails_RegionFails tests that the volumes of a region that cannot be described only fail the
+// instances of that region with volumes, while the other regions are still filled in
+func TestFetchVolumeDetails_RegionFails(t *testing.T) {
+	config := Config{InstanceIDs: []string{"i-1"}, ConfigPath: "test.tf", AttributesToCheck: []string{"block_devices"}}
+	service, instanceMock, _, _ := setupServiceWithMocks(t, config)
+	regionalMock := awsMocks.NewInstanceServiceAPI(t)
+	service.SetRegionalService("eu-west-1", regionalMock)
+
+	instances := []*models.InstanceDetails{
+		{InstanceID: "i-1", BlockDevices: []models.BlockDevice{{DeviceName: "/dev/sdf", VolumeID: "vol-1"}}},
+		{InstanceID: "i-2", Region: "eu-west-1", BlockDevices: []models.BlockDevice{
+			{DeviceName: "/dev/sdf", VolumeID: "vol-2"},
+			{DeviceName: "/dev/sdg", VolumeID: "vol-3"},
+		}},
+		{InstanceID: "i-3", Region: "eu-west-1"},
+	}
+	instanceMock.On("GetVolumesDetails", mock.Anything, []string{"vol-1"}).
+		Return([]*models.VolumeDetails{{VolumeID: "vol-1", VolumeSize: 50, VolumeType: "gp3"}}, nil).Once()
+	regionalMock.On("GetVolumesDetails", mock.Anything, []string{"vol-2", "vol-3"}).
+		Return(nil, errors.New("InvalidVolume.NotFound: The volume 'vol-3' does not exist")).Once()
+
+	failed, err := service.fetchVolumeDetails(context.Background(), instances)
+
+	require.NoError(t, err)
+	assert.Equal(t, 50, instances[0].BlockDevices[0].VolumeSize)
+	require.Len(t, failed, 1)
+	assert.ErrorContains(t, failed["i-2"], "error fetching volume details: InvalidVolume.NotFound")
+}
+
+// TestRun_OnlyStates tests that instances outside the requested states are skipped rather than checked.
+func TestRun_OnlyStates(t *testing.T) {
+	config := Config{
+		InstanceIDs: []string{"i-000000a6", "i-000000a7"},
+		ConfigPath:  "test.tf",
+		OnlyStates:  []string{"Running"},
+	}
+	service, instanceMock, parserMock, reportMock := setupServiceWithMocks(t, config)
+
+	parserMock.On("GetDesiredState", mock.Anything, config.ConfigPath).Return(&models.InstanceDetails{InstanceType: "t2.micro"}, nil)
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).Return([]*models.InstanceDetails{
+		{InstanceID: "i-000000a6", InstanceType: "t2.micro", State: "running"},
+		{InstanceID: "i-000000a7", InstanceType: "t2.large", State: "stopped"},
+	}, nil)
+	// Only the running instance is reported
+	reportMock.On("PrintReport", "i-000000a6", mock.Anything, mock.Anything).Return(nil).Once()
+
+	anyDrift, anyError, err := service.Run(context.Background())
+
+	assert.NoError(t, err)
+	assert.False(t, anyDrift, "The drift of the skipped instance should not count")
+	assert.False(t, anyError)
+	assert.Equal(t, 1, service.Stats().InstancesChecked)
+	assert.Equal(t, 1, service.Stats().InstancesSkipped)
+}
+
+// TestProcessAllInstances_LaunchWindow tests that instances launched outside the requested window are skipped,
+// with the reason recorded apart from instances skipped by state.
+func TestProcessAllInstances_LaunchWindow(t *testing.T) {
+	config := Config{
+		InstanceIDs:    []string{"i-recent", "i-old", "i-new", "i-stopped"},
+		ConfigPath:     "test.tf",
+		OnlyStates:     []string{"running"},
+		LaunchedAfter:  "72h",
+		LaunchedBefore: "1h",
+	}
+	service, instanceMock, _, reportMock := setupServiceWithMocks(t, config)
+
+	launchedAgo := func(ago time.Duration) *time.Time {
+		launchTime := time.Now().Add(-ago)
+		return &launchTime
+	}
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).Return([]*models.InstanceDetails{
+		{InstanceID: "i-recent", InstanceType: "t2.micro", State: "running", LaunchTime: launchedAgo(24 * time.Hour)},
+		{InstanceID: "i-old", InstanceType: "t2.large", State: "running", LaunchTime: launchedAgo(30 * 24 * time.Hour)},
+		{InstanceID: "i-new", InstanceType: "t2.large", State: "running", LaunchTime: launchedAgo(time.Minute)},
+		{InstanceID: "i-stopped", InstanceType: "t2.large", State: "stopped", LaunchTime: launchedAgo(24 * time.Hour)},
+	}, nil)
+	reportMock.On("PrintReport", "i-recent", mock.Anything, mock.Anything).Return(nil).Once()
+
+	results, err := service.processAllInstances(context.Background(), &models.InstanceDetails{InstanceType: "t2.micro"}, true)
+
+	assert.NoError(t, err)
+	skipReasons := make(map[string]string)
+	for _, result := range results {
+		if result.Skipped {
+			skipReasons[result.InstanceID] = result.SkipReason
+		}
+	}
+	assert.Equal(t, map[string]string{
+		"i-old":     skipReasonLaunchTime,
+		"i-new":     skipReasonLaunchTime,
+		"i-stopped": skipReasonState,
+	}, skipReasons)
+}
+
+// TestGenerateSummaryReport_SkipReasons tests that instances skipped for each reason are counted separately
+func TestGenerateSummaryReport_SkipReasons(t *testing.T) {
+	loggerMock := loggerMocks.NewLogger(t)
+	service := NewService(Config{}, awsMocks.NewInstanceServiceAPI(t), terraformMocks.NewDesiredStateProvider(t), reportMocks.NewIPrinter(t), loggerMock)
+
+	loggerMock.On("Info", "Summary: Checked %d instances, %d with drift (%.1f%%), %d with errors, %d skipped by state, %d skipped by launch time",
+		1, 0, 0.0, 0, 1, 2).Return()
+
+	service.generateSummaryReport([]DriftDetectionResult{
+		{InstanceID: "i-1"},
+		{InstanceID: "i-2", Skipped: true, SkipReason: skipReasonState},
+		{InstanceID: "i-3", Skipped: true, SkipReason: skipReasonLaunchTime},
+		{InstanceID: "i-4", Skipped: true, SkipReason: skipReasonLaunchTime},
+	})
+}
+
+// TestRun_EmptyDesiredStateFailsFast tests that a configuration that parses but sets no attribute fails the
+// run, instead of reporting every attribute of every instance as drift.
+func TestRun_EmptyDesiredStateFailsFast(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "main.tf")
+	require.NoError(t, os.WriteFile(configPath, []byte(`resource "aws_instance" "placeholder" {
+  instance_type = ""
+}
+`), 0o600))
+	config := Config{
+		InstanceIDs: []string{"i-00000001"},
+		ConfigPath:  configPath,
+	}
+	instanceMock, _, reportMock, loggerMock := createMocks(t)
+	// The AWS and report mocks fail the test on any call
+	service := NewService(config, instanceMock, terraform.NewParserWithLogger(loggerMock), reportMock, loggerMock)
+
+	_, anyError, err := service.Run(context.Background())
+	var unavailable *DesiredStateUnavailableError
+	require.ErrorAs(t, err, &unavailable)
+	assert.Equal(t, config.ConfigPath, unavailable.Path)
+	assert.True(t, anyError)
+}
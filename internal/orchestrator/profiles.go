@@ -0,0 +1,50 @@
+package orchestrator
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// builtinAttributeProfiles are the named attribute sets available without any configuration.
+// Profiles of the same name in Config.AttributeProfiles replace them.
+var builtinAttributeProfiles = map[string][]string{
+	"security": {"metadata_options", "security_groups", "disable_api_termination", "instance_initiated_shutdown_behavior"},
+	"network":  {"subnet_id", "vpc_id", "availability_zone", "security_groups", "placement_group"},
+	"cost": {"instance_type", "instance_lifecycle", "capacity_reservation_id", "block_devices",
+		"root_delete_on_termination", "cpu_credits"},
+}
+
+// attributeProfiles returns the profiles that can be selected, the configured ones overriding the built-in ones
+func (s *Service) attributeProfiles() map[string][]string {
+	profiles := maps.Clone(builtinAttributeProfiles)
+	maps.Copy(profiles, s.config.AttributeProfiles)
+	return profiles
+}
+
+// expandAttributeProfile adds the attributes of the selected profile to the attributes to check, once,
+// before they are validated. Attributes already listed are kept, so a profile can be combined with --attributes.
+func (s *Service) expandAttributeProfile() error {
+	if s.config.AttributeProfile == "" {
+		return nil
+	}
+
+	profiles := s.attributeProfiles()
+	attributes, ok := profiles[s.config.AttributeProfile]
+	if !ok {
+		return fmt.Errorf("unknown attribute profile %q: expected one of %s",
+			s.config.AttributeProfile, strings.Join(slices.Sorted(maps.Keys(profiles)), ", "))
+	}
+	if len(attributes) == 0 {
+		return fmt.Errorf("attribute profile %q has no attributes", s.config.AttributeProfile)
+	}
+
+	for _, attr := range attributes {
+		if !slices.Contains(s.config.AttributesToCheck, attr) {
+			s.config.AttributesToCheck = append(s.config.AttributesToCheck, attr)
+		}
+	}
+	s.logger.Debug("Attribute profile %q expanded to %s", s.config.AttributeProfile, strings.Join(s.config.AttributesToCheck, ", "))
+	return nil
+}
@@ -0,0 +1,70 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"driftdetector/internal/driftcheck"
+)
+
+func TestBuiltinAttributeProfilesSupported(t *testing.T) {
+	for name, attributes := range builtinAttributeProfiles {
+		_, err := driftcheck.SupportedAttributes(attributes)
+		assert.NoError(t, err, "profile %s", name)
+	}
+}
+
+func TestExpandAttributeProfile(t *testing.T) {
+	// Without a profile the attributes are left as given
+	service, _, _, _ := setupServiceWithMocks(t, Config{AttributesToCheck: []string{"tags"}})
+	require.NoError(t, service.expandAttributeProfile())
+	assert.Equal(t, []string{"tags"}, service.config.AttributesToCheck)
+
+	// A built-in profile is combined with the listed attributes
+	service, _, _, _ = setupServiceWithMocks(t, Config{
+		AttributesToCheck: []string{"tags", "security_groups"},
+		AttributeProfile:  "network",
+	})
+	require.NoError(t, service.expandAttributeProfile())
+	assert.Equal(t, []string{"tags", "security_groups", "subnet_id", "vpc_id", "availability_zone", "placement_group"},
+		service.config.AttributesToCheck)
+
+	// Configured profiles override the built-in ones
+	service, _, _, _ = setupServiceWithMocks(t, Config{
+		AttributeProfile:  "security",
+		AttributeProfiles: map[string][]string{"security": {"metadata_options"}, "compute": {"instance_type", "ami"}},
+	})
+	require.NoError(t, service.expandAttributeProfile())
+	assert.Equal(t, []string{"metadata_options"}, service.config.AttributesToCheck)
+
+	service, _, _, _ = setupServiceWithMocks(t, Config{
+		AttributeProfile:  "empty",
+		AttributeProfiles: map[string][]string{"empty": {}},
+	})
+	assert.ErrorContains(t, service.expandAttributeProfile(), `attribute profile "empty" has no attributes`)
+
+	service, _, _, _ = setupServiceWithMocks(t, Config{
+		AttributeProfile:  "bogus",
+		AttributeProfiles: map[string][]string{"compute": {"instance_type"}},
+	})
+	assert.ErrorContains(t, service.expandAttributeProfile(),
+		`unknown attribute profile "bogus": expected one of compute, cost, network, security`)
+}
+
+// TestRun_UnknownAttributeProfileFailsFast tests that an unknown profile fails the run before any instance is fetched
+func TestRun_UnknownAttributeProfileFailsFast(t *testing.T) {
+	config := Config{
+		InstanceIDs:      []string{"i-00000001"},
+		ConfigPath:       "test.tf",
+		AttributeProfile: "bogus",
+	}
+	// The mocks fail the test on any call
+	service, _, _, _ := setupServiceWithMocks(t, config)
+
+	_, anyError, err := service.Run(context.Background())
+	assert.ErrorContains(t, err, "unknown attribute profile")
+	assert.True(t, anyError)
+}
@@ -0,0 +1,61 @@
+package orchestrator
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// progressSteps is the number of progress lines printed when the output is not a terminal, so logs of
+// long runs are not flooded with a line per instance.
+const progressSteps = 10
+
+// progressCounter prints how many of the queued instances have been checked while a run is in progress.
+// On a terminal the count is updated in place; otherwise a line is printed every tenth of the instances.
+type progressCounter struct {
+	out     io.Writer
+	total   int
+	checked int
+	inPlace bool
+}
+
+// newProgressCounter creates a counter of total instances writing to out, nil when progress is disabled
+func newProgressCounter(enabled bool, out io.Writer, total int) *progressCounter {
+	if !enabled || total == 0 {
+		return nil
+	}
+	file, isFile := out.(*os.File)
+	return &progressCounter{
+		out:     out,
+		total:   total,
+		inPlace: isFile && term.IsTerminal(int(file.Fd())),
+	}
+}
+
+// increment counts a checked instance and prints the progress
+func (p *progressCounter) increment() {
+	if p == nil {
+		return
+	}
+	p.checked++
+
+	if p.inPlace {
+		// Carriage return overwrites the previous count
+		fmt.Fprintf(p.out, "\rChecked %d/%d instances", p.checked, p.total)
+		return
+	}
+	// Print when the count reaches a new step, and always for the last instance
+	if p.checked == p.total || p.checked*progressSteps/p.total != (p.checked-1)*progressSteps/p.total {
+		fmt.Fprintf(p.out, "Checked %d/%d instances\n", p.checked, p.total)
+	}
+}
+
+// finish ends the line updated in place, so subsequent output starts on its own line
+func (p *progressCounter) finish() {
+	if p == nil || !p.inPlace || p.checked == 0 {
+		return
+	}
+	fmt.Fprintln(p.out)
+}
@@ -0,0 +1,44 @@
+package orchestrator
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressCounter(t *testing.T) {
+	// Disabled progress is a nil counter, which is safe to use
+	assert.Nil(t, newProgressCounter(false, &bytes.Buffer{}, 3))
+	var disabled *progressCounter
+	disabled.increment()
+	disabled.finish()
+
+	// A line per tenth of the instances when not writing to a terminal
+	var out bytes.Buffer
+	progress := newProgressCounter(true, &out, 20)
+	for range 20 {
+		progress.increment()
+	}
+	progress.finish()
+	assert.Equal(t, 10, bytes.Count(out.Bytes(), []byte("\n")))
+	assert.Contains(t, out.String(), "Checked 2/20 instances\n")
+	assert.NotContains(t, out.String(), "Checked 3/20 instances")
+	assert.Contains(t, out.String(), "Checked 20/20 instances\n")
+
+	// Fewer instances than steps print a line per instance
+	out.Reset()
+	progress = newProgressCounter(true, &out, 3)
+	for range 3 {
+		progress.increment()
+	}
+	assert.Equal(t, "Checked 1/3 instances\nChecked 2/3 instances\nChecked 3/3 instances\n", out.String())
+
+	// Updated in place on a terminal
+	out.Reset()
+	progress = &progressCounter{out: &out, total: 2, inPlace: true}
+	progress.increment()
+	progress.increment()
+	progress.finish()
+	assert.Equal(t, "\rChecked 1/2 instances\rChecked 2/2 instances\n", out.String())
+}
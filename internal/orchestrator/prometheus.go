@@ -0,0 +1,71 @@
+package orchestrator
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// writePrometheusTextfile writes the statistics of a run in the Prometheus text exposition format,
+// for the node_exporter textfile collector. The file is replaced atomically so the collector never
+// reads a partially written file.
+func (s *Service) writePrometheusTextfile(stats RunStats) error {
+	path := s.config.PrometheusTextfile
+
+	// The temporary file must be in the same directory for the rename to be atomic
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating Prometheus textfile: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	if err := writePrometheusMetrics(tmp, stats); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("error writing Prometheus textfile: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing Prometheus textfile: %w", err)
+	}
+	// CreateTemp creates the file readable by the owner only, but the collector may run as another user
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("error writing Prometheus textfile: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("error replacing Prometheus textfile: %w", err)
+	}
+	return nil
+}
+
+// writePrometheusMetrics writes the statistics of a run as Prometheus gauges.
+func writePrometheusMetrics(w io.Writer, stats RunStats) error {
+	var b strings.Builder
+
+	gauge := func(name, help string, value any) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n%s %v\n", name, help, name, name, value)
+	}
+	gauge("driftdetector_instances_checked", "Number of instances checked in the last run.", stats.InstancesChecked)
+	gauge("driftdetector_instances_drifted", "Number of instances with drift in the last run.", stats.InstancesDrifted)
+	gauge("driftdetector_instances_errored", "Number of instances that could not be checked in the last run.", stats.InstancesErrored)
+	gauge("driftdetector_instances_skipped", "Number of instances skipped because of their state or launch time in the last run.", stats.InstancesSkipped)
+	gauge("driftdetector_api_calls", "Number of AWS API calls made in the last run.", stats.APICalls)
+	gauge("driftdetector_run_duration_seconds", "Duration of the last run in seconds.", stats.DurationSeconds)
+
+	// Attributes are sorted so the file only changes when the values do
+	attributes := make([]string, 0, len(stats.AttributeDrifts))
+	for attribute := range stats.AttributeDrifts {
+		attributes = append(attributes, attribute)
+	}
+	sort.Strings(attributes)
+
+	b.WriteString("# HELP driftdetector_attribute_drifted_instances Number of instances with drift per attribute in the last run.\n")
+	b.WriteString("# TYPE driftdetector_attribute_drifted_instances gauge\n")
+	for _, attribute := range attributes {
+		fmt.Fprintf(&b, "driftdetector_attribute_drifted_instances{attribute=%q} %d\n", attribute, stats.AttributeDrifts[attribute])
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,63 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWritePrometheusMetrics(t *testing.T) {
+	stats := RunStats{
+		InstancesChecked: 5,
+		InstancesDrifted: 2,
+		InstancesErrored: 1,
+		APICalls:         3,
+		DurationSeconds:  1.25,
+		AttributeDrifts:  map[string]int{"tags": 2, "instance_type": 1},
+	}
+
+	var b strings.Builder
+	require.NoError(t, writePrometheusMetrics(&b, stats))
+	output := b.String()
+
+	assert.Contains(t, output, "# TYPE driftdetector_instances_drifted gauge\ndriftdetector_instances_drifted 2\n")
+	assert.Contains(t, output, "driftdetector_instances_checked 5\n")
+	assert.Contains(t, output, "driftdetector_instances_errored 1\n")
+	assert.Contains(t, output, "driftdetector_api_calls 3\n")
+	assert.Contains(t, output, "driftdetector_run_duration_seconds 1.25\n")
+	assert.Contains(t, output,
+		"driftdetector_attribute_drifted_instances{attribute=\"instance_type\"} 1\n"+
+			"driftdetector_attribute_drifted_instances{attribute=\"tags\"} 2\n",
+		"Attributes should be sorted")
+}
+
+func TestWritePrometheusTextfile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "driftdetector.prom")
+	require.NoError(t, os.WriteFile(path, []byte("stale"), 0o644))
+
+	service, _, _, _ := setupServiceWithMocks(t, Config{PrometheusTextfile: path})
+	require.NoError(t, service.writePrometheusTextfile(RunStats{InstancesDrifted: 4, Duration: time.Second}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "driftdetector_instances_drifted 4\n")
+
+	// The file is replaced through a rename, without leaving temporary files behind
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o644), info.Mode().Perm())
+
+	// Missing directories fail instead of silently skipping the metrics
+	service, _, _, _ = setupServiceWithMocks(t, Config{PrometheusTextfile: filepath.Join(dir, "missing", "metrics.prom")})
+	assert.Error(t, service.writePrometheusTextfile(RunStats{}))
+}
@@ -0,0 +1,291 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+
+	"driftdetector/internal/models"
+	"driftdetector/internal/providers/aws"
+	"driftdetector/internal/report"
+)
+
+// regionSeparator separates the optional region prefix from an instance ID, e.g. us-east-1/i-123.
+const regionSeparator = "/"
+
+// splitRegionalInstanceID splits an instance ID of the form region/i-123 into its region and ID.
+// The region is empty for unqualified IDs, which are checked in the default region.
+func splitRegionalInstanceID(id string) (region, instanceID string) {
+	if region, instanceID, found := strings.Cut(id, regionSeparator); found {
+		return region, instanceID
+	}
+	return "", id
+}
+
+// validateRegionalInstanceID checks that an instance ID is either unqualified or has a single non-empty region prefix.
+func validateRegionalInstanceID(id string) error {
+	region, instanceID, qualified := strings.Cut(id, regionSeparator)
+	if !qualified {
+		instanceID = id
+	}
+	if instanceID == "" || (qualified && region == "") || strings.Contains(instanceID, regionSeparator) {
+		return fmt.Errorf("invalid instance ID %q: expected i-xxx or region/i-xxx", id)
+	}
+	return nil
+}
+
+// instanceIDPattern matches EC2 instance IDs, in the short (8 characters) or long (17 characters) format
+var instanceIDPattern = regexp.MustCompile(`^i-[0-9a-f]{8,17}$`)
+
+// validateInstanceIDFormat checks that every instance ID, without its region prefix, looks like an EC2 instance ID,
+// so typos are reported at once before any AWS API call rather than one by one as cryptic AWS errors.
+func validateInstanceIDFormat(ids []string) error {
+	var malformed []string
+	for _, id := range ids {
+		if _, instanceID := splitRegionalInstanceID(id); !instanceIDPattern.MatchString(instanceID) {
+			malformed = append(malformed, fmt.Sprintf("%q", id))
+		}
+	}
+	if len(malformed) == 0 {
+		return nil
+	}
+	return aws.NewAWSError(
+		aws.ErrInvalidInput,
+		aws.EC2ResourceType,
+		"",
+		fmt.Sprintf("malformed instance IDs %s: expected i- followed by 8 to 17 hexadecimal characters", strings.Join(malformed, ", ")),
+		nil,
+	)
+}
+
+// uniqueInstanceIDs returns the instance IDs without duplicates, in the order they were first listed, and the
+// duplicates that were removed. IDs qualified with different regions are different instances.
+func uniqueInstanceIDs(ids []string) ([]string, []string) {
+	unique := make([]string, 0, len(ids))
+	var duplicates []string
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			if !slices.Contains(duplicates, id) {
+				duplicates = append(duplicates, id)
+			}
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+	return unique, duplicates
+}
+
+// regionsToConfigure returns the regions that need their own AWS service: the configured regions
+// followed by any additional region referenced by a qualified instance ID, without duplicates.
+// The regions of Accounts are left out, as their services assume the role of the account.
+func regionsToConfigure(config Config) []string {
+	var regions []string
+	seen := make(map[string]bool)
+	add := func(region string) {
+		if region != "" && !seen[region] {
+			seen[region] = true
+			regions = append(regions, region)
+		}
+	}
+
+	for _, region := range config.Regions {
+		add(region)
+	}
+	for _, id := range config.InstanceIDs {
+		// The services of accounts are configured from Accounts
+		if region, _ := splitRegionalInstanceID(id); !isAccountScope(region) {
+			add(region)
+		}
+	}
+	return regions
+}
+
+// SetRegionalService registers the AWS service used for instances qualified with the given region.
+// Unqualified instance IDs keep using the service passed to NewService.
+func (s *Service) SetRegionalService(region string, awsSrv aws.InstanceServiceAPI) {
+	s.regionalAWSSrvs[region] = awsSrv
+}
+
+// serviceForRegion returns the AWS service responsible for the given region.
+// The empty region maps to the default service.
+func (s *Service) serviceForRegion(region string) (aws.InstanceServiceAPI, error) {
+	if region == "" {
+		return s.awsSrv, nil
+	}
+	awsSrv, ok := s.regionalAWSSrvs[region]
+	if !ok {
+		return nil, fmt.Errorf("no AWS service configured for region %s", region)
+	}
+	return awsSrv, nil
+}
+
+// groupInstanceIDsByRegion groups the instance IDs by region, preserving the order in which
+// regions and IDs first appear so results stay deterministic.
+func groupInstanceIDsByRegion(instanceIDs []string) ([]string, map[string][]string) {
+	var regions []string
+	groups := make(map[string][]string)
+	for _, id := range instanceIDs {
+		region, instanceID := splitRegionalInstanceID(id)
+		if _, exists := groups[region]; !exists {
+			regions = append(regions, region)
+		}
+		groups[region] = append(groups[region], instanceID)
+	}
+	return regions, groups
+}
+
+// fetchRegionalInstanceDetails retrieves the instances of every region from the matching AWS service
+// and tags each instance with the region it was fetched from. With ParallelRegions the regions are fetched
+// concurrently; the AWS services then share the ConcurrencyLimit on the API calls in flight.
+// Instances that could not be fetched while others were are returned as a map of errors keyed by
+// the instance ID as it was requested, so the caller can still check the fetched ones.
+func (s *Service) fetchRegionalInstanceDetails(
+	ctx context.Context,
+	instanceIDs []string,
+) ([]*models.InstanceDetails, map[string]error, error) {
+	regions, groups := groupInstanceIDsByRegion(instanceIDs)
+
+	// Results are stored per region and merged in order, so the output does not depend on timing
+	regionInstances := make([][]*models.InstanceDetails, len(regions))
+	regionFailed := make([]map[string]error, len(regions))
+
+	// Accounts are always fetched concurrently, as an organization has too many to scan one by one
+	g, ctx := errgroup.WithContext(ctx)
+	if !s.config.ParallelRegions && len(s.config.Accounts) == 0 {
+		g.SetLimit(1)
+	}
+	for i, region := range regions {
+		awsSrv, err := s.serviceForRegion(region)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		g.Go(func() error {
+			if region != "" {
+				s.logger.Debug("Fetching %d instances from %s", len(groups[region]), regionName(region))
+			}
+			start := s.clock.Now()
+			instances, failed, err := fetchRegionInstances(ctx, awsSrv, region, groups[region])
+			s.warnIfSlow(s.clock.Since(start), "Fetching %d instances from %s", len(groups[region]), regionName(region))
+			if err != nil {
+				if region != "" {
+					return fmt.Errorf("%s: %w", regionName(region), err)
+				}
+				return err
+			}
+			regionInstances[i], regionFailed[i] = instances, failed
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, nil, err
+	}
+
+	allInstances := make([]*models.InstanceDetails, 0, len(instanceIDs))
+	failed := make(map[string]error)
+	for i := range regions {
+		allInstances = append(allInstances, regionInstances[i]...)
+		for id, fetchErr := range regionFailed[i] {
+			failed[id] = fetchErr
+		}
+	}
+	return allInstances, failed, nil
+}
+
+// fetchRegionInstances retrieves the instances of a single region, tagged with the region.
+// Instances that could not be fetched while others were are returned keyed by their qualified ID.
+func fetchRegionInstances(
+	ctx context.Context,
+	awsSrv aws.InstanceServiceAPI,
+	region string,
+	instanceIDs []string,
+) ([]*models.InstanceDetails, map[string]error, error) {
+	instances, err := awsSrv.GetInstancesDetails(ctx, instanceIDs)
+	failed := make(map[string]error)
+	var partialErr *aws.PartialFetchError
+	switch {
+	case errors.As(err, &partialErr):
+		for id, fetchErr := range partialErr.Failed {
+			failed[qualifyInstanceID(region, id)] = fetchErr
+		}
+	case err != nil:
+		return nil, nil, err
+	}
+
+	for _, instance := range instances {
+		instance.Region = region
+	}
+	return instances, failed, nil
+}
+
+// regionName describes a region for log messages, the empty region being the default one and the scope of
+// an account naming the account too.
+func regionName(region string) string {
+	if region == "" {
+		return "the default region"
+	}
+	if isAccountScope(region) {
+		return accountScopeName(region)
+	}
+	return "region " + region
+}
+
+// qualifyInstanceID returns the instance ID prefixed with its region, the inverse of splitRegionalInstanceID.
+func qualifyInstanceID(region, instanceID string) string {
+	if region == "" {
+		return instanceID
+	}
+	return region + regionSeparator + instanceID
+}
+
+// recordProvenance records the account and region of the fetched and failed instances in their reports,
+// for printers that include them. The identity of each AWS service is resolved once, by the service itself;
+// an account that cannot be resolved is only logged, as it does not prevent checking the instances.
+func (s *Service) recordProvenance(ctx context.Context, instances []*models.InstanceDetails, failed []DriftDetectionResult) {
+	recorder, ok := s.reportPrinter.(report.IProvenanceRecorder)
+	if !ok {
+		return
+	}
+
+	byRegion := make(map[string]report.Provenance)
+	provenance := func(region string) report.Provenance {
+		if p, ok := byRegion[region]; ok {
+			return p
+		}
+		byRegion[region] = s.regionProvenance(ctx, region)
+		return byRegion[region]
+	}
+	for _, instance := range instances {
+		recorder.SetProvenance(instance.InstanceID, provenance(instance.Region))
+	}
+	for _, result := range failed {
+		region, _ := splitRegionalInstanceID(result.InstanceID)
+		recorder.SetProvenance(result.InstanceID, provenance(region))
+	}
+}
+
+// regionProvenance returns the account and region of the AWS service of a region, empty if the service
+// does not know them
+func (s *Service) regionProvenance(ctx context.Context, region string) report.Provenance {
+	awsSrv, err := s.serviceForRegion(region)
+	if err != nil {
+		return report.Provenance{}
+	}
+	identityProvider, ok := awsSrv.(aws.IdentityProvider)
+	if !ok {
+		return report.Provenance{}
+	}
+
+	identity, err := identityProvider.Identity(ctx)
+	if err != nil {
+		s.logger.Warn("Unable to resolve the AWS account of %s: %v", regionName(region), err)
+	}
+	return report.Provenance{AccountID: identity.AccountID, Region: identity.Region}
+}
@@ -0,0 +1,218 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"driftdetector/internal/models"
+	awsProvider "driftdetector/internal/providers/aws"
+	awsMocks "driftdetector/internal/providers/aws/mocks"
+	"driftdetector/internal/report"
+	"driftdetector/pkg/logging"
+)
+
+// TestUniqueInstanceIDs tests that duplicates are removed in listing order and reported once each
+func TestUniqueInstanceIDs(t *testing.T) {
+	unique, duplicates := uniqueInstanceIDs([]string{"i-2", "i-1", "i-2", "eu-west-1/i-1", "i-2"})
+
+	assert.Equal(t, []string{"i-2", "i-1", "eu-west-1/i-1"}, unique)
+	assert.Equal(t, []string{"i-2"}, duplicates)
+
+	unique, duplicates = uniqueInstanceIDs([]string{"i-1"})
+	assert.Equal(t, []string{"i-1"}, unique)
+	assert.Empty(t, duplicates)
+}
+
+// TestSplitRegionalInstanceID tests parsing of optionally region-qualified instance IDs
+func TestSplitRegionalInstanceID(t *testing.T) {
+	region, id := splitRegionalInstanceID("us-east-1/i-123")
+	assert.Equal(t, "us-east-1", region)
+	assert.Equal(t, "i-123", id)
+
+	region, id = splitRegionalInstanceID("i-456")
+	assert.Empty(t, region)
+	assert.Equal(t, "i-456", id)
+}
+
+// TestValidateRegionalInstanceID tests that malformed qualified IDs are rejected
+func TestValidateRegionalInstanceID(t *testing.T) {
+	tests := []struct {
+		id      string
+		wantErr bool
+	}{
+		{"i-123", false},
+		{"us-east-1/i-123", false},
+		{"/i-123", true},
+		{"us-east-1/", true},
+		{"us-east-1/eu-west-1/i-123", true},
+		{"", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			err := validateRegionalInstanceID(tt.id)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// TestValidateInstanceIDFormat tests that all malformed instance IDs are reported together as invalid input
+func TestValidateInstanceIDFormat(t *testing.T) {
+	assert.NoError(t, validateInstanceIDFormat([]string{"i-1234abcd", "us-east-1/i-0123456789abcdef0"}))
+
+	err := validateInstanceIDFormat([]string{"foo", "i-1234abcd", "eu-west-1/bar", "i-1234ABCD", "i-0123456789abcdef01"})
+	assert.True(t, awsProvider.IsErrorCategory(err, awsProvider.ErrInvalidInput))
+	assert.ErrorContains(t, err, `"foo", "eu-west-1/bar", "i-1234ABCD", "i-0123456789abcdef01"`)
+	assert.NotContains(t, err.Error(), `"i-1234abcd"`)
+}
+
+// TestRegionsToConfigure tests that configured and referenced regions are merged without duplicates
+func TestRegionsToConfigure(t *testing.T) {
+	regions := regionsToConfigure(Config{
+		Regions:     []string{"us-east-1"},
+		InstanceIDs: []string{"i-1", "eu-west-1/i-2", "us-east-1/i-3", "eu-west-1/i-4"},
+	})
+	assert.Equal(t, []string{"us-east-1", "eu-west-1"}, regions)
+
+	// The regions of accounts have services of their own
+	regions = regionsToConfigure(Config{InstanceIDs: []string{"i-1", "123456789012:ap-south-1/i-2"}})
+	assert.Empty(t, regions)
+}
+
+// TestRun_MultiRegion tests that instances are routed to the service of their region
+// and the results of all regions are merged.
+func TestRun_MultiRegion(t *testing.T) {
+	config := Config{
+		InstanceIDs: []string{"i-000000a8", "us-east-1/i-000000a9", "eu-west-1/i-000000aa"},
+		ConfigPath:  "test.tf",
+	}
+	service, defaultMock, parserMock, reportMock := setupServiceWithMocks(t, config)
+	eastMock := awsMocks.NewInstanceServiceAPI(t)
+	westMock := awsMocks.NewInstanceServiceAPI(t)
+	service.SetRegionalService("us-east-1", eastMock)
+	service.SetRegionalService("eu-west-1", westMock)
+
+	parserMock.On("GetDesiredState", mock.Anything, config.ConfigPath).Return(&models.InstanceDetails{InstanceType: "t2.micro"}, nil)
+	defaultMock.On("GetInstancesDetails", mock.Anything, []string{"i-000000a8"}).
+		Return([]*models.InstanceDetails{{InstanceID: "i-000000a8", InstanceType: "t2.micro"}}, nil)
+	eastMock.On("GetInstancesDetails", mock.Anything, []string{"i-000000a9"}).
+		Return([]*models.InstanceDetails{{InstanceID: "i-000000a9", InstanceType: "t2.micro"}}, nil)
+	westMock.On("GetInstancesDetails", mock.Anything, []string{"i-000000aa"}).
+		Return([]*models.InstanceDetails{{InstanceID: "i-000000aa", InstanceType: "t2.large"}}, nil)
+	reportMock.On("PrintReport", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	anyDrift, anyError, err := service.Run(context.Background())
+
+	assert.NoError(t, err)
+	assert.True(t, anyDrift, "Drift in eu-west-1 should be reported")
+	assert.False(t, anyError)
+	reportMock.AssertNumberOfCalls(t, "PrintReport", 3)
+}
+
+// TestFetchRegionalInstanceDetails tests region tagging and error handling
+func TestFetchRegionalInstanceDetails(t *testing.T) {
+	service, _, _, _ := setupServiceWithMocks(t, Config{})
+	eastMock := awsMocks.NewInstanceServiceAPI(t)
+	service.SetRegionalService("us-east-1", eastMock)
+
+	eastMock.On("GetInstancesDetails", mock.Anything, []string{"i-1", "i-2"}).
+		Return([]*models.InstanceDetails{{InstanceID: "i-1"}, {InstanceID: "i-2"}}, nil)
+
+	instances, _, err := service.fetchRegionalInstanceDetails(context.Background(), []string{"us-east-1/i-1", "us-east-1/i-2"})
+	assert.NoError(t, err)
+	assert.Len(t, instances, 2)
+	for _, instance := range instances {
+		assert.Equal(t, "us-east-1", instance.Region, "Instances should be tagged with their region")
+	}
+
+	// Unknown regions fail instead of silently using the default region
+	_, _, err = service.fetchRegionalInstanceDetails(context.Background(), []string{"ap-south-1/i-3"})
+	assert.Error(t, err)
+
+	// Errors are annotated with the region
+	westMock := awsMocks.NewInstanceServiceAPI(t)
+	service.SetRegionalService("eu-west-1", westMock)
+	westMock.On("GetInstancesDetails", mock.Anything, []string{"i-4"}).Return(nil, errors.New("AWS error"))
+	_, _, err = service.fetchRegionalInstanceDetails(context.Background(), []string{"eu-west-1/i-4"})
+	assert.ErrorContains(t, err, "region eu-west-1")
+
+	// Partially fetched regions keep the fetched instances and report the failed ones by requested ID
+	westMock.On("GetInstancesDetails", mock.Anything, []string{"i-5", "i-6"}).Return(
+		[]*models.InstanceDetails{{InstanceID: "i-5"}},
+		&awsProvider.PartialFetchError{Failed: map[string]error{"i-6": errors.New("not found")}},
+	)
+	instances, failed, err := service.fetchRegionalInstanceDetails(context.Background(), []string{"eu-west-1/i-5", "eu-west-1/i-6"})
+	assert.NoError(t, err)
+	assert.Len(t, instances, 1)
+	assert.Contains(t, failed, "eu-west-1/i-6")
+}
+
+// TestFetchRegionalInstanceDetails_ParallelRegions tests that regions fetched concurrently keep the request order
+func TestFetchRegionalInstanceDetails_ParallelRegions(t *testing.T) {
+	service, _, _, _ := setupServiceWithMocks(t, Config{ParallelRegions: true})
+	eastMock := awsMocks.NewInstanceServiceAPI(t)
+	westMock := awsMocks.NewInstanceServiceAPI(t)
+	service.SetRegionalService("us-east-1", eastMock)
+	service.SetRegionalService("eu-west-1", westMock)
+
+	// The first region answers last
+	eastMock.On("GetInstancesDetails", mock.Anything, []string{"i-east"}).
+		After(20*time.Millisecond).
+		Return([]*models.InstanceDetails{{InstanceID: "i-east"}}, nil)
+	westMock.On("GetInstancesDetails", mock.Anything, []string{"i-west"}).
+		Return([]*models.InstanceDetails{{InstanceID: "i-west"}}, nil)
+
+	instances, failed, err := service.fetchRegionalInstanceDetails(context.Background(), []string{"us-east-1/i-east", "eu-west-1/i-west"})
+	assert.NoError(t, err)
+	assert.Empty(t, failed)
+	assert.Len(t, instances, 2)
+	assert.Equal(t, "i-east", instances[0].InstanceID, "Results should follow the order of the requested regions")
+	assert.Equal(t, "eu-west-1", instances[1].Region)
+}
+
+// TestRecordProvenance tests that reports carry the account and region of the service each instance was read from
+func TestRecordProvenance(t *testing.T) {
+	stsClient := awsMocks.NewSTSClientAPI(t)
+	stsClient.On("GetCallerIdentity", mock.Anything, mock.Anything).
+		Return(&sts.GetCallerIdentityOutput{Account: aws.String("123456789012")}, nil).Once()
+	defaultSrv := awsProvider.NewInstanceServiceWithClient(awsMocks.NewEC2ClientAPI(t),
+		awsProvider.WithIdentity(stsClient, "us-east-1"))
+
+	var out bytes.Buffer
+	printer := report.NewFormatPrinter(report.OutputFormatTypeJSON, &out, report.PrinterOptions{})
+	service := NewService(Config{}, defaultSrv, nil, printer, logging.NewDefaultLogger())
+	// Services that do not know their identity leave the reports without provenance
+	service.SetRegionalService("eu-west-1", awsMocks.NewInstanceServiceAPI(t))
+
+	instances := []*models.InstanceDetails{{InstanceID: "i-1"}, {InstanceID: "i-2"}, {InstanceID: "i-3", Region: "eu-west-1"}}
+	failed := []DriftDetectionResult{{InstanceID: "i-4", Error: errors.New("not found")}}
+	service.recordProvenance(context.Background(), instances, failed)
+
+	for _, instance := range instances {
+		assert.NoError(t, printer.PrintReport(instance.InstanceID, nil, ""))
+	}
+	assert.NoError(t, printer.(report.IErrorReporter).ReportError("i-4", failed[0].Error, ""))
+	assert.NoError(t, printer.(report.IFlusher).Flush(""))
+
+	var envelope report.JSONEnvelope
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &envelope))
+	var provenance []report.Provenance
+	for _, driftReport := range envelope.Reports {
+		provenance = append(provenance, report.Provenance{AccountID: driftReport.AccountID, Region: driftReport.Region})
+	}
+	east := report.Provenance{AccountID: "123456789012", Region: "us-east-1"}
+	assert.Equal(t, []report.Provenance{east, east, {}, east}, provenance)
+}
@@ -0,0 +1,100 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"driftdetector/internal/providers/aws"
+)
+
+// RunStats holds the counters of a single run, for observability.
+type RunStats struct {
+	InstancesChecked int            `json:"instances_checked"`
+	InstancesDrifted int            `json:"instances_drifted"`
+	InstancesErrored int            `json:"instances_errored"`
+	InstancesSkipped int            `json:"instances_skipped"`
+	APICalls         int64          `json:"api_calls"`
+	Duration         time.Duration  `json:"-"`
+	DurationSeconds  float64        `json:"duration_seconds"`
+	AttributeDrifts  map[string]int `json:"attribute_drifts"` // Number of drifted instances per attribute
+}
+
+// Stats returns the statistics of the last completed run.
+func (s *Service) Stats() RunStats {
+	return s.stats
+}
+
+// collectRunStats computes the statistics of a run from its results.
+func (s *Service) collectRunStats(results []DriftDetectionResult, duration time.Duration) RunStats {
+	stats := RunStats{
+		InstancesChecked: len(results) - countSkipped(results),
+		InstancesDrifted: countDrifts(results),
+		InstancesErrored: countErrors(results),
+		InstancesSkipped: countSkipped(results),
+		APICalls:         s.apiCalls(),
+		Duration:         duration,
+		DurationSeconds:  duration.Seconds(),
+		AttributeDrifts:  make(map[string]int),
+	}
+
+	for _, result := range results {
+		if result.Result == nil {
+			continue
+		}
+		// Keyed attributes such as tags.Name count once per instance under their attribute
+		drifted := make(map[string]bool, len(result.Result.Drifts))
+		for attribute := range result.Result.Drifts {
+			name, _, _ := strings.Cut(attribute, ".")
+			drifted[name] = true
+		}
+		for name := range drifted {
+			stats.AttributeDrifts[name]++
+		}
+	}
+
+	return stats
+}
+
+// apiCalls sums the AWS API calls made by all services that count them.
+func (s *Service) apiCalls() int64 {
+	// The default service may also be registered for a region, so each service is only counted once
+	services := map[aws.InstanceServiceAPI]bool{s.awsSrv: true}
+	for _, awsSrv := range s.regionalAWSSrvs {
+		services[awsSrv] = true
+	}
+
+	var total int64
+	for awsSrv := range services {
+		if counter, ok := awsSrv.(aws.APICallCounter); ok {
+			total += counter.APICalls()
+		}
+	}
+	return total
+}
+
+// logRunStats logs the statistics of a run.
+func (s *Service) logRunStats(stats RunStats) {
+	s.logger.Info("Run stats: %d instances checked, %d drifted, %d errored, %d skipped, %d API calls in %s",
+		stats.InstancesChecked,
+		stats.InstancesDrifted,
+		stats.InstancesErrored,
+		stats.InstancesSkipped,
+		stats.APICalls,
+		stats.Duration.Round(time.Millisecond),
+	)
+}
+
+// writeMetricsFile writes the statistics of a run as JSON to the configured metrics file.
+func (s *Service) writeMetricsFile(stats RunStats) error {
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding metrics: %w", err)
+	}
+	if err := os.WriteFile(s.config.MetricsFile, data, 0o644); err != nil {
+		return fmt.Errorf("error writing metrics file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"driftdetector/internal/driftcheck"
+	"driftdetector/internal/models"
+)
+
+// countingService is an instance service stub that reports a fixed number of API calls
+type countingService struct {
+	calls int64
+}
+
+func (c countingService) APICalls() int64 { return c.calls }
+
+func (c countingService) GetInstancesDetails(context.Context, []string) ([]*models.InstanceDetails, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c countingService) GetDisableApiTermination(context.Context, string) (bool, error) {
+	return false, errors.New("not implemented")
+}
+
+func (c countingService) GetUserData(context.Context, string) (string, error) {
+	return "", errors.New("not implemented")
+}
+
+func (c countingService) GetInstanceInitiatedShutdownBehavior(context.Context, string) (string, error) {
+	return "", errors.New("not implemented")
+}
+
+func (c countingService) GetCreditSpecifications(context.Context, []string) (map[string]string, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c countingService) GetImagesDetails(context.Context, []string) ([]*models.ImageDetails, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c countingService) GetVolumesDetails(context.Context, []string) ([]*models.VolumeDetails, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestCollectRunStats(t *testing.T) {
+	service, _, _, _ := setupServiceWithMocks(t, Config{})
+	regional := countingService{calls: 3}
+	service.SetRegionalService("us-east-1", regional)
+	service.SetRegionalService("us-east-2", regional)
+
+	results := []DriftDetectionResult{
+		{InstanceID: "i-1", HasDrift: true, Result: &driftcheck.DriftResult{
+			HasDrift: true,
+			Drifts: map[string]models.DriftDetail{
+				"instance_type": {Attribute: "instance_type"},
+				"tags.Name":     {Attribute: "tags.Name"},
+				"tags.Env":      {Attribute: "tags.Env"},
+			},
+		}},
+		{InstanceID: "i-2", HasDrift: true, Result: &driftcheck.DriftResult{
+			HasDrift: true,
+			Drifts:   map[string]models.DriftDetail{"tags.Name": {Attribute: "tags.Name"}},
+		}},
+		{InstanceID: "i-3", Result: &driftcheck.DriftResult{}},
+		{InstanceID: "i-4", Error: errors.New("AWS error")},
+	}
+
+	stats := service.collectRunStats(results, 1500*time.Millisecond)
+
+	assert.Equal(t, 4, stats.InstancesChecked)
+	assert.Equal(t, 2, stats.InstancesDrifted)
+	assert.Equal(t, 1, stats.InstancesErrored)
+	assert.Equal(t, int64(3), stats.APICalls, "A service registered for several regions should be counted once")
+	assert.Equal(t, 1.5, stats.DurationSeconds)
+	assert.Equal(t, map[string]int{"instance_type": 1, "tags": 2}, stats.AttributeDrifts)
+}
+
+func TestRun_MetricsFile(t *testing.T) {
+	metricsFile := filepath.Join(t.TempDir(), "metrics.json")
+	config := Config{
+		InstanceIDs: []string{"i-00000001"},
+		ConfigPath:  "test.tf",
+		MetricsFile: metricsFile,
+	}
+	service, instanceMock, parserMock, reportMock := setupServiceWithMocks(t, config)
+
+	parserMock.On("GetDesiredState", mock.Anything, config.ConfigPath).Return(&models.InstanceDetails{InstanceType: "t2.micro"}, nil)
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).Return([]*models.InstanceDetails{
+		{InstanceID: "i-00000001", InstanceType: "t2.large"},
+	}, nil)
+	reportMock.On("PrintReport", "i-00000001", mock.Anything, mock.Anything).Return(nil)
+
+	_, _, err := service.Run(context.Background())
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(metricsFile)
+	require.NoError(t, err)
+
+	var written RunStats
+	require.NoError(t, json.Unmarshal(data, &written))
+	assert.Equal(t, 1, written.InstancesChecked)
+	assert.Equal(t, 1, written.InstancesDrifted)
+	assert.Equal(t, map[string]int{"instance_type": 1}, written.AttributeDrifts)
+	assert.Equal(t, written.InstancesDrifted, service.Stats().InstancesDrifted)
+}
+
+// TestRun_MetricsFileFailsWithinMaxDrifts tests that a failure to write the metrics file does not report drift
+// the MaxDrifts tolerance allows
+func TestRun_MetricsFileFailsWithinMaxDrifts(t *testing.T) {
+	config := Config{
+		InstanceIDs: []string{"i-00000001"},
+		ConfigPath:  "test.tf",
+		MetricsFile: filepath.Join(t.TempDir(), "missing", "metrics.json"),
+		MaxDrifts:   1,
+	}
+	service, instanceMock, parserMock, reportMock := setupServiceWithMocks(t, config)
+
+	parserMock.On("GetDesiredState", mock.Anything, config.ConfigPath).Return(&models.InstanceDetails{InstanceType: "t2.micro"}, nil)
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).Return([]*models.InstanceDetails{
+		{InstanceID: "i-00000001", InstanceType: "t2.large"},
+	}, nil)
+	reportMock.On("PrintReport", "i-00000001", mock.Anything, mock.Anything).Return(nil)
+
+	anyDrift, anyError, err := service.Run(context.Background())
+
+	assert.Error(t, err)
+	assert.True(t, anyError)
+	assert.False(t, anyDrift, "The drift is within the tolerance")
+}
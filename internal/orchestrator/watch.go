@@ -0,0 +1,133 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Watch runs drift detection on every tick of the configured interval until the context is cancelled.
+// The first cycle reports every instance like Run does; later cycles only report the instances whose
+// drift changed since the previous cycle, while a summary is logged every cycle.
+// A failing cycle is logged and retried on the next tick, so transient errors do not stop the monitor.
+func (s *Service) Watch(ctx context.Context) error {
+	s.logger.Info("Starting drift detection watch mode (interval %s)", s.config.WatchInterval)
+	s.logger.Debug("Configuration: %+v", s.config)
+	// Validate configuration
+	if err := s.validateConfig(); err != nil {
+		return err
+	}
+	if err := s.expandAttributeProfile(); err != nil {
+		return err
+	}
+	if err := s.checkAttributes(); err != nil {
+		return err
+	}
+	if err := s.parseEquivalentTypes(); err != nil {
+		return err
+	}
+	if s.config.WatchInterval <= 0 {
+		return fmt.Errorf("watch interval must be positive, got %s", s.config.WatchInterval)
+	}
+
+	ticker := s.clock.NewTicker(s.config.WatchInterval)
+	defer ticker.Stop()
+
+	var previous []DriftDetectionResult
+	for cycle := 1; ; cycle++ {
+		s.logger.Debug("Starting watch cycle %d", cycle)
+		results, err := s.runWatchCycle(ctx, previous)
+		if err != nil {
+			s.logger.Error("Watch cycle %d failed: %s", cycle, err)
+		} else {
+			previous = results
+		}
+
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Stopping drift detection watch mode after %d cycles", cycle)
+			return nil
+		case <-ticker.C():
+		}
+	}
+}
+
+// runWatchCycle runs a single watch cycle and reports the instances whose drift changed since previous.
+// A nil previous reports every instance. The webhook is notified when an instance drifted anew or its drift
+// changed, so drift that is left unresolved is not notified again every cycle.
+func (s *Service) runWatchCycle(ctx context.Context, previous []DriftDetectionResult) ([]DriftDetectionResult, error) {
+	start := s.clock.Now()
+	tfConfig, err := s.parseTerrformConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := s.processAllInstances(ctx, tfConfig, false)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := results
+	if previous != nil {
+		changed = changedResults(previous, results)
+		s.logger.Info("Drift changed for %d instances since the previous cycle", len(changed))
+	}
+
+	for _, result := range changed {
+		if result.Error != nil || result.Skipped {
+			continue // Errors and skipped instances are logged by the summary
+		}
+		if reported := s.reportInstance(result); reported.Error != nil {
+			s.logger.Error("Instance %s: Error - %s", reported.InstanceID, reported.Error)
+		}
+	}
+
+	s.generateSummaryReport(results)
+	if err := s.flushReports(changed); err != nil {
+		s.logger.Error("%s", err)
+	}
+	if s.anyDriftDetected(changed) {
+		s.notifyDrift(ctx, results, s.collectRunStats(results, s.clock.Since(start)))
+	}
+	return results, nil
+}
+
+// changedResults returns the current results whose drift set differs from the previous cycle,
+// including instances that were not part of the previous cycle.
+func changedResults(previous, current []DriftDetectionResult) []DriftDetectionResult {
+	previousFingerprints := make(map[string]string, len(previous))
+	for _, result := range previous {
+		previousFingerprints[result.InstanceID] = driftFingerprint(result)
+	}
+
+	var changed []DriftDetectionResult
+	for _, result := range current {
+		if fingerprint, ok := previousFingerprints[result.InstanceID]; !ok || fingerprint != driftFingerprint(result) {
+			changed = append(changed, result)
+		}
+	}
+	return changed
+}
+
+// driftFingerprint builds a comparable representation of the drift set of a result:
+// the drifted attributes and their values in a stable order.
+func driftFingerprint(result DriftDetectionResult) string {
+	if result.Error != nil {
+		return "error: " + result.Error.Error()
+	}
+	if result.Skipped {
+		return "skipped"
+	}
+	if result.Result == nil || !result.HasDrift {
+		return ""
+	}
+
+	entries := make([]string, 0, len(result.Result.Drifts))
+	for attr, detail := range result.Result.Drifts {
+		// %v prints maps with sorted keys, so the fingerprint is deterministic
+		entries = append(entries, fmt.Sprintf("%s=%v|%v", attr, detail.AWSValue, detail.TerraformValue))
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ";")
+}
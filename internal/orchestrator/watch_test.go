@@ -0,0 +1,227 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"driftdetector/internal/driftcheck"
+	"driftdetector/internal/models"
+	"driftdetector/internal/report"
+)
+
+// driftedResult creates a result with a single instance_type drift
+func driftedResult(instanceID, awsType string) DriftDetectionResult {
+	return DriftDetectionResult{
+		InstanceID: instanceID,
+		HasDrift:   true,
+		Result: &driftcheck.DriftResult{
+			HasDrift: true,
+			Drifts: map[string]models.DriftDetail{
+				"instance_type": {Attribute: "instance_type", AWSValue: awsType, TerraformValue: "t2.micro"},
+			},
+		},
+	}
+}
+
+// TestChangedResults tests that only instances whose drift set changed are returned
+func TestChangedResults(t *testing.T) {
+	previous := []DriftDetectionResult{
+		driftedResult("i-same", "t2.large"),
+		driftedResult("i-value-changed", "t2.large"),
+		driftedResult("i-resolved", "t2.large"),
+		{InstanceID: "i-clean", Result: &driftcheck.DriftResult{}},
+	}
+	current := []DriftDetectionResult{
+		driftedResult("i-same", "t2.large"),
+		driftedResult("i-value-changed", "t2.xlarge"),
+		{InstanceID: "i-resolved", Result: &driftcheck.DriftResult{}},
+		{InstanceID: "i-clean", Result: &driftcheck.DriftResult{}},
+		{InstanceID: "i-new", Error: errors.New("AWS error")},
+	}
+
+	changed := changedResults(previous, current)
+
+	ids := make([]string, 0, len(changed))
+	for _, result := range changed {
+		ids = append(ids, result.InstanceID)
+	}
+	assert.ElementsMatch(t, []string{"i-value-changed", "i-resolved", "i-new"}, ids)
+}
+
+// TestRunWatchCycle tests that a cycle only reports instances whose drift changed
+func TestRunWatchCycle(t *testing.T) {
+	config := Config{
+		InstanceIDs: []string{"i-1", "i-2"},
+		ConfigPath:  "test.tf",
+		Watch:       true,
+	}
+	service, instanceMock, parserMock, reportMock := setupServiceWithMocks(t, config)
+
+	parserMock.On("GetDesiredState", mock.Anything, config.ConfigPath).Return(&models.InstanceDetails{InstanceType: "t2.micro"}, nil)
+
+	// The first and second cycles see the same drift, the third sees i-1 fixed
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).Return([]*models.InstanceDetails{
+		{InstanceID: "i-1", InstanceType: "t2.large"},
+		{InstanceID: "i-2", InstanceType: "t2.micro"},
+	}, nil).Twice()
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).Return([]*models.InstanceDetails{
+		{InstanceID: "i-1", InstanceType: "t2.micro"},
+		{InstanceID: "i-2", InstanceType: "t2.micro"},
+	}, nil).Once()
+
+	// First cycle: both instances are reported
+	reportMock.On("PrintReport", "i-1", mock.Anything, mock.Anything).Return(nil).Once()
+	reportMock.On("PrintReport", "i-2", mock.Anything, mock.Anything).Return(nil).Once()
+	results, err := service.runWatchCycle(context.Background(), nil)
+	assert.NoError(t, err)
+	reportMock.AssertNumberOfCalls(t, "PrintReport", 2)
+
+	// Second cycle: nothing changed, nothing reported
+	results, err = service.runWatchCycle(context.Background(), results)
+	assert.NoError(t, err)
+	reportMock.AssertNumberOfCalls(t, "PrintReport", 2)
+
+	// Third cycle: the resolved drift on i-1 is reported
+	reportMock.On("PrintReport", "i-1", mock.Anything, mock.Anything).Return(nil).Once()
+	_, err = service.runWatchCycle(context.Background(), results)
+	assert.NoError(t, err)
+	reportMock.AssertNumberOfCalls(t, "PrintReport", 3)
+}
+
+// TestRunWatchCycle_NotifyWebhook tests that a cycle notifies the webhook only when drift appeared or changed
+func TestRunWatchCycle_NotifyWebhook(t *testing.T) {
+	server, received := webhookServer(t, http.StatusOK)
+	config := Config{
+		InstanceIDs:   []string{"i-1", "i-2"},
+		ConfigPath:    "test.tf",
+		Watch:         true,
+		NotifyWebhook: server.URL,
+	}
+	service, instanceMock, parserMock, reportMock := setupServiceWithMocks(t, config)
+
+	parserMock.On("GetDesiredState", mock.Anything, config.ConfigPath).Return(&models.InstanceDetails{InstanceType: "t2.micro"}, nil)
+	reportMock.On("PrintReport", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	cycle := func(previous []DriftDetectionResult, i1Type string) []DriftDetectionResult {
+		instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).Return([]*models.InstanceDetails{
+			{InstanceID: "i-1", InstanceType: i1Type},
+			{InstanceID: "i-2", InstanceType: "t2.micro"},
+		}, nil).Once()
+		results, err := service.runWatchCycle(context.Background(), previous)
+		require.NoError(t, err)
+		return results
+	}
+
+	// The first cycle notifies the drift of i-1
+	results := cycle(nil, "t2.large")
+	require.Len(t, *received, 1)
+	assert.Equal(t, []NotifiedInstance{{InstanceID: "i-1", Attributes: []string{"instance_type"}}},
+		(*received)[0].DriftedInstances)
+
+	// The same drift is not notified again
+	results = cycle(results, "t2.large")
+	assert.Len(t, *received, 1)
+
+	// Changed drift is notified
+	results = cycle(results, "t2.xlarge")
+	assert.Len(t, *received, 2)
+
+	// Resolved drift is not
+	cycle(results, "t2.micro")
+	assert.Len(t, *received, 2)
+}
+
+// TestRunWatchCycle_ClearsUnmanaged tests that an attribute the configuration starts to set is no longer listed
+// as unmanaged in the reports of later cycles
+func TestRunWatchCycle_ClearsUnmanaged(t *testing.T) {
+	config := Config{
+		InstanceIDs:         []string{"i-1"},
+		ConfigPath:          "test.tf",
+		AttributesToCheck:   []string{"instance_type", "key_name"},
+		SkipUnsetAttributes: true,
+		Watch:               true,
+	}
+	var out bytes.Buffer
+	instanceMock, parserMock, _, logger := createMocks(t)
+	printer := report.NewFormatPrinter(report.OutputFormatTypeJSONL, &out, report.PrinterOptions{})
+	service := NewService(config, instanceMock, parserMock, printer, logger)
+
+	// The configuration sets key_name from the second cycle on, when the drift of instance_type also changes
+	parserMock.On("GetDesiredState", mock.Anything, config.ConfigPath).Return(&models.InstanceDetails{InstanceType: "t2.micro"}, nil).Once()
+	parserMock.On("GetDesiredState", mock.Anything, config.ConfigPath).
+		Return(&models.InstanceDetails{InstanceType: "t2.micro", KeyName: "deployer"}, nil).Once()
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).
+		Return([]*models.InstanceDetails{{InstanceID: "i-1", InstanceType: "t2.large", KeyName: "deployer"}}, nil).Once()
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).
+		Return([]*models.InstanceDetails{{InstanceID: "i-1", InstanceType: "t2.xlarge", KeyName: "deployer"}}, nil).Once()
+
+	results, err := service.runWatchCycle(context.Background(), nil)
+	require.NoError(t, err)
+	_, err = service.runWatchCycle(context.Background(), results)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	var first, second report.DriftReport
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &second))
+	assert.Equal(t, []models.DriftDetail{{Attribute: "key_name", AWSValue: "deployer", TerraformValue: ""}}, first.Unmanaged)
+	assert.Equal(t, "t2.xlarge", second.Drifts[0].AWSValue)
+	assert.Empty(t, second.Unmanaged)
+}
+
+// TestWatch_StopsOnCancel tests that watch mode keeps cycling until the context is cancelled
+// and survives failing cycles.
+func TestWatch_StopsOnCancel(t *testing.T) {
+	config := Config{
+		InstanceIDs:   []string{"i-00000001"},
+		ConfigPath:    "test.tf",
+		Watch:         true,
+		WatchInterval: time.Minute,
+	}
+	clock := newFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	service, instanceMock, parserMock, _ := setupServiceWithMocks(t, config)
+	service.clock = clock
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	parserMock.On("GetDesiredState", mock.Anything, config.ConfigPath).Return(&models.InstanceDetails{InstanceType: "t2.micro"}, nil)
+	calls := 0
+	instanceMock.On("GetInstancesDetails", mock.Anything, config.InstanceIDs).Return(
+		func(context.Context, []string) ([]*models.InstanceDetails, error) {
+			calls++
+			if calls == 3 {
+				cancel()
+			} else {
+				// The cycle took a whole interval, so the next one is due as soon as it ends
+				clock.Advance(config.WatchInterval)
+			}
+			return nil, errors.New("AWS error")
+		})
+
+	err := service.Watch(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls, "Watch should keep cycling after failures until cancelled")
+}
+
+// TestWatch_InvalidInterval tests that a non-positive interval is rejected
+func TestWatch_InvalidInterval(t *testing.T) {
+	service, _, _, _ := setupServiceWithMocks(t, Config{
+		InstanceIDs: []string{"i-1"},
+		ConfigPath:  "test.tf",
+		Watch:       true,
+	})
+
+	assert.Error(t, service.Watch(context.Background()))
+}
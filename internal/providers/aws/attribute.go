@@ -0,0 +1,89 @@
+package aws
+
+import (
+	"context"
+	"encoding/base64"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// GetDisableApiTermination retrieves whether termination protection is enabled for an instance.
+// It is not part of the DescribeInstances output, so it costs one DescribeInstanceAttribute call per instance.
+func (s *InstanceService) GetDisableApiTermination(ctx context.Context, instanceID string) (bool, error) {
+	if instanceID == "" {
+		return false, NewAWSError(ErrInvalidInput, EC2ResourceType, "", "an instance ID must be provided", nil)
+	}
+
+	var resp *ec2.DescribeInstanceAttributeOutput
+	err := s.call(ctx, EC2ResourceType, instanceID, func() (err error) {
+		resp, err = s.client.DescribeInstanceAttribute(ctx, &ec2.DescribeInstanceAttributeInput{
+			InstanceId: aws.String(instanceID),
+			Attribute:  types.InstanceAttributeNameDisableApiTermination,
+		})
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if resp.DisableApiTermination == nil {
+		return false, nil
+	}
+	return aws.ToBool(resp.DisableApiTermination.Value), nil
+}
+
+// GetUserData retrieves the user data of an instance, decoded from base64, or an empty string if it has none.
+// Like termination protection it costs one DescribeInstanceAttribute call per instance.
+func (s *InstanceService) GetUserData(ctx context.Context, instanceID string) (string, error) {
+	if instanceID == "" {
+		return "", NewAWSError(ErrInvalidInput, EC2ResourceType, "", "an instance ID must be provided", nil)
+	}
+
+	var resp *ec2.DescribeInstanceAttributeOutput
+	err := s.call(ctx, EC2ResourceType, instanceID, func() (err error) {
+		resp, err = s.client.DescribeInstanceAttribute(ctx, &ec2.DescribeInstanceAttributeInput{
+			InstanceId: aws.String(instanceID),
+			Attribute:  types.InstanceAttributeNameUserData,
+		})
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if resp.UserData == nil {
+		return "", nil
+	}
+	userData, err := base64.StdEncoding.DecodeString(aws.ToString(resp.UserData.Value))
+	if err != nil {
+		return "", NewAWSError(ErrInternalError, EC2ResourceType, instanceID, "user data is not valid base64", err)
+	}
+	return string(userData), nil
+}
+
+// GetInstanceInitiatedShutdownBehavior retrieves whether an instance stops or terminates when it is shut down
+// from within. Like termination protection it costs one DescribeInstanceAttribute call per instance.
+func (s *InstanceService) GetInstanceInitiatedShutdownBehavior(ctx context.Context, instanceID string) (string, error) {
+	if instanceID == "" {
+		return "", NewAWSError(ErrInvalidInput, EC2ResourceType, "", "an instance ID must be provided", nil)
+	}
+
+	var resp *ec2.DescribeInstanceAttributeOutput
+	err := s.call(ctx, EC2ResourceType, instanceID, func() (err error) {
+		resp, err = s.client.DescribeInstanceAttribute(ctx, &ec2.DescribeInstanceAttributeInput{
+			InstanceId: aws.String(instanceID),
+			Attribute:  types.InstanceAttributeNameInstanceInitiatedShutdownBehavior,
+		})
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if resp.InstanceInitiatedShutdownBehavior == nil {
+		return "", nil
+	}
+	return aws.ToString(resp.InstanceInitiatedShutdownBehavior.Value), nil
+}
@@ -0,0 +1,119 @@
+package aws
+
+import (
+	"context"
+	"driftdetector/internal/providers/aws/mocks"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestGetDisableApiTermination_Success tests retrieval of the termination protection attribute
+func TestGetDisableApiTermination_Success(t *testing.T) {
+	mockClient := mocks.NewEC2ClientAPI(t)
+
+	mockClient.On("DescribeInstanceAttribute",
+		mock.Anything,
+		mock.MatchedBy(func(input *ec2.DescribeInstanceAttributeInput) bool {
+			return aws.ToString(input.InstanceId) == "i-1" &&
+				input.Attribute == types.InstanceAttributeNameDisableApiTermination
+		}),
+	).Return(&ec2.DescribeInstanceAttributeOutput{
+		DisableApiTermination: &types.AttributeBooleanValue{Value: aws.Bool(true)},
+	}, nil)
+
+	service := NewInstanceServiceWithClient(mockClient)
+	disabled, err := service.GetDisableApiTermination(context.Background(), "i-1")
+
+	assert.NoError(t, err)
+	assert.True(t, disabled)
+	assert.Equal(t, int64(1), service.APICalls())
+}
+
+// TestGetDisableApiTermination_Error tests that AWS errors are classified
+func TestGetDisableApiTermination_Error(t *testing.T) {
+	mockClient := mocks.NewEC2ClientAPI(t)
+
+	mockClient.On("DescribeInstanceAttribute", mock.Anything, mock.Anything).
+		Return(nil, errors.New("InvalidInstanceID.NotFound: The instance ID 'i-1' does not exist"))
+
+	service := NewInstanceServiceWithClient(mockClient)
+	_, err := service.GetDisableApiTermination(context.Background(), "i-1")
+
+	assert.Error(t, err)
+	assert.True(t, IsErrorCategory(err, ErrResourceNotFound))
+}
+
+// TestGetDisableApiTermination_EmptyID tests that an empty instance ID is rejected without calling AWS
+func TestGetDisableApiTermination_EmptyID(t *testing.T) {
+	service := NewInstanceServiceWithClient(mocks.NewEC2ClientAPI(t))
+	_, err := service.GetDisableApiTermination(context.Background(), "")
+
+	assert.True(t, IsErrorCategory(err, ErrInvalidInput))
+}
+
+// TestGetUserData_Success tests retrieval and decoding of the user data attribute
+func TestGetUserData_Success(t *testing.T) {
+	mockClient := mocks.NewEC2ClientAPI(t)
+
+	mockClient.On("DescribeInstanceAttribute",
+		mock.Anything,
+		mock.MatchedBy(func(input *ec2.DescribeInstanceAttributeInput) bool {
+			return aws.ToString(input.InstanceId) == "i-1" && input.Attribute == types.InstanceAttributeNameUserData
+		}),
+	).Return(&ec2.DescribeInstanceAttributeOutput{
+		UserData: &types.AttributeValue{Value: aws.String("IyEvYmluL2Jhc2gKeXVtIGluc3RhbGwgLXkgbmdpbngK")},
+	}, nil)
+
+	service := NewInstanceServiceWithClient(mockClient)
+	userData, err := service.GetUserData(context.Background(), "i-1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "#!/bin/bash\nyum install -y nginx\n", userData)
+}
+
+// TestGetUserData_None tests that an instance without user data has an empty script
+func TestGetUserData_None(t *testing.T) {
+	mockClient := mocks.NewEC2ClientAPI(t)
+	mockClient.On("DescribeInstanceAttribute", mock.Anything, mock.Anything).Return(&ec2.DescribeInstanceAttributeOutput{}, nil)
+
+	service := NewInstanceServiceWithClient(mockClient)
+	userData, err := service.GetUserData(context.Background(), "i-1")
+
+	assert.NoError(t, err)
+	assert.Empty(t, userData)
+}
+
+// TestGetInstanceInitiatedShutdownBehavior_Success tests retrieval of the shutdown behavior attribute
+func TestGetInstanceInitiatedShutdownBehavior_Success(t *testing.T) {
+	mockClient := mocks.NewEC2ClientAPI(t)
+
+	mockClient.On("DescribeInstanceAttribute",
+		mock.Anything,
+		mock.MatchedBy(func(input *ec2.DescribeInstanceAttributeInput) bool {
+			return aws.ToString(input.InstanceId) == "i-1" &&
+				input.Attribute == types.InstanceAttributeNameInstanceInitiatedShutdownBehavior
+		}),
+	).Return(&ec2.DescribeInstanceAttributeOutput{
+		InstanceInitiatedShutdownBehavior: &types.AttributeValue{Value: aws.String("terminate")},
+	}, nil)
+
+	service := NewInstanceServiceWithClient(mockClient)
+	behavior, err := service.GetInstanceInitiatedShutdownBehavior(context.Background(), "i-1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "terminate", behavior)
+}
+
+// TestGetInstanceInitiatedShutdownBehavior_EmptyID tests that an empty instance ID is rejected without calling AWS
+func TestGetInstanceInitiatedShutdownBehavior_EmptyID(t *testing.T) {
+	service := NewInstanceServiceWithClient(mocks.NewEC2ClientAPI(t))
+	_, err := service.GetInstanceInitiatedShutdownBehavior(context.Background(), "")
+
+	assert.True(t, IsErrorCategory(err, ErrInvalidInput))
+}
@@ -0,0 +1,86 @@
+package aws
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+)
+
+// AutoScalingGroupTag is the tag EC2 Auto Scaling adds to every instance it launches, holding the group name.
+// Fixtures mark the members of a group with it.
+const AutoScalingGroupTag = "aws:autoscaling:groupName"
+
+// AutoScalingResourceType is the resource type of Auto Scaling group errors
+const AutoScalingResourceType = "AutoScalingGroup"
+
+// WithAutoScalingClient sets the Auto Scaling client the members of groups are resolved with, for a service
+// created with a provided client. Services created from the default AWS SDK configuration create their own.
+func WithAutoScalingClient(client AutoScalingClientAPI) InstanceServiceOption {
+	return func(s *InstanceService) {
+		s.autoScalingClient = client
+	}
+}
+
+// GetAutoScalingGroupInstanceIDs returns the IDs of the current member instances of an Auto Scaling group,
+// sorted. Members are the instances the group lists, whatever their lifecycle state, e.g. InService,
+// Standby or Pending:Wait on a lifecycle hook; detached instances are not members even though they keep
+// the tag of the group.
+func (s *InstanceService) GetAutoScalingGroupInstanceIDs(ctx context.Context, groupName string) ([]string, error) {
+	if groupName == "" {
+		return nil, NewAWSError(ErrInvalidInput, AutoScalingResourceType, "", "an Auto Scaling group name must be provided", nil)
+	}
+	if s.autoScalingClient == nil {
+		return nil, NewAWSError(ErrConfigurationError, AutoScalingResourceType, groupName, "no Auto Scaling client is configured", nil)
+	}
+
+	var resp *autoscaling.DescribeAutoScalingGroupsOutput
+	err := s.call(ctx, AutoScalingResourceType, groupName, func() (err error) {
+		resp, err = s.autoScalingClient.DescribeAutoScalingGroups(ctx, &autoscaling.DescribeAutoScalingGroupsInput{
+			AutoScalingGroupNames: []string{groupName},
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.AutoScalingGroups) == 0 {
+		return nil, NewAWSError(ErrResourceNotFound, AutoScalingResourceType, groupName, "Auto Scaling group not found", nil)
+	}
+
+	instanceIDs := make([]string, 0, len(resp.AutoScalingGroups[0].Instances))
+	for _, instance := range resp.AutoScalingGroups[0].Instances {
+		instanceIDs = append(instanceIDs, aws.ToString(instance.InstanceId))
+	}
+	sort.Strings(instanceIDs)
+	return instanceIDs, nil
+}
+
+// GetAutoScalingGroupInstanceIDs returns the IDs of the fixture instances tagged as members of the Auto
+// Scaling group that are not terminated or being terminated, sorted, like InstanceService
+func (s *FileInstanceService) GetAutoScalingGroupInstanceIDs(_ context.Context, groupName string) ([]string, error) {
+	if groupName == "" {
+		return nil, NewAWSError(ErrInvalidInput, AutoScalingResourceType, "", "an Auto Scaling group name must be provided", nil)
+	}
+
+	var instanceIDs []string
+	for id, instance := range s.instances {
+		if instance.Tags[AutoScalingGroupTag] != groupName {
+			continue
+		}
+		if instance.State != "" && !isLiveInstanceState(instance.State) {
+			continue
+		}
+		instanceIDs = append(instanceIDs, id)
+	}
+	sort.Strings(instanceIDs)
+	return instanceIDs, nil
+}
+
+// isLiveInstanceState reports whether an instance in the given state is not terminated or being terminated
+func isLiveInstanceState(state string) bool {
+	return slices.Contains(liveInstanceStates, strings.ToLower(state))
+}
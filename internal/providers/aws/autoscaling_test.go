@@ -0,0 +1,109 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"driftdetector/internal/providers/aws/mocks"
+)
+
+// groupOf returns an Auto Scaling group listing the given instances, each in its lifecycle state
+func groupOf(name string, lifecycleStates map[string]types.LifecycleState) types.AutoScalingGroup {
+	group := types.AutoScalingGroup{AutoScalingGroupName: aws.String(name)}
+	for id, state := range lifecycleStates {
+		group.Instances = append(group.Instances, types.Instance{InstanceId: aws.String(id), LifecycleState: state})
+	}
+	return group
+}
+
+// TestGetAutoScalingGroupInstanceIDs tests that every instance the group lists is a member whatever its
+// lifecycle state, and that the members are sorted
+func TestGetAutoScalingGroupInstanceIDs(t *testing.T) {
+	mockAutoScaling := mocks.NewAutoScalingClientAPI(t)
+	mockAutoScaling.On("DescribeAutoScalingGroups", mock.Anything, &autoscaling.DescribeAutoScalingGroupsInput{
+		AutoScalingGroupNames: []string{"web-asg"},
+	}).Return(&autoscaling.DescribeAutoScalingGroupsOutput{
+		AutoScalingGroups: []types.AutoScalingGroup{groupOf("web-asg", map[string]types.LifecycleState{
+			"i-0000000000000000c": types.LifecycleStateInService,
+			"i-0000000000000000a": types.LifecycleStateStandby,
+			"i-0000000000000000b": types.LifecycleStatePendingWait,
+		})},
+	}, nil).Once()
+
+	// The EC2 client is not called, detached instances keeping the tag of the group are not members
+	service := NewInstanceServiceWithClient(mocks.NewEC2ClientAPI(t), WithAutoScalingClient(mockAutoScaling))
+	instanceIDs, err := service.GetAutoScalingGroupInstanceIDs(context.Background(), "web-asg")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"i-0000000000000000a", "i-0000000000000000b", "i-0000000000000000c"}, instanceIDs)
+	assert.Equal(t, int64(1), service.APICalls())
+}
+
+// TestGetAutoScalingGroupInstanceIDs_Error tests that API errors are wrapped with the group name
+func TestGetAutoScalingGroupInstanceIDs_Error(t *testing.T) {
+	mockAutoScaling := mocks.NewAutoScalingClientAPI(t)
+	mockAutoScaling.On("DescribeAutoScalingGroups", mock.Anything, mock.Anything).
+		Return(nil, errors.New("AccessDenied: not authorized")).Once()
+
+	service := NewInstanceServiceWithClient(mocks.NewEC2ClientAPI(t), WithAutoScalingClient(mockAutoScaling))
+	_, err := service.GetAutoScalingGroupInstanceIDs(context.Background(), "web-asg")
+
+	require.Error(t, err)
+	var awsErr *Error
+	require.ErrorAs(t, err, &awsErr)
+	assert.Equal(t, AutoScalingResourceType, awsErr.ResourceType)
+	assert.Equal(t, ErrPermissionDenied, awsErr.Category)
+	assert.Equal(t, "web-asg", awsErr.ResourceID)
+}
+
+func TestGetAutoScalingGroupInstanceIDs_NotFound(t *testing.T) {
+	mockAutoScaling := mocks.NewAutoScalingClientAPI(t)
+	mockAutoScaling.On("DescribeAutoScalingGroups", mock.Anything, mock.Anything).
+		Return(&autoscaling.DescribeAutoScalingGroupsOutput{}, nil).Once()
+
+	service := NewInstanceServiceWithClient(mocks.NewEC2ClientAPI(t), WithAutoScalingClient(mockAutoScaling))
+	_, err := service.GetAutoScalingGroupInstanceIDs(context.Background(), "web-asg")
+
+	var awsErr *Error
+	require.ErrorAs(t, err, &awsErr)
+	assert.Equal(t, ErrResourceNotFound, awsErr.Category)
+}
+
+func TestGetAutoScalingGroupInstanceIDs_EmptyName(t *testing.T) {
+	service := NewInstanceServiceWithClient(mocks.NewEC2ClientAPI(t), WithAutoScalingClient(mocks.NewAutoScalingClientAPI(t)))
+
+	_, err := service.GetAutoScalingGroupInstanceIDs(context.Background(), "")
+
+	assert.Error(t, err)
+}
+
+func TestGetAutoScalingGroupInstanceIDs_NoClient(t *testing.T) {
+	service := NewInstanceServiceWithClient(mocks.NewEC2ClientAPI(t))
+
+	_, err := service.GetAutoScalingGroupInstanceIDs(context.Background(), "web-asg")
+
+	assert.ErrorContains(t, err, "no Auto Scaling client is configured")
+}
+
+func TestFileInstanceService_GetAutoScalingGroupInstanceIDs(t *testing.T) {
+	service, err := NewFileInstanceService(writeFixture(t, `{
+		"i-0000000000000000b": {"tags": {"aws:autoscaling:groupName": "web-asg"}, "state": "running"},
+		"i-0000000000000000a": {"tags": {"aws:autoscaling:groupName": "web-asg"}},
+		"i-0000000000000000c": {"tags": {"aws:autoscaling:groupName": "web-asg"}, "state": "terminated"},
+		"i-0000000000000000d": {"tags": {"aws:autoscaling:groupName": "api-asg"}}
+	}`))
+	require.NoError(t, err)
+
+	instanceIDs, err := service.GetAutoScalingGroupInstanceIDs(context.Background(), "web-asg")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"i-0000000000000000a", "i-0000000000000000b"}, instanceIDs)
+}
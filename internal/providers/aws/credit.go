@@ -0,0 +1,59 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+)
+
+// GetCreditSpecifications retrieves the CPU credit option, standard or unlimited, of multiple burstable
+// performance instances keyed by instance ID, batching the requests in the same way as GetInstancesDetails.
+// Only instances of burstable types, such as T3, have a credit specification.
+func (s *InstanceService) GetCreditSpecifications(ctx context.Context, instanceIDs []string) (map[string]string, error) {
+	if len(instanceIDs) == 0 {
+		return nil, NewAWSError(
+			ErrInvalidInput,
+			EC2ResourceType,
+			"",
+			"at least one instance ID must be provided",
+			nil,
+		)
+	}
+
+	cpuCredits := make(map[string]string, len(instanceIDs))
+	for i := 0; i < len(instanceIDs); i += s.batchSize {
+		end := min(i+s.batchSize, len(instanceIDs))
+		if err := s.getCreditSpecificationsBatch(ctx, instanceIDs[i:end], cpuCredits); err != nil {
+			return nil, err // Error already wrapped in getCreditSpecificationsBatch
+		}
+	}
+
+	return cpuCredits, nil
+}
+
+// getCreditSpecificationsBatch retrieves the credit specifications of a batch of instances in a single
+// API call, adding them to cpuCredits
+func (s *InstanceService) getCreditSpecificationsBatch(ctx context.Context, instanceIDs []string, cpuCredits map[string]string) error {
+	resourceID := fmt.Sprintf("one or more of the following: %v", instanceIDs)
+	if len(instanceIDs) == 1 {
+		resourceID = instanceIDs[0]
+	}
+
+	var resp *ec2.DescribeInstanceCreditSpecificationsOutput
+	err := s.call(ctx, EC2ResourceType, resourceID, func() (err error) {
+		resp, err = s.client.DescribeInstanceCreditSpecifications(ctx, &ec2.DescribeInstanceCreditSpecificationsInput{
+			InstanceIds: instanceIDs,
+		})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, specification := range resp.InstanceCreditSpecifications {
+		cpuCredits[aws.ToString(specification.InstanceId)] = aws.ToString(specification.CpuCredits)
+	}
+	return nil
+}
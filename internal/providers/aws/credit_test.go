@@ -0,0 +1,66 @@
+package aws
+
+import (
+	"context"
+	"driftdetector/internal/providers/aws/mocks"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetCreditSpecifications_Success(t *testing.T) {
+	mockClient := mocks.NewEC2ClientAPI(t)
+
+	mockClient.On("DescribeInstanceCreditSpecifications",
+		mock.Anything,
+		mock.MatchedBy(func(input *ec2.DescribeInstanceCreditSpecificationsInput) bool {
+			return assert.ObjectsAreEqual([]string{"i-1", "i-2"}, input.InstanceIds)
+		}),
+	).Return(&ec2.DescribeInstanceCreditSpecificationsOutput{
+		InstanceCreditSpecifications: []types.InstanceCreditSpecification{
+			{InstanceId: aws.String("i-1"), CpuCredits: aws.String("unlimited")},
+			{InstanceId: aws.String("i-2"), CpuCredits: aws.String("standard")},
+		},
+	}, nil)
+
+	service := NewInstanceServiceWithClient(mockClient)
+	cpuCredits, err := service.GetCreditSpecifications(context.Background(), []string{"i-1", "i-2"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"i-1": "unlimited", "i-2": "standard"}, cpuCredits)
+}
+
+func TestGetCreditSpecifications_BatchSize(t *testing.T) {
+	mockClient := mocks.NewEC2ClientAPI(t)
+	mockClient.On("DescribeInstanceCreditSpecifications", mock.Anything, mock.Anything).
+		Return(&ec2.DescribeInstanceCreditSpecificationsOutput{}, nil)
+
+	service := NewInstanceServiceWithClient(mockClient, WithBatchSize(2))
+	_, err := service.GetCreditSpecifications(context.Background(), []string{"i-1", "i-2", "i-3"})
+
+	assert.NoError(t, err)
+	mockClient.AssertNumberOfCalls(t, "DescribeInstanceCreditSpecifications", 2)
+}
+
+func TestGetCreditSpecifications_Error(t *testing.T) {
+	mockClient := mocks.NewEC2ClientAPI(t)
+	mockClient.On("DescribeInstanceCreditSpecifications", mock.Anything, mock.Anything).
+		Return(nil, errors.New("UnauthorizedOperation: You are not authorized to perform this operation"))
+
+	service := NewInstanceServiceWithClient(mockClient)
+	_, err := service.GetCreditSpecifications(context.Background(), []string{"i-1"})
+
+	assert.True(t, IsErrorCategory(err, ErrPermissionDenied))
+}
+
+func TestGetCreditSpecifications_NoIDs(t *testing.T) {
+	service := NewInstanceServiceWithClient(mocks.NewEC2ClientAPI(t))
+	_, err := service.GetCreditSpecifications(context.Background(), nil)
+
+	assert.True(t, IsErrorCategory(err, ErrInvalidInput))
+}
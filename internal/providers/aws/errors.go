@@ -3,6 +3,7 @@ package aws
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -67,6 +68,11 @@ func (e *Error) Unwrap() error {
 	return e.Underlying
 }
 
+// ErrorCategory returns the category as a string, for reports that group errors without depending on this package
+func (e *Error) ErrorCategory() string {
+	return string(e.Category)
+}
+
 // NewAWSError creates a new AWS error with the specified details
 func NewAWSError(category ErrorCategory, resourceType, resourceID, message string, underlying error) *Error {
 	return &Error{
@@ -78,6 +84,39 @@ func NewAWSError(category ErrorCategory, resourceType, resourceID, message strin
 	}
 }
 
+// PartialFetchError is returned when some of the requested resources could not be fetched.
+// The resources that were fetched successfully are returned alongside it.
+type PartialFetchError struct {
+	// Failed maps the ID of each resource that could not be fetched to its error
+	Failed map[string]error
+}
+
+// Error returns a formatted error message listing the failed resources
+func (e *PartialFetchError) Error() string {
+	ids := e.failedIDs()
+	return fmt.Sprintf("failed to fetch %d resources: %s", len(ids), strings.Join(ids, ", "))
+}
+
+// Unwrap returns the errors of the failed resources, ordered by resource ID
+func (e *PartialFetchError) Unwrap() []error {
+	ids := e.failedIDs()
+	errs := make([]error, len(ids))
+	for i, id := range ids {
+		errs[i] = e.Failed[id]
+	}
+	return errs
+}
+
+// failedIDs returns the IDs of the failed resources in sorted order
+func (e *PartialFetchError) failedIDs() []string {
+	ids := make([]string, 0, len(e.Failed))
+	for id := range e.Failed {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
 // IsErrorCategory checks if an error belongs to a specific error category
 func IsErrorCategory(err error, category ErrorCategory) bool {
 	if err == nil {
@@ -106,11 +145,13 @@ func ClassifyAWSError(err error, resourceType, resourceID string) *Error {
 	// Reference: https://docs.aws.amazon.com/AWSEC2/latest/APIReference/errors-overview.html
 	case contains(errMsg, "InvalidResource") ||
 		contains(errMsg, "InvalidInstanceID.NotFound") ||
-		contains(errMsg, "InvalidInstanceID"):
+		contains(errMsg, "InvalidInstanceID") ||
+		contains(errMsg, "InvalidAMIID"):
 		return NewAWSError(ErrResourceNotFound, resourceType, resourceID,
 			"Resource not found", err)
 
 	case contains(errMsg, "UnauthorizedOperation") ||
+		contains(errMsg, "AccessDenied") || // Auto Scaling and STS
 		contains(errMsg, "AuthFailure") ||
 		contains(errMsg, "InvalidClientTokenId"):
 		return NewAWSError(ErrPermissionDenied, resourceType, resourceID,
@@ -0,0 +1,153 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"driftdetector/internal/models"
+)
+
+// FileSourcePrefix is the scheme of AWS sources that are read from a JSON fixture instead of the AWS API
+const FileSourcePrefix = "file://"
+
+// FileInstanceService serves instance details from a JSON fixture keyed by instance ID, so the tool can be
+// demoed and integration tested without AWS credentials. It implements InstanceServiceAPI without making
+// any API calls.
+type FileInstanceService struct {
+	instances map[string]*models.InstanceDetails
+}
+
+// NewFileInstanceService loads the instances of the JSON fixture at path, either a plain path or a
+// file:// URL. The fixture maps instance IDs to instance details in the same format as the JSON desired state.
+func NewFileInstanceService(path string) (*FileInstanceService, error) {
+	path = strings.TrimPrefix(path, FileSourcePrefix)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read AWS fixture %s: %w", path, err)
+	}
+
+	var instances map[string]*models.InstanceDetails
+	if err := json.Unmarshal(data, &instances); err != nil {
+		return nil, fmt.Errorf("failed to parse AWS fixture %s: %w", path, err)
+	}
+	for id, instance := range instances {
+		if instance == nil {
+			return nil, fmt.Errorf("failed to parse AWS fixture %s: instance %s has no details", path, id)
+		}
+		instance.InstanceID = id
+	}
+	return &FileInstanceService{instances: instances}, nil
+}
+
+// GetInstancesDetails returns a copy of the requested instances. Like InstanceService, instances missing from
+// the fixture are reported in a *PartialFetchError, or on their own when none of the instances were found.
+func (s *FileInstanceService) GetInstancesDetails(_ context.Context, instanceIDs []string) ([]*models.InstanceDetails, error) {
+	if len(instanceIDs) == 0 {
+		return nil, NewAWSError(ErrInvalidInput, EC2ResourceType, "", "at least one instance ID must be provided", nil)
+	}
+
+	instances := make([]*models.InstanceDetails, 0, len(instanceIDs))
+	failed := make(map[string]error)
+	var firstErr error
+	for _, id := range instanceIDs {
+		instance, err := s.instance(id)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			failed[id] = err
+			continue
+		}
+		// Like DescribeInstances, termination protection, user data, the shutdown behavior and CPU credits are
+		// left to be fetched separately. Callers fill in the fetched attributes, which must not leak into the fixture.
+		details := *instance
+		details.DisableApiTermination = nil
+		details.UserData = nil
+		details.InstanceInitiatedShutdownBehavior = ""
+		details.CPUCredits = ""
+		details.BlockDevices = append([]models.BlockDevice(nil), instance.BlockDevices...)
+		instances = append(instances, &details)
+	}
+
+	if len(failed) > 0 {
+		if len(instances) == 0 {
+			return nil, firstErr
+		}
+		return instances, &PartialFetchError{Failed: failed}
+	}
+	return instances, nil
+}
+
+// GetImagesDetails returns the requested AMIs with no deprecation time, as the fixture holds no image details
+func (s *FileInstanceService) GetImagesDetails(_ context.Context, imageIDs []string) ([]*models.ImageDetails, error) {
+	images := make([]*models.ImageDetails, len(imageIDs))
+	for i, id := range imageIDs {
+		images[i] = &models.ImageDetails{ImageID: id}
+	}
+	return images, nil
+}
+
+// GetVolumesDetails returns no volumes, leaving sizes and types as the fixture's block devices set them
+func (s *FileInstanceService) GetVolumesDetails(context.Context, []string) ([]*models.VolumeDetails, error) {
+	return nil, nil
+}
+
+// GetDisableApiTermination returns the termination protection of the instance, disabled unless the fixture sets it
+func (s *FileInstanceService) GetDisableApiTermination(_ context.Context, instanceID string) (bool, error) {
+	instance, err := s.instance(instanceID)
+	if err != nil {
+		return false, err
+	}
+	return instance.DisableApiTermination != nil && *instance.DisableApiTermination, nil
+}
+
+// GetUserData returns the user data of the instance, empty unless the fixture sets it
+func (s *FileInstanceService) GetUserData(_ context.Context, instanceID string) (string, error) {
+	instance, err := s.instance(instanceID)
+	if err != nil {
+		return "", err
+	}
+	if instance.UserData == nil {
+		return "", nil
+	}
+	return *instance.UserData, nil
+}
+
+// GetInstanceInitiatedShutdownBehavior returns the shutdown behavior of the instance, stop unless the fixture sets it
+func (s *FileInstanceService) GetInstanceInitiatedShutdownBehavior(_ context.Context, instanceID string) (string, error) {
+	instance, err := s.instance(instanceID)
+	if err != nil {
+		return "", err
+	}
+	if instance.InstanceInitiatedShutdownBehavior == "" {
+		return "stop", nil
+	}
+	return instance.InstanceInitiatedShutdownBehavior, nil
+}
+
+// GetCreditSpecifications returns the CPU credits of the instances for which the fixture sets them
+func (s *FileInstanceService) GetCreditSpecifications(_ context.Context, instanceIDs []string) (map[string]string, error) {
+	cpuCredits := make(map[string]string)
+	for _, id := range instanceIDs {
+		instance, err := s.instance(id)
+		if err != nil {
+			return nil, err
+		}
+		if instance.CPUCredits != "" {
+			cpuCredits[id] = instance.CPUCredits
+		}
+	}
+	return cpuCredits, nil
+}
+
+// instance returns the fixture's instance with the given ID, or a resource not found error
+func (s *FileInstanceService) instance(instanceID string) (*models.InstanceDetails, error) {
+	instance, ok := s.instances[instanceID]
+	if !ok {
+		return nil, NewAWSError(ErrResourceNotFound, EC2ResourceType, instanceID, "instance not found in AWS fixture", nil)
+	}
+	return instance, nil
+}
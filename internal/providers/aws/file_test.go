@@ -0,0 +1,74 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFixture writes an AWS fixture to a temporary file and returns its file:// URL
+func writeFixture(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "instances.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return FileSourcePrefix + path
+}
+
+func TestFileInstanceService(t *testing.T) {
+	service, err := NewFileInstanceService(writeFixture(t, `{
+		"i-1234567890abcdef0": {"instance_type": "t2.micro", "state": "running", "user_data": "#!/bin/bash\n"},
+		"i-0987654321fedcba0": {"instance_type": "t2.large", "disable_api_termination": true,
+			"instance_initiated_shutdown_behavior": "terminate", "cpu_credits": "unlimited"}
+	}`))
+	require.NoError(t, err)
+
+	instances, err := service.GetInstancesDetails(context.Background(), []string{"i-1234567890abcdef0", "i-0987654321fedcba0"})
+	require.NoError(t, err)
+	require.Len(t, instances, 2)
+	assert.Equal(t, "i-1234567890abcdef0", instances[0].InstanceID)
+	assert.Equal(t, "t2.micro", instances[0].InstanceType)
+	assert.Equal(t, "running", instances[0].State)
+	assert.Nil(t, instances[0].UserData, "User data is only reported when fetched")
+
+	userData, err := service.GetUserData(context.Background(), "i-1234567890abcdef0")
+	assert.NoError(t, err)
+	assert.Equal(t, "#!/bin/bash\n", userData)
+	disabled, err := service.GetDisableApiTermination(context.Background(), "i-0987654321fedcba0")
+	assert.NoError(t, err)
+	assert.True(t, disabled)
+	behavior, err := service.GetInstanceInitiatedShutdownBehavior(context.Background(), "i-0987654321fedcba0")
+	assert.NoError(t, err)
+	assert.Equal(t, "terminate", behavior)
+	behavior, err = service.GetInstanceInitiatedShutdownBehavior(context.Background(), "i-1234567890abcdef0")
+	assert.NoError(t, err)
+	assert.Equal(t, "stop", behavior)
+	cpuCredits, err := service.GetCreditSpecifications(context.Background(), []string{"i-1234567890abcdef0", "i-0987654321fedcba0"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"i-0987654321fedcba0": "unlimited"}, cpuCredits)
+}
+
+func TestFileInstanceService_NotFound(t *testing.T) {
+	service, err := NewFileInstanceService(writeFixture(t, `{"i-1234567890abcdef0": {"instance_type": "t2.micro"}}`))
+	require.NoError(t, err)
+
+	instances, err := service.GetInstancesDetails(context.Background(), []string{"i-1234567890abcdef0", "i-missing"})
+	assert.Len(t, instances, 1)
+	var partialErr *PartialFetchError
+	require.True(t, errors.As(err, &partialErr))
+	assert.True(t, IsErrorCategory(partialErr.Failed["i-missing"], ErrResourceNotFound))
+
+	_, err = service.GetInstancesDetails(context.Background(), []string{"i-missing"})
+	assert.True(t, IsErrorCategory(err, ErrResourceNotFound))
+}
+
+func TestNewFileInstanceService_Invalid(t *testing.T) {
+	_, err := NewFileInstanceService(writeFixture(t, `[{"instance_type": "t2.micro"}]`))
+	assert.ErrorContains(t, err, "failed to parse AWS fixture")
+
+	_, err = NewFileInstanceService(FileSourcePrefix + filepath.Join(t.TempDir(), "missing.json"))
+	assert.ErrorContains(t, err, "failed to read AWS fixture")
+}
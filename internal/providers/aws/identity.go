@@ -0,0 +1,45 @@
+package aws
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// Identity is the AWS account and region a service reads instances from
+type Identity struct {
+	AccountID string
+	Region    string
+}
+
+// WithIdentity sets the STS client the account ID is resolved with and the region of a service created with a
+// provided client. Services created from the default AWS SDK configuration use the client and region of that
+// configuration instead.
+func WithIdentity(stsClient STSClientAPI, region string) InstanceServiceOption {
+	return func(s *InstanceService) {
+		s.stsClient = stsClient
+		s.region = region
+	}
+}
+
+// Identity returns the account the credentials of the service belong to and the region it reads from.
+// The account is resolved with sts:GetCallerIdentity on the first call only, so runs and watch cycles
+// share a single request; its error is kept too. The region is returned even if the account is unknown.
+func (s *InstanceService) Identity(ctx context.Context) (Identity, error) {
+	identity := Identity{Region: s.region}
+	if s.stsClient == nil {
+		return identity, nil
+	}
+
+	s.identityOnce.Do(func() {
+		resp, err := s.stsClient.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+		if err != nil {
+			s.accountErr = ClassifyAWSError(err, "STS", "")
+			return
+		}
+		s.accountID = aws.ToString(resp.Account)
+	})
+	identity.AccountID = s.accountID
+	return identity, s.accountErr
+}
@@ -0,0 +1,44 @@
+package aws
+
+import (
+	"context"
+	"driftdetector/internal/providers/aws/mocks"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestInstanceService_Identity(t *testing.T) {
+	// The account is resolved on the first call only
+	stsClient := mocks.NewSTSClientAPI(t)
+	stsClient.On("GetCallerIdentity", mock.Anything, mock.Anything).
+		Return(&sts.GetCallerIdentityOutput{Account: aws.String("123456789012")}, nil).Once()
+	service := NewInstanceServiceWithClient(mocks.NewEC2ClientAPI(t), WithIdentity(stsClient, "eu-west-1"))
+
+	for range 2 {
+		identity, err := service.Identity(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, Identity{AccountID: "123456789012", Region: "eu-west-1"}, identity)
+	}
+
+	// A failure is kept, with the region still returned
+	stsClient = mocks.NewSTSClientAPI(t)
+	stsClient.On("GetCallerIdentity", mock.Anything, mock.Anything).
+		Return(nil, errors.New("InvalidClientTokenId: The security token included in the request is invalid")).Once()
+	service = NewInstanceServiceWithClient(mocks.NewEC2ClientAPI(t), WithIdentity(stsClient, "eu-west-1"))
+
+	for range 2 {
+		identity, err := service.Identity(context.Background())
+		assert.True(t, IsErrorCategory(err, ErrPermissionDenied))
+		assert.Equal(t, Identity{Region: "eu-west-1"}, identity)
+	}
+
+	// Without an STS client only the region is known
+	identity, err := NewInstanceServiceWithClient(nil).Identity(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, Identity{}, identity)
+}
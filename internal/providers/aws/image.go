@@ -0,0 +1,108 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	"driftdetector/internal/models"
+)
+
+// AMIResourceType is the AWS resource type for Amazon Machine Images
+const AMIResourceType = "AMI"
+
+// GetImagesDetails retrieves details for multiple AMIs, batching the requests
+// in the same way as GetInstancesDetails.
+func (s *InstanceService) GetImagesDetails(ctx context.Context, imageIDs []string) ([]*models.ImageDetails, error) {
+	if len(imageIDs) == 0 {
+		return nil, NewAWSError(
+			ErrInvalidInput,
+			AMIResourceType,
+			"",
+			"at least one image ID must be provided",
+			nil,
+		)
+	}
+
+	allImages := make([]*models.ImageDetails, 0, len(imageIDs))
+	// Process in batches
+	for i := 0; i < len(imageIDs); i += s.batchSize {
+		end := i + s.batchSize
+		if end > len(imageIDs) {
+			end = len(imageIDs)
+		}
+		batch := imageIDs[i:end]
+
+		images, err := s.getImagesBatch(ctx, batch)
+		if err != nil {
+			return nil, err // Error already wrapped in getImagesBatch
+		}
+
+		allImages = append(allImages, images...)
+	}
+
+	return allImages, nil
+}
+
+// getImagesBatch retrieves a batch of images in a single API call
+func (s *InstanceService) getImagesBatch(ctx context.Context, imageIDs []string) ([]*models.ImageDetails, error) {
+	resourceID := fmt.Sprintf("one or more of the following: %v", imageIDs)
+	if len(imageIDs) == 1 {
+		resourceID = imageIDs[0]
+	}
+
+	var resp *ec2.DescribeImagesOutput
+	err := s.call(ctx, AMIResourceType, resourceID, func() (err error) {
+		resp, err = s.client.DescribeImages(ctx, &ec2.DescribeImagesInput{
+			ImageIds: imageIDs,
+			// Deprecated images are exactly the ones we are interested in
+			IncludeDeprecated: aws.Bool(true),
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	images := make([]*models.ImageDetails, 0, len(resp.Images))
+	for _, image := range resp.Images {
+		details, err := convertImageToModel(image)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, details)
+	}
+
+	return images, nil
+}
+
+// convertImageToModel converts an AWS EC2 image to our domain model
+func convertImageToModel(image types.Image) (*models.ImageDetails, error) {
+	imageID := aws.ToString(image.ImageId)
+
+	details := &models.ImageDetails{
+		ImageID: imageID,
+		Name:    aws.ToString(image.Name),
+	}
+
+	// DeprecationTime is returned as an ISO 8601 timestamp, e.g. 2021-11-06T00:00:00.000Z
+	if deprecation := aws.ToString(image.DeprecationTime); deprecation != "" {
+		parsed, err := time.Parse(time.RFC3339, deprecation)
+		if err != nil {
+			return nil, NewAWSError(
+				ErrInternalError,
+				AMIResourceType,
+				imageID,
+				fmt.Sprintf("unable to parse deprecation time %q", deprecation),
+				err,
+			)
+		}
+		details.DeprecationTime = parsed
+	}
+
+	return details, nil
+}
@@ -0,0 +1,81 @@
+package aws
+
+import (
+	"context"
+	"driftdetector/internal/providers/aws/mocks"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestGetImagesDetails_Success tests retrieval of a deprecated and a current AMI
+func TestGetImagesDetails_Success(t *testing.T) {
+	mockClient := mocks.NewEC2ClientAPI(t)
+
+	imageIDs := []string{"ami-deprecated", "ami-current"}
+
+	expectedResponse := &ec2.DescribeImagesOutput{
+		Images: []types.Image{
+			{
+				ImageId:         aws.String(imageIDs[0]),
+				Name:            aws.String("old-image"),
+				DeprecationTime: aws.String("2021-11-06T00:00:00.000Z"),
+			},
+			{
+				ImageId: aws.String(imageIDs[1]),
+				Name:    aws.String("current-image"),
+			},
+		},
+	}
+
+	mockClient.On("DescribeImages",
+		mock.Anything,
+		mock.MatchedBy(func(input *ec2.DescribeImagesInput) bool {
+			return len(input.ImageIds) == 2 && aws.ToBool(input.IncludeDeprecated)
+		}),
+	).Return(expectedResponse, nil)
+
+	service := NewInstanceServiceWithClient(mockClient)
+	results, err := service.GetImagesDetails(context.Background(), imageIDs)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(results))
+	assert.Equal(t, "ami-deprecated", results[0].ImageID)
+	assert.Equal(t, time.Date(2021, 11, 6, 0, 0, 0, 0, time.UTC), results[0].DeprecationTime)
+	assert.True(t, results[0].IsDeprecated(time.Now()))
+	assert.Equal(t, "ami-current", results[1].ImageID)
+	assert.True(t, results[1].DeprecationTime.IsZero())
+	assert.False(t, results[1].IsDeprecated(time.Now()))
+}
+
+func TestGetImagesDetails_NotFound(t *testing.T) {
+	mockClient := mocks.NewEC2ClientAPI(t)
+
+	mockClient.On("DescribeImages", mock.Anything, mock.Anything).
+		Return(nil, errors.New("InvalidAMIID.NotFound"))
+
+	service := NewInstanceServiceWithClient(mockClient)
+	results, err := service.GetImagesDetails(context.Background(), []string{"ami-missing"})
+
+	assert.Error(t, err)
+	assert.Nil(t, results)
+
+	var awsErr *Error
+	assert.True(t, errors.As(err, &awsErr))
+	assert.Equal(t, ErrResourceNotFound, awsErr.Category)
+	assert.Equal(t, AMIResourceType, awsErr.ResourceType)
+	assert.Equal(t, "ami-missing", awsErr.ResourceID)
+}
+
+func TestGetImagesDetails_NoIDs(t *testing.T) {
+	service := NewInstanceServiceWithClient(mocks.NewEC2ClientAPI(t))
+	_, err := service.GetImagesDetails(context.Background(), nil)
+
+	assert.True(t, IsErrorCategory(err, ErrInvalidInput))
+}
@@ -3,11 +3,16 @@ package aws
 import (
 	"context"
 	"fmt"
+	"sync"
+	"sync/atomic"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 
 	"driftdetector/internal/models"
 )
@@ -15,19 +20,107 @@ import (
 const (
 	// EC2ResourceType is the AWS resource type for EC2 instances
 	EC2ResourceType = "EC2Instance"
-	// maxIDsPerRequest is the maximum number of instance IDs that can be requested in a single API call
-	maxIDsPerRequest = 10
+	// DefaultBatchSize is the number of IDs requested in a single API call unless configured otherwise
+	DefaultBatchSize = 100
+	// maxIDsPerRequest is the maximum number of IDs AWS accepts in a single Describe API call
+	maxIDsPerRequest = 1000
 )
 
 // InstanceService handles interactions with AWS EC2 instances
 type InstanceService struct {
-	client EC2ClientAPI
+	client      EC2ClientAPI
+	batchSize   int
+	limiter     *Limiter
+	retryPolicy RetryPolicy
+	apiCalls    atomic.Int64
+	endpoint    Endpoint // Applied when the service creates its client from the default AWS SDK configuration
+
+	autoScalingClient AutoScalingClientAPI // Resolves the members of Auto Scaling groups, nil if not configured
+
+	// The account and region reported by Identity, the account resolved once with stsClient
+	stsClient    STSClientAPI
+	region       string
+	identityOnce sync.Once
+	accountID    string
+	accountErr   error
+}
+
+// Endpoint overrides how the EC2 endpoint is resolved, for regulated or isolated networks that cannot use the
+// default public endpoints.
+type Endpoint struct {
+	URL     string // Base URL of the EC2 API, e.g. a VPC endpoint, instead of the endpoint resolved for the region
+	UseFIPS bool   // Use the FIPS 140 validated endpoint of the region
+}
+
+// ec2Options overrides the endpoint of the EC2 client only, as the clients of other services created from the
+// same configuration, such as STS, cannot be reached through an EC2 endpoint
+func (e Endpoint) ec2Options(o *ec2.Options) {
+	if e.URL != "" {
+		o.BaseEndpoint = aws.String(e.URL)
+	}
+	if e.UseFIPS {
+		o.EndpointOptions.UseFIPSEndpoint = aws.FIPSEndpointStateEnabled
+	}
+}
+
+// InstanceServiceOption configures an InstanceService
+type InstanceServiceOption func(*InstanceService)
+
+// WithBatchSize sets the number of IDs requested in a single API call.
+// The size is clamped between 1 and the AWS limit of 1000 IDs per call.
+func WithBatchSize(size int) InstanceServiceOption {
+	return func(s *InstanceService) {
+		s.batchSize = max(1, min(size, maxIDsPerRequest))
+	}
+}
+
+// WithLimiter sets the Limiter every API call of the service waits for.
+// Sharing one Limiter between services caps their API calls in flight together.
+func WithLimiter(limiter *Limiter) InstanceServiceOption {
+	return func(s *InstanceService) {
+		s.limiter = limiter
+	}
+}
+
+// WithEndpoint sets the EC2 endpoint of a service created from the default AWS SDK configuration.
+// It has no effect on services created with a provided client.
+func WithEndpoint(endpoint Endpoint) InstanceServiceOption {
+	return func(s *InstanceService) {
+		s.endpoint = endpoint
+	}
 }
 
 // NewInstanceServiceWithDefaultConfig creates a new InstanceService with the default AWS SDK configuration.
 // It loads AWS credentials and region information from the environment, config files, or instance metadata.
-func NewInstanceServiceWithDefaultConfig(ctx context.Context) (*InstanceService, error) {
-	cfg, err := config.LoadDefaultConfig(ctx)
+func NewInstanceServiceWithDefaultConfig(ctx context.Context, opts ...InstanceServiceOption) (*InstanceService, error) {
+	return newInstanceServiceFromConfig(ctx, nil, "", opts...)
+}
+
+// NewInstanceServiceForRegion creates a new InstanceService with the default AWS SDK configuration
+// for the given region, overriding any region set in the environment or config files.
+func NewInstanceServiceForRegion(ctx context.Context, region string, opts ...InstanceServiceOption) (*InstanceService, error) {
+	return newInstanceServiceFromConfig(ctx, []func(*config.LoadOptions) error{config.WithRegion(region)}, "", opts...)
+}
+
+// NewInstanceServiceForAccount creates a new InstanceService for the given region of another account, reading
+// instances with the credentials of the role assumed from the default AWS SDK configuration. The role is
+// assumed on the first API call and again whenever its credentials expire.
+func NewInstanceServiceForAccount(ctx context.Context, region, roleARN string, opts ...InstanceServiceOption) (*InstanceService, error) {
+	return newInstanceServiceFromConfig(ctx, []func(*config.LoadOptions) error{config.WithRegion(region)}, roleARN, opts...)
+}
+
+// newInstanceServiceFromConfig loads the default AWS SDK configuration with the given overrides
+// and creates an InstanceService from it, with the credentials of roleARN if it is not empty.
+func newInstanceServiceFromConfig(
+	ctx context.Context,
+	loadOpts []func(*config.LoadOptions) error,
+	roleARN string,
+	opts ...InstanceServiceOption,
+) (*InstanceService, error) {
+	// The options are applied first, as the endpoint is part of the options the client is created with
+	service := NewInstanceServiceWithClient(nil, opts...)
+
+	cfg, err := config.LoadDefaultConfig(ctx, loadOpts...)
 	if err != nil {
 		return nil, NewAWSError(
 			ErrConfigurationError,
@@ -38,19 +131,35 @@ func NewInstanceServiceWithDefaultConfig(ctx context.Context) (*InstanceService,
 		)
 	}
 
-	return NewInstanceServiceWithClient(ec2.NewFromConfig(cfg)), nil
+	if roleARN != "" {
+		cfg.Credentials = aws.NewCredentialsCache(stscreds.NewAssumeRoleProvider(sts.NewFromConfig(cfg), roleARN))
+	}
+
+	service.client = ec2.NewFromConfig(cfg, service.endpoint.ec2Options)
+	service.autoScalingClient = autoscaling.NewFromConfig(cfg)
+	service.stsClient = sts.NewFromConfig(cfg)
+	service.region = cfg.Region
+	return service, nil
 }
 
 // NewInstanceServiceWithClient creates a new InstanceService with a provided client.
 // This is useful for testing and dependency injection.
-func NewInstanceServiceWithClient(client EC2ClientAPI) *InstanceService {
-	return &InstanceService{
-		client: client,
+func NewInstanceServiceWithClient(client EC2ClientAPI, opts ...InstanceServiceOption) *InstanceService {
+	service := &InstanceService{
+		client:      client,
+		batchSize:   DefaultBatchSize,
+		retryPolicy: RetryPolicy{MaxAttempts: 1},
 	}
+	for _, opt := range opts {
+		opt(service)
+	}
+	return service
 }
 
 // GetInstancesDetails retrieves details for multiple EC2 instances in a single API call.
 // This is more efficient than making separate calls for each instance.
+// When only some instances can be fetched, the fetched ones are returned together with a *PartialFetchError
+// holding the error of each failed instance. When none can be fetched, the first error is returned on its own.
 func (s *InstanceService) GetInstancesDetails(ctx context.Context, instanceIDs []string) ([]*models.InstanceDetails, error) {
 	if len(instanceIDs) == 0 {
 		return nil, NewAWSError(
@@ -63,9 +172,11 @@ func (s *InstanceService) GetInstancesDetails(ctx context.Context, instanceIDs [
 	}
 
 	allInstances := make([]*models.InstanceDetails, 0, len(instanceIDs))
+	failed := make(map[string]error)
+	var firstErr error
 	// Process in batches
-	for i := 0; i < len(instanceIDs); i += maxIDsPerRequest {
-		end := i + maxIDsPerRequest
+	for i := 0; i < len(instanceIDs); i += s.batchSize {
+		end := i + s.batchSize
 		if end > len(instanceIDs) {
 			end = len(instanceIDs)
 		}
@@ -74,29 +185,71 @@ func (s *InstanceService) GetInstancesDetails(ctx context.Context, instanceIDs [
 		// Make the API call for this batch
 		instances, err := s.getInstancesBatch(ctx, batch)
 		if err != nil {
-			return nil, err // Error already wrapped in getInstancesBatch
+			if firstErr == nil {
+				firstErr = err // Error already wrapped in getInstancesBatch
+			}
+			instances = s.recoverFailedBatch(ctx, batch, err, failed)
 		}
 
 		allInstances = append(allInstances, instances...)
 	}
 
+	if len(failed) > 0 {
+		if len(allInstances) == 0 {
+			return nil, firstErr
+		}
+		return allInstances, &PartialFetchError{Failed: failed}
+	}
+
 	return allInstances, nil
 }
 
-// getInstancesBatch retrieves a batch of instances (up to 50) in a single API call
+// recoverFailedBatch handles a batch that failed with batchErr and returns the instances that could still be fetched.
+// A single unknown ID fails the whole DescribeInstances call, so in that case the IDs are retried one by one
+// to isolate it. Any other error, such as throttling or missing permissions, is recorded for every ID of the batch.
+func (s *InstanceService) recoverFailedBatch(
+	ctx context.Context,
+	batch []string,
+	batchErr error,
+	failed map[string]error,
+) []*models.InstanceDetails {
+	if len(batch) == 1 || !IsErrorCategory(batchErr, ErrResourceNotFound) {
+		for _, id := range batch {
+			failed[id] = batchErr
+		}
+		return nil
+	}
+
+	var instances []*models.InstanceDetails
+	for _, id := range batch {
+		instance, err := s.getInstancesBatch(ctx, []string{id})
+		if err != nil {
+			failed[id] = err
+			continue
+		}
+		instances = append(instances, instance...)
+	}
+	return instances
+}
+
+// getInstancesBatch retrieves a batch of instances in a single API call
 func (s *InstanceService) getInstancesBatch(ctx context.Context, instanceIDs []string) ([]*models.InstanceDetails, error) {
-	resp, err := s.client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{
-		InstanceIds: instanceIDs,
+	// For better error messages, use just the ID if there's only one
+	resourceID := fmt.Sprintf("one or more of the following: %v", instanceIDs)
+	if len(instanceIDs) == 1 {
+		resourceID = instanceIDs[0]
+	}
+
+	// The AWS error is wrapped with our custom error type
+	var resp *ec2.DescribeInstancesOutput
+	err := s.call(ctx, EC2ResourceType, resourceID, func() (err error) {
+		resp, err = s.client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{
+			InstanceIds: instanceIDs,
+		})
+		return err
 	})
 	if err != nil {
-		// For better error messages, use just the ID if there's only one
-		resourceID := fmt.Sprintf("one or more of the following: %v", instanceIDs)
-		if len(instanceIDs) == 1 {
-			resourceID = instanceIDs[0]
-		}
-
-		// Wrap the AWS error with our custom error type
-		return nil, ClassifyAWSError(err, EC2ResourceType, resourceID)
+		return nil, err
 	}
 
 	// Process all instances in all reservations
@@ -111,6 +264,11 @@ func (s *InstanceService) getInstancesBatch(ctx context.Context, instanceIDs []s
 	return instances, nil
 }
 
+// APICalls returns the number of AWS API calls made by the service
+func (s *InstanceService) APICalls() int64 {
+	return s.apiCalls.Load()
+}
+
 // convertInstanceToModel converts an AWS EC2 instance to our domain model
 func convertInstanceToModel(instance types.Instance) *models.InstanceDetails {
 	instanceID := aws.ToString(instance.InstanceId)
@@ -125,8 +283,10 @@ func convertInstanceToModel(instance types.Instance) *models.InstanceDetails {
 	// Add security groups
 	if len(instance.SecurityGroups) > 0 {
 		details.SecurityGroups = make([]string, len(instance.SecurityGroups))
+		details.SecurityGroupNames = make([]string, len(instance.SecurityGroups))
 		for i, sg := range instance.SecurityGroups {
 			details.SecurityGroups[i] = aws.ToString(sg.GroupId)
+			details.SecurityGroupNames[i] = aws.ToString(sg.GroupName)
 		}
 	}
 
@@ -135,6 +295,66 @@ func convertInstanceToModel(instance types.Instance) *models.InstanceDetails {
 		details.SubnetID = aws.ToString(instance.SubnetId)
 	}
 
+	// Add VPC ID
+	if instance.VpcId != nil {
+		details.VPCID = aws.ToString(instance.VpcId)
+	}
+
+	// Add the primary IP addresses, the public one being released while the instance is stopped
+	details.PrivateIP = aws.ToString(instance.PrivateIpAddress)
+	details.PublicIP = aws.ToString(instance.PublicIpAddress)
+
+	// Add the lifecycle state and launch time
+	if instance.State != nil {
+		details.State = string(instance.State.Name)
+	}
+	details.LaunchTime = instance.LaunchTime
+
+	// Add the metadata service settings
+	if instance.MetadataOptions != nil {
+		details.MetadataOptions = &models.MetadataOptions{
+			HttpTokens:              string(instance.MetadataOptions.HttpTokens),
+			HttpEndpoint:            string(instance.MetadataOptions.HttpEndpoint),
+			HttpPutResponseHopLimit: int(aws.ToInt32(instance.MetadataOptions.HttpPutResponseHopLimit)),
+		}
+	}
+
+	if instance.HibernationOptions != nil {
+		details.HibernationEnabled = aws.ToBool(instance.HibernationOptions.Configured)
+	}
+
+	// Add the purchasing option and targeted capacity reservation, which both affect billing
+	details.InstanceLifecycle = string(instance.InstanceLifecycle)
+	if spec := instance.CapacityReservationSpecification; spec != nil && spec.CapacityReservationTarget != nil {
+		details.CapacityReservationID = aws.ToString(spec.CapacityReservationTarget.CapacityReservationId)
+	}
+
+	// Add the attached EBS volumes. The root volume, whose size and type come from the AMI, is kept apart
+	for _, mapping := range instance.BlockDeviceMappings {
+		deviceName := aws.ToString(mapping.DeviceName)
+		if mapping.Ebs == nil {
+			continue
+		}
+		if deviceName == aws.ToString(instance.RootDeviceName) {
+			details.RootDeleteOnTermination = mapping.Ebs.DeleteOnTermination
+			details.RootVolume = &models.BlockDevice{DeviceName: deviceName, VolumeID: aws.ToString(mapping.Ebs.VolumeId)}
+			continue
+		}
+		details.BlockDevices = append(details.BlockDevices, models.BlockDevice{
+			DeviceName: deviceName,
+			VolumeID:   aws.ToString(mapping.Ebs.VolumeId),
+		})
+	}
+
+	details.KeyName = aws.ToString(instance.KeyName)
+
+	// Add placement details
+	if instance.Placement != nil {
+		details.AvailabilityZone = aws.ToString(instance.Placement.AvailabilityZone)
+		details.PlacementGroup = aws.ToString(instance.Placement.GroupName)
+		details.Tenancy = string(instance.Placement.Tenancy)
+	}
+
 	return details
 }
 
@@ -2,13 +2,17 @@ package aws
 
 import (
 	"context"
+	"driftdetector/internal/models"
 	"driftdetector/internal/providers/aws/mocks"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -26,9 +30,40 @@ func TestGetInstancesDetails_Success(t *testing.T) {
 			{
 				Instances: []types.Instance{
 					{
-						InstanceId:   aws.String(instanceIDs[0]),
-						InstanceType: types.InstanceTypeT2Micro,
-						ImageId:      aws.String("ami-12345"),
+						InstanceId:       aws.String(instanceIDs[0]),
+						InstanceType:     types.InstanceTypeT2Micro,
+						ImageId:          aws.String("ami-12345"),
+						VpcId:            aws.String("vpc-12345"),
+						PrivateIpAddress: aws.String("10.0.1.10"),
+						PublicIpAddress:  aws.String("203.0.113.10"),
+						State:            &types.InstanceState{Name: types.InstanceStateNameRunning},
+						SecurityGroups: []types.GroupIdentifier{
+							{GroupId: aws.String("sg-12345"), GroupName: aws.String("web")},
+						},
+						Placement: &types.Placement{
+							AvailabilityZone: aws.String("us-east-1a"),
+							GroupName:        aws.String("cluster-pg"),
+							Tenancy:          types.TenancyDedicated,
+						},
+						MetadataOptions: &types.InstanceMetadataOptionsResponse{
+							HttpTokens:              types.HttpTokensStateRequired,
+							HttpEndpoint:            types.InstanceMetadataEndpointStateEnabled,
+							HttpPutResponseHopLimit: aws.Int32(2),
+						},
+						LaunchTime:         aws.Time(time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)),
+						InstanceLifecycle:  types.InstanceLifecycleTypeSpot,
+						HibernationOptions: &types.HibernationOptions{Configured: aws.Bool(true)},
+						CapacityReservationSpecification: &types.CapacityReservationSpecificationResponse{
+							CapacityReservationTarget: &types.CapacityReservationTargetResponse{
+								CapacityReservationId: aws.String("cr-12345"),
+							},
+						},
+						KeyName:        aws.String("deployer"),
+						RootDeviceName: aws.String("/dev/xvda"),
+						BlockDeviceMappings: []types.InstanceBlockDeviceMapping{
+							{DeviceName: aws.String("/dev/xvda"), Ebs: &types.EbsInstanceBlockDevice{VolumeId: aws.String("vol-root"), DeleteOnTermination: aws.Bool(false)}},
+							{DeviceName: aws.String("/dev/sdf"), Ebs: &types.EbsInstanceBlockDevice{VolumeId: aws.String("vol-data")}},
+						},
 					},
 					{
 						InstanceId:   aws.String(instanceIDs[1]),
@@ -57,11 +92,38 @@ func TestGetInstancesDetails_Success(t *testing.T) {
 	assert.Equal(t, 2, len(results))
 	assert.Equal(t, instanceIDs[0], results[0].InstanceID)
 	assert.Equal(t, string(types.InstanceTypeT2Micro), results[0].InstanceType)
+	assert.Equal(t, "vpc-12345", results[0].VPCID)
+	assert.Equal(t, []string{"sg-12345"}, results[0].SecurityGroups)
+	assert.Equal(t, []string{"web"}, results[0].SecurityGroupNames)
+	assert.Equal(t, "us-east-1a", results[0].AvailabilityZone)
+	assert.Equal(t, "cluster-pg", results[0].PlacementGroup)
+	assert.Equal(t, "dedicated", results[0].Tenancy)
+	assert.Equal(t, "deployer", results[0].KeyName)
+	assert.Empty(t, results[1].KeyName)
+	assert.Equal(t, &models.MetadataOptions{HttpTokens: "required", HttpEndpoint: "enabled", HttpPutResponseHopLimit: 2},
+		results[0].MetadataOptions)
+	assert.Nil(t, results[1].MetadataOptions)
+	assert.Equal(t, "running", results[0].State)
+	assert.Equal(t, "10.0.1.10", results[0].PrivateIP)
+	assert.Equal(t, "203.0.113.10", results[0].PublicIP)
+	assert.Empty(t, results[1].PublicIP)
+	assert.Equal(t, time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC), *results[0].LaunchTime)
+	assert.Equal(t, "spot", results[0].InstanceLifecycle)
+	assert.Equal(t, "cr-12345", results[0].CapacityReservationID)
+	assert.Empty(t, results[1].InstanceLifecycle, "On-demand instances have no lifecycle")
+	assert.True(t, results[0].HibernationEnabled)
+	assert.False(t, results[1].HibernationEnabled)
+	assert.Equal(t, aws.Bool(false), results[0].RootDeleteOnTermination, "The root volume setting should come from its mapping")
+	assert.Nil(t, results[1].RootDeleteOnTermination)
+	assert.Equal(t, []models.BlockDevice{{DeviceName: "/dev/sdf", VolumeID: "vol-data"}}, results[0].BlockDevices,
+		"The root volume is not a block device")
+	assert.Equal(t, &models.BlockDevice{DeviceName: "/dev/xvda", VolumeID: "vol-root"}, results[0].RootVolume)
+	assert.Nil(t, results[1].RootVolume)
 	assert.Equal(t, instanceIDs[1], results[1].InstanceID)
 	assert.Equal(t, string(types.InstanceTypeT2Medium), results[1].InstanceType)
 }
 
-func TestGetInstanceDetails_InstanceNotFound(t *testing.T) {
+func TestGetInstancesDetails_InstanceNotFound(t *testing.T) {
 	mockClient := mocks.NewEC2ClientAPI(t)
 
 	// nonexistent instance ID
@@ -91,7 +153,7 @@ func TestGetInstanceDetails_InstanceNotFound(t *testing.T) {
 	assert.Equal(t, instanceID, awsErr.ResourceID)
 }
 
-func TestGetInstanceDetails_AWSError(t *testing.T) {
+func TestGetInstancesDetails_AWSError(t *testing.T) {
 	mockClient := mocks.NewEC2ClientAPI(t)
 
 	instanceID := "i-1234567890abcdef0"
@@ -117,3 +179,131 @@ func TestGetInstanceDetails_AWSError(t *testing.T) {
 	assert.Equal(t, EC2ResourceType, awsErr.ResourceType)
 	assert.Equal(t, instanceID, awsErr.ResourceID)
 }
+
+func TestGetInstancesDetails_BatchSize(t *testing.T) {
+	mockClient := mocks.NewEC2ClientAPI(t)
+
+	instanceIDs := []string{"i-1", "i-2", "i-3", "i-4", "i-5"}
+
+	// Five IDs in batches of two take three calls
+	mockClient.On("DescribeInstances",
+		mock.Anything,
+		mock.MatchedBy(func(input *ec2.DescribeInstancesInput) bool {
+			return len(input.InstanceIds) <= 2
+		}),
+	).Return(&ec2.DescribeInstancesOutput{}, nil).Times(3)
+
+	service := NewInstanceServiceWithClient(mockClient, WithBatchSize(2))
+	_, err := service.GetInstancesDetails(context.Background(), instanceIDs)
+
+	assert.NoError(t, err)
+	mockClient.AssertNumberOfCalls(t, "DescribeInstances", 3)
+}
+
+func TestWithBatchSize_Clamped(t *testing.T) {
+	tests := []struct {
+		name     string
+		size     int
+		expected int
+	}{
+		{"Default", 0, DefaultBatchSize},
+		{"Within limits", 250, 250},
+		{"Below minimum", -5, 1},
+		{"Above AWS limit", 5000, maxIDsPerRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []InstanceServiceOption
+			if tt.size != 0 {
+				opts = append(opts, WithBatchSize(tt.size))
+			}
+			service := NewInstanceServiceWithClient(mocks.NewEC2ClientAPI(t), opts...)
+			assert.Equal(t, tt.expected, service.batchSize)
+		})
+	}
+}
+
+func TestWithEndpoint(t *testing.T) {
+	// Keep the test independent of the AWS configuration of the machine
+	t.Setenv("AWS_CONFIG_FILE", "/nonexistent")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/nonexistent")
+
+	service, err := NewInstanceServiceForRegion(context.Background(), "us-gov-west-1",
+		WithEndpoint(Endpoint{URL: "https://vpce-123.ec2.us-gov-west-1.vpce.amazonaws.com", UseFIPS: true}))
+	assert.NoError(t, err)
+
+	options := service.client.(*ec2.Client).Options()
+	assert.Equal(t, "https://vpce-123.ec2.us-gov-west-1.vpce.amazonaws.com", aws.ToString(options.BaseEndpoint))
+	assert.Equal(t, aws.FIPSEndpointStateEnabled, options.EndpointOptions.UseFIPSEndpoint)
+
+	// The clients of other services, such as STS for the account of the reports, use their own endpoints
+	stsOptions := service.stsClient.(*sts.Client).Options()
+	assert.Nil(t, stsOptions.BaseEndpoint)
+	assert.Equal(t, aws.FIPSEndpointStateUnset, stsOptions.EndpointOptions.UseFIPSEndpoint)
+	autoScalingOptions := service.autoScalingClient.(*autoscaling.Client).Options()
+	assert.Nil(t, autoScalingOptions.BaseEndpoint)
+
+	// The default endpoint is resolved for the region
+	service, err = NewInstanceServiceForRegion(context.Background(), "us-east-1")
+	assert.NoError(t, err)
+	options = service.client.(*ec2.Client).Options()
+	assert.Nil(t, options.BaseEndpoint)
+	assert.Equal(t, aws.FIPSEndpointStateUnset, options.EndpointOptions.UseFIPSEndpoint)
+}
+
+func TestGetInstancesDetails_PartialResults(t *testing.T) {
+	mockClient := mocks.NewEC2ClientAPI(t)
+
+	// One unknown ID fails the whole batch, so each ID is retried on its own
+	mockClient.On("DescribeInstances", mock.Anything, mock.MatchedBy(func(input *ec2.DescribeInstancesInput) bool {
+		return len(input.InstanceIds) == 2
+	})).Return(nil, errors.New("InvalidInstanceID.NotFound")).Once()
+	mockClient.On("DescribeInstances", mock.Anything, mock.MatchedBy(func(input *ec2.DescribeInstancesInput) bool {
+		return len(input.InstanceIds) == 1 && input.InstanceIds[0] == "i-good"
+	})).Return(&ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{{Instances: []types.Instance{{InstanceId: aws.String("i-good")}}}},
+	}, nil).Once()
+	mockClient.On("DescribeInstances", mock.Anything, mock.MatchedBy(func(input *ec2.DescribeInstancesInput) bool {
+		return len(input.InstanceIds) == 1 && input.InstanceIds[0] == "i-bad"
+	})).Return(nil, errors.New("InvalidInstanceID.NotFound")).Once()
+
+	service := NewInstanceServiceWithClient(mockClient)
+	results, err := service.GetInstancesDetails(context.Background(), []string{"i-good", "i-bad"})
+
+	assert.Len(t, results, 1)
+	assert.Equal(t, "i-good", results[0].InstanceID)
+
+	var partialErr *PartialFetchError
+	if assert.True(t, errors.As(err, &partialErr)) {
+		assert.Len(t, partialErr.Failed, 1)
+		assert.True(t, IsErrorCategory(partialErr.Failed["i-bad"], ErrResourceNotFound))
+	}
+}
+
+func TestGetInstancesDetails_PartialResultsFailedBatch(t *testing.T) {
+	mockClient := mocks.NewEC2ClientAPI(t)
+
+	// Errors other than unknown IDs fail every instance of the batch without retrying
+	mockClient.On("DescribeInstances", mock.Anything, mock.MatchedBy(func(input *ec2.DescribeInstancesInput) bool {
+		return input.InstanceIds[0] == "i-1"
+	})).Return(&ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{{Instances: []types.Instance{{InstanceId: aws.String("i-1")}, {InstanceId: aws.String("i-2")}}}},
+	}, nil).Once()
+	mockClient.On("DescribeInstances", mock.Anything, mock.MatchedBy(func(input *ec2.DescribeInstancesInput) bool {
+		return input.InstanceIds[0] == "i-3"
+	})).Return(nil, errors.New("RequestLimitExceeded")).Once()
+
+	service := NewInstanceServiceWithClient(mockClient, WithBatchSize(2))
+	results, err := service.GetInstancesDetails(context.Background(), []string{"i-1", "i-2", "i-3", "i-4"})
+
+	assert.Len(t, results, 2)
+
+	var partialErr *PartialFetchError
+	if assert.True(t, errors.As(err, &partialErr)) {
+		assert.Len(t, partialErr.Failed, 2)
+		assert.True(t, IsErrorCategory(partialErr.Failed["i-3"], ErrThrottling))
+		assert.True(t, IsErrorCategory(partialErr.Failed["i-4"], ErrThrottling))
+	}
+	assert.Equal(t, "failed to fetch 2 resources: i-3, i-4", err.Error())
+}
@@ -3,7 +3,9 @@ package aws
 import (
 	"context"
 
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 
 	"driftdetector/internal/models"
 )
@@ -13,11 +15,61 @@ import (
 //go:generate mockery --name=EC2ClientAPI --output=./mocks
 type EC2ClientAPI interface {
 	DescribeInstances(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error)
+	DescribeImages(ctx context.Context, params *ec2.DescribeImagesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeImagesOutput, error)
+	DescribeVolumes(ctx context.Context, params *ec2.DescribeVolumesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVolumesOutput, error)
+	DescribeInstanceAttribute(ctx context.Context, params *ec2.DescribeInstanceAttributeInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstanceAttributeOutput, error)
+	DescribeInstanceCreditSpecifications(ctx context.Context, params *ec2.DescribeInstanceCreditSpecificationsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstanceCreditSpecificationsOutput, error)
+	DescribeRegions(ctx context.Context, params *ec2.DescribeRegionsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeRegionsOutput, error)
 }
 
-// InstanceServiceAPI defines the interface for instance operations
+// AutoScalingClientAPI defines the Auto Scaling operations used to resolve the members of a group
+//
+//go:generate mockery --name=AutoScalingClientAPI --output=./mocks
+type AutoScalingClientAPI interface {
+	DescribeAutoScalingGroups(ctx context.Context, params *autoscaling.DescribeAutoScalingGroupsInput, optFns ...func(*autoscaling.Options)) (*autoscaling.DescribeAutoScalingGroupsOutput, error)
+}
+
+// STSClientAPI defines the STS operations used to check that AWS credentials resolve
+//
+//go:generate mockery --name=STSClientAPI --output=./mocks
+type STSClientAPI interface {
+	GetCallerIdentity(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error)
+}
+
+// InstanceServiceAPI defines the interface for instance operations.
+// It is the only instance service interface: the orchestrator and its mocks target it, and instances are
+// always fetched in batches through the context-first GetInstancesDetails.
 //
 //go:generate mockery --name=InstanceServiceAPI --output=./mocks
 type InstanceServiceAPI interface {
 	GetInstancesDetails(ctx context.Context, instanceIDs []string) ([]*models.InstanceDetails, error)
+	GetImagesDetails(ctx context.Context, imageIDs []string) ([]*models.ImageDetails, error)
+	GetVolumesDetails(ctx context.Context, volumeIDs []string) ([]*models.VolumeDetails, error)
+	GetDisableApiTermination(ctx context.Context, instanceID string) (bool, error)
+	GetUserData(ctx context.Context, instanceID string) (string, error)
+	GetInstanceInitiatedShutdownBehavior(ctx context.Context, instanceID string) (string, error)
+	GetCreditSpecifications(ctx context.Context, instanceIDs []string) (map[string]string, error)
+}
+
+// AutoScalingGroupAPI is implemented by services that can resolve the member instances of an Auto Scaling
+// group, so instance IDs can be discovered instead of listed.
+type AutoScalingGroupAPI interface {
+	GetAutoScalingGroupInstanceIDs(ctx context.Context, groupName string) ([]string, error)
+}
+
+// InstanceLister is implemented by services that can list every instance of their account and region, so
+// whole accounts can be scanned instead of listing instance IDs.
+type InstanceLister interface {
+	ListInstanceIDs(ctx context.Context) ([]string, error)
+}
+
+// IdentityProvider is implemented by services that know the AWS account and region they read instances from,
+// so reports can record where each instance was found.
+type IdentityProvider interface {
+	Identity(ctx context.Context) (Identity, error)
+}
+
+// APICallCounter is implemented by services that count the AWS API calls they make, for run statistics.
+type APICallCounter interface {
+	APICalls() int64
 }
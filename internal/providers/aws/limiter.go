@@ -0,0 +1,39 @@
+package aws
+
+import "context"
+
+// Limiter caps the number of AWS API calls in flight. A single Limiter can be shared by the services of
+// several regions so the cap applies to all of them together. A nil Limiter does not limit anything.
+type Limiter struct {
+	slots chan struct{}
+}
+
+// NewLimiter creates a Limiter allowing at most limit concurrent API calls.
+// It returns nil, an unlimited Limiter, when limit is not positive.
+func NewLimiter(limit int) *Limiter {
+	if limit <= 0 {
+		return nil
+	}
+	return &Limiter{slots: make(chan struct{}, limit)}
+}
+
+// acquire waits for a free slot, or until the context is cancelled
+func (l *Limiter) acquire(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees a slot taken by acquire
+func (l *Limiter) release() {
+	if l == nil {
+		return
+	}
+	<-l.slots
+}
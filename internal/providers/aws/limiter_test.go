@@ -0,0 +1,64 @@
+package aws
+
+import (
+	"context"
+	"driftdetector/internal/providers/aws/mocks"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestLimiter_SharedBetweenServices tests that services sharing a limiter never exceed it together
+func TestLimiter_SharedBetweenServices(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int64
+	track := func(mock.Arguments) {
+		current := inFlight.Add(1)
+		for {
+			observed := maxInFlight.Load()
+			if current <= observed || maxInFlight.CompareAndSwap(observed, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		inFlight.Add(-1)
+	}
+
+	limiter := NewLimiter(2)
+	var services []*InstanceService
+	for range 3 {
+		client := mocks.NewEC2ClientAPI(t)
+		client.On("DescribeInstances", mock.Anything, mock.Anything).Run(track).Return(&ec2.DescribeInstancesOutput{}, nil)
+		services = append(services, NewInstanceServiceWithClient(client, WithBatchSize(1), WithLimiter(limiter)))
+	}
+
+	var wg sync.WaitGroup
+	for _, service := range services {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = service.GetInstancesDetails(context.Background(), []string{"i-1", "i-2", "i-3"})
+		}()
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, maxInFlight.Load(), int64(2), "The shared limit should apply across services")
+}
+
+// TestLimiter_Cancelled tests that waiting for a slot stops when the context is cancelled
+func TestLimiter_Cancelled(t *testing.T) {
+	limiter := NewLimiter(1)
+	assert.NoError(t, limiter.acquire(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, limiter.acquire(ctx), context.Canceled)
+
+	limiter.release()
+	assert.Nil(t, NewLimiter(0), "A non-positive limit should not limit anything")
+	assert.NoError(t, (*Limiter)(nil).acquire(ctx))
+}
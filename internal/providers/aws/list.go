@@ -0,0 +1,65 @@
+package aws
+
+import (
+	"context"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// liveInstanceStates are the states of the instances that exist, leaving out the terminated ones which
+// stay listed for a while after termination
+var liveInstanceStates = []string{"pending", "running", "stopping", "stopped"}
+
+// ListInstanceIDs returns the IDs of every instance of the account and region of the service that is not
+// terminated or being terminated, sorted, so whole accounts can be scanned without listing their instances.
+func (s *InstanceService) ListInstanceIDs(ctx context.Context) ([]string, error) {
+	return s.describeInstanceIDs(ctx, EC2ResourceType, "", []types.Filter{
+		{Name: aws.String("instance-state-name"), Values: liveInstanceStates},
+	})
+}
+
+// describeInstanceIDs returns the IDs of the instances matching the filters, sorted, following every page
+// of the results. Errors are reported for the given resource.
+func (s *InstanceService) describeInstanceIDs(ctx context.Context, resourceType, resourceID string, filters []types.Filter) ([]string, error) {
+	input := &ec2.DescribeInstancesInput{Filters: filters}
+	var instanceIDs []string
+	for {
+		var resp *ec2.DescribeInstancesOutput
+		err := s.call(ctx, resourceType, resourceID, func() (err error) {
+			resp, err = s.client.DescribeInstances(ctx, input)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, reservation := range resp.Reservations {
+			for _, instance := range reservation.Instances {
+				instanceIDs = append(instanceIDs, aws.ToString(instance.InstanceId))
+			}
+		}
+		if aws.ToString(resp.NextToken) == "" {
+			break
+		}
+		input.NextToken = resp.NextToken
+	}
+
+	sort.Strings(instanceIDs)
+	return instanceIDs, nil
+}
+
+// ListInstanceIDs returns the IDs of the fixture instances that are not terminated or being terminated,
+// sorted, like InstanceService
+func (s *FileInstanceService) ListInstanceIDs(context.Context) ([]string, error) {
+	var instanceIDs []string
+	for id, instance := range s.instances {
+		if instance.State != "" && !isLiveInstanceState(instance.State) {
+			continue
+		}
+		instanceIDs = append(instanceIDs, id)
+	}
+	sort.Strings(instanceIDs)
+	return instanceIDs, nil
+}
@@ -0,0 +1,80 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"driftdetector/internal/providers/aws/mocks"
+)
+
+// reservationOf returns a reservation of instances with the given IDs
+func reservationOf(instanceIDs ...string) types.Reservation {
+	var reservation types.Reservation
+	for _, id := range instanceIDs {
+		reservation.Instances = append(reservation.Instances, types.Instance{InstanceId: aws.String(id)})
+	}
+	return reservation
+}
+
+// TestListInstanceIDs tests that instances that are not terminated are listed across pages, and sorted
+func TestListInstanceIDs(t *testing.T) {
+	mockClient := mocks.NewEC2ClientAPI(t)
+	isLiveQuery := func(token *string) func(*ec2.DescribeInstancesInput) bool {
+		return func(input *ec2.DescribeInstancesInput) bool {
+			return len(input.Filters) == 1 && aws.ToString(input.Filters[0].Name) == "instance-state-name" &&
+				assert.ObjectsAreEqual(liveInstanceStates, input.Filters[0].Values) &&
+				aws.ToString(input.NextToken) == aws.ToString(token)
+		}
+	}
+	mockClient.On("DescribeInstances", mock.Anything, mock.MatchedBy(isLiveQuery(nil))).
+		Return(&ec2.DescribeInstancesOutput{
+			Reservations: []types.Reservation{reservationOf("i-0000000000000000b"), reservationOf("i-0000000000000000c")},
+			NextToken:    aws.String("page-2"),
+		}, nil).Once()
+	mockClient.On("DescribeInstances", mock.Anything, mock.MatchedBy(isLiveQuery(aws.String("page-2")))).
+		Return(&ec2.DescribeInstancesOutput{
+			Reservations: []types.Reservation{reservationOf("i-0000000000000000a")},
+		}, nil).Once()
+
+	service := NewInstanceServiceWithClient(mockClient)
+	instanceIDs, err := service.ListInstanceIDs(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"i-0000000000000000a", "i-0000000000000000b", "i-0000000000000000c"}, instanceIDs)
+	assert.Equal(t, int64(2), service.APICalls())
+}
+
+// TestListInstanceIDs_Error tests that API errors are classified
+func TestListInstanceIDs_Error(t *testing.T) {
+	mockClient := mocks.NewEC2ClientAPI(t)
+	mockClient.On("DescribeInstances", mock.Anything, mock.Anything).
+		Return(nil, errors.New("UnauthorizedOperation: not authorized")).Once()
+
+	service := NewInstanceServiceWithClient(mockClient)
+	_, err := service.ListInstanceIDs(context.Background())
+
+	assert.True(t, IsErrorCategory(err, ErrPermissionDenied))
+}
+
+func TestFileInstanceService_ListInstanceIDs(t *testing.T) {
+	service, err := NewFileInstanceService(writeFixture(t, `{
+		"i-0000000000000000b": {"state": "running"},
+		"i-0000000000000000a": {},
+		"i-0000000000000000c": {"state": "terminated"},
+		"i-0000000000000000d": {"state": "shutting-down"}
+	}`))
+	require.NoError(t, err)
+
+	instanceIDs, err := service.ListInstanceIDs(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"i-0000000000000000a", "i-0000000000000000b"}, instanceIDs)
+}
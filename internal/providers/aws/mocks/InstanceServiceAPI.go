@@ -14,6 +14,122 @@ type InstanceServiceAPI struct {
 	mock.Mock
 }
 
+// GetCreditSpecifications provides a mock function with given fields: ctx, instanceIDs
+func (_m *InstanceServiceAPI) GetCreditSpecifications(ctx context.Context, instanceIDs []string) (map[string]string, error) {
+	ret := _m.Called(ctx, instanceIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCreditSpecifications")
+	}
+
+	var r0 map[string]string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) (map[string]string, error)); ok {
+		return rf(ctx, instanceIDs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) map[string]string); ok {
+		r0 = rf(ctx, instanceIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, instanceIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDisableApiTermination provides a mock function with given fields: ctx, instanceID
+func (_m *InstanceServiceAPI) GetDisableApiTermination(ctx context.Context, instanceID string) (bool, error) {
+	ret := _m.Called(ctx, instanceID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDisableApiTermination")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return rf(ctx, instanceID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, instanceID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, instanceID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetImagesDetails provides a mock function with given fields: ctx, imageIDs
+func (_m *InstanceServiceAPI) GetImagesDetails(ctx context.Context, imageIDs []string) ([]*models.ImageDetails, error) {
+	ret := _m.Called(ctx, imageIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetImagesDetails")
+	}
+
+	var r0 []*models.ImageDetails
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) ([]*models.ImageDetails, error)); ok {
+		return rf(ctx, imageIDs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []*models.ImageDetails); ok {
+		r0 = rf(ctx, imageIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.ImageDetails)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, imageIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetInstanceInitiatedShutdownBehavior provides a mock function with given fields: ctx, instanceID
+func (_m *InstanceServiceAPI) GetInstanceInitiatedShutdownBehavior(ctx context.Context, instanceID string) (string, error) {
+	ret := _m.Called(ctx, instanceID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetInstanceInitiatedShutdownBehavior")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
+		return rf(ctx, instanceID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
+		r0 = rf(ctx, instanceID)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, instanceID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetInstancesDetails provides a mock function with given fields: ctx, instanceIDs
 func (_m *InstanceServiceAPI) GetInstancesDetails(ctx context.Context, instanceIDs []string) ([]*models.InstanceDetails, error) {
 	ret := _m.Called(ctx, instanceIDs)
@@ -44,6 +160,64 @@ func (_m *InstanceServiceAPI) GetInstancesDetails(ctx context.Context, instanceI
 	return r0, r1
 }
 
+// GetUserData provides a mock function with given fields: ctx, instanceID
+func (_m *InstanceServiceAPI) GetUserData(ctx context.Context, instanceID string) (string, error) {
+	ret := _m.Called(ctx, instanceID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserData")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
+		return rf(ctx, instanceID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
+		r0 = rf(ctx, instanceID)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, instanceID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetVolumesDetails provides a mock function with given fields: ctx, volumeIDs
+func (_m *InstanceServiceAPI) GetVolumesDetails(ctx context.Context, volumeIDs []string) ([]*models.VolumeDetails, error) {
+	ret := _m.Called(ctx, volumeIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetVolumesDetails")
+	}
+
+	var r0 []*models.VolumeDetails
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) ([]*models.VolumeDetails, error)); ok {
+		return rf(ctx, volumeIDs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []*models.VolumeDetails); ok {
+		r0 = rf(ctx, volumeIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.VolumeDetails)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, volumeIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewInstanceServiceAPI creates a new instance of InstanceServiceAPI. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewInstanceServiceAPI(t interface {
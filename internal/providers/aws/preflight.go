@@ -0,0 +1,71 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// Preflight checks the AWS environment before a scan: that credentials resolve and that the region is valid.
+type Preflight struct {
+	stsClient STSClientAPI
+	ec2Client EC2ClientAPI
+	region    string
+}
+
+// NewPreflight creates a Preflight with the default AWS SDK configuration, for the given region or, if it is
+// empty, the region configured in the environment or config files.
+func NewPreflight(ctx context.Context, region string) (*Preflight, error) {
+	var loadOpts []func(*config.LoadOptions) error
+	if region != "" {
+		loadOpts = append(loadOpts, config.WithRegion(region))
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, loadOpts...)
+	if err != nil {
+		return nil, NewAWSError(ErrConfigurationError, "AWS", "", "unable to load AWS SDK config", err)
+	}
+	return NewPreflightWithClients(sts.NewFromConfig(cfg), ec2.NewFromConfig(cfg), cfg.Region), nil
+}
+
+// NewPreflightWithClients creates a Preflight with the given clients, for testing purposes
+func NewPreflightWithClients(stsClient STSClientAPI, ec2Client EC2ClientAPI, region string) *Preflight {
+	return &Preflight{
+		stsClient: stsClient,
+		ec2Client: ec2Client,
+		region:    region,
+	}
+}
+
+// Region returns the region that is checked
+func (p *Preflight) Region() string {
+	return p.region
+}
+
+// CallerIdentity returns the ARN of the identity the credentials resolve to.
+// It requires no permissions, so it fails only when the credentials are missing or invalid.
+func (p *Preflight) CallerIdentity(ctx context.Context) (string, error) {
+	resp, err := p.stsClient.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", ClassifyAWSError(err, "STS", "")
+	}
+	return aws.ToString(resp.Arn), nil
+}
+
+// CheckRegion checks that the region is set and is a region enabled for the account.
+func (p *Preflight) CheckRegion(ctx context.Context) error {
+	if p.region == "" {
+		return NewAWSError(ErrConfigurationError, "AWS", "", "no region configured, set AWS_REGION or pass a region", nil)
+	}
+	resp, err := p.ec2Client.DescribeRegions(ctx, &ec2.DescribeRegionsInput{RegionNames: []string{p.region}})
+	if err != nil {
+		return ClassifyAWSError(err, "Region", p.region)
+	}
+	if len(resp.Regions) == 0 {
+		return NewAWSError(ErrInvalidInput, "Region", p.region, fmt.Sprintf("region %s is not enabled for this account", p.region), nil)
+	}
+	return nil
+}
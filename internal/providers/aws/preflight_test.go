@@ -0,0 +1,49 @@
+package aws
+
+import (
+	"context"
+	"driftdetector/internal/providers/aws/mocks"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPreflight_CallerIdentity(t *testing.T) {
+	stsClient := mocks.NewSTSClientAPI(t)
+	stsClient.On("GetCallerIdentity", mock.Anything, mock.Anything).
+		Return(&sts.GetCallerIdentityOutput{Arn: aws.String("arn:aws:iam::123456789012:user/ci")}, nil).Once()
+	stsClient.On("GetCallerIdentity", mock.Anything, mock.Anything).
+		Return(nil, errors.New("InvalidClientTokenId: The security token included in the request is invalid")).Once()
+
+	preflight := NewPreflightWithClients(stsClient, mocks.NewEC2ClientAPI(t), "us-east-1")
+
+	arn, err := preflight.CallerIdentity(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "arn:aws:iam::123456789012:user/ci", arn)
+
+	_, err = preflight.CallerIdentity(context.Background())
+	assert.True(t, IsErrorCategory(err, ErrPermissionDenied))
+}
+
+func TestPreflight_CheckRegion(t *testing.T) {
+	ec2Client := mocks.NewEC2ClientAPI(t)
+	ec2Client.On("DescribeRegions", mock.Anything, mock.MatchedBy(func(input *ec2.DescribeRegionsInput) bool {
+		return assert.ObjectsAreEqual([]string{"eu-west-1"}, input.RegionNames)
+	})).Return(&ec2.DescribeRegionsOutput{Regions: []types.Region{{RegionName: aws.String("eu-west-1")}}}, nil)
+	ec2Client.On("DescribeRegions", mock.Anything, mock.Anything).
+		Return(nil, errors.New("InvalidParameterValue: Invalid region: eu-nowhere-1"))
+
+	assert.NoError(t, NewPreflightWithClients(mocks.NewSTSClientAPI(t), ec2Client, "eu-west-1").CheckRegion(context.Background()))
+
+	err := NewPreflightWithClients(mocks.NewSTSClientAPI(t), ec2Client, "eu-nowhere-1").CheckRegion(context.Background())
+	assert.True(t, IsErrorCategory(err, ErrInvalidInput))
+
+	err = NewPreflightWithClients(mocks.NewSTSClientAPI(t), ec2Client, "").CheckRegion(context.Background())
+	assert.True(t, IsErrorCategory(err, ErrConfigurationError))
+}
@@ -0,0 +1,105 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+const (
+	// DefaultRetryAttempts is the number of attempts, including the first, of a retried API call
+	DefaultRetryAttempts = 3
+	// DefaultRetryDelay is the delay before the first retry, doubled for each further retry
+	DefaultRetryDelay = 200 * time.Millisecond
+)
+
+// DefaultRetryableCategories are the transient error categories retried unless configured otherwise
+var DefaultRetryableCategories = []ErrorCategory{ErrThrottling, ErrNetworkError, ErrInternalError}
+
+// neverRetriedCategories are the error categories a retry cannot fix, so they are never retried
+var neverRetriedCategories = []ErrorCategory{ErrPermissionDenied, ErrResourceNotFound, ErrInvalidInput}
+
+// errorCategories lists all error categories
+var errorCategories = []ErrorCategory{
+	ErrResourceNotFound, ErrPermissionDenied, ErrThrottling, ErrConfigurationError,
+	ErrNetworkError, ErrInvalidInput, ErrInternalError,
+}
+
+// RetryPolicy controls which failed API calls are retried, and how often
+type RetryPolicy struct {
+	MaxAttempts int             // Attempts including the first one (1 = no retries)
+	BaseDelay   time.Duration   // Delay before the first retry, doubled for each further retry
+	Categories  []ErrorCategory // Error categories retried
+}
+
+// DefaultRetryPolicy returns the policy retrying the DefaultRetryableCategories
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: DefaultRetryAttempts,
+		BaseDelay:   DefaultRetryDelay,
+		Categories:  DefaultRetryableCategories,
+	}
+}
+
+// ValidateRetryCategory checks that category exists and can be retried.
+// Permission denied, resource not found and invalid input errors cannot be fixed by a retry.
+func ValidateRetryCategory(category ErrorCategory) error {
+	if !slices.Contains(errorCategories, category) {
+		return fmt.Errorf("unknown error category %q", category)
+	}
+	if slices.Contains(neverRetriedCategories, category) {
+		return fmt.Errorf("error category %q is never retried", category)
+	}
+	return nil
+}
+
+// retries returns true if err belongs to one of the retried categories
+func (p RetryPolicy) retries(err error) bool {
+	for _, category := range p.Categories {
+		if !slices.Contains(neverRetriedCategories, category) && IsErrorCategory(err, category) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithRetryPolicy sets the policy for retrying failed API calls. By default calls are not retried.
+func WithRetryPolicy(policy RetryPolicy) InstanceServiceOption {
+	return func(s *InstanceService) {
+		s.retryPolicy = policy
+	}
+}
+
+// call makes an API call through fn, waiting for the limiter and counting each attempt.
+// An error returned by fn is classified for resourceType and resourceID, and retried per the retry policy.
+func (s *InstanceService) call(ctx context.Context, resourceType, resourceID string, fn func() error) error {
+	delay := s.retryPolicy.BaseDelay
+	for attempt := 1; ; attempt++ {
+		err := s.callOnce(ctx, resourceType, resourceID, fn)
+		if err == nil || attempt >= s.retryPolicy.MaxAttempts || !s.retryPolicy.retries(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// callOnce makes a single attempt of an API call
+func (s *InstanceService) callOnce(ctx context.Context, resourceType, resourceID string, fn func() error) error {
+	if err := s.limiter.acquire(ctx); err != nil {
+		return err
+	}
+	defer s.limiter.release()
+
+	s.apiCalls.Add(1)
+	if err := fn(); err != nil {
+		return ClassifyAWSError(err, resourceType, resourceID)
+	}
+	return nil
+}
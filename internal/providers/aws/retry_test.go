@@ -0,0 +1,82 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"driftdetector/internal/providers/aws/mocks"
+)
+
+// testRetryPolicy retries the default categories without waiting
+func testRetryPolicy() RetryPolicy {
+	policy := DefaultRetryPolicy()
+	policy.BaseDelay = 0
+	return policy
+}
+
+func TestCall_RetriesNetworkErrors(t *testing.T) {
+	mockClient := mocks.NewEC2ClientAPI(t)
+
+	// An intermittent DNS failure is smoothed over by a retry
+	mockClient.On("DescribeInstances", mock.Anything, mock.Anything).
+		Return(nil, errors.New("dial tcp: lookup ec2.us-east-1.amazonaws.com: no such host")).Once()
+	mockClient.On("DescribeInstances", mock.Anything, mock.Anything).
+		Return(&ec2.DescribeInstancesOutput{}, nil).Once()
+
+	service := NewInstanceServiceWithClient(mockClient, WithRetryPolicy(testRetryPolicy()))
+	_, err := service.GetInstancesDetails(context.Background(), []string{"i-1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), service.APICalls())
+}
+
+func TestCall_GivesUpAfterMaxAttempts(t *testing.T) {
+	mockClient := mocks.NewEC2ClientAPI(t)
+	mockClient.On("DescribeInstances", mock.Anything, mock.Anything).
+		Return(nil, errors.New("RequestLimitExceeded")).Times(DefaultRetryAttempts)
+
+	service := NewInstanceServiceWithClient(mockClient, WithRetryPolicy(testRetryPolicy()))
+	_, err := service.GetInstancesDetails(context.Background(), []string{"i-1"})
+
+	assert.True(t, IsErrorCategory(err, ErrThrottling))
+}
+
+func TestCall_NeverRetriesPermanentErrors(t *testing.T) {
+	mockClient := mocks.NewEC2ClientAPI(t)
+	mockClient.On("DescribeInstances", mock.Anything, mock.Anything).
+		Return(nil, errors.New("UnauthorizedOperation")).Once()
+
+	// Permission denied is not retried even when configured
+	policy := testRetryPolicy()
+	policy.Categories = append(policy.Categories, ErrPermissionDenied)
+	service := NewInstanceServiceWithClient(mockClient, WithRetryPolicy(policy))
+	_, err := service.GetInstancesDetails(context.Background(), []string{"i-1"})
+
+	assert.True(t, IsErrorCategory(err, ErrPermissionDenied))
+}
+
+func TestCall_ConfiguredCategories(t *testing.T) {
+	mockClient := mocks.NewEC2ClientAPI(t)
+	mockClient.On("DescribeInstances", mock.Anything, mock.Anything).
+		Return(nil, errors.New("connection refused")).Once()
+
+	// Network errors are not retried when only throttling is
+	policy := testRetryPolicy()
+	policy.Categories = []ErrorCategory{ErrThrottling}
+	service := NewInstanceServiceWithClient(mockClient, WithRetryPolicy(policy))
+	_, err := service.GetInstancesDetails(context.Background(), []string{"i-1"})
+
+	assert.True(t, IsErrorCategory(err, ErrNetworkError))
+}
+
+func TestValidateRetryCategory(t *testing.T) {
+	assert.NoError(t, ValidateRetryCategory(ErrNetworkError))
+	assert.NoError(t, ValidateRetryCategory(ErrThrottling))
+	assert.ErrorContains(t, ValidateRetryCategory(ErrResourceNotFound), "never retried")
+	assert.ErrorContains(t, ValidateRetryCategory("dns_error"), "unknown error category")
+}
@@ -0,0 +1,73 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+
+	"driftdetector/internal/models"
+)
+
+// VolumeResourceType is the AWS resource type for EBS volumes
+const VolumeResourceType = "EBS volume"
+
+// GetVolumesDetails retrieves the size, type and tags of multiple EBS volumes, batching the requests
+// in the same way as GetInstancesDetails.
+func (s *InstanceService) GetVolumesDetails(ctx context.Context, volumeIDs []string) ([]*models.VolumeDetails, error) {
+	if len(volumeIDs) == 0 {
+		return nil, NewAWSError(
+			ErrInvalidInput,
+			VolumeResourceType,
+			"",
+			"at least one volume ID must be provided",
+			nil,
+		)
+	}
+
+	allVolumes := make([]*models.VolumeDetails, 0, len(volumeIDs))
+	for i := 0; i < len(volumeIDs); i += s.batchSize {
+		end := min(i+s.batchSize, len(volumeIDs))
+		volumes, err := s.getVolumesBatch(ctx, volumeIDs[i:end])
+		if err != nil {
+			return nil, err // Error already wrapped in getVolumesBatch
+		}
+		allVolumes = append(allVolumes, volumes...)
+	}
+
+	return allVolumes, nil
+}
+
+// getVolumesBatch retrieves a batch of volumes in a single API call
+func (s *InstanceService) getVolumesBatch(ctx context.Context, volumeIDs []string) ([]*models.VolumeDetails, error) {
+	resourceID := fmt.Sprintf("one or more of the following: %v", volumeIDs)
+	if len(volumeIDs) == 1 {
+		resourceID = volumeIDs[0]
+	}
+
+	var resp *ec2.DescribeVolumesOutput
+	err := s.call(ctx, VolumeResourceType, resourceID, func() (err error) {
+		resp, err = s.client.DescribeVolumes(ctx, &ec2.DescribeVolumesInput{VolumeIds: volumeIDs})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	volumes := make([]*models.VolumeDetails, 0, len(resp.Volumes))
+	for _, volume := range resp.Volumes {
+		tags := make(map[string]string, len(volume.Tags))
+		for _, tag := range volume.Tags {
+			tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+		}
+		volumes = append(volumes, &models.VolumeDetails{
+			VolumeID:   aws.ToString(volume.VolumeId),
+			VolumeSize: int(aws.ToInt32(volume.Size)),
+			VolumeType: string(volume.VolumeType),
+			Tags:       tags,
+		})
+	}
+
+	return volumes, nil
+}
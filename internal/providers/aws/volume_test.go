@@ -0,0 +1,50 @@
+package aws
+
+import (
+	"context"
+	"driftdetector/internal/providers/aws/mocks"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetVolumesDetails_Success(t *testing.T) {
+	mockClient := mocks.NewEC2ClientAPI(t)
+
+	mockClient.On("DescribeVolumes",
+		mock.Anything,
+		mock.MatchedBy(func(input *ec2.DescribeVolumesInput) bool {
+			return assert.ObjectsAreEqual([]string{"vol-data"}, input.VolumeIds)
+		}),
+	).Return(&ec2.DescribeVolumesOutput{
+		Volumes: []types.Volume{
+			{
+				VolumeId:   aws.String("vol-data"),
+				Size:       aws.Int32(100),
+				VolumeType: types.VolumeTypeGp3,
+				Tags:       []types.Tag{{Key: aws.String("CostCenter"), Value: aws.String("42")}},
+			},
+		},
+	}, nil)
+
+	service := NewInstanceServiceWithClient(mockClient)
+	results, err := service.GetVolumesDetails(context.Background(), []string{"vol-data"})
+
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "vol-data", results[0].VolumeID)
+	assert.Equal(t, 100, results[0].VolumeSize)
+	assert.Equal(t, "gp3", results[0].VolumeType)
+	assert.Equal(t, map[string]string{"CostCenter": "42"}, results[0].Tags)
+}
+
+func TestGetVolumesDetails_NoIDs(t *testing.T) {
+	service := NewInstanceServiceWithClient(mocks.NewEC2ClientAPI(t))
+	_, err := service.GetVolumesDetails(context.Background(), nil)
+
+	assert.True(t, IsErrorCategory(err, ErrInvalidInput))
+}
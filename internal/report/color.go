@@ -0,0 +1,68 @@
+package report
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// ColorMode controls whether the table output is colorized with ANSI escape codes.
+type ColorMode string
+
+const (
+	// ColorModeAuto colorizes only when writing to a terminal
+	ColorModeAuto ColorMode = "auto"
+	// ColorModeAlways always colorizes, even when the output is redirected
+	ColorModeAlways ColorMode = "always"
+	// ColorModeNever never colorizes
+	ColorModeNever ColorMode = "never"
+)
+
+// ANSI escape codes used by the table output
+const (
+	ansiRed    = "\033[31m"
+	ansiGreen  = "\033[32m"
+	ansiYellow = "\033[33m"
+	ansiReset  = "\033[0m"
+)
+
+// ParseColorMode converts a string to a ColorMode. An empty string selects ColorModeAuto.
+func ParseColorMode(mode string) (ColorMode, error) {
+	switch ColorMode(strings.ToLower(mode)) {
+	case "", ColorModeAuto:
+		return ColorModeAuto, nil
+	case ColorModeAlways:
+		return ColorModeAlways, nil
+	case ColorModeNever:
+		return ColorModeNever, nil
+	default:
+		return "", fmt.Errorf("unsupported color mode: %s (expected auto, always or never)", mode)
+	}
+}
+
+// ShouldColorize resolves a ColorMode for the given output file.
+// In auto mode color is only used when the file is a terminal and NO_COLOR is not set,
+// so that output redirected to a file or pipe never contains escape codes.
+func ShouldColorize(mode ColorMode, out *os.File) bool {
+	switch mode {
+	case ColorModeAlways:
+		return true
+	case ColorModeNever:
+		return false
+	default:
+		if _, noColor := os.LookupEnv("NO_COLOR"); noColor {
+			return false
+		}
+		return out != nil && term.IsTerminal(int(out.Fd()))
+	}
+}
+
+// colorize wraps the text in the given ANSI color when enabled
+func colorize(enabled bool, color, text string) string {
+	if !enabled {
+		return text
+	}
+	return color + text + ansiReset
+}
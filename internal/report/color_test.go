@@ -0,0 +1,80 @@
+package report_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"driftdetector/internal/models"
+	"driftdetector/internal/report"
+)
+
+func TestParseColorMode(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected report.ColorMode
+		wantErr  bool
+	}{
+		{"", report.ColorModeAuto, false},
+		{"auto", report.ColorModeAuto, false},
+		{"ALWAYS", report.ColorModeAlways, false},
+		{"never", report.ColorModeNever, false},
+		{"rainbow", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			mode, err := report.ParseColorMode(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err, "expected error for invalid color mode")
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, mode)
+		})
+	}
+}
+
+func TestShouldColorize(t *testing.T) {
+	// A regular file stands in for redirected output
+	file, err := os.Create(filepath.Join(t.TempDir(), "report.txt"))
+	assert.NoError(t, err)
+	defer file.Close()
+
+	assert.False(t, report.ShouldColorize(report.ColorModeAuto, file), "auto should not colorize redirected output")
+	assert.True(t, report.ShouldColorize(report.ColorModeAlways, file), "always should colorize redirected output")
+	assert.False(t, report.ShouldColorize(report.ColorModeNever, file), "never should not colorize")
+}
+
+func TestPrinter_Color(t *testing.T) {
+	drifts := []models.DriftDetail{
+		{
+			Attribute:      "instance_type",
+			AWSValue:       "t2.micro",
+			TerraformValue: "t2.small",
+		},
+	}
+
+	colored := captureOutput(func() {
+		err := report.NewPrinterWithColor(true).PrintReport("i-123", drifts, report.OutputFormatTypeTABLE)
+		assert.NoError(t, err, "unexpected error")
+	})
+	assert.Contains(t, colored, "\033[31mDRIFT\033[0m", "Drift status should be red")
+
+	plain := captureOutput(func() {
+		err := report.NewDefaultPrinter().PrintReport("i-123", drifts, report.OutputFormatTypeTABLE)
+		assert.NoError(t, err, "unexpected error")
+	})
+	assert.NotContains(t, plain, "\033[", "Uncolored output must not contain escape codes")
+
+	// JSON output is never colorized
+	jsonOutput := captureOutput(func() {
+		printer := report.NewPrinterWithColor(true)
+		assert.NoError(t, printer.PrintReport("i-123", drifts, report.OutputFormatTypeJSON))
+		assert.NoError(t, printer.Flush(report.OutputFormatTypeJSON))
+	})
+	assert.Contains(t, jsonOutput, "\"instance_id\": \"i-123\"")
+	assert.NotContains(t, jsonOutput, "\033[", "JSON output must not contain escape codes")
+}
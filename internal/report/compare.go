@@ -0,0 +1,127 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"driftdetector/internal/models"
+)
+
+// ComparedDrift is the drift of an attribute of an instance, as found by one of the runs being compared
+type ComparedDrift struct {
+	InstanceID string
+	models.DriftDetail
+}
+
+// RunComparison holds the differences between the drifts of two runs, each sorted by instance and attribute.
+type RunComparison struct {
+	NewlyDrifted  []ComparedDrift // Drifts of the later run that the earlier run did not find
+	Resolved      []ComparedDrift // Drifts of the earlier run that the later run no longer found
+	StillDrifting []ComparedDrift // Drifts found by both runs, with the values of the later run
+}
+
+// ReadJSONReport reads the JSON envelope written by a previous run. Envelopes of another major schema
+// version are rejected, as their reports cannot be read reliably.
+func ReadJSONReport(r io.Reader) (*JSONEnvelope, error) {
+	var envelope JSONEnvelope
+	if err := json.NewDecoder(r).Decode(&envelope); err != nil {
+		return nil, fmt.Errorf("error parsing JSON report: %w", err)
+	}
+	if envelope.SchemaVersion == "" {
+		return nil, fmt.Errorf("not a JSON report: schema_version is missing")
+	}
+	major, _, _ := strings.Cut(envelope.SchemaVersion, ".")
+	supportedMajor, _, _ := strings.Cut(JSONSchemaVersion, ".")
+	if major != supportedMajor {
+		return nil, fmt.Errorf("unsupported JSON report schema version %s: expected %s.x", envelope.SchemaVersion, supportedMajor)
+	}
+	return &envelope, nil
+}
+
+// CompareRuns compares the reports of an earlier and a later run, matching drifts by instance and attribute.
+// A drift is only resolved if the later run checked its instance: instances the later run left out or could
+// not check are not known to be fixed.
+func CompareRuns(before, after []DriftReport) RunComparison {
+	beforeDrifts := driftsByKey(before)
+	afterDrifts := driftsByKey(after)
+	checked := make(map[string]bool)
+	for _, report := range after {
+		if report.Error == "" {
+			checked[report.InstanceID] = true
+		}
+	}
+
+	var comparison RunComparison
+	for key, drift := range afterDrifts {
+		if _, existed := beforeDrifts[key]; existed {
+			comparison.StillDrifting = append(comparison.StillDrifting, drift)
+		} else {
+			comparison.NewlyDrifted = append(comparison.NewlyDrifted, drift)
+		}
+	}
+	for key, drift := range beforeDrifts {
+		if _, exists := afterDrifts[key]; !exists && checked[drift.InstanceID] {
+			comparison.Resolved = append(comparison.Resolved, drift)
+		}
+	}
+
+	sortComparedDrifts(comparison.NewlyDrifted)
+	sortComparedDrifts(comparison.Resolved)
+	sortComparedDrifts(comparison.StillDrifting)
+	return comparison
+}
+
+// driftsByKey returns the drifts of the reports keyed by instance ID and attribute
+func driftsByKey(reports []DriftReport) map[[2]string]ComparedDrift {
+	drifts := make(map[[2]string]ComparedDrift)
+	for _, report := range reports {
+		for _, d := range report.Drifts {
+			drifts[[2]string{report.InstanceID, d.Attribute}] = ComparedDrift{InstanceID: report.InstanceID, DriftDetail: d}
+		}
+	}
+	return drifts
+}
+
+// sortComparedDrifts sorts drifts by instance ID, then attribute
+func sortComparedDrifts(drifts []ComparedDrift) {
+	sort.Slice(drifts, func(i, j int) bool {
+		if drifts[i].InstanceID != drifts[j].InstanceID {
+			return drifts[i].InstanceID < drifts[j].InstanceID
+		}
+		return drifts[i].Attribute < drifts[j].Attribute
+	})
+}
+
+// WriteRunComparison writes the comparison of two runs as a table per kind of change.
+func WriteRunComparison(w io.Writer, comparison RunComparison) error {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	sections := []struct {
+		title  string
+		drifts []ComparedDrift
+	}{
+		{"NEWLY DRIFTED", comparison.NewlyDrifted},
+		{"RESOLVED", comparison.Resolved},
+		{"STILL DRIFTING", comparison.StillDrifting},
+	}
+	for _, section := range sections {
+		fmt.Fprintf(writer, "\n%s: %d\n", section.title, len(section.drifts))
+		if len(section.drifts) == 0 {
+			continue
+		}
+		fmt.Fprintln(writer, "\nINSTANCE ID\tATTRIBUTE\tAWS VALUE\tTERRAFORM VALUE")
+		fmt.Fprintln(writer, "-----------\t---------\t---------\t---------------")
+		for _, d := range section.drifts {
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n",
+				d.InstanceID,
+				d.Attribute,
+				formatValueForTable(d.AWSValue),
+				formatValueForTable(d.TerraformValue))
+		}
+	}
+	return writer.Flush()
+}
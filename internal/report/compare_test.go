@@ -0,0 +1,73 @@
+package report_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"driftdetector/internal/models"
+	"driftdetector/internal/report"
+)
+
+func TestReadJSONReport(t *testing.T) {
+	envelope, err := report.ReadJSONReport(strings.NewReader(`{
+		"schema_version": "1.0",
+		"generated_at": "2024-05-01T08:30:00Z",
+		"reports": [{"instance_id": "i-1", "drifts": [{"Attribute": "instance_type", "AWSValue": "t2.micro", "TerraformValue": "t3.micro"}]}]
+	}`))
+	require.NoError(t, err)
+	require.Len(t, envelope.Reports, 1)
+	assert.Equal(t, "instance_type", envelope.Reports[0].Drifts[0].Attribute)
+
+	_, err = report.ReadJSONReport(strings.NewReader(`{"schema_version": "2.0", "reports": []}`))
+	assert.ErrorContains(t, err, "unsupported JSON report schema version 2.0")
+
+	// e.g. a JSON-lines stream or a desired-state file
+	_, err = report.ReadJSONReport(strings.NewReader(`{"instance_type": "t2.micro"}`))
+	assert.ErrorContains(t, err, "schema_version is missing")
+}
+
+func TestCompareRuns(t *testing.T) {
+	drift := func(attribute, aws string) models.DriftDetail {
+		return models.DriftDetail{Attribute: attribute, AWSValue: aws, TerraformValue: "expected"}
+	}
+	before := []report.DriftReport{
+		{InstanceID: "i-1", Drifts: []models.DriftDetail{drift("instance_type", "t2.micro"), drift("ami", "ami-1")}},
+		{InstanceID: "i-2", Drifts: []models.DriftDetail{drift("subnet_id", "subnet-1")}},
+		{InstanceID: "i-3", Drifts: []models.DriftDetail{drift("tags.Env", "dev")}},
+	}
+	after := []report.DriftReport{
+		{InstanceID: "i-1", Drifts: []models.DriftDetail{drift("instance_type", "t2.small"), drift("tenancy", "dedicated")}},
+		{InstanceID: "i-2", Error: "permission_denied: Access denied"},
+		{InstanceID: "i-4", Drifts: []models.DriftDetail{drift("ami", "ami-2")}},
+	}
+
+	comparison := report.CompareRuns(before, after)
+
+	assert.Equal(t, []report.ComparedDrift{
+		{InstanceID: "i-1", DriftDetail: drift("tenancy", "dedicated")},
+		{InstanceID: "i-4", DriftDetail: drift("ami", "ami-2")},
+	}, comparison.NewlyDrifted)
+	// i-2 could not be checked and i-3 was not checked, so their drifts are not known to be resolved
+	assert.Equal(t, []report.ComparedDrift{{InstanceID: "i-1", DriftDetail: drift("ami", "ami-1")}}, comparison.Resolved)
+	assert.Equal(t, []report.ComparedDrift{{InstanceID: "i-1", DriftDetail: drift("instance_type", "t2.small")}}, comparison.StillDrifting)
+}
+
+func TestWriteRunComparison(t *testing.T) {
+	comparison := report.RunComparison{
+		NewlyDrifted: []report.ComparedDrift{
+			{InstanceID: "i-1", DriftDetail: models.DriftDetail{Attribute: "instance_type", AWSValue: "t2.micro", TerraformValue: "t3.micro"}},
+		},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, report.WriteRunComparison(&out, comparison))
+
+	assert.Contains(t, out.String(), "NEWLY DRIFTED: 1")
+	assert.Contains(t, out.String(), "i-1          instance_type  t2.micro   t3.micro")
+	assert.Contains(t, out.String(), "RESOLVED: 0")
+	assert.Contains(t, out.String(), "STILL DRIFTING: 0")
+}
@@ -0,0 +1,86 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"driftdetector/internal/models"
+)
+
+// printDiffReport prints the report as a unified-diff-style block: for each drifted attribute the Terraform
+// (expected) value is prefixed with "-" and the AWS (actual) value with "+". Instances without drift print nothing,
+// just like git diff for unchanged files. With contextLines, like diff -U, up to that many of the matching attributes
+// before and after each drifted one, in attribute order, are printed prefixed with " ".
+func printDiffReport(w io.Writer, report DriftReport, color bool, contextLines int) error {
+	if len(report.Drifts) == 0 {
+		return nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- terraform/%s\n", report.InstanceID)
+	fmt.Fprintf(&b, "+++ aws/%s\n", report.InstanceID)
+	for _, line := range diffLines(report, contextLines) {
+		d := line.DriftDetail
+		if line.match {
+			fmt.Fprintf(&b, " %s: %s\n", d.Attribute, formatValueForTable(d.AWSValue))
+			continue
+		}
+
+		if d.Source != nil {
+			fmt.Fprintf(&b, "@@ %s (%s) @@\n", d.Attribute, d.Source)
+		} else {
+			fmt.Fprintf(&b, "@@ %s @@\n", d.Attribute)
+		}
+
+		// Keys that only exist on one side only have a value on that side
+		if d.Change != models.ChangeAdded {
+			b.WriteString(colorize(color, ansiRed, "-"+formatValueForTable(d.TerraformValue)) + "\n")
+		}
+		if d.Change != models.ChangeRemoved {
+			b.WriteString(colorize(color, ansiGreen, "+"+formatValueForTable(d.AWSValue)) + "\n")
+		}
+	}
+
+	_, err := fmt.Fprint(w, b.String())
+	return err
+}
+
+// diffLines returns the drifts of the report sorted by attribute, with the matches within contextLines of a drift
+func diffLines(report DriftReport, contextLines int) []tableRow {
+	// Sort a copy so the caller's slices are left untouched
+	lines := make([]tableRow, 0, len(report.Drifts)+len(report.Matches))
+	for _, d := range report.Drifts {
+		lines = append(lines, tableRow{DriftDetail: d})
+	}
+	if contextLines > 0 {
+		for _, m := range report.Matches {
+			lines = append(lines, tableRow{DriftDetail: m, match: true})
+		}
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].Attribute < lines[j].Attribute
+	})
+	if contextLines <= 0 {
+		return lines
+	}
+
+	// Keep the matches that are at most contextLines away from a drift
+	keep := make([]bool, len(lines))
+	for i, line := range lines {
+		if line.match {
+			continue
+		}
+		for j := max(0, i-contextLines); j <= min(len(lines)-1, i+contextLines); j++ {
+			keep[j] = true
+		}
+	}
+	kept := lines[:0]
+	for i, line := range lines {
+		if keep[i] {
+			kept = append(kept, line)
+		}
+	}
+	return kept
+}
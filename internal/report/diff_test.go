@@ -0,0 +1,106 @@
+package report_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"driftdetector/internal/models"
+	"driftdetector/internal/report"
+)
+
+func TestPrintReport_Diff(t *testing.T) {
+	drifts := []models.DriftDetail{
+		{Attribute: "tags.Owner", AWSValue: "alice", Change: models.ChangeAdded},
+		{
+			Attribute:      "instance_type",
+			AWSValue:       "t2.large",
+			TerraformValue: "t2.micro",
+			Source:         &models.SourceLocation{Filename: "main.tf", Line: 3},
+		},
+		{Attribute: "tags.Env", TerraformValue: "prod", Change: models.ChangeRemoved},
+	}
+
+	output := captureOutput(func() {
+		err := report.PrintReport(&sync.Mutex{}, "i-123", drifts, report.OutputFormatTypeDIFF)
+		assert.NoError(t, err, "unexpected error")
+	})
+
+	// Attributes are sorted, and keys that only exist on one side only have that side's line
+	expected := "--- terraform/i-123\n" +
+		"+++ aws/i-123\n" +
+		"@@ instance_type (main.tf:3) @@\n" +
+		"-t2.micro\n" +
+		"+t2.large\n" +
+		"@@ tags.Env @@\n" +
+		"-prod\n" +
+		"@@ tags.Owner @@\n" +
+		"+alice\n"
+	assert.Equal(t, expected, output)
+	assert.Equal(t, "tags.Owner", drifts[0].Attribute, "The caller's slice must not be reordered")
+}
+
+func TestPrintReport_DiffNoDrift(t *testing.T) {
+	output := captureOutput(func() {
+		err := report.PrintReport(&sync.Mutex{}, "i-123", nil, report.OutputFormatTypeDIFF)
+		assert.NoError(t, err, "unexpected error")
+	})
+	assert.Empty(t, output, "Instances without drift should print nothing")
+}
+
+func TestPrintReport_DiffColor(t *testing.T) {
+	drifts := []models.DriftDetail{{Attribute: "ami", AWSValue: "ami-new", TerraformValue: "ami-old"}}
+
+	output := captureOutput(func() {
+		err := report.NewPrinterWithColor(true).PrintReport("i-123", drifts, report.OutputFormatTypeDIFF)
+		assert.NoError(t, err, "unexpected error")
+	})
+	assert.Contains(t, output, "\033[31m-ami-old\033[0m")
+	assert.Contains(t, output, "\033[32m+ami-new\033[0m")
+}
+
+func TestPrintReport_DiffContext(t *testing.T) {
+	drifts := []models.DriftDetail{
+		{Attribute: "availability_zone", AWSValue: "us-east-1b", TerraformValue: "us-east-1a"},
+		{Attribute: "vpc_id", AWSValue: "vpc-2", TerraformValue: "vpc-1"},
+	}
+	matches := []models.DriftDetail{
+		{Attribute: "ami", AWSValue: "ami-123", TerraformValue: "ami-123"},
+		{Attribute: "block_devices", AWSValue: "[]", TerraformValue: "[]"},
+		{Attribute: "instance_type", AWSValue: "t3.small", TerraformValue: "t3.small"},
+		{Attribute: "subnet_id", AWSValue: "subnet-1", TerraformValue: "subnet-1"},
+		{Attribute: "tenancy", AWSValue: "default", TerraformValue: "default"},
+	}
+	printer := report.NewPrinter(report.PrinterOptions{DiffContext: 1, MatchesAsContext: true})
+
+	output := captureOutput(func() {
+		assert.NoError(t, printer.PrintReportWithMatches("i-123", drifts, matches, report.OutputFormatTypeDIFF))
+	})
+
+	// Only the matches next to a drift are shown, instance_type being two attributes away from both
+	expected := "--- terraform/i-123\n" +
+		"+++ aws/i-123\n" +
+		" ami: ami-123\n" +
+		"@@ availability_zone @@\n" +
+		"-us-east-1a\n" +
+		"+us-east-1b\n" +
+		" block_devices: []\n" +
+		" tenancy: default\n" +
+		"@@ vpc_id @@\n" +
+		"-vpc-1\n" +
+		"+vpc-2\n"
+	assert.Equal(t, expected, output)
+
+	// The table does not list matches passed as context
+	output = captureOutput(func() {
+		assert.NoError(t, printer.PrintReportWithMatches("i-123", drifts, matches, report.OutputFormatTypeTABLE))
+	})
+	assert.NotContains(t, output, "ami-123")
+
+	// Without context only the drifts are shown
+	output = captureOutput(func() {
+		assert.NoError(t, report.NewDefaultPrinter().PrintReportWithMatches("i-123", drifts, matches, report.OutputFormatTypeDIFF))
+	})
+	assert.NotContains(t, output, "ami-123")
+}
@@ -0,0 +1,78 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// DriftGroup is a set of instances whose attribute drifted the same way: from the same Terraform value
+// to the same AWS value.
+type DriftGroup struct {
+	Attribute      string
+	AWSValue       string
+	TerraformValue string
+	InstanceIDs    []string
+}
+
+// GroupDrifts groups the drifts of an attribute across the reports of a run by their values, largest group
+// first. Per-key drifts of a keyed attribute, such as tags.Env for tags, are grouped per key.
+func GroupDrifts(reports []DriftReport, attribute string) []DriftGroup {
+	var groups []DriftGroup
+	index := make(map[[3]string]int)
+	for _, report := range reports {
+		for _, d := range report.Drifts {
+			if d.Attribute != attribute && !strings.HasPrefix(d.Attribute, attribute+".") {
+				continue
+			}
+			group := DriftGroup{
+				Attribute:      d.Attribute,
+				AWSValue:       formatValueForTable(d.AWSValue),
+				TerraformValue: formatValueForTable(d.TerraformValue),
+			}
+			key := [3]string{group.Attribute, group.AWSValue, group.TerraformValue}
+			i, exists := index[key]
+			if !exists {
+				i = len(groups)
+				index[key] = i
+				groups = append(groups, group)
+			}
+			groups[i].InstanceIDs = append(groups[i].InstanceIDs, report.InstanceID)
+		}
+	}
+
+	for _, group := range groups {
+		sort.Strings(group.InstanceIDs)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if len(groups[i].InstanceIDs) != len(groups[j].InstanceIDs) {
+			return len(groups[i].InstanceIDs) > len(groups[j].InstanceIDs)
+		}
+		return groups[i].Attribute < groups[j].Attribute
+	})
+	return groups
+}
+
+// WriteDriftGroups writes the drift groups of an attribute as a table, a row per group.
+func WriteDriftGroups(w io.Writer, attribute string, groups []DriftGroup) error {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(writer, "\nDRIFT OF %s BY VALUE:\n\n", attribute)
+	if len(groups) == 0 {
+		fmt.Fprintf(writer, "No instance has drift in %s\n", attribute)
+		return writer.Flush()
+	}
+	fmt.Fprintln(writer, "ATTRIBUTE\tAWS VALUE\tTERRAFORM VALUE\tINSTANCES")
+	fmt.Fprintln(writer, "---------\t---------\t---------------\t---------")
+	for _, group := range groups {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%d: %s\n",
+			group.Attribute,
+			group.AWSValue,
+			group.TerraformValue,
+			len(group.InstanceIDs),
+			strings.Join(group.InstanceIDs, ", "))
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,71 @@
+package report_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"driftdetector/internal/models"
+	"driftdetector/internal/report"
+)
+
+func TestGroupDrifts(t *testing.T) {
+	typeDrift := func(aws, tf string) models.DriftDetail {
+		return models.DriftDetail{Attribute: "instance_type", AWSValue: aws, TerraformValue: tf}
+	}
+	reports := []report.DriftReport{
+		{InstanceID: "i-3", Drifts: []models.DriftDetail{typeDrift("t2.micro", "t3.micro")}},
+		{InstanceID: "i-1", Drifts: []models.DriftDetail{typeDrift("t2.micro", "t3.micro"), {Attribute: "ami", AWSValue: "ami-1"}}},
+		{InstanceID: "i-2", Drifts: []models.DriftDetail{typeDrift("t2.large", "t3.micro")}},
+		{InstanceID: "i-4", Drifts: []models.DriftDetail{{Attribute: "ami", AWSValue: "ami-1"}}},
+	}
+
+	groups := report.GroupDrifts(reports, "instance_type")
+
+	// The largest group comes first, and drifts of other attributes are left out
+	require.Len(t, groups, 2)
+	assert.Equal(t, report.DriftGroup{
+		Attribute:      "instance_type",
+		AWSValue:       "t2.micro",
+		TerraformValue: "t3.micro",
+		InstanceIDs:    []string{"i-1", "i-3"},
+	}, groups[0])
+	assert.Equal(t, []string{"i-2"}, groups[1].InstanceIDs)
+}
+
+func TestGroupDrifts_KeyedAttribute(t *testing.T) {
+	reports := []report.DriftReport{
+		{InstanceID: "i-1", Drifts: []models.DriftDetail{{Attribute: "tags.Env", AWSValue: "dev", TerraformValue: "prod"}}},
+		{InstanceID: "i-2", Drifts: []models.DriftDetail{
+			{Attribute: "tags.Env", AWSValue: "dev", TerraformValue: "prod"},
+			{Attribute: "tags.Owner", AWSValue: "ops"},
+		}},
+		{InstanceID: "i-3", Drifts: []models.DriftDetail{{Attribute: "tagsets", AWSValue: "x"}}},
+	}
+
+	groups := report.GroupDrifts(reports, "tags")
+
+	require.Len(t, groups, 2, "Keys are grouped on their own, and only the attribute's keys match")
+	assert.Equal(t, "tags.Env", groups[0].Attribute)
+	assert.Equal(t, []string{"i-1", "i-2"}, groups[0].InstanceIDs)
+	assert.Equal(t, "tags.Owner", groups[1].Attribute)
+	assert.Equal(t, "<nil>", groups[1].TerraformValue)
+}
+
+func TestWriteDriftGroups(t *testing.T) {
+	var out bytes.Buffer
+	groups := []report.DriftGroup{
+		{Attribute: "instance_type", AWSValue: "t2.micro", TerraformValue: "t3.micro", InstanceIDs: []string{"i-1", "i-3"}},
+	}
+
+	require.NoError(t, report.WriteDriftGroups(&out, "instance_type", groups))
+
+	assert.Contains(t, out.String(), "DRIFT OF instance_type BY VALUE:")
+	assert.Regexp(t, `instance_type\s+t2\.micro\s+t3\.micro\s+2: i-1, i-3`, out.String())
+
+	out.Reset()
+	require.NoError(t, report.WriteDriftGroups(&out, "ami", nil))
+	assert.Contains(t, out.String(), "No instance has drift in ami")
+}
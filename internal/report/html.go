@@ -0,0 +1,65 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+)
+
+// htmlReportTemplate renders a standalone page with inline CSS, so the report can be shared as a single file.
+// html/template escapes every value, so tag values from AWS cannot inject markup into the page.
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"value":  formatValueForTable,
+	"source": formatSource,
+	"status": formatStatus,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Drift Report</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #24292f; }
+h1 { font-size: 1.6em; }
+h2 { font-size: 1.2em; margin-top: 2em; }
+.summary { padding: 1em; border-radius: 6px; background: #f6f8fa; }
+.summary .drifted { color: #cf222e; font-weight: bold; }
+.summary .clean { color: #1a7f37; font-weight: bold; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #d0d7de; padding: 6px 12px; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+tr.drift td { background: #ffebe9; }
+td.status { color: #cf222e; font-weight: bold; }
+p.no-drift { color: #1a7f37; }
+p.error { color: #9a6700; }
+</style>
+</head>
+<body>
+<h1>Drift Report</h1>
+<div class="summary">
+<p>{{.Instances}} instances checked: <span class="{{if .Drifted}}drifted{{else}}clean{{end}}">{{.Drifted}} with drift</span>, {{.Attributes}} drifted attributes in total.{{if .Errored}} {{.Errored}} instances could not be checked.{{end}}</p>
+</div>
+{{range .Reports}}
+<h2>{{.InstanceID}}</h2>
+{{if .Error}}
+<p class="error">Error: {{.Error}}</p>
+{{else if .Drifts}}
+<table>
+<tr><th>Attribute</th><th>AWS Value</th><th>Terraform Value</th><th>Source</th><th>Status</th></tr>
+{{range .Drifts}}<tr class="drift"><td>{{.Attribute}}</td><td>{{value .AWSValue}}</td><td>{{value .TerraformValue}}</td><td>{{source .Source}}</td><td class="status">{{status .}}</td></tr>
+{{end}}</table>
+{{else}}
+<p class="no-drift">No drift detected.</p>
+{{end}}
+{{end}}
+</body>
+</html>
+`))
+
+// renderHTMLReport renders the reports of a run as a standalone HTML page with a summary and a table per instance
+func renderHTMLReport(reports []DriftReport) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := htmlReportTemplate.Execute(&buf, newTemplateData(reports)); err != nil {
+		return nil, fmt.Errorf("error rendering HTML report: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,59 @@
+package report_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"driftdetector/internal/models"
+	"driftdetector/internal/report"
+)
+
+func TestPrinter_HTML(t *testing.T) {
+	printer := report.NewDefaultPrinter()
+
+	// Reports are buffered until Flush
+	buffered := captureOutput(func() {
+		assert.NoError(t, printer.PrintReport("i-1", []models.DriftDetail{
+			{Attribute: "instance_type", AWSValue: "t2.large", TerraformValue: "t2.micro"},
+			{Attribute: "tags.Owner", AWSValue: "<script>alert(1)</script>", Change: models.ChangeAdded},
+		}, report.OutputFormatTypeHTML))
+		assert.NoError(t, printer.PrintReport("i-2", nil, report.OutputFormatTypeHTML))
+	})
+	assert.Empty(t, buffered, "HTML reports should not be written before Flush")
+
+	output := captureOutput(func() {
+		assert.NoError(t, printer.Flush(report.OutputFormatTypeHTML))
+	})
+
+	assert.True(t, strings.HasPrefix(output, "<!DOCTYPE html>"), "Output should be a standalone HTML page")
+	assert.Contains(t, output, "<style>", "CSS should be inlined")
+	assert.Contains(t, output, "2 instances checked", "Summary should count all instances")
+	assert.Contains(t, output, "1 with drift", "Summary should count drifted instances")
+	assert.Contains(t, output, "<h2>i-1</h2>")
+	assert.Contains(t, output, `<tr class="drift"><td>instance_type</td><td>t2.large</td><td>t2.micro</td>`)
+	assert.Contains(t, output, "No drift detected.", "Instances without drift should be listed")
+	assert.NotContains(t, output, "<script>", "Values must be escaped")
+	assert.Contains(t, output, "&lt;script&gt;")
+}
+
+func TestPrinter_HTMLOutputFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.html")
+	printer := report.NewPrinter(report.PrinterOptions{OutputFile: path})
+
+	output := captureOutput(func() {
+		assert.NoError(t, printer.PrintReport("i-1", []models.DriftDetail{
+			{Attribute: "instance_type", AWSValue: "t2.large", TerraformValue: "t2.micro"},
+		}, report.OutputFormatTypeHTML))
+		assert.NoError(t, printer.Flush(report.OutputFormatTypeHTML))
+	})
+	assert.Empty(t, output, "Nothing should be written to stdout when an output file is set")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "<h2>i-1</h2>")
+}
@@ -8,3 +8,34 @@ import "driftdetector/internal/models"
 type IPrinter interface {
 	PrintReport(instanceID string, drifts []models.DriftDetail, format OutputFormatType) error
 }
+
+// IFlusher is implemented by printers that buffer reports of document formats such as SARIF and HTML,
+// which are a single document per run. Flush writes the buffered reports in the given format and must be
+// called once all instances have been reported.
+type IFlusher interface {
+	Flush(format OutputFormatType) error
+}
+
+// IErrorReporter is implemented by printers that include instances which could not be checked in their reports.
+type IErrorReporter interface {
+	ReportError(instanceID string, err error, format OutputFormatType) error
+}
+
+// IMatchPrinter is implemented by printers that can also list the attributes compared without drift, as evidence
+// of what was verified. Formats that only report drift ignore the matches.
+type IMatchPrinter interface {
+	PrintReportWithMatches(instanceID string, drifts, matches []models.DriftDetail, format OutputFormatType) error
+}
+
+// IProvenanceRecorder is implemented by printers that record the AWS account and region each instance was read
+// from in its report. SetProvenance must be called before the instance is reported.
+type IProvenanceRecorder interface {
+	SetProvenance(instanceID string, provenance Provenance)
+}
+
+// IUnmanagedRecorder is implemented by printers that list the attributes an instance has on AWS while the
+// configuration leaves them unset, e.g. a subnet chosen by AWS. SetUnmanaged must be called before the instance
+// is reported.
+type IUnmanagedRecorder interface {
+	SetUnmanaged(instanceID string, unmanaged []models.DriftDetail)
+}
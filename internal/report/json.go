@@ -0,0 +1,79 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// JSONSchemaVersion is the version of the JSON output. It is bumped whenever the shape of JSONEnvelope or
+// DriftReport changes: the minor version for added fields, the major version for changed or removed ones.
+const JSONSchemaVersion = "1.3"
+
+// JSONEnvelope is the top-level object of the JSON output, holding the reports of a run.
+// It gives consumers a stable contract to check the schema version against before reading the reports.
+type JSONEnvelope struct {
+	SchemaVersion string        `json:"schema_version"`
+	GeneratedAt   time.Time     `json:"generated_at"`
+	Reports       []DriftReport `json:"reports"`
+}
+
+// defaultJSONIndent is the indentation of the JSON document unless configured otherwise
+const defaultJSONIndent = 2
+
+// jsonIndent returns the indentation of the JSON document, or an empty string for a compact document
+func (o PrinterOptions) jsonIndent() string {
+	if o.JSONCompact {
+		return ""
+	}
+	if o.JSONIndent <= 0 {
+		return strings.Repeat(" ", defaultJSONIndent)
+	}
+	return strings.Repeat(" ", o.JSONIndent)
+}
+
+// renderJSONReport renders the reports of a run as a JSON envelope, indented with indent, or on a single
+// line if indent is empty
+func renderJSONReport(reports []DriftReport, indent string) ([]byte, error) {
+	envelope := JSONEnvelope{
+		SchemaVersion: JSONSchemaVersion,
+		GeneratedAt:   time.Now().UTC(),
+		Reports:       reports,
+	}
+	// Consumers can always iterate the reports, even for a clean run
+	if envelope.Reports == nil {
+		envelope.Reports = []DriftReport{}
+	}
+
+	var data []byte
+	var err error
+	if indent == "" {
+		data, err = json.Marshal(envelope)
+	} else {
+		data, err = json.MarshalIndent(envelope, "", indent)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling report to JSON: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// JSONLine is a line of the JSON-lines output, holding the report of a single instance.
+// Each line carries the schema version, as a stream has no envelope to hold it.
+type JSONLine struct {
+	SchemaVersion string `json:"schema_version"`
+	DriftReport
+}
+
+// writeJSONLine writes the report of an instance as a single line of JSON.
+// Callers must hold the write coordinator, so that lines of concurrent instances never interleave.
+func writeJSONLine(w io.Writer, report DriftReport) error {
+	data, err := json.Marshal(JSONLine{SchemaVersion: JSONSchemaVersion, DriftReport: report})
+	if err != nil {
+		return fmt.Errorf("error marshaling report to JSON: %w", err)
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
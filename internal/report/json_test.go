@@ -0,0 +1,196 @@
+package report_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"driftdetector/internal/models"
+	"driftdetector/internal/report"
+)
+
+func TestJSONEnvelope(t *testing.T) {
+	drifts := []models.DriftDetail{{Attribute: "instance_type", AWSValue: "t2.micro", TerraformValue: "t2.small"}}
+	printer := report.NewPrinter(report.PrinterOptions{})
+
+	output := captureOutput(func() {
+		assert.NoError(t, printer.PrintReport("i-1", drifts, report.OutputFormatTypeJSON))
+		assert.NoError(t, printer.PrintReport("i-2", nil, report.OutputFormatTypeJSON))
+		assert.NoError(t, printer.Flush(report.OutputFormatTypeJSON))
+	})
+
+	// The whole run is a single envelope
+	var envelope map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal([]byte(output), &envelope))
+	assert.Contains(t, envelope, "schema_version")
+	assert.Contains(t, envelope, "generated_at")
+	assert.Contains(t, envelope, "reports")
+
+	var decoded report.JSONEnvelope
+	require.NoError(t, json.Unmarshal([]byte(output), &decoded))
+	assert.Equal(t, report.JSONSchemaVersion, decoded.SchemaVersion)
+	assert.WithinDuration(t, time.Now(), decoded.GeneratedAt, time.Minute)
+	require.Len(t, decoded.Reports, 2)
+	assert.Equal(t, "i-1", decoded.Reports[0].InstanceID)
+	assert.Equal(t, "instance_type", decoded.Reports[0].Drifts[0].Attribute)
+
+	// An instance without drift has an empty array rather than null
+	var reports struct {
+		Reports []map[string]json.RawMessage `json:"reports"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(output), &reports))
+	assert.JSONEq(t, "[]", string(reports.Reports[1]["drifts"]))
+}
+
+func TestJSONEnvelope_Provenance(t *testing.T) {
+	printer := report.NewPrinter(report.PrinterOptions{})
+	printer.SetProvenance("i-1", report.Provenance{AccountID: "123456789012", Region: "eu-west-1"})
+	printer.SetProvenance("eu-west-2/i-2", report.Provenance{Region: "eu-west-2"})
+
+	output := captureOutput(func() {
+		assert.NoError(t, printer.PrintReport("i-1", nil, report.OutputFormatTypeJSON))
+		assert.NoError(t, printer.ReportError("eu-west-2/i-2", errors.New("boom"), report.OutputFormatTypeJSON))
+		assert.NoError(t, printer.PrintReport("i-3", nil, report.OutputFormatTypeJSON))
+		assert.NoError(t, printer.Flush(report.OutputFormatTypeJSON))
+	})
+
+	var decoded report.JSONEnvelope
+	require.NoError(t, json.Unmarshal([]byte(output), &decoded))
+	require.Len(t, decoded.Reports, 3)
+	assert.Equal(t, "123456789012", decoded.Reports[0].AccountID)
+	assert.Equal(t, "eu-west-1", decoded.Reports[0].Region)
+	assert.Empty(t, decoded.Reports[1].AccountID)
+	assert.Equal(t, "eu-west-2", decoded.Reports[1].Region)
+
+	// Reports of instances without provenance leave the fields out
+	assert.Equal(t, 2, strings.Count(output, `"region"`))
+}
+
+func TestJSONEnvelope_NoReports(t *testing.T) {
+	output := captureOutput(func() {
+		assert.NoError(t, report.NewDefaultPrinter().Flush(report.OutputFormatTypeJSON))
+	})
+
+	// A clean run still produces an envelope, with an empty list of reports
+	assert.Contains(t, output, "\"schema_version\": \"1.3\"")
+	assert.Contains(t, output, "\"reports\": []")
+}
+
+func TestJSONEnvelope_Compact(t *testing.T) {
+	var sink bytes.Buffer
+	printer := report.NewPrinter(report.PrinterOptions{
+		JSONCompact: true,
+		Sinks:       []report.Sink{{Format: report.OutputFormatTypeJSON, Writer: &sink}},
+	})
+
+	output := captureOutput(func() {
+		assert.NoError(t, printer.PrintReport("i-1", nil, report.OutputFormatTypeJSON))
+		assert.NoError(t, printer.Flush(report.OutputFormatTypeJSON))
+	})
+
+	// The envelope is a single line, on stdout and in sinks alike
+	for _, document := range []string{output, sink.String()} {
+		assert.Equal(t, 1, strings.Count(document, "\n"))
+		assert.True(t, strings.HasSuffix(document, "\n"))
+		assert.Contains(t, document, `"schema_version":"1.3"`)
+		var decoded report.JSONEnvelope
+		require.NoError(t, json.Unmarshal([]byte(document), &decoded))
+		require.Len(t, decoded.Reports, 1)
+	}
+}
+
+func TestJSONEnvelope_Indent(t *testing.T) {
+	printer := report.NewPrinter(report.PrinterOptions{JSONIndent: 4})
+
+	output := captureOutput(func() {
+		assert.NoError(t, printer.Flush(report.OutputFormatTypeJSON))
+	})
+
+	assert.Contains(t, output, "\n    \"schema_version\": \"1.3\"")
+}
+
+func TestPrintReport_JSONEnvelope(t *testing.T) {
+	output := captureOutput(func() {
+		assert.NoError(t, report.PrintReport(&sync.Mutex{}, "i-1", nil, report.OutputFormatTypeJSON))
+	})
+
+	// A single report is written as an envelope holding just that report
+	var decoded report.JSONEnvelope
+	require.NoError(t, json.Unmarshal([]byte(output), &decoded))
+	require.Len(t, decoded.Reports, 1)
+	assert.Equal(t, "i-1", decoded.Reports[0].InstanceID)
+}
+
+func TestJSONLines(t *testing.T) {
+	drifts := []models.DriftDetail{{Attribute: "instance_type", AWSValue: "t2.micro", TerraformValue: "t2.small"}}
+	printer := report.NewPrinter(report.PrinterOptions{})
+
+	// Reports are written as they come, without waiting for Flush
+	output := captureOutput(func() {
+		assert.NoError(t, printer.PrintReport("i-1", drifts, report.OutputFormatTypeJSONL))
+		assert.NoError(t, printer.PrintReport("i-2", nil, report.OutputFormatTypeJSONL))
+	})
+
+	lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	require.Len(t, lines, 2)
+	var line report.JSONLine
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &line))
+	assert.Equal(t, report.JSONSchemaVersion, line.SchemaVersion)
+	assert.Equal(t, "i-1", line.InstanceID)
+	assert.Equal(t, "instance_type", line.Drifts[0].Attribute)
+
+	// Nothing is left to write on Flush
+	output = captureOutput(func() {
+		assert.NoError(t, printer.Flush(report.OutputFormatTypeJSONL))
+	})
+	assert.Empty(t, output)
+}
+
+func TestJSONLines_Concurrent(t *testing.T) {
+	var out bytes.Buffer
+	printer := report.NewPrinter(report.PrinterOptions{Sinks: []report.Sink{
+		{Format: report.OutputFormatTypeJSONL, Writer: &out},
+	}})
+	drifts := []models.DriftDetail{{Attribute: "tags", AWSValue: map[string]string{"Name": "web"}}}
+
+	captureOutput(func() {
+		var wg sync.WaitGroup
+		for i := range 50 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, printer.PrintReport(fmt.Sprintf("i-%08d", i), drifts, report.OutputFormatTypeDIFF))
+			}()
+		}
+		wg.Wait()
+	})
+
+	// Lines of concurrent instances never interleave
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	require.Len(t, lines, 50)
+	for _, l := range lines {
+		var line report.JSONLine
+		assert.NoError(t, json.Unmarshal([]byte(l), &line))
+	}
+}
+
+func TestReportError_JSONLines(t *testing.T) {
+	printer := report.NewPrinter(report.PrinterOptions{})
+
+	output := captureOutput(func() {
+		assert.NoError(t, printer.ReportError("i-123", errors.New("boom"), report.OutputFormatTypeJSONL))
+	})
+
+	var line report.JSONLine
+	require.NoError(t, json.Unmarshal([]byte(output), &line))
+	assert.Equal(t, "i-123", line.InstanceID)
+	assert.Equal(t, "boom", line.Error)
+}
@@ -0,0 +1,77 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// junitSuiteName is the name of the test suite and the class name of its test cases
+const junitSuiteName = "driftdetector"
+
+// junitTestSuite is the top-level JUnit XML document, a single suite with a test case per instance
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+}
+
+// junitProblem is the content of a failure or error element
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Details string `xml:",chardata"`
+}
+
+// buildJUnitTestSuite converts the reports of a run into a JUnit test suite.
+// Instances with drift are failures listing the drifted attributes, errored instances are errors.
+func buildJUnitTestSuite(reports []DriftReport) junitTestSuite {
+	suite := junitTestSuite{Name: junitSuiteName, Tests: len(reports), TestCases: make([]junitTestCase, 0, len(reports))}
+
+	for _, report := range reports {
+		testCase := junitTestCase{Name: report.InstanceID, ClassName: junitSuiteName}
+
+		switch {
+		case report.Error != "":
+			suite.Errors++
+			testCase.Error = &junitProblem{Message: report.Error, Type: "error"}
+		case len(report.Drifts) > 0:
+			suite.Failures++
+			attributes := make([]string, len(report.Drifts))
+			var details strings.Builder
+			for i, d := range report.Drifts {
+				attributes[i] = d.Attribute
+				fmt.Fprintf(&details, "%s: AWS %s, Terraform %s (%s)\n",
+					d.Attribute, formatValueForTable(d.AWSValue), formatValueForTable(d.TerraformValue), formatStatus(d))
+			}
+			testCase.Failure = &junitProblem{
+				Message: fmt.Sprintf("drift detected in: %s", strings.Join(attributes, ", ")),
+				Type:    "drift",
+				Details: details.String(),
+			}
+		}
+
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	return suite
+}
+
+// renderJUnitReport renders the reports of a run as a single JUnit XML document, terminated by a newline
+func renderJUnitReport(reports []DriftReport) ([]byte, error) {
+	data, err := xml.MarshalIndent(buildJUnitTestSuite(reports), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling report to JUnit XML: %w", err)
+	}
+	return append(append([]byte(xml.Header), data...), '\n'), nil
+}
@@ -0,0 +1,79 @@
+package report_test
+
+import (
+	"encoding/xml"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"driftdetector/internal/models"
+	"driftdetector/internal/report"
+)
+
+// junitDocument is the subset of a JUnit XML report checked by the tests
+type junitDocument struct {
+	Name      string `xml:"name,attr"`
+	Tests     int    `xml:"tests,attr"`
+	Failures  int    `xml:"failures,attr"`
+	Errors    int    `xml:"errors,attr"`
+	TestCases []struct {
+		Name    string `xml:"name,attr"`
+		Failure *struct {
+			Message string `xml:"message,attr"`
+			Details string `xml:",chardata"`
+		} `xml:"failure"`
+		Error *struct {
+			Message string `xml:"message,attr"`
+		} `xml:"error"`
+	} `xml:"testcase"`
+}
+
+func TestPrinter_JUnit(t *testing.T) {
+	printer := report.NewDefaultPrinter()
+
+	// Reports are buffered until Flush
+	buffered := captureOutput(func() {
+		assert.NoError(t, printer.PrintReport("i-1", []models.DriftDetail{
+			{Attribute: "instance_type", AWSValue: "t2.large", TerraformValue: "t2.micro"},
+			{Attribute: "tags.Env", AWSValue: "prod", TerraformValue: "staging", Change: models.ChangeChanged},
+		}, report.OutputFormatTypeJUnit))
+		assert.NoError(t, printer.PrintReport("i-2", nil, report.OutputFormatTypeJUnit))
+		assert.NoError(t, printer.ReportError("i-3", errors.New("instance not found"), report.OutputFormatTypeJUnit))
+	})
+	assert.Empty(t, buffered, "JUnit reports should not be written before Flush")
+
+	output := captureOutput(func() {
+		assert.NoError(t, printer.Flush(report.OutputFormatTypeJUnit))
+	})
+
+	var doc junitDocument
+	require.NoError(t, xml.Unmarshal([]byte(output), &doc), "Output should be a single XML document")
+	assert.Equal(t, "driftdetector", doc.Name)
+	assert.Equal(t, 3, doc.Tests)
+	assert.Equal(t, 1, doc.Failures)
+	assert.Equal(t, 1, doc.Errors)
+	require.Len(t, doc.TestCases, 3)
+
+	assert.Equal(t, "i-1", doc.TestCases[0].Name)
+	require.NotNil(t, doc.TestCases[0].Failure, "Drift should be a failure")
+	assert.Equal(t, "drift detected in: instance_type, tags.Env", doc.TestCases[0].Failure.Message)
+	assert.Contains(t, doc.TestCases[0].Failure.Details, "instance_type: AWS t2.large, Terraform t2.micro")
+
+	assert.Nil(t, doc.TestCases[1].Failure, "Instances without drift should pass")
+	assert.Nil(t, doc.TestCases[1].Error)
+
+	require.NotNil(t, doc.TestCases[2].Error, "Errored instances should be errors")
+	assert.Equal(t, "instance not found", doc.TestCases[2].Error.Message)
+}
+
+func TestPrinter_ReportErrorIgnoredForStreamingFormats(t *testing.T) {
+	printer := report.NewDefaultPrinter()
+
+	output := captureOutput(func() {
+		assert.NoError(t, printer.ReportError("i-1", errors.New("boom"), report.OutputFormatTypeTABLE))
+		assert.NoError(t, printer.Flush(report.OutputFormatTypeJUnit))
+	})
+	assert.NotContains(t, output, "i-1", "Errors should only be buffered for document formats")
+}
@@ -2,32 +2,107 @@ package report
 
 import (
 	"driftdetector/internal/models"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"sort"
+	"strings"
 	"sync"
 	"text/tabwriter"
+	"text/template"
 )
 
 // OutputFormatType defines the format types for the drift report.
 type OutputFormatType string
 
 const (
-	// OutputFormatTypeJSON represents JSON output format
+	// OutputFormatTypeJSON represents JSON output format, a JSONEnvelope buffered and written as one document by Flush
 	OutputFormatTypeJSON OutputFormatType = "JSON"
 	// OutputFormatTypeTABLE represents table output format
 	OutputFormatTypeTABLE OutputFormatType = "TABLE"
+	// OutputFormatTypeSARIF represents SARIF output format, buffered and written as one document by Flush
+	OutputFormatTypeSARIF OutputFormatType = "SARIF"
+	// OutputFormatTypeDIFF represents a unified-diff-style output of the drifted attributes
+	OutputFormatTypeDIFF OutputFormatType = "DIFF"
+	// OutputFormatTypeHTML represents a standalone HTML page, buffered and written as one document by Flush
+	OutputFormatTypeHTML OutputFormatType = "HTML"
+	// OutputFormatTypeJUnit represents JUnit XML output with a test case per instance, buffered and written as one document by Flush
+	OutputFormatTypeJUnit OutputFormatType = "JUNIT"
+	// OutputFormatTypeJSONL represents JSON-lines output: a JSONLine per instance, written as soon as the instance is checked
+	OutputFormatTypeJSONL OutputFormatType = "JSONL"
+	// OutputFormatTypeTemplate represents output rendered by a user-supplied text/template, buffered and executed once by Flush
+	OutputFormatTypeTemplate OutputFormatType = "TEMPLATE"
 )
 
+// outputFormats lists the supported output formats
+var outputFormats = []OutputFormatType{
+	OutputFormatTypeTABLE, OutputFormatTypeJSON, OutputFormatTypeSARIF,
+	OutputFormatTypeDIFF, OutputFormatTypeHTML, OutputFormatTypeJUnit, OutputFormatTypeJSONL, OutputFormatTypeTemplate,
+}
+
+// ParseOutputFormat parses an output format name such as "json", case-insensitively
+func ParseOutputFormat(name string) (OutputFormatType, error) {
+	for _, format := range outputFormats {
+		if strings.EqualFold(name, string(format)) {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported output format %q: must be one of table, json, sarif, diff, html, junit, jsonl or template", name)
+}
+
+// IsDocumentFormat reports whether the format renders all reports of a run as a single document.
+// Printers buffer the reports of such formats until Flush.
+func IsDocumentFormat(format OutputFormatType) bool {
+	return format == OutputFormatTypeJSON || format == OutputFormatTypeSARIF || format == OutputFormatTypeHTML ||
+		format == OutputFormatTypeJUnit || format == OutputFormatTypeTemplate
+}
+
 // DriftReport represents a report for a single instance.
 type DriftReport struct {
-	InstanceID string               `json:"instance_id"`
-	Drifts     []models.DriftDetail `json:"drifts"`
+	InstanceID    string               `json:"instance_id"`
+	Drifts        []models.DriftDetail `json:"drifts"`
+	Error         string               `json:"error,omitempty"`          // Set when the instance could not be checked
+	ErrorCategory string               `json:"error_category,omitempty"` // Category of Error, such as permission_denied
+	AccountID     string               `json:"account_id,omitempty"`     // AWS account the instance was read from
+	Region        string               `json:"region,omitempty"`         // AWS region the instance was read from
+	// Matches are the attributes compared without drift, only listed by the table and as context of the diff
+	Matches []models.DriftDetail `json:"-"`
+	// Unmanaged are the attributes the configuration leaves unset while AWS has a value, set by SetUnmanaged
+	Unmanaged []models.DriftDetail `json:"unmanaged,omitempty"`
+}
+
+// Provenance is the AWS account and region an instance was read from.
+// Either is empty when it could not be resolved.
+type Provenance struct {
+	AccountID string
+	Region    string
+}
+
+// categorizedError is implemented by errors that carry a category, such as aws.Error
+type categorizedError interface {
+	error
+	ErrorCategory() string
+}
+
+// errorCategory returns the category of err, or an empty string if it has none
+func errorCategory(err error) string {
+	var categorized categorizedError
+	if errors.As(err, &categorized) {
+		return categorized.ErrorCategory()
+	}
+	return ""
 }
 
 // PrintReport prints the drift report for a given instance using the specified output format.
-// Supported formats: "json" (machine-readable) and "table" (human-friendly).
+// Supported formats: "table" and "diff" (human-friendly); document formats such as "json" are written
+// as a document holding just this report.
 func PrintReport(writeCoordinator *sync.Mutex, instanceID string, drifts []models.DriftDetail, outputFormat OutputFormatType) error {
+	return printReport(writeCoordinator, instanceID, drifts, outputFormat, false)
+}
+
+// printReport prints the drift report to stdout, colorizing the table and diff output when color is true.
+func printReport(writeCoordinator *sync.Mutex, instanceID string, drifts []models.DriftDetail, outputFormat OutputFormatType, color bool) error {
 	// Acquire the mutex lock before writing to stdout.
 	// This is to ensure that multiple goroutines do not write to stdout at the same time, which can affect the output order.
 	// Since we care about the order of the output (especially for Table format), writeCoordinator help to synchronise write operation,
@@ -39,53 +114,139 @@ func PrintReport(writeCoordinator *sync.Mutex, instanceID string, drifts []model
 		InstanceID: instanceID,
 		Drifts:     drifts,
 	}
+	return writeReport(os.Stdout, report, outputFormat, PrinterOptions{Color: color})
+}
 
+// writeReport writes a single report in the given format, as configured by options. Document formats are
+// written as a document holding just this report.
+// Callers must hold the write coordinator.
+func writeReport(w io.Writer, report DriftReport, outputFormat OutputFormatType, options PrinterOptions) error {
 	switch outputFormat {
-	case OutputFormatTypeJSON:
-		return printJSONReport(report)
 	case OutputFormatTypeTABLE:
-		return printTableReport(report)
+		if options.MatchesAsContext {
+			report.Matches = nil
+		}
+		return printTableReport(w, report, options.Color, options.MaxColumnWidth)
+	case OutputFormatTypeDIFF:
+		return printDiffReport(w, report, options.Color, options.DiffContext)
+	case OutputFormatTypeJSONL:
+		return writeJSONLine(w, report)
+	case OutputFormatTypeJSON, OutputFormatTypeSARIF, OutputFormatTypeHTML, OutputFormatTypeJUnit, OutputFormatTypeTemplate:
+		return writeDocument(w, []DriftReport{report}, outputFormat, options)
 	default:
 		return fmt.Errorf("unsupported output format: %s", outputFormat)
 	}
 }
 
-// printJSONReport prints the report in JSON format
-func printJSONReport(report DriftReport) error {
-	data, err := json.MarshalIndent(report, "", "  ")
+// writeDocument renders the reports of a run as a single document of the given format and writes it
+func writeDocument(w io.Writer, reports []DriftReport, format OutputFormatType, options PrinterOptions) error {
+	data, err := renderDocument(reports, format, options)
 	if err != nil {
-		return fmt.Errorf("error marshaling report to JSON: %w", err)
+		return err
 	}
-	fmt.Println(string(data))
-	return nil
+	_, err = w.Write(data)
+	return err
 }
 
-// printTableReport prints the report in a human-friendly table format
-func printTableReport(report DriftReport) error {
+// renderDocument renders the reports of a run as a single document of the given format.
+// options.ConfigPath is the location of SARIF results without a source location, and options.Template
+// renders the template format.
+func renderDocument(reports []DriftReport, format OutputFormatType, options PrinterOptions) ([]byte, error) {
+	switch format {
+	case OutputFormatTypeJSON:
+		return renderJSONReport(reports, options.jsonIndent())
+	case OutputFormatTypeSARIF:
+		return renderSARIFReport(reports, options.ConfigPath)
+	case OutputFormatTypeHTML:
+		return renderHTMLReport(reports)
+	case OutputFormatTypeJUnit:
+		return renderJUnitReport(reports)
+	case OutputFormatTypeTemplate:
+		return renderTemplateReport(reports, options.Template)
+	default:
+		return nil, fmt.Errorf("unsupported document format: %s", format)
+	}
+}
+
+// printTableReport prints the report in a human-friendly table format.
+// Only the last column and the summary line are colorized, so escape codes never affect the column alignment.
+// Values longer than maxColumnWidth are truncated with an ellipsis so long tag maps do not blow out the
+// column widths; the JSON output always holds the full values.
+func printTableReport(w io.Writer, report DriftReport, color bool, maxColumnWidth int) error {
 	// Using tabwriter to produce a nicely aligned table output.
-	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
 
 	// Print header
 	fmt.Fprintf(writer, "\nINSTANCE ID:\t%s\n\n", report.InstanceID)
-	fmt.Fprintln(writer, "ATTRIBUTE\tAWS VALUE\tTERRAFORM VALUE\tSTATUS")
-	fmt.Fprintln(writer, "---------\t---------\t---------------\t------")
+	fmt.Fprintln(writer, "ATTRIBUTE\tAWS VALUE\tTERRAFORM VALUE\tSOURCE\tSTATUS")
+	fmt.Fprintln(writer, "---------\t---------\t---------------\t------\t------")
 
 	// Print each attribute comparison
-	for _, d := range report.Drifts {
-		fmt.Fprintf(writer, "%s\t%v\t%v\t%s\n",
+	for _, row := range tableRows(report) {
+		d := row.DriftDetail
+		awsValue, tfValue := formatValueForTable(d.AWSValue), formatValueForTable(d.TerraformValue)
+		// Unordered lists only show the entries that differ, as the full lists are hard to compare
+		if d.SetChanges != nil {
+			awsValue, tfValue = formatSetEntries("+", d.SetChanges.Added), formatSetEntries("-", d.SetChanges.Removed)
+		}
+		status := colorize(color, ansiRed, formatStatus(d))
+		if row.match {
+			status = colorize(color, ansiGreen, "OK")
+		} else if row.unmanaged {
+			status = colorize(color, ansiYellow, "UNMANAGED")
+		}
+		fmt.Fprintf(writer, "%s\t%v\t%v\t%s\t%s\n",
 			d.Attribute,
-			formatValueForTable(d.AWSValue),
-			formatValueForTable(d.TerraformValue),
-			"DRIFT")
+			truncateValue(awsValue, maxColumnWidth),
+			truncateValue(tfValue, maxColumnWidth),
+			formatSource(d.Source),
+			status)
 	}
 
 	// Print summary
 	fmt.Fprintln(writer, "")
-	fmt.Fprintf(writer, "Summary: %d attributes with drift found\n", len(report.Drifts))
+	summaryColor := ansiGreen
+	if len(report.Drifts) > 0 {
+		summaryColor = ansiRed
+	}
+	summary := fmt.Sprintf("Summary: %d attributes with drift found", len(report.Drifts))
+	if len(report.Unmanaged) > 0 {
+		summary += fmt.Sprintf(", %d unmanaged", len(report.Unmanaged))
+	}
+	fmt.Fprintln(writer, colorize(color, summaryColor, summary))
 
 	return writer.Flush()
 }
 
+// tableRow is a row of the table: a drift, an attribute compared without drift or an unmanaged attribute
+type tableRow struct {
+	models.DriftDetail
+	match     bool
+	unmanaged bool
+}
+
+// tableRows returns the rows of the table. The drifts are listed as given, unless the report also holds
+// matches or unmanaged attributes, in which case all rows are sorted by attribute.
+func tableRows(report DriftReport) []tableRow {
+	rows := make([]tableRow, 0, len(report.Drifts)+len(report.Matches)+len(report.Unmanaged))
+	for _, d := range report.Drifts {
+		rows = append(rows, tableRow{DriftDetail: d})
+	}
+	if len(report.Matches) == 0 && len(report.Unmanaged) == 0 {
+		return rows
+	}
+	for _, m := range report.Matches {
+		rows = append(rows, tableRow{DriftDetail: m, match: true})
+	}
+	for _, u := range report.Unmanaged {
+		rows = append(rows, tableRow{DriftDetail: u, unmanaged: true})
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].Attribute < rows[j].Attribute
+	})
+	return rows
+}
+
 // formatValueForTable formats values for better display in the table
 func formatValueForTable(v any) string {
 	if v == nil {
@@ -100,19 +261,297 @@ func formatValueForTable(v any) string {
 	return fmt.Sprintf("%v", v)
 }
 
+// formatSetEntries formats the entries added to or removed from an unordered list, each with the given prefix
+func formatSetEntries(prefix string, entries []string) string {
+	if len(entries) == 0 {
+		return "<none>"
+	}
+	formatted := make([]string, len(entries))
+	for i, entry := range entries {
+		formatted[i] = prefix + entry
+	}
+	return strings.Join(formatted, " ")
+}
+
+// formatSource formats the location of an attribute in the Terraform configuration
+func formatSource(source *models.SourceLocation) string {
+	if source == nil {
+		return "-"
+	}
+	return source.String()
+}
+
+// formatStatus formats the status column, including the kind of change for per-key drifts
+func formatStatus(d models.DriftDetail) string {
+	if d.Change == "" {
+		return "DRIFT"
+	}
+	return fmt.Sprintf("DRIFT (%s)", d.Change)
+}
+
+// Sink is an additional destination of the reports of a DefaultPrinter, in its own format.
+// Streaming formats are written to the sink per instance, document formats once on Flush.
+type Sink struct {
+	Format OutputFormatType
+	Writer io.Writer
+}
+
 // DefaultPrinter is the default implementation of the report printer
 type DefaultPrinter struct {
 	writeCoordinator *sync.Mutex
+	options          PrinterOptions
+	buffered         *[]DriftReport // Reports waiting for Flush, shared between copies of the printer
+	output           io.Writer      // Where reports are written instead of stdout, if set
+
+	// Account and region of the instances, set by SetProvenance and keyed by instance ID
+	provenance map[string]Provenance
+	// Unmanaged attributes of the instances, set by SetUnmanaged and keyed by instance ID
+	unmanaged map[string][]models.DriftDetail
+}
+
+// PrinterOptions configures a DefaultPrinter
+type PrinterOptions struct {
+	Color bool // Colorize table and diff output
+	// MaxColumnWidth truncates table values on stdout longer than this many characters (0 = no limit)
+	MaxColumnWidth int
+	ConfigPath     string // Terraform configuration path, used as the location of SARIF results
+	OutputFile     string // File that Flush writes the document to instead of stdout
+	JSONCompact    bool   // Write the JSON document on a single line, e.g. for log ingestion
+	JSONIndent     int    // Spaces per indentation level of the JSON document (0 = 2)
+	// Template renders the template format, parsed from the user's file by ParseTemplateFile
+	Template *template.Template
+	// DiffContext is the number of matching attributes shown before and after each drifted one in diff output,
+	// like diff -U, out of the matches passed to PrintReportWithMatches
+	DiffContext int
+	// MatchesAsContext is set when matches are only passed as context of the diff, so the table does not list them
+	MatchesAsContext bool
+	// Sinks receive every report in their own format, in addition to the format passed to PrintReport
+	// which is written to stdout, e.g. a JSON file next to the table on stdout. Sinks are never colorized
+	// nor truncated.
+	Sinks []Sink
 }
 
 // NewDefaultPrinter creates a new DefaultPrinter instance
 func NewDefaultPrinter() DefaultPrinter {
+	return NewPrinter(PrinterOptions{})
+}
+
+// NewPrinterWithColor creates a new DefaultPrinter that colorizes table output when color is true
+func NewPrinterWithColor(color bool) DefaultPrinter {
+	return NewPrinter(PrinterOptions{Color: color})
+}
+
+// NewPrinter creates a new DefaultPrinter with the given options
+func NewPrinter(options PrinterOptions) DefaultPrinter {
 	return DefaultPrinter{
 		writeCoordinator: &sync.Mutex{},
+		options:          options,
+		buffered:         &[]DriftReport{},
+		provenance:       make(map[string]Provenance),
+		unmanaged:        make(map[string][]models.DriftDetail),
 	}
 }
 
+// FormatPrinter is a printer bound to an output format and writer. The format passed to its methods is
+// ignored, so callers do not have to resolve it for every report.
+type FormatPrinter struct {
+	printer DefaultPrinter
+	format  OutputFormatType
+}
+
+// NewFormatPrinter creates a printer writing every report to w in the given format, with the given options.
+// Like a DefaultPrinter, it buffers the reports of document formats until Flush, which writes them to the
+// output file of the options if set, and writes every report to the sinks too.
+func NewFormatPrinter(format OutputFormatType, w io.Writer, options PrinterOptions) IPrinter {
+	printer := NewPrinter(options)
+	printer.output = w
+	return FormatPrinter{printer: printer, format: format}
+}
+
+// Format returns the output format the printer is bound to
+func (p FormatPrinter) Format() OutputFormatType {
+	return p.format
+}
+
+// PrintReport implements the printer interface, in the printer's format
+func (p FormatPrinter) PrintReport(instanceID string, drifts []models.DriftDetail, _ OutputFormatType) error {
+	return p.printer.PrintReport(instanceID, drifts, p.format)
+}
+
+// PrintReportWithMatches implements IMatchPrinter, in the printer's format
+func (p FormatPrinter) PrintReportWithMatches(instanceID string, drifts, matches []models.DriftDetail, _ OutputFormatType) error {
+	return p.printer.PrintReportWithMatches(instanceID, drifts, matches, p.format)
+}
+
+// ReportError implements IErrorReporter, in the printer's format
+func (p FormatPrinter) ReportError(instanceID string, err error, _ OutputFormatType) error {
+	return p.printer.ReportError(instanceID, err, p.format)
+}
+
+// SetProvenance implements IProvenanceRecorder
+func (p FormatPrinter) SetProvenance(instanceID string, provenance Provenance) {
+	p.printer.SetProvenance(instanceID, provenance)
+}
+
+// SetUnmanaged implements IUnmanagedRecorder
+func (p FormatPrinter) SetUnmanaged(instanceID string, unmanaged []models.DriftDetail) {
+	p.printer.SetUnmanaged(instanceID, unmanaged)
+}
+
+// Flush implements IFlusher, in the printer's format
+func (p FormatPrinter) Flush(OutputFormatType) error {
+	return p.printer.Flush(p.format)
+}
+
 // PrintReport implements the printer interface
 func (p DefaultPrinter) PrintReport(instanceID string, drifts []models.DriftDetail, format OutputFormatType) error {
-	return PrintReport(p.writeCoordinator, instanceID, drifts, format)
+	return p.PrintReportWithMatches(instanceID, drifts, nil, format)
+}
+
+// PrintReportWithMatches prints the report like PrintReport, with the table also listing the matching
+// attributes with an OK status
+func (p DefaultPrinter) PrintReportWithMatches(instanceID string, drifts, matches []models.DriftDetail, format OutputFormatType) error {
+	p.writeCoordinator.Lock()
+	defer p.writeCoordinator.Unlock()
+
+	// JSON lists an instance without drift with an empty array, null being left to instances that errored
+	if drifts == nil {
+		drifts = []models.DriftDetail{}
+	}
+
+	report := p.withProvenance(DriftReport{InstanceID: instanceID, Drifts: drifts, Matches: matches})
+	report.Unmanaged = p.unmanaged[instanceID]
+	if p.buffers(format) {
+		*p.buffered = append(*p.buffered, report)
+	}
+
+	var errs []error
+	if !IsDocumentFormat(format) {
+		errs = append(errs, writeReport(p.stdout(), report, format, p.options))
+	}
+	for _, sink := range p.options.Sinks {
+		if !IsDocumentFormat(sink.Format) {
+			errs = append(errs, writeReport(sink.Writer, report, sink.Format, p.sinkOptions()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ReportError records an instance that could not be checked, with the category of err if it has one.
+// Document formats, including JSON, include errored instances on Flush and JSON lines are written right away;
+// the other formats leave them to the run summary.
+func (p DefaultPrinter) ReportError(instanceID string, err error, format OutputFormatType) error {
+	p.writeCoordinator.Lock()
+	defer p.writeCoordinator.Unlock()
+
+	report := p.withProvenance(DriftReport{InstanceID: instanceID, Error: err.Error(), ErrorCategory: errorCategory(err)})
+	if p.buffers(format) {
+		*p.buffered = append(*p.buffered, report)
+	}
+
+	var errs []error
+	if format == OutputFormatTypeJSONL {
+		errs = append(errs, writeJSONLine(p.stdout(), report))
+	}
+	for _, sink := range p.options.Sinks {
+		if sink.Format == OutputFormatTypeJSONL {
+			errs = append(errs, writeJSONLine(sink.Writer, report))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SetProvenance records the account and region of an instance, included in its report
+func (p DefaultPrinter) SetProvenance(instanceID string, provenance Provenance) {
+	p.writeCoordinator.Lock()
+	defer p.writeCoordinator.Unlock()
+	p.provenance[instanceID] = provenance
+}
+
+// SetUnmanaged records the attributes of an instance the configuration leaves unset while AWS has a value,
+// included in its report. An empty list clears those recorded before.
+func (p DefaultPrinter) SetUnmanaged(instanceID string, unmanaged []models.DriftDetail) {
+	p.writeCoordinator.Lock()
+	defer p.writeCoordinator.Unlock()
+	if len(unmanaged) == 0 {
+		delete(p.unmanaged, instanceID)
+		return
+	}
+	p.unmanaged[instanceID] = unmanaged
+}
+
+// withProvenance returns the report with the account and region recorded for its instance.
+// Callers must hold the write coordinator.
+func (p DefaultPrinter) withProvenance(report DriftReport) DriftReport {
+	provenance := p.provenance[report.InstanceID]
+	report.AccountID = provenance.AccountID
+	report.Region = provenance.Region
+	return report
+}
+
+// stdout returns where reports in the printer's own format are written: the printer's output, or stdout
+func (p DefaultPrinter) stdout() io.Writer {
+	if p.output != nil {
+		return p.output
+	}
+	return os.Stdout
+}
+
+// sinkOptions returns the options sinks are written with, which are never colorized nor truncated
+func (p DefaultPrinter) sinkOptions() PrinterOptions {
+	options := p.options
+	options.Color = false
+	options.MaxColumnWidth = 0
+	return options
+}
+
+// buffers returns true if reports are buffered for Flush, i.e. the format or one of the sinks is a document format
+func (p DefaultPrinter) buffers(format OutputFormatType) bool {
+	if IsDocumentFormat(format) {
+		return true
+	}
+	for _, sink := range p.options.Sinks {
+		if IsDocumentFormat(sink.Format) {
+			return true
+		}
+	}
+	return false
+}
+
+// Flush writes the buffered reports as a single document of the given format, if it is a document format,
+// and to each document sink, then clears the buffer.
+// A document without results is written if nothing was buffered, so a clean run still produces a valid report.
+func (p DefaultPrinter) Flush(format OutputFormatType) error {
+	p.writeCoordinator.Lock()
+	defer p.writeCoordinator.Unlock()
+
+	reports := *p.buffered
+	*p.buffered = nil
+
+	var errs []error
+	if IsDocumentFormat(format) {
+		errs = append(errs, p.writeOutputDocument(reports, format))
+	}
+	for _, sink := range p.options.Sinks {
+		if IsDocumentFormat(sink.Format) {
+			errs = append(errs, writeDocument(sink.Writer, reports, sink.Format, p.sinkOptions()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// writeOutputDocument writes the document of the format passed to PrintReport to the output file, or the printer's output
+func (p DefaultPrinter) writeOutputDocument(reports []DriftReport, format OutputFormatType) error {
+	if p.options.OutputFile == "" {
+		return writeDocument(p.stdout(), reports, format, p.options)
+	}
+
+	data, err := renderDocument(reports, format, p.options)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(p.options.OutputFile, data, 0o644); err != nil {
+		return fmt.Errorf("error writing report to %s: %w", p.options.OutputFile, err)
+	}
+	return nil
 }
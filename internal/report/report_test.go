@@ -3,13 +3,18 @@ package report_test
 import (
 	"bytes"
 	"driftdetector/internal/models"
+	"encoding/json"
+	"errors"
 	"io"
 	"os"
+	"strings"
 	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"driftdetector/internal/providers/aws"
 	"driftdetector/internal/report"
 )
 
@@ -70,6 +75,145 @@ func TestPrintReport_Table(t *testing.T) {
 	assert.Contains(t, output, "t2.small", "Table output should contain Terraform value")
 }
 
+func TestPrintReportWithMatches_Table(t *testing.T) {
+	drifts := []models.DriftDetail{{Attribute: "instance_type", AWSValue: "t2.micro", TerraformValue: "t2.small"}}
+	matches := []models.DriftDetail{
+		{Attribute: "ami", AWSValue: "ami-123", TerraformValue: "ami-123"},
+		{Attribute: "subnet_id", AWSValue: "subnet-1", TerraformValue: "subnet-1"},
+	}
+	printer := report.NewPrinter(report.PrinterOptions{})
+
+	output := captureOutput(func() {
+		assert.NoError(t, printer.PrintReportWithMatches("i-123", drifts, matches, report.OutputFormatTypeTABLE))
+	})
+
+	// Every compared attribute is listed, sorted by attribute
+	lines := strings.Split(output, "\n")
+	var rows []string
+	for _, line := range lines {
+		if strings.HasPrefix(line, "ami") || strings.HasPrefix(line, "instance_type") || strings.HasPrefix(line, "subnet_id") {
+			rows = append(rows, line)
+		}
+	}
+	require.Len(t, rows, 3)
+	assert.True(t, strings.HasSuffix(rows[0], "OK"), rows[0])
+	assert.True(t, strings.HasSuffix(rows[1], "DRIFT"), rows[1])
+	assert.True(t, strings.HasSuffix(rows[2], "OK"), rows[2])
+	assert.Contains(t, output, "Summary: 1 attributes with drift found")
+
+	// The JSON output only reports drift
+	output = captureOutput(func() {
+		assert.NoError(t, printer.PrintReportWithMatches("i-123", drifts, matches, report.OutputFormatTypeJSONL))
+	})
+	assert.NotContains(t, output, "ami-123")
+}
+
+func TestPrintReport_Unmanaged(t *testing.T) {
+	drifts := []models.DriftDetail{{Attribute: "instance_type", AWSValue: "t2.micro", TerraformValue: "t2.small"}}
+	printer := report.NewPrinter(report.PrinterOptions{})
+	printer.SetUnmanaged("i-123", []models.DriftDetail{{Attribute: "key_name", AWSValue: "deployer"}})
+
+	output := captureOutput(func() {
+		assert.NoError(t, printer.PrintReport("i-123", drifts, report.OutputFormatTypeTABLE))
+	})
+
+	// The unmanaged attribute is listed with its own status, sorted among the drifts
+	lines := strings.Split(output, "\n")
+	var rows []string
+	for _, line := range lines {
+		if strings.HasPrefix(line, "instance_type") || strings.HasPrefix(line, "key_name") {
+			rows = append(rows, line)
+		}
+	}
+	require.Len(t, rows, 2)
+	assert.True(t, strings.HasSuffix(rows[0], "DRIFT"), rows[0])
+	assert.True(t, strings.HasSuffix(rows[1], "UNMANAGED"), rows[1])
+	assert.Contains(t, output, "Summary: 1 attributes with drift found, 1 unmanaged")
+
+	// JSON lists them apart from the drifts
+	output = captureOutput(func() {
+		assert.NoError(t, printer.PrintReport("i-123", drifts, report.OutputFormatTypeJSONL))
+	})
+	var decoded report.DriftReport
+	require.NoError(t, json.Unmarshal([]byte(output), &decoded))
+	require.Len(t, decoded.Drifts, 1)
+	assert.Equal(t, []models.DriftDetail{{Attribute: "key_name", AWSValue: "deployer"}}, decoded.Unmanaged)
+
+	// Instances without unmanaged attributes leave the field out
+	output = captureOutput(func() {
+		assert.NoError(t, printer.PrintReport("i-456", drifts, report.OutputFormatTypeJSONL))
+	})
+	assert.NotContains(t, output, `"unmanaged"`)
+}
+
+func TestPrintReport_TableChangeStatus(t *testing.T) {
+	drifts := []models.DriftDetail{
+		{
+			Attribute: "tags.Owner",
+			AWSValue:  "ops",
+			Change:    models.ChangeAdded,
+		},
+	}
+
+	output := captureOutput(func() {
+		err := report.PrintReport(&sync.Mutex{}, "i-1234567890abcdef0", drifts, report.OutputFormatTypeTABLE)
+		assert.NoError(t, err, "unexpected error")
+	})
+
+	assert.Contains(t, output, "tags.Owner", "Table output should contain the per-key attribute")
+	assert.Contains(t, output, "DRIFT (added)", "Table output should contain the change type")
+}
+
+func TestPrintReport_SetChanges(t *testing.T) {
+	drifts := []models.DriftDetail{
+		{
+			Attribute:      "security_groups",
+			AWSValue:       []string{"sg-1", "sg-2", "sg-3", "sg-4"},
+			TerraformValue: []string{"sg-1", "sg-2", "sg-3", "sg-5"},
+			SetChanges:     &models.SetChanges{Added: []string{"sg-4"}, Removed: []string{"sg-5"}},
+		},
+	}
+
+	output := captureOutput(func() {
+		err := report.PrintReport(&sync.Mutex{}, "i-1234567890abcdef0", drifts, report.OutputFormatTypeTABLE)
+		assert.NoError(t, err, "unexpected error")
+	})
+	assert.Contains(t, output, "+sg-4", "Table output should contain the added group")
+	assert.Contains(t, output, "-sg-5", "Table output should contain the removed group")
+	assert.NotContains(t, output, "sg-1", "Table output should leave out the unchanged groups")
+
+	output = captureOutput(func() {
+		err := report.PrintReport(&sync.Mutex{}, "i-1234567890abcdef0", drifts, report.OutputFormatTypeJSON)
+		assert.NoError(t, err, "unexpected error")
+	})
+	assert.Contains(t, output, `"SetChanges": {`)
+	assert.Contains(t, output, `"added": [`)
+	assert.Contains(t, output, `"removed": [`)
+}
+
+func TestPrintReport_TableSource(t *testing.T) {
+	drifts := []models.DriftDetail{
+		{
+			Attribute:      "instance_type",
+			AWSValue:       "t2.micro",
+			TerraformValue: "t2.small",
+			Source:         &models.SourceLocation{Filename: "configs/sample.tf", Line: 3},
+		},
+		{
+			Attribute: "ami",
+			AWSValue:  "ami-123",
+		},
+	}
+
+	output := captureOutput(func() {
+		err := report.PrintReport(&sync.Mutex{}, "i-1234567890abcdef0", drifts, report.OutputFormatTypeTABLE)
+		assert.NoError(t, err, "unexpected error")
+	})
+
+	assert.Contains(t, output, "SOURCE", "Table output should contain the source column")
+	assert.Contains(t, output, "configs/sample.tf:3", "Table output should contain the attribute location")
+}
+
 func TestPrintReport_InvalidFormat(t *testing.T) {
 	instanceID := "i-1234567890abcdef0"
 	drifts := []models.DriftDetail{
@@ -84,6 +228,77 @@ func TestPrintReport_InvalidFormat(t *testing.T) {
 	assert.Error(t, err, "expected error for invalid output format")
 }
 
+func TestPrintReport_Sinks(t *testing.T) {
+	drifts := []models.DriftDetail{{Attribute: "instance_type", AWSValue: "t2.micro", TerraformValue: "t2.small"}}
+
+	var jsonOut, sarifOut bytes.Buffer
+	printer := report.NewPrinter(report.PrinterOptions{Sinks: []report.Sink{
+		{Format: report.OutputFormatTypeJSON, Writer: &jsonOut},
+		{Format: report.OutputFormatTypeSARIF, Writer: &sarifOut},
+	}})
+
+	output := captureOutput(func() {
+		assert.NoError(t, printer.PrintReport("i-123", drifts, report.OutputFormatTypeTABLE))
+		assert.NoError(t, printer.Flush(report.OutputFormatTypeTABLE))
+	})
+
+	// The table goes to stdout, each sink gets its own format
+	assert.Contains(t, output, "INSTANCE ID:")
+	assert.NotContains(t, output, "\"instance_id\"")
+	assert.Contains(t, jsonOut.String(), "\"instance_id\": \"i-123\"")
+	assert.Contains(t, sarifOut.String(), "\"version\": \"2.1.0\"")
+	assert.Contains(t, sarifOut.String(), "i-123")
+}
+
+func TestFormatPrinter(t *testing.T) {
+	drifts := []models.DriftDetail{{Attribute: "instance_type", AWSValue: "t2.micro", TerraformValue: "t2.small"}}
+
+	// The format passed per report is ignored in favor of the printer's
+	var tableOut bytes.Buffer
+	printer := report.NewFormatPrinter(report.OutputFormatTypeTABLE, &tableOut, report.PrinterOptions{})
+	assert.NoError(t, printer.PrintReport("i-123", drifts, report.OutputFormatTypeJSON))
+	assert.Contains(t, tableOut.String(), "INSTANCE ID:")
+
+	// Document formats are written on Flush, with the options of the printer, and every report to the sinks
+	var jsonOut, sinkOut bytes.Buffer
+	printer = report.NewFormatPrinter(report.OutputFormatTypeJSON, &jsonOut, report.PrinterOptions{
+		JSONCompact: true,
+		Sinks:       []report.Sink{{Format: report.OutputFormatTypeJSONL, Writer: &sinkOut}},
+	})
+	assert.NoError(t, printer.PrintReport("i-123", drifts, report.OutputFormatTypeTABLE))
+	assert.Empty(t, jsonOut.String())
+
+	flusher, ok := printer.(report.IFlusher)
+	require.True(t, ok)
+	assert.NoError(t, flusher.Flush(report.OutputFormatTypeTABLE))
+	assert.Contains(t, jsonOut.String(), `"instance_id":"i-123"`)
+	assert.Equal(t, 1, strings.Count(jsonOut.String(), "\n"), "The compact JSON document should be a single line")
+	assert.Contains(t, sinkOut.String(), `"instance_id":"i-123"`)
+}
+
+func TestReportError_JSON(t *testing.T) {
+	printer := report.NewPrinter(report.PrinterOptions{})
+	err := aws.NewAWSError(aws.ErrPermissionDenied, aws.EC2ResourceType, "i-123", "Access denied", nil)
+
+	output := captureOutput(func() {
+		assert.NoError(t, printer.ReportError("i-123", err, report.OutputFormatTypeJSON))
+		assert.NoError(t, printer.Flush(report.OutputFormatTypeJSON))
+	})
+
+	assert.Contains(t, output, "\"error\": \"permission_denied: Access denied")
+	assert.Contains(t, output, "\"error_category\": \"permission_denied\"")
+}
+
+func TestReportError_Table(t *testing.T) {
+	printer := report.NewPrinter(report.PrinterOptions{})
+
+	// The table leaves errors to the run summary
+	output := captureOutput(func() {
+		assert.NoError(t, printer.ReportError("i-123", errors.New("boom"), report.OutputFormatTypeTABLE))
+	})
+	assert.Empty(t, output)
+}
+
 func TestFormatValueForTable(t *testing.T) {
 	// We need to call the package-private function, so we'll test indirectly
 	// by comparing the output from PrintReport
@@ -0,0 +1,166 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"driftdetector/internal/driftcheck"
+	"driftdetector/internal/models"
+)
+
+// SARIF constants, see https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+const (
+	sarifVersion  = "2.1.0"
+	sarifSchema   = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifToolName = "driftdetector"
+	sarifLevel    = "warning"
+	// sarifHighLevel is the level of results for attributes of high severity
+	sarifHighLevel = "error"
+)
+
+// sarifLog is the top-level SARIF document
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+type sarifLogicalLocation struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+}
+
+// sarifRuleID returns the rule ID for a drifted attribute.
+// Per-key drifts such as tags.Environment share the rule of their attribute.
+func sarifRuleID(attribute string) string {
+	ruleID, _, _ := strings.Cut(attribute, ".")
+	return ruleID
+}
+
+// sarifLevelFor returns the level of the results of an attribute, from its severity
+func sarifLevelFor(attribute string) string {
+	if driftcheck.AttributeSeverity(attribute) == driftcheck.SeverityHigh {
+		return sarifHighLevel
+	}
+	return sarifLevel
+}
+
+// sarifPhysicalLocationFor returns the location of a drifted attribute, falling back to the top of
+// the configuration file when the attribute is not defined in it (code scanning requires a line).
+func sarifPhysicalLocationFor(d models.DriftDetail, configPath string) sarifPhysicalLocation {
+	if d.Source != nil {
+		return sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: d.Source.Filename},
+			Region:           sarifRegion{StartLine: d.Source.Line},
+		}
+	}
+	return sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{URI: configPath},
+		Region:           sarifRegion{StartLine: 1},
+	}
+}
+
+// buildSARIFLog converts the reports of a run into a single SARIF document.
+// configPath is used as the physical location of results whose attribute has no source location.
+func buildSARIFLog(reports []DriftReport, configPath string) sarifLog {
+	rules := make(map[string]sarifRule)
+	results := make([]sarifResult, 0)
+
+	for _, report := range reports {
+		for _, d := range report.Drifts {
+			ruleID := sarifRuleID(d.Attribute)
+			if _, exists := rules[ruleID]; !exists {
+				rules[ruleID] = sarifRule{
+					ID:               ruleID,
+					ShortDescription: sarifMessage{Text: fmt.Sprintf("AWS EC2 %s differs from the Terraform configuration", ruleID)},
+				}
+			}
+
+			results = append(results, sarifResult{
+				RuleID: ruleID,
+				Level:  sarifLevelFor(d.Attribute),
+				Message: sarifMessage{Text: fmt.Sprintf("Instance %s: %s drifted (AWS: %s, Terraform: %s)",
+					report.InstanceID, d.Attribute, formatValueForTable(d.AWSValue), formatValueForTable(d.TerraformValue))},
+				Locations: []sarifLocation{{
+					PhysicalLocation: sarifPhysicalLocationFor(d, configPath),
+					LogicalLocations: []sarifLogicalLocation{{Name: report.InstanceID, Kind: "resource"}},
+				}},
+			})
+		}
+	}
+
+	// Rules are listed in a stable order
+	ruleList := make([]sarifRule, 0, len(rules))
+	for _, rule := range rules {
+		ruleList = append(ruleList, rule)
+	}
+	sort.Slice(ruleList, func(i, j int) bool { return ruleList[i].ID < ruleList[j].ID })
+
+	return sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool:    sarifTool{Driver: sarifDriver{Name: sarifToolName, Rules: ruleList}},
+			Results: results,
+		}},
+	}
+}
+
+// renderSARIFReport renders the reports of a run as a single SARIF document, terminated by a newline
+func renderSARIFReport(reports []DriftReport, configPath string) ([]byte, error) {
+	data, err := json.MarshalIndent(buildSARIFLog(reports, configPath), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling report to SARIF: %w", err)
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,119 @@
+package report_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"driftdetector/internal/models"
+	"driftdetector/internal/report"
+)
+
+// sarifDocument is the subset of a SARIF log checked by the tests
+type sarifDocument struct {
+	Version string `json:"version"`
+	Runs    []struct {
+		Tool struct {
+			Driver struct {
+				Name  string `json:"name"`
+				Rules []struct {
+					ID string `json:"id"`
+				} `json:"rules"`
+			} `json:"driver"`
+		} `json:"tool"`
+		Results []struct {
+			RuleID    string `json:"ruleId"`
+			Level     string `json:"level"`
+			Locations []struct {
+				PhysicalLocation struct {
+					ArtifactLocation struct {
+						URI string `json:"uri"`
+					} `json:"artifactLocation"`
+					Region struct {
+						StartLine int `json:"startLine"`
+					} `json:"region"`
+				} `json:"physicalLocation"`
+				LogicalLocations []struct {
+					Name string `json:"name"`
+				} `json:"logicalLocations"`
+			} `json:"locations"`
+		} `json:"results"`
+	} `json:"runs"`
+}
+
+func TestPrinter_SARIF(t *testing.T) {
+	printer := report.NewPrinter(report.PrinterOptions{ConfigPath: "configs/sample.tf"})
+
+	// Reports are buffered until Flush
+	buffered := captureOutput(func() {
+		assert.NoError(t, printer.PrintReport("i-1", []models.DriftDetail{
+			{Attribute: "instance_type", AWSValue: "t2.large", TerraformValue: "t2.micro"},
+			{Attribute: "tags.Env", AWSValue: "prod", TerraformValue: "staging", Change: models.ChangeChanged},
+		}, report.OutputFormatTypeSARIF))
+		assert.NoError(t, printer.PrintReport("i-2", []models.DriftDetail{
+			{
+				Attribute: "tags.Owner",
+				AWSValue:  "ops",
+				Change:    models.ChangeAdded,
+				Source:    &models.SourceLocation{Filename: "configs/sample.tf", Line: 8},
+			},
+		}, report.OutputFormatTypeSARIF))
+	})
+	assert.Empty(t, buffered, "SARIF reports should not be written before Flush")
+
+	output := captureOutput(func() {
+		assert.NoError(t, printer.Flush(report.OutputFormatTypeSARIF))
+	})
+
+	var doc sarifDocument
+	assert.NoError(t, json.Unmarshal([]byte(output), &doc), "Output should be a single JSON document")
+	assert.Equal(t, "2.1.0", doc.Version)
+	assert.Len(t, doc.Runs, 1)
+
+	run := doc.Runs[0]
+	assert.Equal(t, "driftdetector", run.Tool.Driver.Name)
+	assert.Len(t, run.Tool.Driver.Rules, 2, "Per-key tag drifts should share the tags rule")
+	assert.Equal(t, "instance_type", run.Tool.Driver.Rules[0].ID)
+	assert.Equal(t, "tags", run.Tool.Driver.Rules[1].ID)
+
+	assert.Len(t, run.Results, 3)
+	assert.Equal(t, "instance_type", run.Results[0].RuleID)
+	location := run.Results[0].Locations[0]
+	assert.Equal(t, "configs/sample.tf", location.PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, 1, location.PhysicalLocation.Region.StartLine, "Unknown source lines fall back to the top of the file")
+	assert.Equal(t, "i-1", location.LogicalLocations[0].Name)
+	assert.Equal(t, "i-2", run.Results[2].Locations[0].LogicalLocations[0].Name)
+	assert.Equal(t, 8, run.Results[2].Locations[0].PhysicalLocation.Region.StartLine, "Known source lines should be used")
+	assert.Equal(t, "warning", run.Results[0].Level)
+}
+
+func TestPrinter_SARIFHighSeverity(t *testing.T) {
+	printer := report.NewPrinter(report.PrinterOptions{ConfigPath: "main.tf"})
+
+	output := captureOutput(func() {
+		assert.NoError(t, printer.PrintReport("i-1", []models.DriftDetail{
+			{Attribute: "root_delete_on_termination", AWSValue: false, TerraformValue: true},
+		}, report.OutputFormatTypeSARIF))
+		assert.NoError(t, printer.Flush(report.OutputFormatTypeSARIF))
+	})
+
+	var doc sarifDocument
+	require.NoError(t, json.Unmarshal([]byte(output), &doc))
+	require.Len(t, doc.Runs[0].Results, 1)
+	assert.Equal(t, "error", doc.Runs[0].Results[0].Level, "High severity drift should be an error")
+}
+
+func TestPrinter_SARIFEmpty(t *testing.T) {
+	printer := report.NewPrinter(report.PrinterOptions{ConfigPath: "main.tf"})
+
+	output := captureOutput(func() {
+		assert.NoError(t, printer.Flush(report.OutputFormatTypeSARIF))
+	})
+
+	var doc sarifDocument
+	assert.NoError(t, json.Unmarshal([]byte(output), &doc), "A clean run should still produce a SARIF document")
+	assert.Len(t, doc.Runs, 1)
+	assert.Empty(t, doc.Runs[0].Results)
+}
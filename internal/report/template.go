@@ -0,0 +1,73 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// templateFuncs are the helpers available to user-supplied templates, next to the text/template built-ins:
+//
+//	join        joins strings with a separator, e.g. {{join ", " .SetChanges.Added}}
+//	formatValue formats an AWS or Terraform value like the table, e.g. <nil> and <empty>
+//	source      formats the location of an attribute in the Terraform configuration, or -
+//	status      formats the status of a drift like the table, e.g. DRIFT (added)
+var templateFuncs = template.FuncMap{
+	"join":        func(sep string, elems []string) string { return strings.Join(elems, sep) },
+	"formatValue": formatValueForTable,
+	"source":      formatSource,
+	"status":      formatStatus,
+}
+
+// TemplateData is the data user-supplied templates are executed with: the reports of a run and their summary
+type TemplateData struct {
+	Reports    []DriftReport // A report per instance, in the order they were checked
+	Instances  int           // Number of instances reported, including those that could not be checked
+	Drifted    int           // Number of instances with drift
+	Errored    int           // Number of instances that could not be checked
+	Attributes int           // Number of drifted attributes across all instances
+}
+
+// newTemplateData summarizes the reports of a run
+func newTemplateData(reports []DriftReport) TemplateData {
+	data := TemplateData{Reports: reports, Instances: len(reports)}
+	for _, report := range reports {
+		if report.Error != "" {
+			data.Errored++
+		}
+		if len(report.Drifts) > 0 {
+			data.Drifted++
+		}
+		data.Attributes += len(report.Drifts)
+	}
+	return data
+}
+
+// ParseTemplateFile parses a text/template file for the template output format, with the helpers of
+// templateFuncs. The template is executed once per run against a TemplateData.
+func ParseTemplateFile(path string) (*template.Template, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading template file: %w", err)
+	}
+	tmpl, err := template.New(filepath.Base(path)).Funcs(templateFuncs).Option("missingkey=error").Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing template file %s: %w", path, err)
+	}
+	return tmpl, nil
+}
+
+// renderTemplateReport renders the reports of a run with a user-supplied template
+func renderTemplateReport(reports []DriftReport, tmpl *template.Template) ([]byte, error) {
+	if tmpl == nil {
+		return nil, fmt.Errorf("the template output format requires a template file")
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, newTemplateData(reports)); err != nil {
+		return nil, fmt.Errorf("error rendering template report: %w", err)
+	}
+	return buf.Bytes(), nil
+}
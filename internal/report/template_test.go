@@ -0,0 +1,74 @@
+package report_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"driftdetector/internal/models"
+	"driftdetector/internal/report"
+)
+
+// writeTemplate writes a template file and returns its path
+func writeTemplate(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "report.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestPrinter_Template(t *testing.T) {
+	tmpl, err := report.ParseTemplateFile(writeTemplate(t, `{{.Drifted}}/{{.Instances}} drifted, {{.Errored}} errored, {{.Attributes}} attributes
+{{range .Reports}}{{$id := .InstanceID}}{{if .Error}}{{$id}}: {{.Error}}
+{{end}}{{range .Drifts}}{{$id}} {{.Attribute}} {{formatValue .AWSValue}} {{formatValue .TerraformValue}} {{status .}}{{with .SetChanges}} {{join "," .Added}}{{end}}
+{{end}}{{end}}`))
+	require.NoError(t, err)
+	printer := report.NewPrinter(report.PrinterOptions{Template: tmpl})
+
+	// Reports are buffered until Flush
+	buffered := captureOutput(func() {
+		assert.NoError(t, printer.PrintReport("i-1", []models.DriftDetail{
+			{Attribute: "instance_type", AWSValue: "t2.large", TerraformValue: "t2.micro"},
+			{Attribute: "tags.Owner", AWSValue: "", Change: models.ChangeAdded},
+			{Attribute: "security_groups", SetChanges: &models.SetChanges{Added: []string{"sg-1", "sg-2"}}},
+		}, report.OutputFormatTypeTemplate))
+		assert.NoError(t, printer.PrintReport("i-2", nil, report.OutputFormatTypeTemplate))
+		assert.NoError(t, printer.ReportError("i-3", errors.New("access denied"), report.OutputFormatTypeTemplate))
+	})
+	assert.Empty(t, buffered, "Template reports should not be written before Flush")
+
+	output := captureOutput(func() {
+		assert.NoError(t, printer.Flush(report.OutputFormatTypeTemplate))
+	})
+	assert.Equal(t, `1/3 drifted, 1 errored, 3 attributes
+i-1 instance_type t2.large t2.micro DRIFT
+i-1 tags.Owner <empty> <nil> DRIFT (added)
+i-1 security_groups <nil> <nil> DRIFT sg-1,sg-2
+i-3: access denied
+`, output)
+}
+
+func TestParseTemplateFile_Errors(t *testing.T) {
+	_, err := report.ParseTemplateFile(filepath.Join(t.TempDir(), "missing.tmpl"))
+	assert.ErrorContains(t, err, "error reading template file")
+
+	_, err = report.ParseTemplateFile(writeTemplate(t, "{{range .Reports}"))
+	assert.ErrorContains(t, err, "error parsing template file")
+
+	// Fields that do not exist fail when the template is executed
+	tmpl, err := report.ParseTemplateFile(writeTemplate(t, "{{.Bogus}}"))
+	require.NoError(t, err)
+	printer := report.NewPrinter(report.PrinterOptions{Template: tmpl})
+	captureOutput(func() {
+		assert.ErrorContains(t, printer.Flush(report.OutputFormatTypeTemplate), "error rendering template report")
+	})
+
+	// The template format cannot be rendered without a template
+	captureOutput(func() {
+		assert.ErrorContains(t, report.NewDefaultPrinter().Flush(report.OutputFormatTypeTemplate), "requires a template file")
+	})
+}
@@ -0,0 +1,61 @@
+package report
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+const (
+	// ColumnWidthAuto sizes the table value columns to fit the terminal
+	ColumnWidthAuto = "auto"
+
+	// tableFixedWidth approximates the width taken by the attribute, source and status columns and the
+	// padding between columns, which the AWS and Terraform value columns share the rest of the terminal with
+	tableFixedWidth = 60
+	// minColumnWidth is the narrowest value column of auto mode, below which values are unreadable anyway
+	minColumnWidth = 12
+	// ellipsis marks a truncated value
+	ellipsis = "…"
+)
+
+// ResolveMaxColumnWidth resolves the --max-col-width option for the given output file: a number of characters,
+// 0 for no limit, or auto to fit the value columns to the width of the terminal. In auto mode values are not
+// truncated when the output is not a terminal, so redirected output always holds the full values.
+func ResolveMaxColumnWidth(option string, out *os.File) (int, error) {
+	if option == "" || strings.EqualFold(option, ColumnWidthAuto) {
+		if out == nil {
+			return 0, nil
+		}
+		width, _, err := term.GetSize(int(out.Fd()))
+		if err != nil {
+			return 0, nil // Not a terminal
+		}
+		return autoColumnWidth(width), nil
+	}
+
+	width, err := strconv.Atoi(option)
+	if err != nil || width < 0 {
+		return 0, fmt.Errorf("unsupported max column width: %s (expected auto or a non-negative number)", option)
+	}
+	return width, nil
+}
+
+// autoColumnWidth returns the width of each value column for a terminal of the given width
+func autoColumnWidth(terminalWidth int) int {
+	return max((terminalWidth-tableFixedWidth)/2, minColumnWidth)
+}
+
+// truncateValue shortens a formatted value to maxWidth characters, ending it with an ellipsis.
+// A maxWidth of 0 leaves the value untouched.
+func truncateValue(value string, maxWidth int) string {
+	if maxWidth <= 0 || utf8.RuneCountInString(value) <= maxWidth {
+		return value
+	}
+	runes := []rune(value)
+	return string(runes[:maxWidth-1]) + ellipsis
+}
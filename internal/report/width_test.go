@@ -0,0 +1,59 @@
+package report_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"driftdetector/internal/models"
+	"driftdetector/internal/report"
+)
+
+func TestResolveMaxColumnWidth(t *testing.T) {
+	// A regular file stands in for redirected output
+	file, err := os.Create(filepath.Join(t.TempDir(), "report.txt"))
+	assert.NoError(t, err)
+	defer file.Close()
+
+	width, err := report.ResolveMaxColumnWidth("auto", file)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, width, "auto should not truncate redirected output")
+
+	width, err = report.ResolveMaxColumnWidth("40", file)
+	assert.NoError(t, err)
+	assert.Equal(t, 40, width)
+
+	width, err = report.ResolveMaxColumnWidth("0", file)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, width)
+
+	_, err = report.ResolveMaxColumnWidth("wide", file)
+	assert.Error(t, err)
+	_, err = report.ResolveMaxColumnWidth("-1", file)
+	assert.Error(t, err)
+}
+
+func TestPrinter_MaxColumnWidth(t *testing.T) {
+	tags := map[string]string{"Name": "web-server-production", "Owner": "platform-team", "CostCenter": "12345"}
+	drifts := []models.DriftDetail{
+		{Attribute: "tags", AWSValue: tags, TerraformValue: "t2.small"},
+	}
+
+	output := captureOutput(func() {
+		err := report.NewPrinter(report.PrinterOptions{MaxColumnWidth: 20}).PrintReport("i-123", drifts, report.OutputFormatTypeTABLE)
+		assert.NoError(t, err, "unexpected error")
+	})
+	assert.Contains(t, output, "map[CostCenter:1234…", "Long values should be truncated with an ellipsis")
+	assert.Contains(t, output, "t2.small", "Short values should be left untouched")
+
+	// JSON output always holds the full values
+	jsonOutput := captureOutput(func() {
+		printer := report.NewPrinter(report.PrinterOptions{MaxColumnWidth: 20})
+		assert.NoError(t, printer.PrintReport("i-123", drifts, report.OutputFormatTypeJSON))
+		assert.NoError(t, printer.Flush(report.OutputFormatTypeJSON))
+	})
+	assert.Contains(t, jsonOutput, "web-server-production")
+	assert.NotContains(t, jsonOutput, "…")
+}
@@ -0,0 +1,112 @@
+package terraform
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"driftdetector/internal/models"
+)
+
+// cacheEntry holds a parsed configuration together with the modification time of the file it was parsed from.
+type cacheEntry struct {
+	modTime   time.Time
+	instance  *models.InstanceDetails
+	instances []*models.InstanceDetails // Set instead of instance for entries of ParseHCLConfigs
+}
+
+// CachingParser wraps an IProvider and memoizes parsed configurations by file path.
+// An entry is reused as long as the file's modification time is unchanged, or for a directory that of its
+// most recently modified file, which makes repeated
+// runs (e.g. watch mode) cheap while still picking up edits to the configuration.
+// The returned InstanceDetails are shared between calls and must not be modified.
+type CachingParser struct {
+	parser     IProvider
+	mu         sync.Mutex
+	entries    map[string]cacheEntry
+	allEntries map[string]cacheEntry
+}
+
+// NewCachingParser creates a new CachingParser around the given parser
+func NewCachingParser(parser IProvider) *CachingParser {
+	return &CachingParser{
+		parser:     parser,
+		entries:    make(map[string]cacheEntry),
+		allEntries: make(map[string]cacheEntry),
+	}
+}
+
+// GetDesiredState implements DesiredStateProvider, returning the cached desired state at key, see ParseHCLConfig.
+func (p *CachingParser) GetDesiredState(ctx context.Context, key string) (*models.InstanceDetails, error) {
+	return getDesiredState(ctx, p, key)
+}
+
+// ParseHCLConfig returns the cached configuration for configPath if the file has not been modified
+// since it was parsed, and parses it with the wrapped parser otherwise. Failed parses are not cached.
+// Remote configurations have no modification time and are fetched on every call.
+func (p *CachingParser) ParseHCLConfig(configPath string) (*models.InstanceDetails, error) {
+	if isRemotePath(configPath) {
+		return p.parser.ParseHCLConfig(configPath)
+	}
+
+	modTime, err := configModTime(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat HCL file %s: %w", configPath, err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if entry, ok := p.entries[configPath]; ok && entry.modTime.Equal(modTime) {
+		return entry.instance, nil
+	}
+
+	instance, err := p.parser.ParseHCLConfig(configPath)
+	if err != nil {
+		delete(p.entries, configPath)
+		return nil, err
+	}
+
+	p.entries[configPath] = cacheEntry{
+		modTime:  modTime,
+		instance: instance,
+	}
+	return instance, nil
+}
+
+// ParseHCLConfigs caches the instances of every resource in the same way as ParseHCLConfig, if the wrapped
+// parser implements IMultiProvider.
+func (p *CachingParser) ParseHCLConfigs(configPath string) ([]*models.InstanceDetails, error) {
+	multiParser, ok := p.parser.(IMultiProvider)
+	if !ok {
+		return nil, fmt.Errorf("reading every instance of %s is not supported by this parser", displayPath(configPath))
+	}
+	if isRemotePath(configPath) {
+		return multiParser.ParseHCLConfigs(configPath)
+	}
+
+	modTime, err := configModTime(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat HCL file %s: %w", configPath, err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if entry, ok := p.allEntries[configPath]; ok && entry.modTime.Equal(modTime) {
+		return entry.instances, nil
+	}
+
+	instances, err := multiParser.ParseHCLConfigs(configPath)
+	if err != nil {
+		delete(p.allEntries, configPath)
+		return nil, err
+	}
+
+	p.allEntries[configPath] = cacheEntry{
+		modTime:   modTime,
+		instances: instances,
+	}
+	return instances, nil
+}
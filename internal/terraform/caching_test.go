@@ -0,0 +1,119 @@
+package terraform
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"driftdetector/internal/models"
+	"driftdetector/internal/terraform/mocks"
+	"driftdetector/pkg/logging"
+)
+
+// writeConfig writes a placeholder config file and sets its modification time
+func writeConfig(t *testing.T, path string, modTime time.Time) {
+	assert.NoError(t, os.WriteFile(path, []byte("# placeholder"), 0o600))
+	assert.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestCachingParser_CacheHit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.tf")
+	writeConfig(t, path, time.Now())
+
+	expected := &models.InstanceDetails{InstanceType: "t2.micro"}
+	parserMock := mocks.NewIProvider(t)
+	parserMock.On("ParseHCLConfig", path).Return(expected, nil).Once()
+
+	parser := NewCachingParser(parserMock)
+
+	first, err := parser.ParseHCLConfig(path)
+	assert.NoError(t, err)
+	second, err := parser.ParseHCLConfig(path)
+	assert.NoError(t, err)
+
+	// The wrapped parser must only be called once for an unchanged file
+	assert.Same(t, expected, first)
+	assert.Same(t, expected, second)
+}
+
+func TestCachingParser_InvalidatedOnModification(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.tf")
+	modTime := time.Now().Add(-time.Hour)
+	writeConfig(t, path, modTime)
+
+	original := &models.InstanceDetails{InstanceType: "t2.micro"}
+	updated := &models.InstanceDetails{InstanceType: "t2.large"}
+	parserMock := mocks.NewIProvider(t)
+	parserMock.On("ParseHCLConfig", path).Return(original, nil).Once()
+	parserMock.On("ParseHCLConfig", path).Return(updated, nil).Once()
+
+	parser := NewCachingParser(parserMock)
+
+	result, err := parser.ParseHCLConfig(path)
+	assert.NoError(t, err)
+	assert.Same(t, original, result)
+
+	// Touch the file so its modification time changes
+	writeConfig(t, path, modTime.Add(time.Minute))
+
+	result, err = parser.ParseHCLConfig(path)
+	assert.NoError(t, err)
+	assert.Same(t, updated, result, "A modified file should be parsed again")
+}
+
+func TestCachingParser_ErrorsNotCached(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.tf")
+	writeConfig(t, path, time.Now())
+
+	expected := &models.InstanceDetails{InstanceType: "t2.micro"}
+	parserMock := mocks.NewIProvider(t)
+	parserMock.On("ParseHCLConfig", path).Return(nil, errors.New("parse error")).Once()
+	parserMock.On("ParseHCLConfig", path).Return(expected, nil).Once()
+
+	parser := NewCachingParser(parserMock)
+
+	_, err := parser.ParseHCLConfig(path)
+	assert.Error(t, err)
+
+	result, err := parser.ParseHCLConfig(path)
+	assert.NoError(t, err)
+	assert.Same(t, expected, result, "A failed parse should be retried")
+}
+
+func TestCachingParser_MissingFile(t *testing.T) {
+	// The wrapped parser is never reached when the file cannot be stat'ed
+	parser := NewCachingParser(mocks.NewIProvider(t))
+
+	result, err := parser.ParseHCLConfig(filepath.Join(t.TempDir(), "missing.tf"))
+	assert.Error(t, err)
+	assert.Nil(t, result)
+}
+
+func TestCachingParser_WithDefaultParser(t *testing.T) {
+	parser := NewCachingParser(NewDefaultParser())
+
+	instance, err := parser.ParseHCLConfig(filepath.Join("testdata", "valid_instance.tf"))
+	assert.NoError(t, err)
+	assert.Equal(t, "t2.micro", instance.InstanceType)
+}
+
+func TestCachingParser_ParseHCLConfigs(t *testing.T) {
+	parser := NewCachingParser(NewParserWithLogger(logging.NewMockLogger()))
+	path := filepath.Join("testdata", "named_instances.tf")
+
+	instances, err := parser.ParseHCLConfigs(path)
+	assert.NoError(t, err)
+	assert.Len(t, instances, 2)
+
+	cached, err := parser.ParseHCLConfigs(path)
+	assert.NoError(t, err)
+	assert.Same(t, instances[0], cached[0])
+
+	// The wrapped parser must be able to read every instance
+	_, err = NewCachingParser(mocks.NewIProvider(t)).ParseHCLConfigs(path)
+	assert.ErrorContains(t, err, "not supported")
+}
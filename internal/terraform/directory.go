@@ -0,0 +1,126 @@
+package terraform
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// configFileExtension is the extension of the files read from a configuration directory
+const configFileExtension = ".tf"
+
+// isConfigDir reports whether configPath is a local directory of configuration files
+func isConfigDir(configPath string) bool {
+	if isRemotePath(configPath) {
+		return false
+	}
+	info, err := os.Stat(configPath)
+	return err == nil && info.IsDir()
+}
+
+// parseConfigPath parses the configuration at configPath: a single file, local or remote, or a local
+// directory, whose .tf files are parsed with up to concurrency files at a time (0 = unlimited).
+// It also returns the path to use in messages.
+func parseConfigPath(configPath string, concurrency int) (*ConfigFile, string, error) {
+	if isConfigDir(configPath) {
+		cfg, err := parseConfigDir(configPath, concurrency)
+		return cfg, configPath, err
+	}
+	return parseConfigFile(configPath)
+}
+
+// parseConfigDir parses the .tf files of a directory concurrently and merges their resource blocks, in the
+// order of the file names and then of the blocks within each file, like Terraform loads a module.
+// Parsing stops at the first error in file name order, and a resource defined in two files is an error, so
+// the outcome does not depend on which file is parsed first.
+func parseConfigDir(dir string, concurrency int) (*ConfigFile, error) {
+	files, err := configDirFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no %s files found in %s", configFileExtension, dir)
+	}
+
+	// Each file is parsed into its own slot, so the results are merged in a deterministic order
+	configs := make([]*ConfigFile, len(files))
+	errs := make([]error, len(files))
+	var g errgroup.Group
+	if concurrency > 0 {
+		g.SetLimit(concurrency)
+	}
+	for i, file := range files {
+		g.Go(func() error {
+			configs[i], _, errs[i] = parseConfigFile(file)
+			return nil
+		})
+	}
+	_ = g.Wait() // Errors are collected per file
+
+	merged := &ConfigFile{}
+	definedIn := make(map[string]string)
+	for i, file := range files {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		for _, res := range configs[i].Resources {
+			address := res.Type + "." + res.Name
+			if previous, exists := definedIn[address]; exists {
+				return nil, fmt.Errorf("duplicate resource %s in %s, already defined in %s", address, file, previous)
+			}
+			definedIn[address] = file
+			merged.Resources = append(merged.Resources, res)
+		}
+	}
+	return merged, nil
+}
+
+// configDirFiles returns the paths of the .tf files of a directory, sorted by name. Subdirectories,
+// which hold other modules, are not read.
+func configDirFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration directory %s: %w", dir, err)
+	}
+	var files []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && filepath.Ext(entry.Name()) == configFileExtension {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// configModTime returns the modification time of a configuration file, or for a directory the latest
+// modification time of the directory and its .tf files, since editing a file does not change the
+// modification time of its directory.
+func configModTime(configPath string) (time.Time, error) {
+	info, err := os.Stat(configPath)
+	if err != nil {
+		return time.Time{}, err
+	}
+	modTime := info.ModTime()
+	if !info.IsDir() {
+		return modTime, nil
+	}
+
+	files, err := configDirFiles(configPath)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, file := range files {
+		fileInfo, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if fileInfo.ModTime().After(modTime) {
+			modTime = fileInfo.ModTime()
+		}
+	}
+	return modTime, nil
+}
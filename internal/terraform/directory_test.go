@@ -0,0 +1,111 @@
+package terraform
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"driftdetector/pkg/logging"
+)
+
+// writeConfigDir writes files of aws_instance resources named after the file to a temporary directory
+func writeConfigDir(t testing.TB, files, resourcesPerFile int) string {
+	dir := t.TempDir()
+	for i := range files {
+		var content strings.Builder
+		for j := range resourcesPerFile {
+			fmt.Fprintf(&content, "resource \"aws_instance\" \"web_%03d_%03d\" {\n  instance_type = \"t2.micro\"\n  ami = \"ami-%03d\"\n  tags = {\n    Name = \"web-%03d-%03d\"\n  }\n}\n\n", i, j, i, i, j)
+		}
+		require.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("instances_%03d.tf", i)), []byte(content.String()), 0o644))
+	}
+	return dir
+}
+
+func TestDefaultParser_Directory(t *testing.T) {
+	dir := writeConfigDir(t, 5, 2)
+	// Files of other types and subdirectories are not read
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("not HCL"), 0o644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "modules"), 0o755))
+
+	for _, concurrency := range []int{0, 1, 3} {
+		t.Run(fmt.Sprintf("concurrency %d", concurrency), func(t *testing.T) {
+			parser := NewParserWithLogger(logging.NewMockLogger()).WithConcurrency(concurrency)
+
+			instances, err := parser.ParseHCLConfigs(dir)
+			require.NoError(t, err)
+			require.Len(t, instances, 10)
+			// Resources are merged in file name order, whatever file finished parsing first
+			for i, instance := range instances {
+				assert.Equal(t, fmt.Sprintf("web-%03d-%03d", i/2, i%2), instance.Tags["Name"])
+			}
+
+			instance, err := parser.ParseHCLConfig(dir)
+			require.NoError(t, err)
+			assert.Equal(t, "ami-000", instance.AMI)
+			assert.Equal(t, filepath.Join(dir, "instances_000.tf"), instance.SourceLocations["ami"].Filename)
+		})
+	}
+}
+
+func TestDefaultParser_DirectoryErrors(t *testing.T) {
+	parser := NewParserWithLogger(logging.NewMockLogger())
+
+	_, err := parser.ParseHCLConfig(t.TempDir())
+	assert.ErrorContains(t, err, "no .tf files found")
+
+	// A resource defined twice is reported against the later file, however the files are scheduled
+	dir := writeConfigDir(t, 3, 1)
+	duplicate := "resource \"aws_instance\" \"web_000_000\" {\n  instance_type = \"t2.large\"\n}\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "z_duplicate.tf"), []byte(duplicate), 0o644))
+	for range 5 {
+		_, err = parser.ParseHCLConfig(dir)
+		assert.EqualError(t, err, fmt.Sprintf("duplicate resource aws_instance.web_000_000 in %s, already defined in %s",
+			filepath.Join(dir, "z_duplicate.tf"), filepath.Join(dir, "instances_000.tf")))
+	}
+
+	// The first invalid file in name order is reported
+	dir = writeConfigDir(t, 2, 1)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a_invalid.tf"), []byte("resource {"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b_invalid.tf"), []byte("resource {"), 0o644))
+	_, err = parser.ParseHCLConfig(dir)
+	assert.ErrorContains(t, err, "a_invalid.tf")
+}
+
+func TestConfigModTime_Directory(t *testing.T) {
+	dir := writeConfigDir(t, 2, 1)
+	file := filepath.Join(dir, "instances_001.tf")
+	later := time.Now().Add(time.Hour).Truncate(time.Second)
+	require.NoError(t, os.Chtimes(file, later, later))
+
+	// Editing a file changes the modification time of the configuration
+	modTime, err := configModTime(dir)
+	require.NoError(t, err)
+	assert.True(t, modTime.Equal(later))
+}
+
+// BenchmarkParseConfigDir compares parsing a directory of many files one at a time with parsing them concurrently
+func BenchmarkParseConfigDir(b *testing.B) {
+	dir := writeConfigDir(b, 64, 50)
+	for _, bm := range []struct {
+		name        string
+		concurrency int
+	}{
+		{"serial", 1},
+		{"concurrent", runtime.NumCPU()},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			for range b.N {
+				if _, err := parseConfigDir(dir, bm.concurrency); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package terraform
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+	"github.com/zclconf/go-cty/cty/gocty"
+)
+
+// metaArgumentsSchema holds the meta-arguments of a resource block that declare several instances of the resource
+var metaArgumentsSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{{Name: "count"}, {Name: "for_each"}},
+}
+
+// metaArgumentFunctions are the functions count and for_each can use and still be evaluated statically, e.g.
+// for_each = toset(["web", "api"])
+var metaArgumentFunctions = map[string]function.Function{
+	"length": stdlib.LengthFunc,
+	"range":  stdlib.RangeFunc,
+	"toset":  stdlib.MakeToFunc(cty.Set(cty.DynamicPseudoType)),
+	"tomap":  stdlib.MakeToFunc(cty.Map(cty.DynamicPseudoType)),
+	"tolist": stdlib.MakeToFunc(cty.List(cty.DynamicPseudoType)),
+}
+
+// resourceInstance is one of the instances declared by a resource block
+type resourceInstance struct {
+	name    string           // Name of the resource, with the index or key of the instance, e.g. web[0] or web["api"]
+	evalCtx *hcl.EvalContext // Defines count.index or each.key and each.value, nil for a block without count or for_each
+}
+
+// expandResource returns the body of a resource block without its meta-arguments and the instances the block
+// declares: one for a plain block, one per index of count or key of for_each. count and for_each must be
+// evaluated statically, as variables and other resources are unknown without a plan; an error is returned
+// if they cannot be.
+func expandResource(res *ResourceBlock) (hcl.Body, []resourceInstance, error) {
+	content, body, diags := res.Body.PartialContent(metaArgumentsSchema)
+	if diags.HasErrors() {
+		return nil, nil, fmt.Errorf("%s", diags.Error())
+	}
+
+	if attr, ok := content.Attributes["count"]; ok {
+		instances, err := expandCount(res.Name, attr)
+		return body, instances, err
+	}
+	if attr, ok := content.Attributes["for_each"]; ok {
+		instances, err := expandForEach(res.Name, attr)
+		return body, instances, err
+	}
+	return body, []resourceInstance{{name: res.Name}}, nil
+}
+
+// expandCount returns an instance per index of a count meta-argument
+func expandCount(name string, attr *hcl.Attribute) ([]resourceInstance, error) {
+	value, err := evaluateMetaArgument(attr)
+	if err != nil {
+		return nil, err
+	}
+	var count int
+	if value.Type() != cty.Number || gocty.FromCtyValue(value, &count) != nil || count < 0 {
+		return nil, fmt.Errorf("count must be a whole number, not %s", value.GoString())
+	}
+
+	instances := make([]resourceInstance, 0, count)
+	for i := range count {
+		instances = append(instances, resourceInstance{
+			name: name + "[" + strconv.Itoa(i) + "]",
+			evalCtx: &hcl.EvalContext{Variables: map[string]cty.Value{
+				"count": cty.ObjectVal(map[string]cty.Value{"index": cty.NumberIntVal(int64(i))}),
+			}},
+		})
+	}
+	return instances, nil
+}
+
+// expandForEach returns an instance per key of a for_each meta-argument, which like in Terraform must be
+// a map or a set of strings. Instances are in key order.
+func expandForEach(name string, attr *hcl.Attribute) ([]resourceInstance, error) {
+	value, err := evaluateMetaArgument(attr)
+	if err != nil {
+		return nil, err
+	}
+	ty := value.Type()
+	isSet := ty.IsSetType()
+	if !isSet && !ty.IsMapType() && !ty.IsObjectType() {
+		return nil, fmt.Errorf("for_each must be a map or a set of strings, not a %s", ty.FriendlyName())
+	}
+
+	var instances []resourceInstance
+	for it := value.ElementIterator(); it.Next(); {
+		key, element := it.Element()
+		if isSet {
+			key = element
+		}
+		if key.Type() != cty.String || key.IsNull() {
+			return nil, fmt.Errorf("for_each must be a map or a set of strings, not a %s", ty.FriendlyName())
+		}
+		instances = append(instances, resourceInstance{
+			name: name + "[" + strconv.Quote(key.AsString()) + "]",
+			evalCtx: &hcl.EvalContext{Variables: map[string]cty.Value{
+				"each": cty.ObjectVal(map[string]cty.Value{"key": key, "value": element}),
+			}},
+		})
+	}
+	return instances, nil
+}
+
+// evaluateMetaArgument evaluates a count or for_each expression without variables, failing if it refers to any
+func evaluateMetaArgument(attr *hcl.Attribute) (cty.Value, error) {
+	value, diags := attr.Expr.Value(&hcl.EvalContext{Functions: metaArgumentFunctions})
+	if diags.HasErrors() {
+		return cty.NilVal, fmt.Errorf("%s cannot be evaluated statically: %s", attr.Name, diags.Error())
+	}
+	if !value.IsWhollyKnown() || value.IsNull() {
+		return cty.NilVal, fmt.Errorf("%s cannot be evaluated statically", attr.Name)
+	}
+	return value, nil
+}
@@ -0,0 +1,90 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"driftdetector/internal/models"
+)
+
+// Export formats for rendering instance details as desired state
+const (
+	ExportFormatHCL  = "hcl"
+	ExportFormatJSON = "json"
+)
+
+// Export renders instances as desired state in the given format, the inverse of parsing: HCL renders an
+// aws_instance resource block per instance, JSON the --desired-json format. Each instance is named after
+// its ID. Only the attributes managed when bootstrapping are exported: instance type, AMI, tags,
+// security groups and subnet.
+func Export(instances []*models.InstanceDetails, format string) ([]byte, error) {
+	switch format {
+	case ExportFormatHCL:
+		return exportHCL(instances), nil
+	case ExportFormatJSON:
+		return exportJSON(instances)
+	default:
+		return nil, fmt.Errorf("unsupported export format %q: must be hcl or json", format)
+	}
+}
+
+// exportHCL renders an aws_instance resource block per instance
+func exportHCL(instances []*models.InstanceDetails) []byte {
+	file := hclwrite.NewEmptyFile()
+	for i, instance := range instances {
+		if i > 0 {
+			file.Body().AppendNewline()
+		}
+		block := file.Body().AppendNewBlock("resource", []string{awsInstanceType, instance.InstanceID})
+		body := block.Body()
+
+		if instance.AMI != "" {
+			body.SetAttributeValue("ami", cty.StringVal(instance.AMI))
+		}
+		body.SetAttributeValue("instance_type", cty.StringVal(instance.InstanceType))
+		if instance.SubnetID != "" {
+			body.SetAttributeValue("subnet_id", cty.StringVal(instance.SubnetID))
+		}
+		if instance.KeyName != "" {
+			body.SetAttributeValue("key_name", cty.StringVal(instance.KeyName))
+		}
+		if len(instance.SecurityGroups) > 0 {
+			groups := make([]cty.Value, len(instance.SecurityGroups))
+			for i, group := range instance.SecurityGroups {
+				groups[i] = cty.StringVal(group)
+			}
+			body.SetAttributeValue("vpc_security_group_ids", cty.ListVal(groups))
+		}
+		if len(instance.Tags) > 0 {
+			tags := make(map[string]cty.Value, len(instance.Tags))
+			for key, value := range instance.Tags {
+				tags[key] = cty.StringVal(value)
+			}
+			body.SetAttributeValue("tags", cty.MapVal(tags))
+		}
+	}
+	return file.Bytes()
+}
+
+// exportJSON renders the instances keyed by ID, in the format read by JSONParser
+func exportJSON(instances []*models.InstanceDetails) ([]byte, error) {
+	exported := make(map[string]models.InstanceDetails, len(instances))
+	for _, instance := range instances {
+		exported[instance.InstanceID] = models.InstanceDetails{
+			InstanceType:   instance.InstanceType,
+			AMI:            instance.AMI,
+			Tags:           instance.Tags,
+			SecurityGroups: instance.SecurityGroups,
+			SubnetID:       instance.SubnetID,
+		}
+	}
+
+	data, err := json.MarshalIndent(exported, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling instances to JSON: %w", err)
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,70 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"driftdetector/internal/models"
+	"driftdetector/pkg/logging"
+)
+
+// exportedInstance is an instance as fetched from AWS, with attributes that are not exported
+func exportedInstance() *models.InstanceDetails {
+	return &models.InstanceDetails{
+		InstanceID:       "i-0123456789abcdef0",
+		InstanceType:     "t3.micro",
+		AMI:              "ami-0c55b159cbfafe1f0",
+		Tags:             map[string]string{"Name": "web", "cost-center": "42"},
+		SecurityGroups:   []string{"sg-1", "sg-2"},
+		SubnetID:         "subnet-1",
+		VPCID:            "vpc-1",
+		AvailabilityZone: "us-east-1a",
+		State:            "running",
+	}
+}
+
+// exportedFields returns the exported attributes of an instance
+func exportedFields(instance *models.InstanceDetails) models.InstanceDetails {
+	return models.InstanceDetails{
+		InstanceType:   instance.InstanceType,
+		AMI:            instance.AMI,
+		Tags:           instance.Tags,
+		SecurityGroups: instance.SecurityGroups,
+		SubnetID:       instance.SubnetID,
+	}
+}
+
+func TestExport_HCL(t *testing.T) {
+	data, err := Export([]*models.InstanceDetails{exportedInstance()}, ExportFormatHCL)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `resource "aws_instance" "i-0123456789abcdef0" {`)
+	assert.NotContains(t, string(data), "vpc-1")
+
+	// The rendered block parses back to the exported attributes
+	path := filepath.Join(t.TempDir(), "main.tf")
+	assert.NoError(t, os.WriteFile(path, data, 0o644))
+	parsed, err := NewParserWithLogger(logging.NewMockLogger()).ParseHCLConfig(path)
+	assert.NoError(t, err)
+	parsed.SourceLocations = nil
+	assert.Equal(t, exportedFields(exportedInstance()), *parsed)
+}
+
+func TestExport_JSON(t *testing.T) {
+	data, err := Export([]*models.InstanceDetails{exportedInstance()}, ExportFormatJSON)
+	assert.NoError(t, err)
+
+	// The rendered file is read back by --desired-json
+	path := filepath.Join(t.TempDir(), "desired.json")
+	assert.NoError(t, os.WriteFile(path, data, 0o644))
+	parsed, err := NewJSONParserWithLogger(logging.NewMockLogger()).ParseHCLConfig(path)
+	assert.NoError(t, err)
+	assert.Equal(t, exportedFields(exportedInstance()), *parsed)
+}
+
+func TestExport_UnsupportedFormat(t *testing.T) {
+	_, err := Export(nil, "yaml")
+	assert.Error(t, err)
+}
@@ -1,6 +1,19 @@
 package terraform
 
-import "driftdetector/internal/models"
+import (
+	"context"
+
+	"driftdetector/internal/models"
+)
+
+// DesiredStateProvider is the interface for the sources of the desired state of instances, such as HCL
+// configurations, launch templates, plans and JSON files. The key locates the desired state in the source,
+// e.g. the path of a configuration.
+//
+//go:generate mockery --name=DesiredStateProvider --output=./mocks
+type DesiredStateProvider interface {
+	GetDesiredState(ctx context.Context, key string) (*models.InstanceDetails, error)
+}
 
 // IProvider is the interface for Terraform operations
 //
@@ -8,3 +21,18 @@ import "driftdetector/internal/models"
 type IProvider interface {
 	ParseHCLConfig(configPath string) (*models.InstanceDetails, error)
 }
+
+// IMultiProvider is implemented by providers that can read every instance defined in a configuration,
+// so that instances can be matched to resources rather than all compared to the first one.
+type IMultiProvider interface {
+	ParseHCLConfigs(configPath string) ([]*models.InstanceDetails, error)
+}
+
+// getDesiredState parses the desired state at key with parser, unless the context is already done, as
+// parsing cannot be interrupted.
+func getDesiredState(ctx context.Context, parser IProvider, key string) (*models.InstanceDetails, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return parser.ParseHCLConfig(key)
+}
@@ -0,0 +1,40 @@
+package terraform
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"driftdetector/pkg/logging"
+)
+
+func TestGetDesiredState(t *testing.T) {
+	logger := logging.NewMockLogger()
+	tests := []struct {
+		name     string
+		provider DesiredStateProvider
+		key      string
+	}{
+		{"HCL", NewParserWithLogger(logger), filepath.Join("testdata", "valid_instance.tf")},
+		{"Launch template", NewLaunchTemplateParserWithLogger(logger, "web"), filepath.Join("testdata", "launch_template.tf")},
+		{"JSON", NewJSONParserWithLogger(logger), filepath.Join("testdata", "desired_instance.json")},
+		{"Plan", NewPlanParserWithLogger(logger), filepath.Join("testdata", "plan.json")},
+		{"Cached", NewCachingParser(NewParserWithLogger(logger)), filepath.Join("testdata", "valid_instance.tf")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance, err := tt.provider.GetDesiredState(context.Background(), tt.key)
+			assert.NoError(t, err)
+			assert.NotEmpty(t, instance.InstanceType)
+
+			// Parsing is not started once the context is done
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			_, err = tt.provider.GetDesiredState(ctx, tt.key)
+			assert.ErrorIs(t, err, context.Canceled)
+		})
+	}
+}
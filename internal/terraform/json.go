@@ -0,0 +1,81 @@
+package terraform
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"driftdetector/internal/models"
+	"driftdetector/pkg/logging"
+)
+
+// JSONParser reads the desired state of an instance from a hand-authored JSON file instead of Terraform.
+// The file holds either a single object with the fields of models.InstanceDetails, or an object mapping
+// names to such objects, in which case the first name in sorted order is used, just like DefaultParser
+// uses the first aws_instance resource it finds.
+type JSONParser struct {
+	logger logging.Logger
+}
+
+// NewJSONParser creates a new instance of JSONParser
+func NewJSONParser() *JSONParser {
+	return NewJSONParserWithLogger(
+		logging.NewDefaultLogger(),
+	)
+}
+
+// NewJSONParserWithLogger creates a new instance of JSONParser with a specific logger
+func NewJSONParserWithLogger(logger logging.Logger) *JSONParser {
+	return &JSONParser{
+		logger: logger,
+	}
+}
+
+// GetDesiredState implements DesiredStateProvider, returning the instance details of the JSON file at key.
+func (p JSONParser) GetDesiredState(ctx context.Context, key string) (*models.InstanceDetails, error) {
+	return getDesiredState(ctx, p, key)
+}
+
+// ParseHCLConfig reads the desired instance details from the JSON file at configPath.
+// The name comes from IProvider; the file is JSON, not HCL. Like HCL files, it may be read from a URL.
+func (p JSONParser) ParseHCLConfig(configPath string) (*models.InstanceDetails, error) {
+	data, err := readConfigFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JSON file %s: %w", displayPath(configPath), err)
+	}
+	configPath = displayPath(configPath)
+
+	// A single instance is decoded strictly, so that anything else is tried as a map of instances
+	var instance models.InstanceDetails
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&instance); err == nil {
+		if reflect.DeepEqual(instance, models.InstanceDetails{}) {
+			return nil, fmt.Errorf("no instance found in %s", configPath)
+		}
+		p.logger.Debug("Successfully parsed instance details: type=%s, ami=%s", instance.InstanceType, instance.AMI)
+		return &instance, nil
+	}
+
+	var instances map[string]models.InstanceDetails
+	if err := json.Unmarshal(data, &instances); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON file %s: %w", configPath, err)
+	}
+	if len(instances) == 0 {
+		return nil, fmt.Errorf("no instance found in %s", configPath)
+	}
+
+	names := make([]string, 0, len(instances))
+	for name := range instances {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	p.logger.Info("Found instance: %s", names[0])
+	instance = instances[names[0]]
+	p.logger.Debug("Successfully parsed instance details: type=%s, ami=%s", instance.InstanceType, instance.AMI)
+	return &instance, nil
+}
@@ -0,0 +1,54 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"driftdetector/pkg/logging"
+)
+
+func TestJSONParser_SingleInstance(t *testing.T) {
+	parser := NewJSONParserWithLogger(logging.NewMockLogger())
+	instance, err := parser.ParseHCLConfig(filepath.Join("testdata", "desired_instance.json"))
+
+	require.NoError(t, err)
+	assert.Equal(t, "t2.micro", instance.InstanceType)
+	assert.Equal(t, "ami-0c55b159cbfafe1f0", instance.AMI)
+	assert.Equal(t, "subnet-12345", instance.SubnetID)
+	assert.Equal(t, []string{"sg-12345", "sg-67890"}, instance.SecurityGroups)
+	assert.Equal(t, map[string]string{"Name": "TestInstance", "Env": "Test"}, instance.Tags)
+}
+
+func TestJSONParser_MapOfInstances(t *testing.T) {
+	parser := NewJSONParserWithLogger(logging.NewMockLogger())
+	instance, err := parser.ParseHCLConfig(filepath.Join("testdata", "desired_instances.json"))
+
+	// The first name in sorted order is used
+	require.NoError(t, err)
+	assert.Equal(t, "t3.small", instance.InstanceType)
+	assert.Equal(t, "ami-api", instance.AMI)
+}
+
+func TestJSONParser_Errors(t *testing.T) {
+	parser := NewJSONParserWithLogger(logging.NewMockLogger())
+
+	_, err := parser.ParseHCLConfig(filepath.Join("testdata", "missing.json"))
+	assert.Error(t, err, "Missing files should fail")
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"invalid.json": `{"instance_type": `,
+		"empty.json":   `{}`,
+		"array.json":   `[{"instance_type": "t2.micro"}]`,
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+		_, err := parser.ParseHCLConfig(path)
+		assert.Error(t, err, "%s should fail", name)
+	}
+}
@@ -0,0 +1,160 @@
+package terraform
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2/gohcl"
+
+	"driftdetector/internal/models"
+	"driftdetector/pkg/logging"
+)
+
+const (
+	awsLaunchTemplateType = "aws_launch_template"
+	// instanceResourceType is the tag_specifications resource type whose tags are applied to instances
+	instanceResourceType = "instance"
+)
+
+// launchTemplateAttributeNames maps aws_launch_template argument and block names to the attribute names
+// used for drift detection where they differ, in addition to hclAttributeNames
+var launchTemplateAttributeNames = map[string]string{
+	"image_id":           "ami",
+	"tag_specifications": "tags",
+	"network_interfaces": "subnet_id",
+}
+
+// LaunchTemplateParser reads the desired state from a named aws_launch_template resource of an HCL
+// configuration file, for instances launched from a template rather than defined as aws_instance.
+type LaunchTemplateParser struct {
+	logger logging.Logger
+	name   string
+}
+
+// NewLaunchTemplateParserWithLogger creates a new LaunchTemplateParser for the aws_launch_template resource
+// with the given name
+func NewLaunchTemplateParserWithLogger(logger logging.Logger, name string) *LaunchTemplateParser {
+	return &LaunchTemplateParser{
+		logger: logger,
+		name:   name,
+	}
+}
+
+// GetDesiredState implements DesiredStateProvider, returning the instance attributes configured by the launch template of the HCL configuration at key.
+func (p LaunchTemplateParser) GetDesiredState(ctx context.Context, key string) (*models.InstanceDetails, error) {
+	return getDesiredState(ctx, p, key)
+}
+
+// ParseHCLConfig parses an HCL configuration file and maps the attributes of the named aws_launch_template
+// to the instance attributes they configure. The instance tags are the tags of the tag_specifications for
+// the instance resource type, and the subnet and security groups may also come from the first network
+// interface. Like for aws_instance, the file may be a local path or a URL.
+func (p LaunchTemplateParser) ParseHCLConfig(configPath string) (*models.InstanceDetails, error) {
+	cfg, configPath, err := parseConfigPath(configPath, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	p.logger.Debug("Searching for %s resource %s in configuration", awsLaunchTemplateType, p.name)
+	for _, res := range cfg.Resources {
+		if res.Type != awsLaunchTemplateType || res.Name != p.name {
+			continue
+		}
+		p.logger.Info("Found aws_launch_template resource: %s", res.Name)
+
+		var template HCLLaunchTemplate
+		if diags := gohcl.DecodeBody(res.Body, nil, &template); diags.HasErrors() {
+			return nil, fmt.Errorf("failed to decode aws_launch_template '%s': %s", res.Name, diags.Error())
+		}
+
+		userData, err := decodeLaunchTemplateUserData(template.UserData)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user data of aws_launch_template '%s': %w", res.Name, err)
+		}
+
+		instanceDetails := &models.InstanceDetails{
+			InstanceType:                      template.InstanceType,
+			AMI:                               template.ImageID,
+			Tags:                              instanceTags(template.TagSpecifications),
+			SecurityGroups:                    template.SecurityGroups,
+			KeyName:                           template.KeyName,
+			MetadataOptions:                   convertMetadataOptions(template.MetadataOptions),
+			DisableApiTermination:             template.DisableApiTermination,
+			UserData:                          userData,
+			InstanceInitiatedShutdownBehavior: template.ShutdownBehavior,
+			InstanceLifecycle:                 convertMarketType(template.InstanceMarketOptions),
+			CapacityReservationID:             convertCapacityReservationID(template.CapacityReservationSpecification),
+			CPUCredits:                        convertCPUCredits(template.CreditSpecification),
+			SourceLocations:                   launchTemplateSourceLocations(res),
+		}
+		if placement := template.Placement; placement != nil {
+			instanceDetails.AvailabilityZone = placement.AvailabilityZone
+			instanceDetails.PlacementGroup = placement.GroupName
+			instanceDetails.Tenancy = placement.Tenancy
+		}
+		if len(template.NetworkInterfaces) > 0 {
+			primary := template.NetworkInterfaces[0]
+			instanceDetails.SubnetID = primary.SubnetID
+			if instanceDetails.SecurityGroups == nil {
+				instanceDetails.SecurityGroups = primary.SecurityGroups
+			}
+		}
+
+		p.logger.Debug("Successfully parsed launch template details: type=%s, ami=%s", template.InstanceType, template.ImageID)
+		return instanceDetails, nil
+	}
+
+	return nil, fmt.Errorf("no '%s' resource named '%s' found in %s", awsLaunchTemplateType, p.name, configPath)
+}
+
+// instanceTags merges the tags of the tag specifications applied to instances, nil if there are none
+func instanceTags(specifications []*HCLTagSpecification) map[string]string {
+	var tags map[string]string
+	for _, specification := range specifications {
+		if specification.ResourceType != instanceResourceType {
+			continue
+		}
+		if tags == nil {
+			tags = make(map[string]string, len(specification.Tags))
+		}
+		for key, value := range specification.Tags {
+			tags[key] = value
+		}
+	}
+	return tags
+}
+
+// decodeLaunchTemplateUserData decodes the base64-encoded user data of a launch template, nil if it is not set
+func decodeLaunchTemplateUserData(encoded *string) (*string, error) {
+	if encoded == nil {
+		return nil, nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(*encoded)
+	if err != nil {
+		return nil, fmt.Errorf("user_data is not valid base64: %w", err)
+	}
+	userData := string(decoded)
+	return &userData, nil
+}
+
+// launchTemplateSourceLocations records where each attribute of a launch template is defined, keyed by
+// the attribute name used for drift detection
+func launchTemplateSourceLocations(res *ResourceBlock) map[string]models.SourceLocation {
+	locations := attributeSourceLocations(res.Body)
+	// The tags of the template itself are not applied to instances
+	delete(locations, "tags")
+	for name, attribute := range launchTemplateAttributeNames {
+		if location, ok := locations[name]; ok {
+			delete(locations, name)
+			locations[attribute] = location
+		}
+	}
+	if location, ok := locations["placement"]; ok {
+		delete(locations, "placement")
+		for _, attribute := range []string{"availability_zone", "placement_group", "tenancy"} {
+			locations[attribute] = location
+		}
+	}
+	return locations
+}
@@ -0,0 +1,79 @@
+package terraform
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"driftdetector/pkg/logging"
+)
+
+func TestLaunchTemplateParser_MapsInstanceAttributes(t *testing.T) {
+	parser := NewLaunchTemplateParserWithLogger(logging.NewMockLogger(), "web")
+	instance, err := parser.ParseHCLConfig(filepath.Join("testdata", "launch_template.tf"))
+
+	require.NoError(t, err)
+	assert.Equal(t, "t3.small", instance.InstanceType)
+	assert.Equal(t, "ami-0c55b159cbfafe1f0", instance.AMI)
+	assert.Equal(t, "us-east-1a", instance.AvailabilityZone)
+	assert.Equal(t, "default", instance.Tenancy)
+	assert.Equal(t, "subnet-12345", instance.SubnetID)
+	assert.Equal(t, []string{"sg-12345"}, instance.SecurityGroups)
+	assert.Equal(t, "required", instance.MetadataOptions.HttpTokens)
+	assert.True(t, *instance.DisableApiTermination)
+	assert.Equal(t, "standard", instance.CPUCredits)
+
+	// User data is base64-encoded in launch templates
+	require.NotNil(t, instance.UserData)
+	assert.Equal(t, "#!/bin/bash\necho hello\n", *instance.UserData)
+
+	// Only the tags applied to instances are desired, not those of the template or its volumes
+	assert.Equal(t, map[string]string{"Name": "web", "Env": "prod"}, instance.Tags)
+}
+
+func TestLaunchTemplateParser_SelectsTemplateByName(t *testing.T) {
+	parser := NewLaunchTemplateParserWithLogger(logging.NewMockLogger(), "worker")
+	instance, err := parser.ParseHCLConfig(filepath.Join("testdata", "launch_template.tf"))
+
+	require.NoError(t, err)
+	assert.Equal(t, "c5.large", instance.InstanceType)
+	assert.Equal(t, []string{"sg-worker"}, instance.SecurityGroups)
+	assert.Nil(t, instance.Tags)
+	assert.Nil(t, instance.UserData)
+}
+
+func TestLaunchTemplateParser_NoInstanceType(t *testing.T) {
+	// Templates used with a mixed instances policy leave the instance type to the Auto Scaling group
+	parser := NewLaunchTemplateParserWithLogger(logging.NewMockLogger(), "mixed")
+	instance, err := parser.ParseHCLConfig(filepath.Join("testdata", "launch_template.tf"))
+
+	require.NoError(t, err)
+	assert.Equal(t, "ami-mixed", instance.AMI)
+	assert.Empty(t, instance.InstanceType)
+	assert.NotContains(t, instance.SourceLocations, "instance_type")
+}
+
+func TestLaunchTemplateParser_SourceLocations(t *testing.T) {
+	parser := NewLaunchTemplateParserWithLogger(logging.NewMockLogger(), "web")
+	instance, err := parser.ParseHCLConfig(filepath.Join("testdata", "launch_template.tf"))
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, instance.SourceLocations["ami"].Line)
+	assert.Equal(t, 4, instance.SourceLocations["instance_type"].Line)
+	assert.Equal(t, 9, instance.SourceLocations["availability_zone"].Line)
+	assert.Equal(t, 14, instance.SourceLocations["subnet_id"].Line)
+	assert.Equal(t, 33, instance.SourceLocations["tags"].Line)
+}
+
+func TestLaunchTemplateParser_Errors(t *testing.T) {
+	parser := NewLaunchTemplateParserWithLogger(logging.NewMockLogger(), "missing")
+	_, err := parser.ParseHCLConfig(filepath.Join("testdata", "launch_template.tf"))
+	assert.ErrorContains(t, err, "no 'aws_launch_template' resource named 'missing'")
+
+	// aws_instance resources are not launch templates
+	parser = NewLaunchTemplateParserWithLogger(logging.NewMockLogger(), "example")
+	_, err = parser.ParseHCLConfig(filepath.Join("testdata", "valid_instance.tf"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,102 @@
+package terraform
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"gopkg.in/yaml.v3"
+)
+
+// AttributeMapping maps logical attribute names (as used by --attributes, e.g. subnet_id) to the name
+// of the HCL attribute that holds them, for modules that do not follow the aws_instance argument names.
+type AttributeMapping map[string]string
+
+// attributeMappingFile is the YAML layout of an attribute mapping file:
+//
+//	attributes:
+//	  subnet_id: subnet
+type attributeMappingFile struct {
+	Attributes AttributeMapping `yaml:"attributes"`
+}
+
+// LoadAttributeMapping reads an attribute mapping from a YAML file and validates it.
+func LoadAttributeMapping(path string) (AttributeMapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attribute mapping %s: %w", path, err)
+	}
+
+	var file attributeMappingFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode attribute mapping %s: %w", path, err)
+	}
+
+	if err := file.Attributes.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid attribute mapping %s: %w", path, err)
+	}
+	return file.Attributes, nil
+}
+
+// Validate checks that every logical attribute is one the parser reads and is mapped to a non-empty name.
+func (m AttributeMapping) Validate() error {
+	for logical, hclName := range m {
+		if _, ok := defaultHCLAttributeName(logical); !ok {
+			return fmt.Errorf("unknown attribute %q", logical)
+		}
+		if hclName == "" {
+			return fmt.Errorf("attribute %q is mapped to an empty HCL attribute name", logical)
+		}
+	}
+	return nil
+}
+
+// defaultHCLAttributeName returns the aws_instance argument name the parser reads a logical attribute from.
+func defaultHCLAttributeName(logical string) (string, bool) {
+	schema, _ := gohcl.ImpliedBodySchema(HCLInstance{})
+	for _, attr := range schema.Attributes {
+		name := attr.Name
+		if mapped, ok := hclAttributeNames[name]; ok {
+			name = mapped
+		}
+		if name == logical {
+			return attr.Name, true
+		}
+	}
+	return "", false
+}
+
+// apply returns a copy of a resource body in which the custom attribute names are renamed to the
+// argument names HCLInstance decodes, so the fixed struct tags work with any module convention.
+// When both the custom and the standard name are set, the custom one wins.
+func (m AttributeMapping) apply(body hcl.Body) hcl.Body {
+	syntaxBody, ok := body.(*hclsyntax.Body)
+	if len(m) == 0 || !ok {
+		return body
+	}
+
+	renames := make(map[string]string, len(m))
+	for logical, hclName := range m {
+		if standard, ok := defaultHCLAttributeName(logical); ok {
+			renames[hclName] = standard
+		}
+	}
+
+	renamed := *syntaxBody
+	renamed.Attributes = make(hclsyntax.Attributes, len(syntaxBody.Attributes))
+	for name, attr := range syntaxBody.Attributes {
+		if _, overridden := renamed.Attributes[name]; overridden {
+			continue // Already set from a custom attribute name
+		}
+		if standard, ok := renames[name]; ok {
+			renamedAttr := *attr
+			renamedAttr.Name = standard
+			renamed.Attributes[standard] = &renamedAttr
+			continue
+		}
+		renamed.Attributes[name] = attr
+	}
+	return &renamed
+}
@@ -0,0 +1,58 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"driftdetector/pkg/logging"
+)
+
+func TestLoadAttributeMapping(t *testing.T) {
+	mapping, err := LoadAttributeMapping(filepath.Join("testdata", "attribute_mapping.yaml"))
+
+	require.NoError(t, err)
+	assert.Equal(t, AttributeMapping{"subnet_id": "subnet", "security_groups": "sg_ids"}, mapping)
+}
+
+func TestLoadAttributeMapping_Invalid(t *testing.T) {
+	_, err := LoadAttributeMapping(filepath.Join("testdata", "missing.yaml"))
+	assert.Error(t, err, "Missing files should fail")
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"invalid.yaml": "attributes: [",
+		"unknown.yaml": "attributes:\n  iam_instance_profile: profile\n",
+		"empty.yaml":   "attributes:\n  subnet_id: \"\"\n",
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+		_, err := LoadAttributeMapping(path)
+		assert.Error(t, err, "%s should fail", name)
+	}
+}
+
+func TestParseHCLConfig_AttributeMapping(t *testing.T) {
+	testFile := filepath.Join("testdata", "custom_names_instance.tf")
+	mapping := AttributeMapping{"subnet_id": "subnet", "security_groups": "sg_ids"}
+
+	parser := NewParserWithAttributeMapping(logging.NewMockLogger(), mapping)
+	instance, err := parser.ParseHCLConfig(testFile)
+
+	require.NoError(t, err)
+	assert.Equal(t, "t2.micro", instance.InstanceType)
+	assert.Equal(t, "subnet-custom", instance.SubnetID)
+	assert.Equal(t, []string{"sg-12345"}, instance.SecurityGroups)
+
+	// Source locations point at the custom attributes
+	assert.Equal(t, 4, instance.SourceLocations["subnet_id"].Line)
+	assert.Equal(t, 5, instance.SourceLocations["security_groups"].Line)
+
+	// Without the mapping the custom names are not understood
+	_, err = NewParserWithLogger(logging.NewMockLogger()).ParseHCLConfig(testFile)
+	assert.Error(t, err)
+}
@@ -4,11 +4,74 @@ import "github.com/hashicorp/hcl/v2"
 
 // HCLInstance represents the structure of an aws_instance resource in HCL.
 type HCLInstance struct {
-	AMI            string            `hcl:"ami,optional"`
-	InstanceType   string            `hcl:"instance_type"`
-	Tags           map[string]string `hcl:"tags,optional"`
-	SecurityGroups []string          `hcl:"vpc_security_group_ids,optional"`
-	SubnetID       string            `hcl:"subnet_id,optional"`
+	AMI                   string              `hcl:"ami,optional"`
+	InstanceType          string              `hcl:"instance_type"`
+	Tags                  map[string]string   `hcl:"tags,optional"`
+	SecurityGroups        []string            `hcl:"vpc_security_group_ids,optional"`
+	SubnetID              string              `hcl:"subnet_id,optional"`
+	AvailabilityZone      string              `hcl:"availability_zone,optional"`
+	PlacementGroup        string              `hcl:"placement_group,optional"`
+	KeyName               string              `hcl:"key_name,optional"`
+	Tenancy               string              `hcl:"tenancy,optional"`
+	MetadataOptions       *HCLMetadataOptions `hcl:"metadata_options,block"`
+	DisableApiTermination *bool               `hcl:"disable_api_termination,optional"`
+	Hibernation           bool                `hcl:"hibernation,optional"`
+	UserData              *string             `hcl:"user_data,optional"`
+	UserDataBase64        *string             `hcl:"user_data_base64,optional"`
+	ShutdownBehavior      string              `hcl:"instance_initiated_shutdown_behavior,optional"`
+	PrivateIP             string              `hcl:"private_ip,optional"`
+	VolumeTags            map[string]string   `hcl:"volume_tags,optional"`
+
+	InstanceMarketOptions            *HCLInstanceMarketOptions            `hcl:"instance_market_options,block"`
+	CapacityReservationSpecification *HCLCapacityReservationSpecification `hcl:"capacity_reservation_specification,block"`
+	CreditSpecification              *HCLCreditSpecification              `hcl:"credit_specification,block"`
+	EBSBlockDevices                  []*HCLEBSBlockDevice                 `hcl:"ebs_block_device,block"`
+	RootBlockDevice                  *HCLRootBlockDevice                  `hcl:"root_block_device,block"`
+}
+
+// HCLCreditSpecification represents the credit_specification block of an aws_instance or aws_launch_template resource.
+type HCLCreditSpecification struct {
+	CPUCredits string `hcl:"cpu_credits,optional"`
+}
+
+// HCLRootBlockDevice represents the root_block_device block of an aws_instance resource.
+type HCLRootBlockDevice struct {
+	DeleteOnTermination *bool    `hcl:"delete_on_termination,optional"`
+	Remain              hcl.Body `hcl:",remain"` // e.g. volume_size or encrypted, which are not compared
+}
+
+// HCLEBSBlockDevice represents an ebs_block_device block of an aws_instance resource.
+type HCLEBSBlockDevice struct {
+	DeviceName string   `hcl:"device_name"`
+	VolumeSize int      `hcl:"volume_size,optional"`
+	VolumeType string   `hcl:"volume_type,optional"`
+	Remain     hcl.Body `hcl:",remain"` // e.g. encrypted or iops, which are not compared
+}
+
+// HCLInstanceMarketOptions represents the instance_market_options block of an aws_instance or aws_launch_template resource.
+type HCLInstanceMarketOptions struct {
+	MarketType string   `hcl:"market_type,optional"`
+	Remain     hcl.Body `hcl:",remain"` // e.g. spot_options, which are not compared
+}
+
+// HCLCapacityReservationSpecification represents the capacity_reservation_specification block of an
+// aws_instance or aws_launch_template resource.
+type HCLCapacityReservationSpecification struct {
+	Preference string                        `hcl:"capacity_reservation_preference,optional"`
+	Target     *HCLCapacityReservationTarget `hcl:"capacity_reservation_target,block"`
+}
+
+// HCLCapacityReservationTarget represents the capacity_reservation_target block of a capacity reservation specification.
+type HCLCapacityReservationTarget struct {
+	CapacityReservationID string   `hcl:"capacity_reservation_id,optional"`
+	Remain                hcl.Body `hcl:",remain"` // e.g. capacity_reservation_resource_group_arn, which is not compared
+}
+
+// HCLMetadataOptions represents the metadata_options block of an aws_instance resource.
+type HCLMetadataOptions struct {
+	HttpTokens              string `hcl:"http_tokens,optional"`
+	HttpEndpoint            string `hcl:"http_endpoint,optional"`
+	HttpPutResponseHopLimit int    `hcl:"http_put_response_hop_limit,optional"`
 }
 
 // ResourceBlock represents a single resource block in HCL.
@@ -23,3 +86,46 @@ type ConfigFile struct {
 	Resources []*ResourceBlock `hcl:"resource,block"`
 	Remain    hcl.Body         `hcl:",remain"` // Catch-all for other blocks if necessary
 }
+
+// HCLLaunchTemplate represents the attributes of an aws_launch_template resource used as desired state.
+// Attributes without an instance counterpart, e.g. name or block_device_mappings, are ignored.
+type HCLLaunchTemplate struct {
+	ImageID               string                 `hcl:"image_id,optional"`
+	InstanceType          string                 `hcl:"instance_type,optional"`
+	SecurityGroups        []string               `hcl:"vpc_security_group_ids,optional"`
+	KeyName               string                 `hcl:"key_name,optional"`
+	DisableApiTermination *bool                  `hcl:"disable_api_termination,optional"`
+	UserData              *string                `hcl:"user_data,optional"` // Always base64-encoded in launch templates
+	ShutdownBehavior      string                 `hcl:"instance_initiated_shutdown_behavior,optional"`
+	MetadataOptions       *HCLMetadataOptions    `hcl:"metadata_options,block"`
+	Placement             *HCLPlacement          `hcl:"placement,block"`
+	NetworkInterfaces     []*HCLNetworkInterface `hcl:"network_interfaces,block"`
+	TagSpecifications     []*HCLTagSpecification `hcl:"tag_specifications,block"`
+
+	InstanceMarketOptions            *HCLInstanceMarketOptions            `hcl:"instance_market_options,block"`
+	CapacityReservationSpecification *HCLCapacityReservationSpecification `hcl:"capacity_reservation_specification,block"`
+	CreditSpecification              *HCLCreditSpecification              `hcl:"credit_specification,block"`
+
+	Remain hcl.Body `hcl:",remain"`
+}
+
+// HCLPlacement represents the placement block of an aws_launch_template resource.
+type HCLPlacement struct {
+	AvailabilityZone string   `hcl:"availability_zone,optional"`
+	GroupName        string   `hcl:"group_name,optional"`
+	Tenancy          string   `hcl:"tenancy,optional"`
+	Remain           hcl.Body `hcl:",remain"`
+}
+
+// HCLNetworkInterface represents a network_interfaces block of an aws_launch_template resource.
+type HCLNetworkInterface struct {
+	SubnetID       string   `hcl:"subnet_id,optional"`
+	SecurityGroups []string `hcl:"security_groups,optional"`
+	Remain         hcl.Body `hcl:",remain"`
+}
+
+// HCLTagSpecification represents a tag_specifications block of an aws_launch_template resource.
+type HCLTagSpecification struct {
+	ResourceType string            `hcl:"resource_type,optional"`
+	Tags         map[string]string `hcl:"tags,optional"`
+}
@@ -1,10 +1,14 @@
 package terraform
 
 import (
+	"context"
+	"encoding/base64"
 	"fmt"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/gohcl"
 	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 
 	"driftdetector/internal/models"
 	"driftdetector/pkg/logging"
@@ -12,8 +16,19 @@ import (
 
 const awsInstanceType = "aws_instance"
 
+// hclAttributeNames maps HCL attribute and block names to the attribute names used for drift detection where they differ
+var hclAttributeNames = map[string]string{
+	"vpc_security_group_ids":             "security_groups",
+	"user_data_base64":                   "user_data",
+	"instance_market_options":            "instance_lifecycle",
+	"capacity_reservation_specification": "capacity_reservation_id",
+	"ebs_block_device":                   "block_devices",
+}
+
 type DefaultParser struct {
-	logger logging.Logger
+	logger           logging.Logger
+	attributeMapping AttributeMapping
+	concurrency      int // Files of a configuration directory parsed at a time (0 = unlimited)
 }
 
 // NewDefaultParser creates a new instance of DefaultParser
@@ -30,53 +45,295 @@ func NewParserWithLogger(logger logging.Logger) *DefaultParser {
 	}
 }
 
+// NewParserWithAttributeMapping creates a new instance of DefaultParser that reads attributes
+// from the custom HCL attribute names in mapping.
+func NewParserWithAttributeMapping(logger logging.Logger, mapping AttributeMapping) *DefaultParser {
+	return &DefaultParser{
+		logger:           logger,
+		attributeMapping: mapping,
+	}
+}
+
+// WithConcurrency returns a copy of the parser that parses up to limit files of a configuration directory
+// at a time (0 = unlimited)
+func (p DefaultParser) WithConcurrency(limit int) *DefaultParser {
+	p.concurrency = limit
+	return &p
+}
+
+// GetDesiredState implements DesiredStateProvider, returning the first aws_instance resource of the HCL configuration at key.
+func (p DefaultParser) GetDesiredState(ctx context.Context, key string) (*models.InstanceDetails, error) {
+	return getDesiredState(ctx, p, key)
+}
+
 // ParseHCLConfig parses an HCL configuration file and extracts the details of the first aws_instance resource found.
+// The file may be a local path, an s3://bucket/key URL or an http(s):// URL. A local directory is read as
+// a module, with the resources of its .tf files in file name order.
 func (p DefaultParser) ParseHCLConfig(configPath string) (*models.InstanceDetails, error) {
+	cfg, configPath, err := parseConfigPath(configPath, p.concurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find aws_instance resource blocks
+	p.logger.Debug("Searching for %s resources in configuration", awsInstanceType)
+	for _, res := range cfg.Resources {
+		if res.Type == awsInstanceType {
+			p.logger.Info("Found aws_instance resource: %s", res.Name)
+			instances, err := p.decodeInstances(res)
+			if err != nil {
+				return nil, err
+			}
+			if len(instances) == 0 {
+				continue
+			}
+
+			instanceDetails := instances[0]
+			p.logger.Debug("Successfully parsed instance details: type=%s, ami=%s", instanceDetails.InstanceType, instanceDetails.AMI)
+			return instanceDetails, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no '%s' resource found in %s", awsInstanceType, configPath)
+}
+
+// ParseHCLConfigs parses an HCL configuration file and extracts the details of every aws_instance resource,
+// in the order they are defined. Like ParseHCLConfig, resources that cannot be decoded are skipped.
+func (p DefaultParser) ParseHCLConfigs(configPath string) ([]*models.InstanceDetails, error) {
+	cfg, configPath, err := parseConfigPath(configPath, p.concurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	var instances []*models.InstanceDetails
+	for _, res := range cfg.Resources {
+		if res.Type != awsInstanceType {
+			continue
+		}
+		resourceInstances, err := p.decodeInstances(res)
+		if err != nil {
+			return nil, err
+		}
+		instances = append(instances, resourceInstances...)
+	}
+
+	if len(instances) == 0 {
+		return nil, fmt.Errorf("no '%s' resource found in %s", awsInstanceType, configPath)
+	}
+	p.logger.Info("Found %d aws_instance resources", len(instances))
+	return instances, nil
+}
+
+// decodeInstances maps an aws_instance resource to the domain model: one instance, or one per index of count
+// or key of for_each, in order. Instances that cannot be decoded are skipped after logging a warning, and so is
+// the whole resource if its count or for_each cannot be evaluated statically.
+func (p DefaultParser) decodeInstances(res *ResourceBlock) ([]*models.InstanceDetails, error) {
+	body, resourceInstances, err := expandResource(res)
+	if err != nil {
+		p.logger.Warn("Skipping aws_instance '%s', whose instances cannot be determined without a plan: %v", res.Name, err)
+		return nil, nil
+	}
+	if len(resourceInstances) != 1 || resourceInstances[0].evalCtx != nil {
+		p.logger.Debug("Expanded aws_instance '%s' to %d instances", res.Name, len(resourceInstances))
+	}
+
+	var instances []*models.InstanceDetails
+	for _, resourceInstance := range resourceInstances {
+		instanceDetails, err := p.decodeInstance(resourceInstance.name, body, resourceInstance.evalCtx)
+		if err != nil {
+			return nil, err
+		}
+		if instanceDetails != nil {
+			instances = append(instances, instanceDetails)
+		}
+	}
+	return instances, nil
+}
+
+// decodeInstance maps an instance of an aws_instance resource to the domain model, evaluating its attributes in
+// evalCtx. It returns nil, after logging a warning, if the instance cannot be decoded.
+func (p DefaultParser) decodeInstance(name string, body hcl.Body, evalCtx *hcl.EvalContext) (*models.InstanceDetails, error) {
+	// Found an aws_instance, now decode its attributes
+	body = p.attributeMapping.apply(body)
+	var instance HCLInstance
+	diags := gohcl.DecodeBody(body, evalCtx, &instance)
+	if diags.HasErrors() {
+		p.logger.Warn("Failed to decode aws_instance '%s': %s", name, diags.Error())
+		return nil, nil
+	}
+
+	userData, err := decodeUserData(instance)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user data of aws_instance '%s': %w", name, err)
+	}
+
+	// Map to domain model
+	return &models.InstanceDetails{
+		InstanceType:                      instance.InstanceType,
+		AMI:                               instance.AMI,
+		Tags:                              instance.Tags,
+		SecurityGroups:                    instance.SecurityGroups,
+		SubnetID:                          instance.SubnetID,
+		AvailabilityZone:                  instance.AvailabilityZone,
+		PlacementGroup:                    instance.PlacementGroup,
+		KeyName:                           instance.KeyName,
+		Tenancy:                           instance.Tenancy,
+		MetadataOptions:                   convertMetadataOptions(instance.MetadataOptions),
+		DisableApiTermination:             instance.DisableApiTermination,
+		HibernationEnabled:                instance.Hibernation,
+		UserData:                          userData,
+		InstanceInitiatedShutdownBehavior: instance.ShutdownBehavior,
+		InstanceLifecycle:                 convertMarketType(instance.InstanceMarketOptions),
+		CapacityReservationID:             convertCapacityReservationID(instance.CapacityReservationSpecification),
+		VolumeTags:                        instance.VolumeTags,
+		BlockDevices:                      convertEBSBlockDevices(instance.EBSBlockDevices),
+		RootDeleteOnTermination:           convertRootDeleteOnTermination(instance.RootBlockDevice),
+		CPUCredits:                        convertCPUCredits(instance.CreditSpecification),
+		PrivateIP:                         instance.PrivateIP,
+		SourceLocations:                   attributeSourceLocations(body),
+		// InstanceID is not defined in HCL, it is assigned by AWS
+	}, nil
+}
+
+// parseConfigFile reads and parses an HCL configuration file, local or remote, and decodes its top-level
+// resource blocks. It also returns the path to use in messages, with any URL credentials redacted.
+func parseConfigFile(configPath string) (*ConfigFile, string, error) {
+	src, err := readConfigFile(configPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read HCL file %s: %w", displayPath(configPath), err)
+	}
+	configPath = displayPath(configPath)
+
 	parser := hclparse.NewParser()
-	file, diags := parser.ParseHCLFile(configPath)
+	file, diags := parser.ParseHCL(src, configPath)
 
 	if diags.HasErrors() {
-		return nil, fmt.Errorf("failed to parse HCL file %s: %s", configPath, diags.Error())
+		return nil, "", fmt.Errorf("failed to parse HCL file %s: %s", configPath, diags.Error())
 	}
 
 	if file == nil || file.Body == nil {
-		return nil, fmt.Errorf("parsed HCL file is empty or invalid: %s", configPath)
+		return nil, "", fmt.Errorf("parsed HCL file is empty or invalid: %s", configPath)
 	}
 
-	// First, decode the top-level resource blocks
 	var cfg ConfigFile
 	diags = gohcl.DecodeBody(file.Body, nil, &cfg)
 	if diags.HasErrors() {
-		return nil, fmt.Errorf("failed to decode HCL body %s: %s", configPath, diags.Error())
+		return nil, "", fmt.Errorf("failed to decode HCL body %s: %s", configPath, diags.Error())
 	}
+	return &cfg, configPath, nil
+}
 
-	// Find aws_instance resource blocks
-	p.logger.Debug("Searching for %s resources in configuration", awsInstanceType)
-	for _, res := range cfg.Resources {
-		if res.Type == awsInstanceType {
-			p.logger.Info("Found aws_instance resource: %s", res.Name)
-			// Found an aws_instance, now decode its attributes
-			var instance HCLInstance
-			diags = gohcl.DecodeBody(res.Body, nil, &instance)
-			if diags.HasErrors() {
-				p.logger.Warn("Failed to decode aws_instance '%s': %s", res.Name, diags.Error())
-				continue
-			}
+// convertRootDeleteOnTermination returns the delete_on_termination of the root_block_device block, nil if not set
+func convertRootDeleteOnTermination(device *HCLRootBlockDevice) *bool {
+	if device == nil {
+		return nil
+	}
+	return device.DeleteOnTermination
+}
 
-			// Map to domain model
-			instanceDetails := &models.InstanceDetails{
-				InstanceType:   instance.InstanceType,
-				AMI:            instance.AMI,
-				Tags:           instance.Tags,
-				SecurityGroups: instance.SecurityGroups,
-				SubnetID:       instance.SubnetID,
-				// InstanceID is not defined in HCL, it is assigned by AWS
-			}
+// convertCPUCredits returns the cpu_credits of the credit_specification block, empty if not set
+func convertCPUCredits(spec *HCLCreditSpecification) string {
+	if spec == nil {
+		return ""
+	}
+	return spec.CPUCredits
+}
 
-			p.logger.Debug("Successfully parsed instance details: type=%s, ami=%s", instance.InstanceType, instance.AMI)
-			return instanceDetails, nil
+// convertMetadataOptions maps the metadata_options block to the domain model, nil if the block is not set
+func convertMetadataOptions(options *HCLMetadataOptions) *models.MetadataOptions {
+	if options == nil {
+		return nil
+	}
+	return &models.MetadataOptions{
+		HttpTokens:              options.HttpTokens,
+		HttpEndpoint:            options.HttpEndpoint,
+		HttpPutResponseHopLimit: options.HttpPutResponseHopLimit,
+	}
+}
+
+// convertMarketType maps the market type of the instance_market_options block to the instance lifecycle
+// AWS reports, empty for on-demand instances
+func convertMarketType(options *HCLInstanceMarketOptions) string {
+	if options == nil {
+		return ""
+	}
+	return options.MarketType
+}
+
+// convertCapacityReservationID returns the capacity reservation targeted by the capacity_reservation_specification
+// block, empty if none is targeted
+func convertCapacityReservationID(spec *HCLCapacityReservationSpecification) string {
+	if spec == nil || spec.Target == nil {
+		return ""
+	}
+	return spec.Target.CapacityReservationID
+}
+
+// convertEBSBlockDevices maps the ebs_block_device blocks to the domain model, nil if there are none so
+// that volumes attached outside of the instance resource are not reported as drift
+func convertEBSBlockDevices(devices []*HCLEBSBlockDevice) []models.BlockDevice {
+	if len(devices) == 0 {
+		return nil
+	}
+	blockDevices := make([]models.BlockDevice, len(devices))
+	for i, device := range devices {
+		blockDevices[i] = models.BlockDevice{
+			DeviceName: device.DeviceName,
+			VolumeSize: device.VolumeSize,
+			VolumeType: device.VolumeType,
 		}
 	}
+	return blockDevices
+}
 
-	return nil, fmt.Errorf("no '%s' resource found in %s", awsInstanceType, configPath)
+// decodeUserData returns the user data script, set as plain text in user_data or base64-encoded in
+// user_data_base64, or nil if neither is set.
+func decodeUserData(instance HCLInstance) (*string, error) {
+	if instance.UserDataBase64 == nil {
+		return instance.UserData, nil
+	}
+	if instance.UserData != nil {
+		return nil, fmt.Errorf("only one of user_data and user_data_base64 may be set")
+	}
+	decoded, err := base64.StdEncoding.DecodeString(*instance.UserDataBase64)
+	if err != nil {
+		return nil, fmt.Errorf("user_data_base64 is not valid base64: %w", err)
+	}
+	userData := string(decoded)
+	return &userData, nil
+}
+
+// attributeSourceLocations records where each attribute and nested block (e.g. metadata_options) of a
+// resource body is defined, keyed by the attribute name used for drift detection.
+func attributeSourceLocations(body hcl.Body) map[string]models.SourceLocation {
+	// Diagnostics are ignored: JustAttributes complains about nested blocks but still returns the attributes
+	attrs, _ := body.JustAttributes()
+
+	locations := make(map[string]models.SourceLocation, len(attrs))
+	for name, attr := range attrs {
+		if mapped, ok := hclAttributeNames[name]; ok {
+			name = mapped
+		}
+		locations[name] = models.SourceLocation{
+			Filename: attr.Range.Filename,
+			Line:     attr.Range.Start.Line,
+		}
+	}
+
+	if syntaxBody, ok := body.(*hclsyntax.Body); ok {
+		for _, block := range syntaxBody.Blocks {
+			name := block.Type
+			if mapped, ok := hclAttributeNames[name]; ok {
+				name = mapped
+			}
+			if _, ok := locations[name]; ok {
+				continue // Repeated blocks are located at their first occurrence
+			}
+			locations[name] = models.SourceLocation{
+				Filename: block.TypeRange.Filename,
+				Line:     block.TypeRange.Start.Line,
+			}
+		}
+	}
+	return locations
 }
@@ -1,11 +1,15 @@
 package terraform
 
 import (
+	"bytes"
 	"path/filepath"
 	"testing"
 
+	"driftdetector/internal/models"
 	"driftdetector/pkg/logging"
 
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -25,6 +29,9 @@ func TestParseHCLConfig_CompleteInstanceDetails(t *testing.T) {
 	assert.Equal(t, "ami-0c55b159cbfafe1f0", instance.AMI)
 	assert.Equal(t, "t2.micro", instance.InstanceType)
 	assert.Equal(t, "subnet-12345", instance.SubnetID)
+	assert.Equal(t, "us-east-1a", instance.AvailabilityZone)
+	assert.Empty(t, instance.PlacementGroup)
+	assert.Empty(t, instance.Tenancy, "Unset tenancy is left for the comparator to default")
 
 	// Check security groups
 	assert.Len(t, instance.SecurityGroups, 2)
@@ -32,6 +39,214 @@ func TestParseHCLConfig_CompleteInstanceDetails(t *testing.T) {
 	assert.Equal(t, "sg-67890", instance.SecurityGroups[1])
 }
 
+func TestParseHCLConfig_SourceLocations(t *testing.T) {
+	testFile := filepath.Join("testdata", "valid_instance.tf")
+
+	parser := NewParserWithLogger(logging.NewMockLogger())
+	instance, err := parser.ParseHCLConfig(testFile)
+	assert.NoError(t, err)
+
+	// Locations are keyed by the attribute names used for drift detection
+	assert.Equal(t, models.SourceLocation{Filename: testFile, Line: 3}, instance.SourceLocations["instance_type"])
+	assert.Equal(t, 6, instance.SourceLocations["security_groups"].Line)
+	assert.Equal(t, 8, instance.SourceLocations["tags"].Line)
+	assert.NotContains(t, instance.SourceLocations, "placement_group", "Undefined attributes have no location")
+}
+
+func TestParseHCLConfig_Tenancy(t *testing.T) {
+	parser := NewParserWithLogger(logging.NewMockLogger())
+	instance, err := parser.ParseHCLConfig(filepath.Join("testdata", "dedicated_instance.tf"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "dedicated", instance.Tenancy)
+	assert.Equal(t, "deployer", instance.KeyName)
+}
+
+func TestParseHCLConfig_Hibernation(t *testing.T) {
+	parser := NewParserWithLogger(logging.NewMockLogger())
+	instance, err := parser.ParseHCLConfig(filepath.Join("testdata", "hibernation_instance.tf"))
+
+	assert.NoError(t, err)
+	assert.True(t, instance.HibernationEnabled)
+
+	// Hibernation is disabled unless set
+	instance, err = parser.ParseHCLConfig(filepath.Join("testdata", "dedicated_instance.tf"))
+	assert.NoError(t, err)
+	assert.False(t, instance.HibernationEnabled)
+}
+
+func TestParseHCLConfig_ShutdownBehavior(t *testing.T) {
+	parser := NewParserWithLogger(logging.NewMockLogger())
+	instance, err := parser.ParseHCLConfig(filepath.Join("testdata", "shutdown_behavior_instance.tf"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "terminate", instance.InstanceInitiatedShutdownBehavior)
+}
+
+func TestParseHCLConfig_PrivateIP(t *testing.T) {
+	parser := NewParserWithLogger(logging.NewMockLogger())
+	instance, err := parser.ParseHCLConfig(filepath.Join("testdata", "private_ip_instance.tf"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.1.10", instance.PrivateIP)
+}
+
+func TestParseHCLConfig_UnresolvedInstance(t *testing.T) {
+	parser := NewParserWithLogger(logging.NewMockLogger())
+	instance, err := parser.ParseHCLConfig(filepath.Join("testdata", "unresolved_instance.tf"))
+
+	// The resource parses, but there is nothing to compare instances against
+	assert.NoError(t, err)
+	assert.True(t, instance.IsEmpty())
+}
+
+func TestParseHCLConfig_CreditSpecification(t *testing.T) {
+	parser := NewParserWithLogger(logging.NewMockLogger())
+	instance, err := parser.ParseHCLConfig(filepath.Join("testdata", "credit_specification_instance.tf"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "standard", instance.CPUCredits)
+}
+
+func TestParseHCLConfig_MarketAndCapacityReservation(t *testing.T) {
+	parser := NewParserWithLogger(logging.NewMockLogger())
+	instance, err := parser.ParseHCLConfig(filepath.Join("testdata", "spot_instance.tf"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "spot", instance.InstanceLifecycle)
+	assert.Equal(t, "cr-12345", instance.CapacityReservationID)
+	assert.Equal(t, 5, instance.SourceLocations["instance_lifecycle"].Line)
+	assert.Equal(t, 13, instance.SourceLocations["capacity_reservation_id"].Line)
+}
+
+func TestParseHCLConfig_EBSBlockDevices(t *testing.T) {
+	parser := NewParserWithLogger(logging.NewMockLogger())
+
+	instance, err := parser.ParseHCLConfig(filepath.Join("testdata", "ebs_volumes_instance.tf"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []models.BlockDevice{
+		{DeviceName: "/dev/sdf", VolumeSize: 100, VolumeType: "gp3"},
+		{DeviceName: "/dev/sdg"},
+	}, instance.BlockDevices)
+	assert.Equal(t, 5, instance.SourceLocations["block_devices"].Line)
+	assert.Equal(t, false, *instance.RootDeleteOnTermination)
+	assert.Equal(t, map[string]string{"CostCenter": "42"}, instance.VolumeTags)
+
+	// Without ebs_block_device blocks, volumes are not managed by the instance resource
+	instance, err = parser.ParseHCLConfig(filepath.Join("testdata", "valid_instance.tf"))
+	assert.NoError(t, err)
+	assert.Nil(t, instance.BlockDevices)
+	assert.Nil(t, instance.RootDeleteOnTermination)
+}
+
+func TestParseHCLConfigs(t *testing.T) {
+	parser := NewParserWithLogger(logging.NewMockLogger())
+
+	instances, err := parser.ParseHCLConfigs(filepath.Join("testdata", "named_instances.tf"))
+
+	assert.NoError(t, err)
+	if assert.Len(t, instances, 2) {
+		assert.Equal(t, "web", instances[0].Tags["Name"])
+		assert.Equal(t, "t3.small", instances[0].InstanceType)
+		assert.Equal(t, "worker", instances[1].Tags["Name"])
+		assert.Equal(t, 16, instances[1].SourceLocations["instance_type"].Line)
+	}
+
+	_, err = parser.ParseHCLConfigs(filepath.Join("testdata", "no_instance.tf"))
+	assert.Error(t, err)
+}
+
+func TestParseHCLConfigs_CountAndForEach(t *testing.T) {
+	logger := logging.NewMockLogger()
+	var logs bytes.Buffer
+	logger.SetOutput(&logs)
+	parser := NewParserWithLogger(logger)
+
+	instances, err := parser.ParseHCLConfigs(filepath.Join("testdata", "counted_instances.tf"))
+
+	assert.NoError(t, err)
+	var names, types []string
+	for _, instance := range instances {
+		names = append(names, instance.Tags["Name"])
+		types = append(types, instance.InstanceType)
+	}
+	assert.Equal(t, []string{"web-0", "web-1", "worker-batch", "worker-queue", "db-primary", "db-replica"}, names)
+	assert.Equal(t, []string{"t3.small", "t3.small", "c5.large", "c5.large", "r5.large", "r5.xlarge"}, types)
+	assert.Equal(t, 4, instances[0].SourceLocations["instance_type"].Line)
+
+	// A count that depends on a variable is reported rather than read as a single instance
+	assert.Contains(t, logs.String(), "Skipping aws_instance 'scaled'")
+	assert.Contains(t, logs.String(), "count cannot be evaluated statically")
+
+	// The first instance is the desired state of a single resource
+	instance, err := parser.ParseHCLConfig(filepath.Join("testdata", "counted_instances.tf"))
+	assert.NoError(t, err)
+	assert.Equal(t, "web-0", instance.Tags["Name"])
+}
+
+func TestExpandResource_InvalidMetaArguments(t *testing.T) {
+	parser := NewParserWithLogger(logging.NewMockLogger())
+	for name, metaArgument := range map[string]string{
+		"negative count": "count = -1",
+		"string count":   `count = "two"`,
+		"list for_each":  `for_each = ["a", "b"]`,
+		"number set":     "for_each = toset([1, 2])",
+	} {
+		cfg, diags := hclparse.NewParser().ParseHCL([]byte(`resource "aws_instance" "web" {
+  `+metaArgument+`
+  instance_type = "t3.small"
+}`), "main.tf")
+		if !assert.False(t, diags.HasErrors(), name) {
+			continue
+		}
+		var file ConfigFile
+		assert.False(t, gohcl.DecodeBody(cfg.Body, nil, &file).HasErrors(), name)
+		_, _, err := expandResource(file.Resources[0])
+		assert.Error(t, err, name)
+
+		instances, err := parser.decodeInstances(file.Resources[0])
+		assert.NoError(t, err, name)
+		assert.Empty(t, instances, name)
+	}
+}
+
+func TestParseHCLConfig_UserData(t *testing.T) {
+	parser := NewParserWithLogger(logging.NewMockLogger())
+	script := "#!/bin/bash\nyum install -y nginx\n"
+
+	instance, err := parser.ParseHCLConfig(filepath.Join("testdata", "user_data_instance.tf"))
+	assert.NoError(t, err)
+	if assert.NotNil(t, instance.UserData) {
+		assert.Equal(t, script, *instance.UserData)
+	}
+
+	// user_data_base64 is decoded, and located under user_data
+	testFile := filepath.Join("testdata", "user_data_base64_instance.tf")
+	instance, err = parser.ParseHCLConfig(testFile)
+	assert.NoError(t, err)
+	if assert.NotNil(t, instance.UserData) {
+		assert.Equal(t, script, *instance.UserData)
+	}
+	assert.Equal(t, models.SourceLocation{Filename: testFile, Line: 4}, instance.SourceLocations["user_data"])
+}
+
+func TestParseHCLConfig_MetadataOptions(t *testing.T) {
+	testFile := filepath.Join("testdata", "imdsv2_instance.tf")
+
+	parser := NewParserWithLogger(logging.NewMockLogger())
+	instance, err := parser.ParseHCLConfig(testFile)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &models.MetadataOptions{HttpTokens: "required", HttpPutResponseHopLimit: 2}, instance.MetadataOptions)
+	assert.Equal(t, models.SourceLocation{Filename: testFile, Line: 5}, instance.SourceLocations["metadata_options"])
+
+	// Without the block the metadata options are not managed by Terraform
+	instance, err = parser.ParseHCLConfig(filepath.Join("testdata", "valid_instance.tf"))
+	assert.NoError(t, err)
+	assert.Nil(t, instance.MetadataOptions)
+}
+
 func TestParseHCLConfig_NoInstance(t *testing.T) {
 	// Get the path to the test file
 	testFile := filepath.Join("testdata", "no_instance.tf")
@@ -0,0 +1,222 @@
+package terraform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"driftdetector/internal/models"
+	"driftdetector/pkg/logging"
+)
+
+// planDeleteAction is the action of a resource change that destroys the resource
+const planDeleteAction = "delete"
+
+// PlanParser reads the desired state of instances from a Terraform plan in JSON, as output by
+// terraform show -json plan.out. The after state of a planned change is the precise intended state of the
+// instance after apply, with variables resolved and computed values known at plan time filled in.
+// User data is not read, as plans only hold its hash.
+type PlanParser struct {
+	logger logging.Logger
+}
+
+// NewPlanParser creates a new instance of PlanParser
+func NewPlanParser() *PlanParser {
+	return NewPlanParserWithLogger(
+		logging.NewDefaultLogger(),
+	)
+}
+
+// NewPlanParserWithLogger creates a new instance of PlanParser with a specific logger
+func NewPlanParserWithLogger(logger logging.Logger) *PlanParser {
+	return &PlanParser{
+		logger: logger,
+	}
+}
+
+// terraformPlan represents the parts of the JSON plan representation used for drift detection
+type terraformPlan struct {
+	FormatVersion   string               `json:"format_version"`
+	ResourceChanges []planResourceChange `json:"resource_changes"`
+}
+
+// planResourceChange represents an entry of resource_changes
+type planResourceChange struct {
+	Address string `json:"address"`
+	Mode    string `json:"mode"`
+	Type    string `json:"type"`
+	Change  struct {
+		Actions []string        `json:"actions"`
+		After   json.RawMessage `json:"after"`
+	} `json:"change"`
+}
+
+// planInstance represents the after state of an aws_instance resource change. Nested blocks are lists in plans.
+type planInstance struct {
+	AMI                   string            `json:"ami"`
+	InstanceType          string            `json:"instance_type"`
+	Tags                  map[string]string `json:"tags"`
+	TagsAll               map[string]string `json:"tags_all"` // Includes the default_tags of the provider
+	SecurityGroups        []string          `json:"vpc_security_group_ids"`
+	SubnetID              string            `json:"subnet_id"`
+	AvailabilityZone      string            `json:"availability_zone"`
+	PlacementGroup        string            `json:"placement_group"`
+	KeyName               string            `json:"key_name"`
+	Tenancy               string            `json:"tenancy"`
+	DisableApiTermination *bool             `json:"disable_api_termination"`
+	Hibernation           bool              `json:"hibernation"`
+	ShutdownBehavior      string            `json:"instance_initiated_shutdown_behavior"`
+	PrivateIP             string            `json:"private_ip"`
+	PublicIP              string            `json:"public_ip"` // Only known for instances that already exist
+	VolumeTags            map[string]string `json:"volume_tags"`
+	MetadataOptions       []struct {
+		HttpTokens              string `json:"http_tokens"`
+		HttpEndpoint            string `json:"http_endpoint"`
+		HttpPutResponseHopLimit int    `json:"http_put_response_hop_limit"`
+	} `json:"metadata_options"`
+	InstanceMarketOptions []struct {
+		MarketType string `json:"market_type"`
+	} `json:"instance_market_options"`
+	CapacityReservationSpecification []struct {
+		CapacityReservationTarget []struct {
+			CapacityReservationID string `json:"capacity_reservation_id"`
+		} `json:"capacity_reservation_target"`
+	} `json:"capacity_reservation_specification"`
+	CreditSpecification []struct {
+		CPUCredits string `json:"cpu_credits"`
+	} `json:"credit_specification"`
+	EBSBlockDevices []struct {
+		DeviceName string `json:"device_name"`
+		VolumeSize int    `json:"volume_size"`
+		VolumeType string `json:"volume_type"`
+	} `json:"ebs_block_device"`
+	RootBlockDevices []struct {
+		DeleteOnTermination *bool `json:"delete_on_termination"`
+	} `json:"root_block_device"`
+}
+
+// GetDesiredState implements DesiredStateProvider, returning the after state of the first aws_instance resource change of the plan at key.
+func (p PlanParser) GetDesiredState(ctx context.Context, key string) (*models.InstanceDetails, error) {
+	return getDesiredState(ctx, p, key)
+}
+
+// ParseHCLConfig reads the after state of the first aws_instance resource change of the plan at planPath.
+// The name comes from IProvider; the file is a JSON plan, not HCL. Like HCL files, it may be read from a URL.
+func (p PlanParser) ParseHCLConfig(planPath string) (*models.InstanceDetails, error) {
+	instances, err := p.ParseHCLConfigs(planPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(instances) == 0 {
+		return nil, fmt.Errorf("no %s resource change found in %s", awsInstanceType, displayPath(planPath))
+	}
+	return instances[0], nil
+}
+
+// ParseHCLConfigs reads the after state of every aws_instance resource change of the plan at planPath, in
+// plan order. Resources planned for destruction have no after state and are left out.
+func (p PlanParser) ParseHCLConfigs(planPath string) ([]*models.InstanceDetails, error) {
+	data, err := readConfigFile(planPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan file %s: %w", displayPath(planPath), err)
+	}
+	planPath = displayPath(planPath)
+
+	var plan terraformPlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to decode plan file %s: %w", planPath, err)
+	}
+	if plan.FormatVersion == "" {
+		return nil, fmt.Errorf("%s is not a JSON plan: use the output of terraform show -json", planPath)
+	}
+
+	var instances []*models.InstanceDetails
+	for _, change := range plan.ResourceChanges {
+		if change.Type != awsInstanceType || change.Mode == "data" {
+			continue
+		}
+		if slices.Equal(change.Change.Actions, []string{planDeleteAction}) {
+			p.logger.Debug("Skipping %s, which is planned for destruction", change.Address)
+			continue
+		}
+
+		var after planInstance
+		if err := json.Unmarshal(change.Change.After, &after); err != nil {
+			return nil, fmt.Errorf("failed to decode the planned state of %s in %s: %w", change.Address, planPath, err)
+		}
+		p.logger.Info("Found aws_instance resource change: %s", change.Address)
+		instance := after.toInstanceDetails()
+		p.logger.Debug("Successfully parsed instance details: type=%s, ami=%s", instance.InstanceType, instance.AMI)
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
+
+// toInstanceDetails maps the after state to the domain model, like DefaultParser maps HCL resources
+func (after planInstance) toInstanceDetails() *models.InstanceDetails {
+	// tags_all holds the tags AWS will report, including the default tags of the provider
+	tags := after.Tags
+	if after.TagsAll != nil {
+		tags = after.TagsAll
+	}
+
+	var metadataOptions *HCLMetadataOptions
+	if len(after.MetadataOptions) > 0 {
+		metadataOptions = &HCLMetadataOptions{
+			HttpTokens:              after.MetadataOptions[0].HttpTokens,
+			HttpEndpoint:            after.MetadataOptions[0].HttpEndpoint,
+			HttpPutResponseHopLimit: after.MetadataOptions[0].HttpPutResponseHopLimit,
+		}
+	}
+	var marketOptions *HCLInstanceMarketOptions
+	if len(after.InstanceMarketOptions) > 0 {
+		marketOptions = &HCLInstanceMarketOptions{MarketType: after.InstanceMarketOptions[0].MarketType}
+	}
+	var capacityReservation *HCLCapacityReservationSpecification
+	if len(after.CapacityReservationSpecification) > 0 && len(after.CapacityReservationSpecification[0].CapacityReservationTarget) > 0 {
+		capacityReservation = &HCLCapacityReservationSpecification{Target: &HCLCapacityReservationTarget{
+			CapacityReservationID: after.CapacityReservationSpecification[0].CapacityReservationTarget[0].CapacityReservationID,
+		}}
+	}
+	var creditSpecification *HCLCreditSpecification
+	if len(after.CreditSpecification) > 0 {
+		creditSpecification = &HCLCreditSpecification{CPUCredits: after.CreditSpecification[0].CPUCredits}
+	}
+	var rootBlockDevice *HCLRootBlockDevice
+	if len(after.RootBlockDevices) > 0 {
+		rootBlockDevice = &HCLRootBlockDevice{DeleteOnTermination: after.RootBlockDevices[0].DeleteOnTermination}
+	}
+	var blockDevices []*HCLEBSBlockDevice
+	for _, device := range after.EBSBlockDevices {
+		blockDevices = append(blockDevices, &HCLEBSBlockDevice{
+			DeviceName: device.DeviceName,
+			VolumeSize: device.VolumeSize,
+			VolumeType: device.VolumeType,
+		})
+	}
+
+	return &models.InstanceDetails{
+		InstanceType:                      after.InstanceType,
+		AMI:                               after.AMI,
+		Tags:                              tags,
+		SecurityGroups:                    after.SecurityGroups,
+		SubnetID:                          after.SubnetID,
+		AvailabilityZone:                  after.AvailabilityZone,
+		PlacementGroup:                    after.PlacementGroup,
+		KeyName:                           after.KeyName,
+		Tenancy:                           after.Tenancy,
+		MetadataOptions:                   convertMetadataOptions(metadataOptions),
+		DisableApiTermination:             after.DisableApiTermination,
+		HibernationEnabled:                after.Hibernation,
+		InstanceInitiatedShutdownBehavior: after.ShutdownBehavior,
+		InstanceLifecycle:                 convertMarketType(marketOptions),
+		CapacityReservationID:             convertCapacityReservationID(capacityReservation),
+		VolumeTags:                        after.VolumeTags,
+		BlockDevices:                      convertEBSBlockDevices(blockDevices),
+		RootDeleteOnTermination:           convertRootDeleteOnTermination(rootBlockDevice),
+		CPUCredits:                        convertCPUCredits(creditSpecification),
+		PrivateIP:                         after.PrivateIP,
+		PublicIP:                          after.PublicIP,
+	}
+}
@@ -0,0 +1,70 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"driftdetector/internal/models"
+	"driftdetector/pkg/logging"
+)
+
+func TestPlanParser_ParseHCLConfig(t *testing.T) {
+	parser := NewPlanParserWithLogger(logging.NewMockLogger())
+	instance, err := parser.ParseHCLConfig(filepath.Join("testdata", "plan.json"))
+
+	// The first managed aws_instance change is used, with its after state
+	require.NoError(t, err)
+	disabled, deleteOnTermination := true, false
+	assert.Equal(t, &models.InstanceDetails{
+		InstanceType:                      "t3.small",
+		AMI:                               "ami-0c55b159cbfafe1f0",
+		Tags:                              map[string]string{"Name": "web", "Team": "platform"},
+		SecurityGroups:                    []string{"sg-12345", "sg-67890"},
+		SubnetID:                          "subnet-12345",
+		AvailabilityZone:                  "us-east-1a",
+		Tenancy:                           "default",
+		MetadataOptions:                   &models.MetadataOptions{HttpTokens: "required", HttpEndpoint: "enabled", HttpPutResponseHopLimit: 2},
+		DisableApiTermination:             &disabled,
+		HibernationEnabled:                true,
+		InstanceInitiatedShutdownBehavior: "stop",
+		CPUCredits:                        "unlimited",
+		InstanceLifecycle:                 "spot",
+		CapacityReservationID:             "cr-12345",
+		BlockDevices:                      []models.BlockDevice{{DeviceName: "/dev/sdf", VolumeSize: 100, VolumeType: "gp3"}},
+		RootDeleteOnTermination:           &deleteOnTermination,
+	}, instance)
+}
+
+func TestPlanParser_ParseHCLConfigs(t *testing.T) {
+	parser := NewPlanParserWithLogger(logging.NewMockLogger())
+	instances, err := parser.ParseHCLConfigs(filepath.Join("testdata", "plan.json"))
+
+	// Data sources and resources planned for destruction are left out
+	require.NoError(t, err)
+	require.Len(t, instances, 2)
+	assert.Equal(t, "t3.small", instances[0].InstanceType)
+	assert.Equal(t, "t3.large", instances[1].InstanceType)
+	assert.Equal(t, map[string]string{"Name": "api"}, instances[1].Tags, "tags should be used when tags_all is unknown")
+}
+
+func TestPlanParser_Errors(t *testing.T) {
+	parser := NewPlanParserWithLogger(logging.NewMockLogger())
+	dir := t.TempDir()
+
+	_, err := parser.ParseHCLConfig(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err, "Missing files should fail")
+
+	notPlan := filepath.Join(dir, "desired.json")
+	require.NoError(t, os.WriteFile(notPlan, []byte(`{"instance_type": "t2.micro"}`), 0o644))
+	_, err = parser.ParseHCLConfig(notPlan)
+	assert.ErrorContains(t, err, "not a JSON plan")
+
+	noInstances := filepath.Join(dir, "empty_plan.json")
+	require.NoError(t, os.WriteFile(noInstances, []byte(`{"format_version": "1.2", "resource_changes": []}`), 0o644))
+	_, err = parser.ParseHCLConfig(noInstances)
+	assert.ErrorContains(t, err, "no aws_instance resource change found")
+}
@@ -0,0 +1,122 @@
+package terraform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+const (
+	// remoteFetchTimeout bounds the time spent fetching a remote configuration file
+	remoteFetchTimeout = 30 * time.Second
+	// defaultS3Region is used when no region is configured, requests for buckets in other regions are retried
+	// in the region S3 reports for the bucket
+	defaultS3Region = "us-east-1"
+	// bucketRegionHeader is the header S3 reports the region of a bucket in when it is requested in another region
+	bucketRegionHeader = "X-Amz-Bucket-Region"
+)
+
+// readConfigFile reads a configuration file from a local path, an s3://bucket/key URL or an http(s):// URL.
+// S3 objects are fetched with the default AWS credentials; HTTP(S) URLs may carry basic auth credentials.
+func readConfigFile(path string) ([]byte, error) {
+	if !isRemotePath(path) {
+		return os.ReadFile(path)
+	}
+
+	u, err := url.Parse(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), remoteFetchTimeout)
+	defer cancel()
+
+	if u.Scheme == "s3" {
+		return readS3Object(ctx, u)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// isRemotePath returns true if the path is an s3:// or http(s):// URL rather than a local file
+func isRemotePath(path string) bool {
+	return strings.HasPrefix(path, "s3://") || strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// readS3Object reads the object of an s3://bucket/key URL with the default AWS configuration, so the
+// endpoint of the partition, FIPS and custom endpoints are resolved by the SDK. A custom endpoint set with
+// AWS_ENDPOINT_URL, such as a local S3-compatible store, is addressed path-style.
+func readS3Object(ctx context.Context, u *url.URL) ([]byte, error) {
+	bucket, key := u.Host, strings.TrimPrefix(u.Path, "/")
+	if bucket == "" || key == "" {
+		return nil, fmt.Errorf("S3 URLs must be of the form s3://bucket/key")
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load AWS SDK config: %w", err)
+	}
+	if cfg.Region == "" {
+		cfg.Region = defaultS3Region
+	}
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		o.UsePathStyle = cfg.BaseEndpoint != nil
+		// Configuration files uploaded without a checksum are common and not worth a warning on every run
+		o.DisableLogOutputChecksumValidationSkipped = true
+	})
+
+	input := &s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)}
+	resp, err := client.GetObject(ctx, input)
+	if region := bucketRegion(err); region != "" && region != cfg.Region {
+		// The bucket is in another region than the configured one
+		resp, err = client.GetObject(ctx, input, func(o *s3.Options) { o.Region = region })
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// bucketRegion returns the region S3 reports for the bucket in the response of a failed request, if any
+func bucketRegion(err error) string {
+	var respErr *awshttp.ResponseError
+	if !errors.As(err, &respErr) || respErr.HTTPResponse() == nil {
+		return ""
+	}
+	return respErr.HTTPResponse().Header.Get(bucketRegionHeader)
+}
+
+// displayPath returns the path with any credentials of a URL redacted, for messages and source locations
+func displayPath(path string) string {
+	u, err := url.Parse(path)
+	if err != nil || u.User == nil {
+		return path
+	}
+	return u.Redacted()
+}
@@ -0,0 +1,136 @@
+package terraform
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"driftdetector/internal/models"
+	"driftdetector/internal/terraform/mocks"
+	"driftdetector/pkg/logging"
+)
+
+// serveFile serves the testdata file for every request, after checking it with the given function
+func serveFile(t *testing.T, name string, check func(r *http.Request)) *httptest.Server {
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		check(r)
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestReadConfigFile_HTTP(t *testing.T) {
+	server := serveFile(t, "valid_instance.tf", func(r *http.Request) {
+		assert.Equal(t, "/configs/main.tf", r.URL.Path)
+	})
+
+	parser := NewParserWithLogger(logging.NewMockLogger())
+	instance, err := parser.ParseHCLConfig(server.URL + "/configs/main.tf")
+
+	require.NoError(t, err)
+	assert.Equal(t, "t2.micro", instance.InstanceType)
+}
+
+func TestReadConfigFile_HTTPBasicAuth(t *testing.T) {
+	server := serveFile(t, "desired_instance.json", func(r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "ci", user)
+		assert.Equal(t, "secret", password)
+	})
+
+	url := strings.Replace(server.URL, "http://", "http://ci:secret@", 1) + "/desired.json"
+	parser := NewJSONParserWithLogger(logging.NewMockLogger())
+	instance, err := parser.ParseHCLConfig(url)
+
+	require.NoError(t, err)
+	assert.Equal(t, "t2.micro", instance.InstanceType)
+}
+
+func TestReadConfigFile_HTTPErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	url := strings.Replace(server.URL, "http://", "http://ci:secret@", 1) + "/main.tf"
+	parser := NewParserWithLogger(logging.NewMockLogger())
+	_, err := parser.ParseHCLConfig(url)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "404")
+	assert.NotContains(t, err.Error(), "secret", "Credentials must not be leaked in errors")
+}
+
+func TestReadConfigFile_S3(t *testing.T) {
+	server := serveFile(t, "valid_instance.tf", func(r *http.Request) {
+		// Custom endpoints are addressed path-style and requests are signed
+		assert.Equal(t, "/my-bucket/envs/prod/main.tf", r.URL.Path)
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256"))
+		assert.Contains(t, r.Header.Get("Authorization"), "/eu-west-1/s3/aws4_request")
+	})
+
+	t.Setenv("AWS_ENDPOINT_URL", server.URL)
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_REGION", "eu-west-1")
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+
+	parser := NewParserWithLogger(logging.NewMockLogger())
+	instance, err := parser.ParseHCLConfig("s3://my-bucket/envs/prod/main.tf")
+
+	require.NoError(t, err)
+	assert.Equal(t, "t2.micro", instance.InstanceType)
+}
+
+// TestReadConfigFile_S3OtherRegion tests that a bucket in another region than the configured one is read from its region
+func TestReadConfigFile_S3OtherRegion(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "valid_instance.tf"))
+	require.NoError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Authorization"), "/eu-central-1/s3/aws4_request") {
+			w.Header().Set("X-Amz-Bucket-Region", "eu-central-1")
+			w.WriteHeader(http.StatusMovedPermanently)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	t.Setenv("AWS_ENDPOINT_URL", server.URL)
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_REGION", "eu-west-1")
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+
+	data, err = readConfigFile("s3://my-bucket/main.tf")
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "t2.micro")
+}
+
+func TestReadConfigFile_InvalidS3URL(t *testing.T) {
+	_, err := readConfigFile("s3://my-bucket")
+	assert.ErrorContains(t, err, "s3://bucket/key")
+}
+
+func TestCachingParser_RemoteNotCached(t *testing.T) {
+	url := "https://example.com/main.tf"
+	parserMock := mocks.NewIProvider(t)
+	parserMock.On("ParseHCLConfig", url).Return(&models.InstanceDetails{}, nil).Twice()
+
+	parser := NewCachingParser(parserMock)
+	_, err := parser.ParseHCLConfig(url)
+	assert.NoError(t, err)
+	_, err = parser.ParseHCLConfig(url)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,38 @@
+resource "aws_instance" "web" {
+  count         = 2
+  ami           = "ami-0c55b159cbfafe1f0"
+  instance_type = "t3.small"
+
+  tags = {
+    Name = "web-${count.index}"
+  }
+}
+
+resource "aws_instance" "worker" {
+  for_each      = toset(["batch", "queue"])
+  ami           = "ami-0c55b159cbfafe1f0"
+  instance_type = "c5.large"
+
+  tags = {
+    Name = "worker-${each.key}"
+  }
+}
+
+resource "aws_instance" "db" {
+  for_each = {
+    primary = "r5.large"
+    replica = "r5.xlarge"
+  }
+  ami           = "ami-0c55b159cbfafe1f0"
+  instance_type = each.value
+
+  tags = {
+    Name = "db-${each.key}"
+  }
+}
+
+resource "aws_instance" "scaled" {
+  count         = var.replicas
+  ami           = "ami-0c55b159cbfafe1f0"
+  instance_type = "t3.micro"
+}
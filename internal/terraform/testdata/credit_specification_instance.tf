@@ -0,0 +1,8 @@
+resource "aws_instance" "burstable" {
+  ami           = "ami-0c55b159cbfafe1f0"
+  instance_type = "t3.medium"
+
+  credit_specification {
+    cpu_credits = "standard"
+  }
+}
@@ -0,0 +1,6 @@
+resource "aws_instance" "wrapped" {
+  ami           = "ami-0c55b159cbfafe1f0"
+  instance_type = "t2.micro"
+  subnet        = "subnet-custom"
+  sg_ids        = ["sg-12345"]
+}
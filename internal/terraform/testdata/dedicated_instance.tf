@@ -0,0 +1,6 @@
+resource "aws_instance" "dedicated" {
+  ami           = "ami-0c55b159cbfafe1f0"
+  instance_type = "m5.large"
+  tenancy       = "dedicated"
+  key_name      = "deployer"
+}
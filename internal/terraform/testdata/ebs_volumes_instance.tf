@@ -0,0 +1,24 @@
+resource "aws_instance" "database" {
+  ami           = "ami-0c55b159cbfafe1f0"
+  instance_type = "r5.large"
+
+  ebs_block_device {
+    device_name = "/dev/sdf"
+    volume_size = 100
+    volume_type = "gp3"
+    encrypted   = true
+  }
+
+  ebs_block_device {
+    device_name = "/dev/sdg"
+  }
+
+  root_block_device {
+    volume_size           = 50
+    delete_on_termination = false
+  }
+
+  volume_tags = {
+    CostCenter = "42"
+  }
+}
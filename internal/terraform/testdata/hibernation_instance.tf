@@ -0,0 +1,9 @@
+resource "aws_instance" "hibernating" {
+  ami           = "ami-0c55b159cbfafe1f0"
+  instance_type = "m5.large"
+  hibernation   = true
+
+  root_block_device {
+    encrypted = true
+  }
+}
@@ -0,0 +1,65 @@
+resource "aws_launch_template" "web" {
+  name_prefix   = "web-"
+  image_id      = "ami-0c55b159cbfafe1f0"
+  instance_type = "t3.small"
+  user_data     = "IyEvYmluL2Jhc2gKZWNobyBoZWxsbwo="
+
+  disable_api_termination = true
+
+  placement {
+    availability_zone = "us-east-1a"
+    tenancy           = "default"
+  }
+
+  network_interfaces {
+    device_index                = 0
+    subnet_id                   = "subnet-12345"
+    security_groups             = ["sg-12345"]
+    associate_public_ip_address = false
+  }
+
+  metadata_options {
+    http_tokens = "required"
+  }
+
+  block_device_mappings {
+    device_name = "/dev/xvda"
+
+    ebs {
+      volume_size = 20
+    }
+  }
+
+  tag_specifications {
+    resource_type = "instance"
+    tags = {
+      Name = "web"
+      Env  = "prod"
+    }
+  }
+
+  tag_specifications {
+    resource_type = "volume"
+    tags = {
+      Name = "web-volume"
+    }
+  }
+
+  tags = {
+    Team = "platform"
+  }
+
+  credit_specification {
+    cpu_credits = "standard"
+  }
+}
+
+resource "aws_launch_template" "worker" {
+  image_id               = "ami-worker"
+  instance_type          = "c5.large"
+  vpc_security_group_ids = ["sg-worker"]
+}
+
+resource "aws_launch_template" "mixed" {
+  image_id = "ami-mixed"
+}
@@ -0,0 +1,21 @@
+resource "aws_instance" "web" {
+  ami           = "ami-0c55b159cbfafe1f0"
+  instance_type = "t3.small"
+
+  tags = {
+    Name = "web"
+  }
+}
+
+resource "aws_security_group" "web" {
+  name = "web"
+}
+
+resource "aws_instance" "worker" {
+  ami           = "ami-0c55b159cbfafe1f0"
+  instance_type = "c5.large"
+
+  tags = {
+    Name = "worker"
+  }
+}
@@ -0,0 +1,6 @@
+resource "aws_instance" "dns" {
+  ami           = "ami-0c55b159cbfafe1f0"
+  instance_type = "t3.small"
+  subnet_id     = "subnet-12345"
+  private_ip    = "10.0.1.10"
+}
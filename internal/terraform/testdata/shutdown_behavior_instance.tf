@@ -0,0 +1,5 @@
+resource "aws_instance" "batch" {
+  ami                                  = "ami-0c55b159cbfafe1f0"
+  instance_type                        = "c5.large"
+  instance_initiated_shutdown_behavior = "terminate"
+}
@@ -0,0 +1,18 @@
+resource "aws_instance" "spot" {
+  ami           = "ami-0c55b159cbfafe1f0"
+  instance_type = "m5.large"
+
+  instance_market_options {
+    market_type = "spot"
+
+    spot_options {
+      max_price = "0.05"
+    }
+  }
+
+  capacity_reservation_specification {
+    capacity_reservation_target {
+      capacity_reservation_id = "cr-12345"
+    }
+  }
+}
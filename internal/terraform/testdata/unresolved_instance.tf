@@ -0,0 +1,5 @@
+resource "aws_instance" "placeholder" {
+  # Filled in by a wrapper script that was not run
+  instance_type = ""
+  tags          = {}
+}
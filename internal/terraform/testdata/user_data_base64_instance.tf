@@ -0,0 +1,5 @@
+resource "aws_instance" "web" {
+  ami              = "ami-0c55b159cbfafe1f0"
+  instance_type    = "t2.micro"
+  user_data_base64 = "IyEvYmluL2Jhc2gKeXVtIGluc3RhbGwgLXkgbmdpbngK"
+}
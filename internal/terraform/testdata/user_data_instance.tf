@@ -0,0 +1,8 @@
+resource "aws_instance" "web" {
+  ami           = "ami-0c55b159cbfafe1f0"
+  instance_type = "t2.micro"
+  user_data     = <<-SCRIPT
+    #!/bin/bash
+    yum install -y nginx
+  SCRIPT
+}